@@ -3,13 +3,16 @@ package all
 
 import (
 	"github.com/ardanlabs/service/app/domain/auditapp"
+	"github.com/ardanlabs/service/app/domain/categoryapp"
 	"github.com/ardanlabs/service/app/domain/checkapp"
 	"github.com/ardanlabs/service/app/domain/homeapp"
+	"github.com/ardanlabs/service/app/domain/maintenanceapp"
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/domain/rawapp"
 	"github.com/ardanlabs/service/app/domain/tranapp"
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/domain/vproductapp"
+	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/app/sdk/mux"
 	"github.com/ardanlabs/service/foundation/web"
 )
@@ -24,10 +27,19 @@ type add struct{}
 
 // Add implements the RouterAdder interface.
 func (add) Add(app *web.App, cfg mux.Config) {
+	// A nil breaker has to stay a nil interface for requests to never be
+	// failed fast.
+	var productBreaker mid.CircuitBreaker
+	if cfg.SalesConfig.ProductBreaker != nil {
+		productBreaker = cfg.SalesConfig.ProductBreaker
+	}
+
 	checkapp.Routes(app, checkapp.Config{
-		Build: cfg.Build,
-		Log:   cfg.Log,
-		DB:    cfg.DB,
+		Build:          cfg.Build,
+		Log:            cfg.Log,
+		DB:             cfg.DB,
+		ProductBus:     cfg.BusConfig.ProductBus,
+		ProductBreaker: productBreaker,
 	})
 
 	homeapp.Routes(app, homeapp.Config{
@@ -36,10 +48,54 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		AuthClient: cfg.SalesConfig.AuthClient,
 	})
 
+	// A nil signer has to stay a nil interface for the image routes to be
+	// left out.
+	var imageSigner productapp.ImageSigner
+	if cfg.SalesConfig.ProductImageSigner != nil {
+		imageSigner = cfg.SalesConfig.ProductImageSigner
+	}
+
+	// A nil mode has to stay a nil interface for writes to be accepted.
+	var readOnly mid.ReadOnlyMode
+	if cfg.SalesConfig.Maintenance != nil {
+		readOnly = cfg.SalesConfig.Maintenance
+
+		maintenanceapp.Routes(app, maintenanceapp.Config{
+			Log:        cfg.Log,
+			AuthClient: cfg.SalesConfig.AuthClient,
+			Switch:     cfg.SalesConfig.Maintenance,
+		})
+	}
+
 	productapp.Routes(app, productapp.Config{
-		Log:        cfg.Log,
-		ProductBus: cfg.BusConfig.ProductBus,
-		AuthClient: cfg.SalesConfig.AuthClient,
+		Log:            cfg.Log,
+		DB:             cfg.DB,
+		ProductBus:     cfg.BusConfig.ProductBus,
+		CategoryBus:    cfg.BusConfig.CategoryBus,
+		AuditBus:       cfg.BusConfig.AuditBus,
+		AuthClient:     cfg.SalesConfig.AuthClient,
+		CreateLimiter:  cfg.SalesConfig.CreateLimiter,
+		CacheMaxAge:    cfg.SalesConfig.ProductCacheMaxAge,
+		MaxRowsPerPage: cfg.SalesConfig.ProductMaxRowsPerPage,
+		Defaults: productapp.Defaults{
+			Quantity:    cfg.SalesConfig.ProductDefaultQuantity,
+			GenerateSKU: cfg.SalesConfig.ProductGenerateSKU,
+		},
+		ImageSigner:         imageSigner,
+		ImageUploadTTL:      cfg.SalesConfig.ProductImageUploadTTL,
+		RequireDeleteReason: cfg.SalesConfig.ProductRequireDeleteReason,
+		StrictDelete:        cfg.SalesConfig.ProductStrictDelete,
+		ReadOnly:            readOnly,
+		Events:              cfg.SalesConfig.ProductEvents,
+		MaxBodySize:         cfg.SalesConfig.ProductMaxBodySize,
+		ImportMaxBodySize:   cfg.SalesConfig.ProductImportMaxBodySize,
+		QueryTimeout:        cfg.SalesConfig.ProductQueryTimeout,
+		BulkQueryTimeout:    cfg.SalesConfig.ProductBulkQueryTimeout,
+		TolerateCountErrors: cfg.SalesConfig.ProductTolerateCountErrors,
+		ImportSignature:     cfg.SalesConfig.ProductImportSignature,
+		Breaker:             productBreaker,
+		ValidateSchema:      cfg.SalesConfig.ProductValidateSchema,
+		FieldNaming:         cfg.SalesConfig.ProductFieldNaming,
 	})
 
 	rawapp.Routes(app)
@@ -64,6 +120,12 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		AuthClient: cfg.SalesConfig.AuthClient,
 	})
 
+	categoryapp.Routes(app, categoryapp.Config{
+		Log:         cfg.Log,
+		CategoryBus: cfg.BusConfig.CategoryBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+	})
+
 	vproductapp.Routes(app, vproductapp.Config{
 		Log:         cfg.Log,
 		UserBus:     cfg.BusConfig.UserBus,
@@ -3,6 +3,7 @@ package crud
 
 import (
 	"github.com/ardanlabs/service/app/domain/auditapp"
+	"github.com/ardanlabs/service/app/domain/categoryapp"
 	"github.com/ardanlabs/service/app/domain/checkapp"
 	"github.com/ardanlabs/service/app/domain/homeapp"
 	"github.com/ardanlabs/service/app/domain/productapp"
@@ -23,9 +24,10 @@ type add struct{}
 // Add implements the RouterAdder interface.
 func (add) Add(app *web.App, cfg mux.Config) {
 	checkapp.Routes(app, checkapp.Config{
-		Build: cfg.Build,
-		Log:   cfg.Log,
-		DB:    cfg.DB,
+		Build:      cfg.Build,
+		Log:        cfg.Log,
+		DB:         cfg.DB,
+		ProductBus: cfg.BusConfig.ProductBus,
 	})
 
 	homeapp.Routes(app, homeapp.Config{
@@ -33,9 +35,29 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		AuthClient: cfg.SalesConfig.AuthClient,
 	})
 
+	// A nil signer has to stay a nil interface for the image routes to be
+	// left out.
+	var imageSigner productapp.ImageSigner
+	if cfg.SalesConfig.ProductImageSigner != nil {
+		imageSigner = cfg.SalesConfig.ProductImageSigner
+	}
+
 	productapp.Routes(app, productapp.Config{
-		ProductBus: cfg.BusConfig.ProductBus,
-		AuthClient: cfg.SalesConfig.AuthClient,
+		Log:            cfg.Log,
+		DB:             cfg.DB,
+		ProductBus:     cfg.BusConfig.ProductBus,
+		CategoryBus:    cfg.BusConfig.CategoryBus,
+		AuditBus:       cfg.BusConfig.AuditBus,
+		AuthClient:     cfg.SalesConfig.AuthClient,
+		CreateLimiter:  cfg.SalesConfig.CreateLimiter,
+		CacheMaxAge:    cfg.SalesConfig.ProductCacheMaxAge,
+		MaxRowsPerPage: cfg.SalesConfig.ProductMaxRowsPerPage,
+		Defaults: productapp.Defaults{
+			Quantity:    cfg.SalesConfig.ProductDefaultQuantity,
+			GenerateSKU: cfg.SalesConfig.ProductGenerateSKU,
+		},
+		ImageSigner:    imageSigner,
+		ImageUploadTTL: cfg.SalesConfig.ProductImageUploadTTL,
 	})
 
 	tranapp.Routes(app, tranapp.Config{
@@ -56,4 +78,10 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		AuditBus:   cfg.BusConfig.AuditBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
 	})
+
+	categoryapp.Routes(app, categoryapp.Config{
+		Log:         cfg.Log,
+		CategoryBus: cfg.BusConfig.CategoryBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,15 +18,26 @@ import (
 	"github.com/ardanlabs/service/api/services/sales/build/all"
 	"github.com/ardanlabs/service/api/services/sales/build/crud"
 	"github.com/ardanlabs/service/api/services/sales/build/reporting"
+	"github.com/ardanlabs/service/app/domain/productgrpc"
+	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/debug"
+	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/app/sdk/mux"
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/domain/categorybus/stores/categorydb"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productbreaker"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productcache"
 	"github.com/ardanlabs/service/business/domain/productbus/stores/productdb"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productflight"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productmetrics"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productretry"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productslow"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/extensions/useraudit"
 	"github.com/ardanlabs/service/business/domain/userbus/extensions/userotel"
@@ -34,9 +46,21 @@ import (
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductdb"
 	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/eventstream"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/webhook"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/breaker"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/maintenance"
+	"github.com/ardanlabs/service/foundation/nonce"
+	"github.com/ardanlabs/service/foundation/objstore"
 	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/ratelimit"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 /*
@@ -62,7 +86,7 @@ func main() {
 		return otel.GetTraceID(ctx)
 	}
 
-	log = logger.NewWithEvents(os.Stdout, logger.LevelInfo, "SALES", traceIDFn, events)
+	log = logger.NewWithEvents(os.Stdout, logger.LevelInfo, "SALES", traceIDFn, events, logger.WithRequestID(web.GetRequestID))
 
 	// -------------------------------------------------------------------------
 
@@ -93,6 +117,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 			ShutdownTimeout    time.Duration `conf:"default:20s"`
 			APIHost            string        `conf:"default:0.0.0.0:3000"`
 			DebugHost          string        `conf:"default:0.0.0.0:3010"`
+			GRPCHost           string        `conf:"default:0.0.0.0:3020"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
 		}
 		Auth struct {
@@ -115,6 +140,141 @@ func run(ctx context.Context, log *logger.Logger) error {
 			// 0.05 should be enough for most systems. Some might want to have
 			// this even lower.
 		}
+		Cache struct {
+			ProductMaxAge time.Duration `conf:"default:60s"`
+			ProductSize   int           `conf:"default:10000"`
+			ProductTTL    time.Duration `conf:"default:1m"`
+			// Products cached for longer than ProductSoftTTL are served
+			// while they're refreshed in the background. Set it to 0 to
+			// only refresh products once ProductTTL expires them.
+			ProductSoftTTL time.Duration `conf:"default:30s"`
+			// The total of a product query is reused for the following
+			// pages for ProductCountTTL. Set it to 0 to count every page.
+			ProductCountTTL time.Duration `conf:"default:0s"`
+		}
+		Body struct {
+			// Product requests with a body larger than ProductMaxSize
+			// bytes are rejected, imports are allowed up to
+			// ProductImportMaxSize bytes.
+			ProductMaxSize       int64 `conf:"default:1048576"`
+			ProductImportMaxSize int64 `conf:"default:67108864"`
+		}
+		Timeout struct {
+			// Product requests taking longer than ProductQuery fail with
+			// a 504 and their queries are canceled. The export and bulk
+			// routes get ProductBulkQuery instead. Set them to 0 for no
+			// timeout.
+			ProductQuery     time.Duration `conf:"default:5s"`
+			ProductBulkQuery time.Duration `conf:"default:30s"`
+		}
+		Paging struct {
+			ProductMaxRows int `conf:"default:100"`
+
+			// A product query whose total can't be counted still returns
+			// its page, with a total of -1, when ProductTolerateCountErrors
+			// is set. It fails with a 500 otherwise.
+			ProductTolerateCountErrors bool `conf:"default:false"`
+		}
+		Naming struct {
+			// ProductFields is the naming of the product fields, camelCase
+			// or snake_case, for clients that don't set X-Field-Naming.
+			ProductFields string `conf:"default:camelCase"`
+		}
+		Defaults struct {
+			ProductQuantity    int  `conf:"default:0"`
+			ProductGenerateSKU bool `conf:"default:false"`
+
+			// ProductSKUPattern generates the skus of the products created
+			// without one, with {seq:N}, {name:N} and {rand:N} placeholders.
+			ProductSKUPattern string `conf:"default:PRD-{rand:16}"`
+		}
+		Search struct {
+			// Fuzzy product searches match the names with at least
+			// ProductSimilarity of trigram similarity, between 0 and 1.
+			ProductSimilarity float64 `conf:"default:0.3"`
+		}
+		Popular struct {
+			// The popular products are ranked again every ProductRefresh
+			// and counted live once the ranking is older than
+			// ProductMaxAge. Set ProductRefresh to 0 to only rank them on
+			// /v1/products/popular/refresh.
+			ProductRefresh time.Duration `conf:"default:10m"`
+			ProductMaxAge  time.Duration `conf:"default:30m"`
+		}
+		Names struct {
+			// The tenants listed, or every tenant when * is listed, can't
+			// give two products the same name.
+			ProductUniqueTenants []string
+		}
+		Maintenance struct {
+			// The service starts read-only when ReadOnly is set. It can
+			// be flipped at runtime on /v1/maintenance.
+			ReadOnly bool `conf:"default:false"`
+		}
+		Audit struct {
+			// Products can only be deleted with a reason when
+			// ProductRequireDeleteReason is set.
+			ProductRequireDeleteReason bool `conf:"default:false"`
+		}
+		Schema struct {
+			// The bodies creating and updating products are checked
+			// against their JSON Schema when ProductValidate is set.
+			ProductValidate bool `conf:"default:false"`
+		}
+		Delete struct {
+			// Deleting a product that's already deleted returns a 404
+			// instead of a 204 when ProductStrict is set.
+			ProductStrict bool `conf:"default:false"`
+		}
+		Retry struct {
+			ProductAttempts   int           `conf:"default:3"`
+			ProductBackoff    time.Duration `conf:"default:50ms"`
+			ProductMaxBackoff time.Duration `conf:"default:1s"`
+		}
+		Breaker struct {
+			// The product store fails fast once ProductErrorRate of the
+			// calls made within ProductWindow fail, once there were at
+			// least ProductMinCalls of them, and is probed again after
+			// ProductCooldown. Set ProductErrorRate to 0 to never fail fast.
+			ProductErrorRate float64       `conf:"default:0.5"`
+			ProductMinCalls  int           `conf:"default:20"`
+			ProductWindow    time.Duration `conf:"default:10s"`
+			ProductCooldown  time.Duration `conf:"default:5s"`
+		}
+		SlowQuery struct {
+			// Product store operations taking at least ProductThreshold
+			// are logged. Set it to 0 to log none of them.
+			ProductThreshold time.Duration `conf:"default:200ms"`
+		}
+		RateLimit struct {
+			CreateRate  float64 `conf:"default:1"`
+			CreateBurst int     `conf:"default:10"`
+		}
+		Images struct {
+			// Product images can only be uploaded when a bucket is set.
+			BucketURL       string
+			PublicURL       string
+			Region          string `conf:"default:us-east-1"`
+			AccessKeyID     string
+			SecretAccessKey string        `conf:"mask"`
+			UploadTTL       time.Duration `conf:"default:15m"`
+		}
+		Webhook struct {
+			URLs    []string
+			Secret  string        `conf:"mask"`
+			Retries int           `conf:"default:3"`
+			Backoff time.Duration `conf:"default:1s"`
+		}
+		Signing struct {
+			// Product imports are authenticated with a signature made with
+			// ProductImportSecret instead of a bearer token when it's set.
+			// The signed imports act as ProductImportUserID of
+			// ProductImportTenantID, the default tenant when it's empty.
+			ProductImportSecret   string `conf:"mask"`
+			ProductImportUserID   string
+			ProductImportTenantID string
+			ProductImportSkew     time.Duration `conf:"default:5m"`
+		}
 	}{
 		Version: conf.Version{
 			Build: build,
@@ -175,12 +335,112 @@ func run(ctx context.Context, log *logger.Logger) error {
 	userAuditExt := useraudit.NewExtension(auditbus.NewBusiness(log, auditdb.NewStore(log, db)))
 	userStorage := usercache.NewStore(log, userdb.NewStore(log, db), time.Minute)
 
+	// Slow operations are timed per attempt so a retried operation only
+	// logs the attempts that were slow.
+	var productDB productbus.Storer = productdb.NewStore(log, db)
+	if cfg.SlowQuery.ProductThreshold > 0 {
+		productDB = productslow.NewStore(log, productDB, cfg.SlowQuery.ProductThreshold)
+	}
+
+	productRetry := productretry.NewStore(log, productDB, productretry.Policy{
+		Attempts:   cfg.Retry.ProductAttempts,
+		Backoff:    cfg.Retry.ProductBackoff,
+		MaxBackoff: cfg.Retry.ProductMaxBackoff,
+	})
+
+	// The breaker sees a retried write as a single call that only fails
+	// once its retries are exhausted.
+	var productBreaker *breaker.Breaker
+	var productBreakerStore productbus.Storer = productRetry
+	if cfg.Breaker.ProductErrorRate > 0 {
+		productBreaker = breaker.New(breaker.Policy{
+			ErrorRate: cfg.Breaker.ProductErrorRate,
+			MinCalls:  cfg.Breaker.ProductMinCalls,
+			Window:    cfg.Breaker.ProductWindow,
+			Cooldown:  cfg.Breaker.ProductCooldown,
+		})
+		productBreakerStore = productbreaker.NewStore(productRetry, productBreaker)
+	}
+
+	// Shared lookups sit in front of the metrics so the metrics count the
+	// round trips that reach the database, and the cache sits in front of
+	// the shared lookups so only misses are shared.
+	productFlight := productflight.NewStore(productmetrics.NewStore(productBreakerStore, prometheus.DefaultRegisterer))
+	productStorage := productcache.NewStore(productFlight, productcache.NewMemory(cfg.Cache.ProductSize, cfg.Cache.ProductTTL), productcache.Policy{
+		SoftTTL: cfg.Cache.ProductSoftTTL,
+		HardTTL: cfg.Cache.ProductTTL,
+	})
+
 	delegate := delegate.New(log)
 	auditBus := auditbus.NewBusiness(log, auditdb.NewStore(log, db))
 	userBus := userbus.NewBusiness(log, delegate, userStorage, userOtelExt, userAuditExt)
-	productBus := productbus.NewBusiness(log, userBus, delegate, productdb.NewStore(log, db))
+	uniqueNames, err := uniqueNamePolicy(cfg.Names.ProductUniqueTenants)
+	if err != nil {
+		return fmt.Errorf("parsing unique name tenants: %w", err)
+	}
+
+	skuPattern, err := productbus.ParseSKUPattern(cfg.Defaults.ProductSKUPattern)
+	if err != nil {
+		return fmt.Errorf("parsing product sku pattern: %w", err)
+	}
+
+	fieldNaming, err := web.ParseNaming(cfg.Naming.ProductFields)
+	if err != nil {
+		return fmt.Errorf("parsing product field naming: %w", err)
+	}
+
+	productBus := productbus.NewBusiness(log, userBus, delegate, productStorage,
+		productbus.WithCountCache(cfg.Cache.ProductCountTTL),
+		productbus.WithUniqueNames(uniqueNames),
+		productbus.WithSimilarityThreshold(cfg.Search.ProductSimilarity),
+		productbus.WithSKUPattern(skuPattern),
+		productbus.WithPopularMaxAge(cfg.Popular.ProductMaxAge),
+	)
 	homeBus := homebus.NewBusiness(log, userBus, delegate, homedb.NewStore(log, db))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(log, db))
+	categoryBus := categorybus.NewBusiness(log, categorydb.NewStore(log, db))
+
+	// -------------------------------------------------------------------------
+	// Initialize webhook support
+
+	if len(cfg.Webhook.URLs) > 0 {
+		log.Info(ctx, "startup", "status", "initializing webhook support", "urls", cfg.Webhook.URLs)
+
+		dispatcher := webhook.New(webhook.Config{
+			Log:     log,
+			URLs:    cfg.Webhook.URLs,
+			Secret:  cfg.Webhook.Secret,
+			Retries: cfg.Webhook.Retries,
+			Backoff: cfg.Webhook.Backoff,
+		})
+
+		dispatcher.Register(delegate, productbus.DomainName, productbus.ActionCreated, productbus.ActionUpdated, productbus.ActionDeleted)
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(ctx, cfg.Web.ShutdownTimeout)
+			defer cancel()
+
+			if err := dispatcher.Shutdown(ctx); err != nil {
+				log.Error(ctx, "shutdown", "status", "webhook dispatcher stopped", "err", err)
+			}
+		}()
+	}
+
+	// -------------------------------------------------------------------------
+	// Start popular products refresh
+
+	if cfg.Popular.ProductRefresh > 0 {
+		log.Info(ctx, "startup", "status", "starting popular products refresh", "interval", cfg.Popular.ProductRefresh)
+
+		stopRefresh := productBus.StartPopularRefresh(ctx, cfg.Popular.ProductRefresh)
+		defer stopRefresh()
+	}
+
+	// -------------------------------------------------------------------------
+	// Initialize event stream support
+
+	productEvents := eventstream.New(log)
+	productEvents.Register(delegate, productbus.DomainName, productbus.ActionCreated, productbus.ActionUpdated, productbus.ActionDeleted)
 
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
@@ -230,6 +490,17 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	// The REST and gRPC servers share the mode so flipping it on
+	// /v1/maintenance stops the writes of both.
+	maintenanceMode := maintenance.New(cfg.Maintenance.ReadOnly)
+
+	// A default quantity of zero in the configuration keeps the quantity
+	// required.
+	var productDefaultQuantity *int
+	if cfg.Defaults.ProductQuantity > 0 {
+		productDefaultQuantity = &cfg.Defaults.ProductQuantity
+	}
+
 	cfgMux := mux.Config{
 		Build:  build,
 		Log:    log,
@@ -237,16 +508,71 @@ func run(ctx context.Context, log *logger.Logger) error {
 		Tracer: tracer,
 		BusConfig: mux.BusConfig{
 			AuditBus:    auditBus,
+			CategoryBus: categoryBus,
 			UserBus:     userBus,
 			ProductBus:  productBus,
 			HomeBus:     homeBus,
 			VProductBus: vproductBus,
 		},
 		SalesConfig: mux.SalesConfig{
-			AuthClient: authClient,
+			AuthClient:                 authClient,
+			CreateLimiter:              ratelimit.NewMemory(cfg.RateLimit.CreateRate, cfg.RateLimit.CreateBurst),
+			ProductCacheMaxAge:         cfg.Cache.ProductMaxAge,
+			ProductMaxRowsPerPage:      cfg.Paging.ProductMaxRows,
+			ProductDefaultQuantity:     productDefaultQuantity,
+			ProductGenerateSKU:         cfg.Defaults.ProductGenerateSKU,
+			ProductRequireDeleteReason: cfg.Audit.ProductRequireDeleteReason,
+			ProductStrictDelete:        cfg.Delete.ProductStrict,
+			ProductBreaker:             productBreaker,
+			ProductValidateSchema:      cfg.Schema.ProductValidate,
+			Maintenance:                maintenanceMode,
+			ProductEvents:              productEvents,
+			ProductMaxBodySize:         cfg.Body.ProductMaxSize,
+			ProductImportMaxBodySize:   cfg.Body.ProductImportMaxSize,
+			ProductQueryTimeout:        cfg.Timeout.ProductQuery,
+			ProductBulkQueryTimeout:    cfg.Timeout.ProductBulkQuery,
+			ProductTolerateCountErrors: cfg.Paging.ProductTolerateCountErrors,
+			ProductFieldNaming:         fieldNaming,
 		},
 	}
 
+	if cfg.Images.BucketURL != "" {
+		signer, err := objstore.New(objstore.Config{
+			BucketURL:       cfg.Images.BucketURL,
+			PublicURL:       cfg.Images.PublicURL,
+			Region:          cfg.Images.Region,
+			AccessKeyID:     cfg.Images.AccessKeyID,
+			SecretAccessKey: cfg.Images.SecretAccessKey,
+		})
+		if err != nil {
+			return fmt.Errorf("constructing image signer: %w", err)
+		}
+
+		cfgMux.SalesConfig.ProductImageSigner = signer
+		cfgMux.SalesConfig.ProductImageUploadTTL = cfg.Images.UploadTTL
+	}
+
+	if cfg.Signing.ProductImportSecret != "" {
+		signature := mid.SignatureConfig{
+			Secret: []byte(cfg.Signing.ProductImportSecret),
+			Claims: auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Subject: cfg.Signing.ProductImportUserID,
+				},
+				Roles:    []string{role.User.String()},
+				TenantID: cfg.Signing.ProductImportTenantID,
+			},
+			Skew:   cfg.Signing.ProductImportSkew,
+			Nonces: nonce.NewMemory(),
+		}
+
+		if err := signature.Validate(); err != nil {
+			return fmt.Errorf("validating product import signing: %w", err)
+		}
+
+		cfgMux.SalesConfig.ProductImportSignature = &signature
+	}
+
 	webAPI := mux.WebAPI(cfgMux,
 		buildRoutes(),
 		mux.WithCORS(cfg.Web.CORSAllowedOrigins),
@@ -262,7 +588,11 @@ func run(ctx context.Context, log *logger.Logger) error {
 		ErrorLog:     logger.NewStdLogger(log, logger.LevelError),
 	}
 
-	serverErrors := make(chan error, 1)
+	// Streams only end once their clients go away so they're ended as the
+	// shutdown starts for it not to wait on them.
+	api.RegisterOnShutdown(productEvents.Close)
+
+	serverErrors := make(chan error, 2)
 
 	go func() {
 		log.Info(ctx, "startup", "status", "api router started", "host", api.Addr)
@@ -270,6 +600,29 @@ func run(ctx context.Context, log *logger.Logger) error {
 		serverErrors <- api.ListenAndServe()
 	}()
 
+	// -------------------------------------------------------------------------
+	// Start gRPC Service
+
+	log.Info(ctx, "startup", "status", "initializing gRPC support")
+
+	grpcServer := productgrpc.NewServer(productgrpc.Config{
+		Log:        log,
+		ProductBus: productBus,
+		AuthClient: authClient,
+		ReadOnly:   maintenanceMode,
+	})
+
+	lis, err := net.Listen("tcp", cfg.Web.GRPCHost)
+	if err != nil {
+		return fmt.Errorf("grpc listen: %w", err)
+	}
+
+	go func() {
+		log.Info(ctx, "startup", "status", "grpc router started", "host", cfg.Web.GRPCHost)
+
+		serverErrors <- grpcServer.Serve(lis)
+	}()
+
 	// -------------------------------------------------------------------------
 	// Shutdown
 
@@ -284,10 +637,24 @@ func run(ctx context.Context, log *logger.Logger) error {
 		ctx, cancel := context.WithTimeout(ctx, cfg.Web.ShutdownTimeout)
 		defer cancel()
 
+		grpcStopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+
 		if err := api.Shutdown(ctx); err != nil {
 			api.Close()
+			grpcServer.Stop()
 			return fmt.Errorf("could not stop server gracefully: %w", err)
 		}
+
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+			return fmt.Errorf("could not stop grpc server gracefully: %w", ctx.Err())
+		}
 	}
 
 	return nil
@@ -316,3 +683,32 @@ func buildRoutes() mux.RouteAdder {
 
 	return all.Routes()
 }
+
+// uniqueNamePolicy returns the policy reporting the tenants whose products
+// must have unique names. A nil policy is returned when no tenant is listed.
+func uniqueNamePolicy(tenants []string) (func(tenantID uuid.UUID) bool, error) {
+	if len(tenants) == 0 {
+		return nil, nil
+	}
+
+	ids := make(map[uuid.UUID]struct{}, len(tenants))
+	for _, tenant := range tenants {
+		if tenant == "*" {
+			return func(uuid.UUID) bool { return true }, nil
+		}
+
+		id, err := uuid.Parse(tenant)
+		if err != nil {
+			return nil, fmt.Errorf("tenant[%s]: %w", tenant, err)
+		}
+
+		ids[id] = struct{}{}
+	}
+
+	policy := func(tenantID uuid.UUID) bool {
+		_, exists := ids[tenantID]
+		return exists
+	}
+
+	return policy, nil
+}
@@ -29,6 +29,7 @@ func query200(sd apitest.SeedData) []apitest.Table {
 				Page:        1,
 				RowsPerPage: 10,
 				Total:       len(sd.Admins[0].Audits),
+				Pages:       1,
 				Items:       toAppAudits(sd.Admins[0].Audits),
 			},
 			CmpFunc: func(got any, exp any) string {
@@ -77,7 +78,7 @@ func query400(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusBadRequest,
 			Method:     http.MethodGet,
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: ser_id\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: ser_id, must be one of action, actor_id, obj_domain, obj_id, obj_name, timestamp\"}]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -0,0 +1,36 @@
+package category_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+)
+
+func Test_Category(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Category")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, query200(sd), "query-200")
+	test.Run(t, query400(sd), "query-400")
+	test.Run(t, productExpand200(sd), "product-expand-200")
+	test.Run(t, productFilter200(sd), "product-filter-200")
+
+	test.Run(t, create200(sd), "create-200")
+	test.Run(t, create400(sd), "create-400")
+	test.Run(t, create401(sd), "create-401")
+	test.Run(t, create409(sd), "create-409")
+
+	test.Run(t, delete200(sd), "delete-200")
+	test.Run(t, delete404(sd), "delete-404")
+	test.Run(t, delete409(sd), "delete-409")
+}
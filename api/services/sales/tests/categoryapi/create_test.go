@@ -0,0 +1,121 @@
+package category_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/categoryapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func create200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/categories",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &categoryapp.NewCategory{
+				Name: "Instruments",
+			},
+			GotResp: &categoryapp.Category{},
+			ExpResp: &categoryapp.Category{
+				Name: "Instruments",
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*categoryapp.Category)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*categoryapp.Category)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/categories",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &categoryapp.NewCategory{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "emptytoken",
+			URL:        "/v1/categories",
+			Token:      "&nbsp;",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "error parsing token: token contains an invalid number of segments"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "wronguser",
+			URL:        "/v1/categories",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			Input: &categoryapp.NewCategory{
+				Name: "Drums",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create409(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "duplicate",
+			URL:        "/v1/categories",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusConflict,
+			Input: &categoryapp.NewCategory{
+				Name: sd.Categories[0].Name.String(),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Aborted, "category name already exists"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,65 @@
+package category_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func delete200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "unused",
+			URL:        fmt.Sprintf("/v1/categories/%s", sd.Categories[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+	}
+
+	return table
+}
+
+func delete404(sd apitest.SeedData) []apitest.Table {
+	id := uuid.New()
+
+	table := []apitest.Table{
+		{
+			Name:       "missing",
+			URL:        fmt.Sprintf("/v1/categories/%s", id),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "category not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func delete409(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "inuse",
+			URL:        fmt.Sprintf("/v1/categories/%s", sd.Categories[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusConflict,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Aborted, "category still has products"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
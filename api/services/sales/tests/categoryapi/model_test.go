@@ -0,0 +1,26 @@
+package category_test
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/categoryapp"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+)
+
+func toAppCategory(bus categorybus.Category) categoryapp.Category {
+	return categoryapp.Category{
+		ID:          bus.ID.String(),
+		Name:        bus.Name.String(),
+		DateCreated: bus.DateCreated.Format(time.RFC3339),
+		DateUpdated: bus.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppCategories(cats []categorybus.Category) []categoryapp.Category {
+	app := make([]categoryapp.Category, len(cats))
+	for i, cat := range cats {
+		app[i] = toAppCategory(cat)
+	}
+
+	return app
+}
@@ -0,0 +1,171 @@
+package category_test
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/categoryapp"
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/google/go-cmp/cmp"
+)
+
+func query200(sd apitest.SeedData) []apitest.Table {
+	cats := make([]categorybus.Category, len(sd.Categories))
+	copy(cats, sd.Categories)
+
+	sort.Slice(cats, func(i, j int) bool {
+		return cats[i].Name.String() <= cats[j].Name.String()
+	})
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/categories?page=1&rows=10&orderBy=name,ASC",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[categoryapp.Category]{},
+			ExpResp: &query.Result[categoryapp.Category]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(cats),
+				Pages:       1,
+				Items:       toAppCategories(cats),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func query400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-query-filter",
+			URL:        "/v1/categories?page=1&rows=10&category_id=123",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"category_id\",\"error\":\"invalid UUID length: 3\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-orderby-value",
+			URL:        "/v1/categories?page=1&rows=10&orderBy=cat_id,ASC",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: cat_id, must be one of category_id, name\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func toAppProduct(sd apitest.SeedData) productapp.Product {
+	prd := sd.Users[0].Products[0]
+
+	return productapp.Product{
+		ID:          prd.ID.String(),
+		UserID:      prd.UserID.String(),
+		Name:        prd.Name.String(),
+		Cost:        prd.Cost.String(),
+		Quantity:    prd.Quantity.Value(),
+		CategoryID:  sd.Categories[0].ID.String(),
+		DateCreated: prd.DateCreated.Format(time.RFC3339),
+		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func productExpand200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	expanded := toAppProduct(sd)
+	expanded.CategoryName = sd.Categories[0].Name.String()
+
+	table := []apitest.Table{
+		{
+			Name:       "expand",
+			URL:        fmt.Sprintf("/v1/products/%s?expand=category", prd.ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.Product{},
+			ExpResp:    &expanded,
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-expand",
+			URL:        fmt.Sprintf("/v1/products/%s?expand=user", prd.ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"expand\",\"error\":\"unknown relation: \\\"user\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func productFilter200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "category",
+			URL:        fmt.Sprintf("/v1/products?page=1&rows=10&category_id=%s", sd.Categories[0].ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Pages:       1,
+				Items:       []productapp.Product{toAppProduct(sd)},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "empty",
+			URL:        fmt.Sprintf("/v1/products?page=1&rows=10&category_id=%s", sd.Categories[1].ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Items:       []productapp.Product{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
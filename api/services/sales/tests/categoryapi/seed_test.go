@@ -0,0 +1,75 @@
+package category_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/business/types/sku"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	cats, err := categorybus.TestGenerateSeedCategories(ctx, 2, busDomain.Category)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding categories : %w", err)
+	}
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	np := productbus.NewProduct{
+		UserID:     usrs[0].ID,
+		SKU:        sku.MustParse("GTR-001"),
+		Name:       name.MustParse("Guitar"),
+		Cost:       money.MustParse(10.34),
+		Quantity:   quantity.MustParse(10),
+		CategoryID: &cats[0].ID,
+	}
+
+	prd, err := busDomain.Product.Create(ctx, np)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:     usrs[0],
+		Products: []productbus.Product{prd},
+		Token:    apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := apitest.SeedData{
+		Users:      []apitest.User{tu1},
+		Admins:     []apitest.User{tu2},
+		Categories: cats,
+	}
+
+	return sd, nil
+}
@@ -34,6 +34,7 @@ func query200(sd apitest.SeedData) []apitest.Table {
 				Page:        1,
 				RowsPerPage: 10,
 				Total:       len(hmes),
+				Pages:       1,
 				Items:       toAppHomes(hmes),
 			},
 			CmpFunc: func(got any, exp any) string {
@@ -66,7 +67,7 @@ func query400(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusBadRequest,
 			Method:     http.MethodGet,
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: ome_id\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: ome_id, must be one of home_id, type, user_id\"}]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -0,0 +1,143 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+)
+
+func auditTrail200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/%s/audit?page=1&rows=10", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.AuditEntry]{},
+			ExpResp:    &query.Result[productapp.AuditEntry]{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.AuditEntry])
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.Total != 1 || len(gotResp.Items) != 1 {
+					return fmt.Sprintf("got %d entries, exp 1", gotResp.Total)
+				}
+
+				entry := gotResp.Items[0]
+
+				expEntry := productapp.AuditEntry{
+					ID:        entry.ID,
+					ActorID:   sd.Users[0].ID.String(),
+					Action:    "updated",
+					Before:    entry.Before,
+					After:     entry.After,
+					Timestamp: entry.Timestamp,
+				}
+
+				if diff := cmp.Diff(entry, expEntry); diff != "" {
+					return diff
+				}
+
+				if entry.Before == nil || entry.Before.Name != sd.Users[0].Products[0].Name.String() {
+					return "before snapshot should hold the original product"
+				}
+
+				if entry.After == nil || entry.After.Name != "Guitar" {
+					return "after snapshot should hold the updated product"
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func auditTrailDelete200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "reason",
+			URL:        fmt.Sprintf("/v1/products/%s/audit?page=1&rows=100", sd.Admins[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.AuditEntry]{},
+			ExpResp:    &query.Result[productapp.AuditEntry]{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.AuditEntry])
+				if !exists {
+					return "error occurred"
+				}
+
+				if len(gotResp.Items) == 0 {
+					return "got no entries, exp the delete"
+				}
+
+				// The delete is the latest change of the product.
+				entry := gotResp.Items[len(gotResp.Items)-1]
+
+				expEntry := productapp.AuditEntry{
+					ID:        entry.ID,
+					ActorID:   sd.Admins[0].ID.String(),
+					Action:    "deleted",
+					Before:    entry.Before,
+					Reason:    "discontinued",
+					Timestamp: entry.Timestamp,
+				}
+
+				return cmp.Diff(entry, expEntry)
+			},
+		},
+	}
+
+	return table
+}
+
+func auditTrail401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "notadmin",
+			URL:        fmt.Sprintf("/v1/products/%s/audit", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusUnauthorized,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func auditTrail404(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "other-tenant",
+			URL:        fmt.Sprintf("/v1/products/%s/audit", prd.ID),
+			Token:      sd.Admins[1].Token,
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "query: productID[%s]: product not found", prd.ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,105 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func clone200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "copy",
+			URL:        fmt.Sprintf("/v1/products/%s/clone", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.Product{},
+			ExpResp:    toClone(prd, prd.Name.String(), prd.Quantity.Value()),
+			CmpFunc:    cmpClone(prd),
+		},
+		{
+			Name:       "overrides",
+			URL:        fmt.Sprintf("/v1/products/%s/clone", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.UpdateProduct{
+				Name:     dbtest.StringPointer("Cloned Guitar"),
+				Quantity: dbtest.IntPointer(3),
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: toClone(prd, "Cloned Guitar", 3),
+			CmpFunc: cmpClone(prd),
+		},
+	}
+
+	return table
+}
+
+func clone404(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "other-tenant",
+			URL:        fmt.Sprintf("/v1/products/%s/clone", prd.ID),
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "query: productID[%s]: db: product not found", prd.ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+// toClone returns the clone expected from the source. The fields the
+// database assigns are filled from the response by cmpClone.
+func toClone(prd productbus.Product, name string, quantity int) *productapp.Product {
+	exp := toAppProductPtr(prd)
+	exp.Name = name
+	exp.Quantity = quantity
+	exp.ImageURL = ""
+
+	return exp
+}
+
+// cmpClone checks the clone is a new product with a SKU of its own.
+func cmpClone(src productbus.Product) func(got any, exp any) string {
+	return func(got any, exp any) string {
+		gotResp, exists := got.(*productapp.Product)
+		if !exists {
+			return "error occurred"
+		}
+
+		if gotResp.ID == src.ID.String() {
+			return "should get back a new product"
+		}
+
+		if gotResp.SKU == src.SKU.String() {
+			return fmt.Sprintf("should get a new sku, got %s", gotResp.SKU)
+		}
+
+		expResp := exp.(*productapp.Product)
+		expResp.ID = gotResp.ID
+		expResp.SKU = gotResp.SKU
+		expResp.DateCreated = gotResp.DateCreated
+		expResp.DateUpdated = gotResp.DateUpdated
+		expResp.Warnings = gotResp.Warnings
+
+		return cmp.Diff(gotResp, expResp)
+	}
+}
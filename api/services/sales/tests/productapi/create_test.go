@@ -1,15 +1,24 @@
 package product_test
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 func create200(sd apitest.SeedData) []apitest.Table {
+	idemKey := uuid.NewString()
+	var idemPrdID string
+
 	table := []apitest.Table{
 		{
 			Name:       "basic",
@@ -18,15 +27,17 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			Method:     http.MethodPost,
 			StatusCode: http.StatusOK,
 			Input: &productapp.NewProduct{
+				SKU:      "GTR-001",
 				Name:     "Guitar",
-				Cost:     10.34,
-				Quantity: 10,
+				Cost:     "10.34",
+				Quantity: dbtest.IntPointer(10),
 			},
 			GotResp: &productapp.Product{},
 			ExpResp: &productapp.Product{
+				SKU:      "GTR-001",
 				Name:     "Guitar",
 				UserID:   sd.Users[0].ID.String(),
-				Cost:     10.34,
+				Cost:     "10.34",
 				Quantity: 10,
 			},
 			CmpFunc: func(got any, exp any) string {
@@ -44,13 +55,176 @@ func create200(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:       "whitespace",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:      "CSE-001",
+				Name:     "  Guitar \t  Case ",
+				Cost:     "20.00",
+				Quantity: dbtest.IntPointer(5),
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{
+				SKU:      "CSE-001",
+				Name:     "Guitar Case",
+				UserID:   sd.Users[0].ID.String(),
+				Cost:     "20.00",
+				Quantity: 5,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "warnings",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:      "SMP-001",
+				Name:     "Sample",
+				Cost:     "0.00",
+				Quantity: dbtest.IntPointer(200000),
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{
+				SKU:      "SMP-001",
+				Name:     "Sample",
+				UserID:   sd.Users[0].ID.String(),
+				Cost:     "0.00",
+				Quantity: 200000,
+				Warnings: []productapp.Warning{
+					{Field: "cost", Message: "cost is zero"},
+					{Field: "quantity", Message: "quantity is unusually large"},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:  "idempotent-first",
+			URL:   "/v1/products",
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"Idempotency-Key": idemKey,
+			},
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:      "VLN-001",
+				Name:     "Violin",
+				Cost:     "99.99",
+				Quantity: dbtest.IntPointer(3),
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				idemPrdID = gotResp.ID
+
+				return ""
+			},
+		},
+		{
+			Name:  "idempotent-retry",
+			URL:   "/v1/products",
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"Idempotency-Key": idemKey,
+			},
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:      "VLN-001",
+				Name:     "Violin",
+				Cost:     "99.99",
+				Quantity: dbtest.IntPointer(3),
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.ID != idemPrdID {
+					return fmt.Sprintf("got product %s, exp the original product %s", gotResp.ID, idemPrdID)
+				}
+
+				return ""
+			},
+		},
 	}
 
 	return table
 }
 
 func create400(sd apitest.SeedData) []apitest.Table {
+	var fieldErrors errs.FieldErrors
+	fieldErrors.Add("sku", errors.New("invalid sku \"bad sku\""))
+	fieldErrors.Add("name", errors.New("invalid name \"a$\""))
+	fieldErrors.Add("cost", errors.New("invalid money \"10.345\": more than two decimal places"))
+	fieldErrors.Add("quantity", errors.New("invalid quantity 2000000"))
+
 	table := []apitest.Table{
+		{
+			Name:       "invalid-fields",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.NewProduct{
+				SKU:      "bad sku",
+				Name:     "a$",
+				Cost:     "10.345",
+				Quantity: dbtest.IntPointer(2000000),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.New(errs.InvalidArgument, fmt.Errorf("parse: %w", fieldErrors)),
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*errs.Error)
+				expResp := exp.(*errs.Error)
+
+				if diff := cmp.Diff(gotResp.Fields, expResp.Fields); diff != "" {
+					return diff
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
 		{
 			Name:       "missing-input",
 			URL:        "/v1/products",
@@ -59,7 +233,222 @@ func create400(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusBadRequest,
 			Input:      &productapp.NewProduct{},
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"cost\",\"error\":\"cost is a required field\"},{\"field\":\"quantity\",\"error\":\"quantity is a required field\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"sku\",\"error\":\"sku is a required field\"},{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"cost\",\"error\":\"cost is a required field\"},{\"field\":\"quantity\",\"error\":\"quantity is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "unknown-field",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: map[string]any{
+				"sku":     "GTR-0001",
+				"name":    "Guitar",
+				"cost":    "10.34",
+				"quanity": 10,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.NewFieldErrors("quanity", errors.New("unknown field")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create413(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "body-too-large",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Input: &productapp.NewProduct{
+				Name:     strings.Repeat("a", 2<<20),
+				Cost:     "10.00",
+				Quantity: dbtest.IntPointer(1),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PayloadTooLarge, "request body must be at most %d bytes", 1<<20),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkCreate200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "partial",
+			URL:        "/v1/products/bulk",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProducts{
+				{SKU: "DRM-001", Name: "Drums", Cost: "200.50", Quantity: dbtest.IntPointer(2)},
+				{SKU: "DRM-002", Cost: "5.00", Quantity: dbtest.IntPointer(1)},
+			},
+			GotResp: &productapp.BulkResult{},
+			ExpResp: &productapp.BulkResult{
+				Items: []productapp.Product{
+					{
+						SKU:      "DRM-001",
+						Name:     "Drums",
+						UserID:   sd.Users[0].ID.String(),
+						Cost:     "200.50",
+						Quantity: 2,
+					},
+				},
+				Total: 1,
+				Errors: []productapp.BulkError{
+					{Index: 1, Error: "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]"},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.BulkResult)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.BulkResult)
+				if len(gotResp.Items) != len(expResp.Items) {
+					return cmp.Diff(gotResp, expResp)
+				}
+
+				for i := range expResp.Items {
+					expResp.Items[i].ID = gotResp.Items[i].ID
+					expResp.Items[i].DateCreated = gotResp.Items[i].DateCreated
+					expResp.Items[i].DateUpdated = gotResp.Items[i].DateUpdated
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkCreate207(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "partial",
+			URL:        "/v1/products/bulk?mode=partial",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusMultiStatus,
+			Input: &productapp.NewProducts{
+				{SKU: "DRM-101", Name: "Drums", Cost: "200.50", Quantity: dbtest.IntPointer(2)},
+				{SKU: sd.Users[0].Products[0].SKU.String(), Name: "Drums", Cost: "200.50", Quantity: dbtest.IntPointer(2)},
+				{SKU: "DRM-102", Cost: "5.00", Quantity: dbtest.IntPointer(1)},
+				{SKU: "DRM-103", Name: "Cymbals", Cost: "50.00", Quantity: dbtest.IntPointer(4)},
+			},
+			GotResp: &productapp.BulkMultiStatus{},
+			ExpResp: &productapp.BulkMultiStatus{
+				Mode:      "partial",
+				Succeeded: 2,
+				Failed:    2,
+				Items: []productapp.BulkItemResult{
+					{Index: 0, Status: http.StatusCreated},
+					{Index: 1, Status: http.StatusConflict, Error: productbus.ErrDuplicateSKU.Error()},
+					{Index: 2, Status: http.StatusBadRequest, Error: "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]"},
+					{Index: 3, Status: http.StatusCreated},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.BulkMultiStatus)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.BulkMultiStatus)
+				expResp.Note = gotResp.Note
+
+				if len(gotResp.Items) != len(expResp.Items) {
+					return cmp.Diff(gotResp, expResp)
+				}
+
+				for i, item := range gotResp.Items {
+					if item.Status == http.StatusCreated {
+						if item.ID == "" {
+							return fmt.Sprintf("item %d should have an id", i)
+						}
+						expResp.Items[i].ID = item.ID
+					}
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkCreate400(sd apitest.SeedData) []apitest.Table {
+	var fieldErrors errs.FieldErrors
+	fieldErrors.Add("[1]", errors.New("validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]"))
+
+	table := []apitest.Table{
+		{
+			Name:       "atomic",
+			URL:        "/v1/products/bulk?mode=atomic",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.NewProducts{
+				{SKU: "DRM-001", Name: "Drums", Cost: "200.50", Quantity: dbtest.IntPointer(2)},
+				{SKU: "DRM-002", Cost: "5.00", Quantity: dbtest.IntPointer(1)},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: fieldErrors.ToError(),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "empty",
+			URL:        "/v1/products/bulk",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &productapp.NewProducts{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "no products provided"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create409(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "duplicate-sku",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusConflict,
+			Input: &productapp.NewProduct{
+				SKU:      sd.Users[0].Products[0].SKU.String(),
+				Name:     "Guitar",
+				Cost:     "10.34",
+				Quantity: dbtest.IntPointer(10),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.New(errs.Aborted, productbus.ErrDuplicateSKU),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
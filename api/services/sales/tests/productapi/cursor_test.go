@@ -0,0 +1,62 @@
+package product_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/google/uuid"
+)
+
+// Test_ProductCursorETag asks twice for the same cursor window and checks the
+// second request, made conditional on the tag of the first, isn't answered
+// with the window again.
+func Test_ProductCursorETag(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_ProductCursorETag")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	url := "/v1/products?rows=2&cursor=" + productCursor(uuid.Nil)
+
+	get := func(inm string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.Header.Set("Authorization", "Bearer "+sd.Admins[0].Token)
+		if inm != "" {
+			r.Header.Set("If-None-Match", inm)
+		}
+
+		w := httptest.NewRecorder()
+		test.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := get("")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of %d for the response : %d", http.StatusOK, w.Code)
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("Should receive an ETag header for the cursor window")
+	}
+
+	w = get(etag)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("Should receive a status code of %d for the response : %d", http.StatusNotModified, w.Code)
+	}
+
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Fatalf("Should receive the ETag %q again : %q", etag, got)
+	}
+}
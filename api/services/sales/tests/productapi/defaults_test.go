@@ -0,0 +1,164 @@
+package product_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Test_ProductDefaults runs the product routes with a custom defaults policy
+// in place of the built-in one.
+func Test_ProductDefaults(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.NewWithRoutes(t, "Test_ProductDefaults", defaultsRoutes{defaults: stockPolicy{}})
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, defaults200(sd), "defaults-200")
+	test.Run(t, defaults400(sd), "defaults-400")
+}
+
+// =============================================================================
+
+// stockPolicy stocks the products created without a quantity and describes
+// the ones created without a description. It doesn't generate SKUs.
+type stockPolicy struct{}
+
+func (stockPolicy) Apply(ctx context.Context, np productapp.NewProduct) productapp.NewProduct {
+	if np.Quantity == nil {
+		quantity := 5
+		np.Quantity = &quantity
+	}
+
+	if np.Description == "" {
+		np.Description = "Restocked weekly"
+	}
+
+	return np
+}
+
+// defaultsRoutes binds the product routes with the specified policy.
+type defaultsRoutes struct {
+	defaults productapp.DefaultsPolicy
+}
+
+func (r defaultsRoutes) Add(app *web.App, cfg mux.Config) {
+	productapp.Routes(app, productapp.Config{
+		Log:         cfg.Log,
+		DB:          cfg.DB,
+		ProductBus:  cfg.BusConfig.ProductBus,
+		CategoryBus: cfg.BusConfig.CategoryBus,
+		AuditBus:    cfg.BusConfig.AuditBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+		Defaults:    r.defaults,
+	})
+}
+
+// =============================================================================
+
+func defaults200(sd apitest.SeedData) []apitest.Table {
+	cmpFunc := func(got any, exp any) string {
+		gotResp, exists := got.(*productapp.Product)
+		if !exists {
+			return "error occurred"
+		}
+
+		expResp := exp.(*productapp.Product)
+
+		expResp.ID = gotResp.ID
+		expResp.DateCreated = gotResp.DateCreated
+		expResp.DateUpdated = gotResp.DateUpdated
+
+		return cmp.Diff(gotResp, expResp)
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "missing-quantity",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:  "DEF-001",
+				Name: "Defaulted",
+				Cost: "10.34",
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{
+				SKU:         "DEF-001",
+				Name:        "Defaulted",
+				Description: "Restocked weekly",
+				UserID:      sd.Users[0].ID.String(),
+				Cost:        "10.34",
+				Quantity:    5,
+			},
+			CmpFunc: cmpFunc,
+		},
+		{
+			Name:       "zero-quantity",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:         "DEF-002",
+				Name:        "Sold Out",
+				Description: "Back in spring",
+				Cost:        "10.34",
+				Quantity:    dbtest.IntPointer(0),
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{
+				SKU:         "DEF-002",
+				Name:        "Sold Out",
+				Description: "Back in spring",
+				UserID:      sd.Users[0].ID.String(),
+				Cost:        "10.34",
+				Quantity:    0,
+			},
+			CmpFunc: cmpFunc,
+		},
+	}
+
+	return table
+}
+
+func defaults400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-sku",
+			URL:        "/v1/products",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.NewProduct{
+				Name: "No SKU",
+				Cost: "10.34",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"sku\",\"error\":\"sku is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
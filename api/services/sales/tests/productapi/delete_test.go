@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -18,9 +20,28 @@ func delete200(sd apitest.SeedData) []apitest.Table {
 			Method:     http.MethodDelete,
 			StatusCode: http.StatusNoContent,
 		},
+		{
+			Name:       "asuser-again",
+			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNoContent,
+		},
+		{
+			Name:       "asuser-again-strict",
+			URL:        fmt.Sprintf("/v1/products/%s?strict=true", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.New(errs.NotFound, productbus.ErrNotFound),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 		{
 			Name:       "asadmin",
-			URL:        fmt.Sprintf("/v1/products/%s", sd.Admins[0].Products[0].ID),
+			URL:        fmt.Sprintf("/v1/products/%s?reason=discontinued", sd.Admins[0].Products[0].ID),
 			Token:      sd.Admins[0].Token,
 			Method:     http.MethodDelete,
 			StatusCode: http.StatusNoContent,
@@ -30,6 +51,42 @@ func delete200(sd apitest.SeedData) []apitest.Table {
 	return table
 }
 
+func restore200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "asuser",
+			URL:        fmt.Sprintf("/v1/products/%s/restore", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.Product{},
+			ExpResp: &productapp.Product{
+				ID:       sd.Users[0].Products[0].ID.String(),
+				UserID:   sd.Users[0].ID.String(),
+				SKU:      "GTR-100",
+				Name:     "Guitar",
+				Cost:     "10.34",
+				Quantity: 10,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
 func delete401(sd apitest.SeedData) []apitest.Table {
 	table := []apitest.Table{
 		{
@@ -72,3 +129,65 @@ func delete401(sd apitest.SeedData) []apitest.Table {
 
 	return table
 }
+
+func bulkDelete200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "by-id",
+			URL:        fmt.Sprintf("/v1/products?product_id=%s&confirm=true", sd.Users[0].Products[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.BulkDeleteResult{},
+			ExpResp:    &productapp.BulkDeleteResult{Deleted: 1},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "by-id-again",
+			URL:        fmt.Sprintf("/v1/products?product_id=%s&confirm=true", sd.Users[0].Products[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.BulkDeleteResult{},
+			ExpResp:    &productapp.BulkDeleteResult{Deleted: 0},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkDelete400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "not-confirmed",
+			URL:        fmt.Sprintf("/v1/products?product_id=%s", sd.Users[0].Products[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusBadRequest,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"confirm\",\"error\":\"bulk delete must be confirmed with confirm=true\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "empty-filter",
+			URL:        "/v1/products?confirm=true&include_deleted=true",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusBadRequest,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "at least one filter is required"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,126 @@
+package product_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func diff200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "timestamps",
+			URL:        fmt.Sprintf("/v1/products/%s/diff?from=2000-01-01T00:00:00Z&to=2100-01-01T00:00:00Z", prd.ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.ProductDiff{},
+			ExpResp:    &productapp.ProductDiff{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.ProductDiff)
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.From.Name != prd.Name.String() {
+					return fmt.Sprintf("from should hold the original product, got name %q", gotResp.From.Name)
+				}
+
+				if gotResp.To.Name != "Guitar" {
+					return fmt.Sprintf("to should hold the updated product, got name %q", gotResp.To.Name)
+				}
+
+				expChange := productapp.FieldChange{Field: "name", Old: prd.Name.String(), New: "Guitar"}
+				for _, change := range gotResp.Changes {
+					if change.Field == "name" {
+						return cmp.Diff(change, expChange)
+					}
+				}
+
+				return "the name change is missing"
+			},
+		},
+		{
+			Name:       "same",
+			URL:        fmt.Sprintf("/v1/products/%s/diff?from=2100-01-01T00:00:00Z&to=2100-01-01T00:00:00Z", prd.ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.ProductDiff{},
+			ExpResp:    &productapp.ProductDiff{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.ProductDiff)
+				if !exists {
+					return "error occurred"
+				}
+
+				if len(gotResp.Changes) != 0 {
+					return fmt.Sprintf("got %d changes, exp 0", len(gotResp.Changes))
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func diff400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-from",
+			URL:        fmt.Sprintf("/v1/products/%s/diff?from=yesterday&to=2100-01-01T00:00:00Z", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("from", errors.New("must be an audit entry id or an RFC 3339 time")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func diff404(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:       "no-history",
+			URL:        fmt.Sprintf("/v1/products/%s/diff?from=2000-01-01T00:00:00Z&to=2100-01-01T00:00:00Z", prd.ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "from: productID[%s]: version not found", prd.ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "other-tenant",
+			URL:        fmt.Sprintf("/v1/products/%s/diff?from=2000-01-01T00:00:00Z&to=2100-01-01T00:00:00Z", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[1].Token,
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "query: productID[%s]: product not found", sd.Users[0].Products[0].ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,146 @@
+package product_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Test_ProductEvents checks the product lifecycle actions are only sent to
+// subscribers, like the webhooks, once the changes are committed.
+func Test_ProductEvents(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_ProductEvents")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	var rec eventRecorder
+	for _, action := range []string{productbus.ActionCreated, productbus.ActionUpdated, productbus.ActionDeleted} {
+		test.DB.BusDomain.Delegate.Register(productbus.DomainName, action, rec.record)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, eventsRolledBack(sd), "events-rolledback")
+	if got := rec.take(); len(got) != 0 {
+		t.Fatalf("Should not send the actions of a rolled back change : %v", got)
+	}
+
+	test.Run(t, eventsCommitted(sd), "events-committed")
+	if diff := cmp.Diff(rec.take(), []string{productbus.ActionUpdated + ":" + sd.Admins[0].Products[0].ID.String()}); diff != "" {
+		t.Fatalf("Should send the action of a committed change : %s", diff)
+	}
+}
+
+func eventsRolledBack(sd apitest.SeedData) []apitest.Table {
+	prds := sd.Admins[0].Products
+
+	table := []apitest.Table{
+		{
+			Name:       "second-fails",
+			URL:        "/v1/products/bulk",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPatch,
+			StatusCode: http.StatusConflict,
+			Input: productapp.BulkUpdateItems{
+				{ID: prds[0].ID.String(), Fields: productapp.UpdateProduct{Description: dbtest.StringPointer("Rolled back")}},
+				{ID: prds[1].ID.String(), Fields: productapp.UpdateProduct{SKU: dbtest.StringPointer(prds[0].SKU.String())}},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: &errs.Error{Code: errs.Aborted},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got.(*errs.Error).Code, exp.(*errs.Error).Code)
+			},
+		},
+	}
+
+	return table
+}
+
+func eventsCommitted(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Admins[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/products/bulk",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPatch,
+			StatusCode: http.StatusMultiStatus,
+			Input: productapp.BulkUpdateItems{
+				{ID: prd.ID.String(), Fields: productapp.UpdateProduct{Description: dbtest.StringPointer("Committed")}},
+			},
+			GotResp: &productapp.BulkMultiStatus{},
+			ExpResp: &productapp.BulkMultiStatus{
+				Mode:      "atomic",
+				Atomic:    true,
+				Succeeded: 1,
+				Items: []productapp.BulkItemResult{
+					{Index: 0, Status: http.StatusOK, ID: prd.ID.String()},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.BulkMultiStatus)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.BulkMultiStatus)
+				expResp.Note = gotResp.Note
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+// =============================================================================
+
+// eventRecorder keeps the product lifecycle actions it receives as
+// "action:productID".
+type eventRecorder struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (r *eventRecorder) record(ctx context.Context, data delegate.Data) error {
+	var params productbus.ActionParms
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.actions = append(r.actions, data.Action+":"+params.ProductID.String())
+
+	return nil
+}
+
+func (r *eventRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	actions := r.actions
+	r.actions = nil
+
+	return actions
+}
@@ -0,0 +1,85 @@
+package product_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+)
+
+// Test_ProductExportCanceled cancels exports once they started to stream and
+// checks the client is left with the truncated stream and nothing appended.
+func Test_ProductExportCanceled(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_ProductExportCanceled")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	tests := []struct {
+		name    string
+		url     string
+		expBody string
+	}{
+		{
+			name:    "ndjson",
+			url:     "/v1/products/export",
+			expBody: "",
+		},
+		{
+			name:    "json",
+			url:     "/v1/products/export?format=json",
+			expBody: "[",
+		},
+		{
+			name:    "csv",
+			url:     "/v1/products/export?format=csv&fields=sku,name",
+			expBody: "sku,name\n",
+		},
+	}
+
+	for _, tt := range tests {
+		f := func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			r := httptest.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			r.Header.Set("Authorization", "Bearer "+sd.Users[0].Token)
+
+			w := cancelWriter{ResponseRecorder: httptest.NewRecorder(), cancel: cancel}
+
+			test.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: Should receive a status code of %d for the response : %d", tt.name, http.StatusOK, w.Code)
+			}
+
+			if got := w.Body.String(); got != tt.expBody {
+				t.Fatalf("%s: Should receive the truncated stream %q : %q", tt.name, tt.expBody, got)
+			}
+		}
+
+		t.Run("export-canceled-"+tt.name, f)
+	}
+}
+
+// cancelWriter cancels the request as soon as the response starts, so the
+// export fails once its status is written.
+type cancelWriter struct {
+	*httptest.ResponseRecorder
+	cancel context.CancelFunc
+}
+
+func (w cancelWriter) WriteHeader(statusCode int) {
+	w.cancel()
+	w.ResponseRecorder.WriteHeader(statusCode)
+}
@@ -0,0 +1,169 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/foundation/graphql"
+	"github.com/google/go-cmp/cmp"
+)
+
+const gqlProductFields = "id userID sku name description cost quantity categoryID dateCreated dateUpdated"
+
+type gqlProductPage struct {
+	Total int                  `json:"total"`
+	Items []productapp.Product `json:"items"`
+}
+
+type gqlResponse struct {
+	Data struct {
+		Product  *productapp.Product `json:"product"`
+		Products *gqlProductPage     `json:"products"`
+		Create   *productapp.Product `json:"createProduct"`
+	} `json:"data"`
+	Errors []graphql.Error `json:"errors"`
+}
+
+func graphQL200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "product",
+			URL:        "/graphql",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodPost,
+			Input: graphql.Request{
+				Query:     fmt.Sprintf("query ($id: ID!) { product(id: $id) { %s } }", gqlProductFields),
+				Variables: map[string]any{"id": prd.ID.String()},
+			},
+			GotResp: &gqlResponse{},
+			ExpResp: toAppProductPtr(prd),
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*gqlResponse)
+				if !exists {
+					return "error occurred"
+				}
+
+				if len(gotResp.Errors) != 0 {
+					return fmt.Sprintf("got errors %+v", gotResp.Errors)
+				}
+
+				return cmp.Diff(gotResp.Data.Product, exp)
+			},
+		},
+		{
+			Name:       "products",
+			URL:        "/graphql",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodPost,
+			Input: graphql.Request{
+				Query: fmt.Sprintf(`{ products(filter: {productID: %q}, page: 1, rows: 10, orderBy: "name,ASC") { total items { %s } } }`, prd.ID, gqlProductFields),
+			},
+			GotResp: &gqlResponse{},
+			ExpResp: &gqlProductPage{Total: 1, Items: []productapp.Product{toAppProduct(prd)}},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*gqlResponse)
+				if !exists {
+					return "error occurred"
+				}
+
+				if len(gotResp.Errors) != 0 {
+					return fmt.Sprintf("got errors %+v", gotResp.Errors)
+				}
+
+				return cmp.Diff(gotResp.Data.Products, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func graphQLErrors(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "syntax",
+			URL:        "/graphql",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodPost,
+			Input:      graphql.Request{Query: "{ products( }"},
+			GotResp:    &gqlResponse{},
+			ExpResp:    &gqlResponse{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*gqlResponse)
+				if !exists {
+					return "error occurred"
+				}
+
+				if len(gotResp.Errors) != 1 {
+					return fmt.Sprintf("got %d errors, exp 1", len(gotResp.Errors))
+				}
+
+				return ""
+			},
+		},
+		{
+			Name:       "not-owner",
+			URL:        "/graphql",
+			Token:      sd.Users[1].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodPost,
+			Input: graphql.Request{
+				Query: fmt.Sprintf("{ product(id: %q) { id } }", sd.Users[0].Products[0].ID),
+			},
+			GotResp: &gqlResponse{},
+			ExpResp: &gqlResponse{},
+			CmpFunc: func(got any, exp any) string {
+				return expGraphQLError(got, "product", "unauthenticated")
+			},
+		},
+		{
+			Name:       "invalid-input",
+			URL:        "/graphql",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodPost,
+			Input: graphql.Request{
+				Query: `mutation { createProduct(input: {name: "Guitar"}) { id } }`,
+			},
+			GotResp: &gqlResponse{},
+			ExpResp: &gqlResponse{},
+			CmpFunc: func(got any, exp any) string {
+				return expGraphQLError(got, "createProduct", "invalid_argument")
+			},
+		},
+	}
+
+	return table
+}
+
+// expGraphQLError checks the response holds a single error for the field
+// with the expected code.
+func expGraphQLError(got any, field string, code string) string {
+	gotResp, exists := got.(*gqlResponse)
+	if !exists {
+		return "error occurred"
+	}
+
+	if len(gotResp.Errors) != 1 {
+		return fmt.Sprintf("got %d errors, exp 1", len(gotResp.Errors))
+	}
+
+	gotErr := gotResp.Errors[0]
+
+	if len(gotErr.Path) != 1 || gotErr.Path[0] != field {
+		return fmt.Sprintf("got path %v, exp [%s]", gotErr.Path, field)
+	}
+
+	if gotErr.Extensions["code"] != code {
+		return fmt.Sprintf("got code %v, exp %s", gotErr.Extensions["code"], code)
+	}
+
+	return ""
+}
@@ -0,0 +1,120 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/google/go-cmp/cmp"
+)
+
+func imageUploadURL200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/%s/image/upload-url", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewImageUpload{
+				ContentType: "image/png",
+				Size:        1024,
+			},
+			GotResp: &productapp.ImageUploadURL{},
+			ExpResp: &productapp.ImageUploadURL{
+				Method: http.MethodPut,
+				Headers: map[string]string{
+					"Content-Type":   "image/png",
+					"Content-Length": "1024",
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.ImageUploadURL)
+				if !exists {
+					return "error occurred"
+				}
+
+				prefix := fmt.Sprintf("products/%s/%s/", prd.TenantID, prd.ID)
+				if !strings.HasPrefix(gotResp.Key, prefix) || !strings.HasSuffix(gotResp.Key, ".png") {
+					return fmt.Sprintf("unexpected key: %s", gotResp.Key)
+				}
+
+				if !strings.Contains(gotResp.UploadURL, "X-Amz-Signature=") {
+					return fmt.Sprintf("upload url isn't signed: %s", gotResp.UploadURL)
+				}
+
+				expResp := exp.(*productapp.ImageUploadURL)
+				expResp.UploadURL = gotResp.UploadURL
+				expResp.Key = gotResp.Key
+				expResp.ImageURL = gotResp.ImageURL
+				expResp.ExpiresAt = gotResp.ExpiresAt
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func imageUploadURL400(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "content-type",
+			URL:        fmt.Sprintf("/v1/products/%s/image/upload-url", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.NewImageUpload{
+				ContentType: "application/pdf",
+				Size:        1024,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.NewFieldErrors("contentType", productbus.ErrImageType),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "size",
+			URL:        fmt.Sprintf("/v1/products/%s/image/upload-url", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.NewImageUpload{
+				ContentType: "image/png",
+				Size:        productbus.MaxImageSize + 1,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.NewFieldErrors("size", fmt.Errorf("value must be at most %d bytes", productbus.MaxImageSize)),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "confirm-unknown-key",
+			URL:        fmt.Sprintf("/v1/products/%s/image/confirm", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.ConfirmImage{
+				Key: "products/unknown.png",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.New(errs.FailedPrecondition, productbus.ErrNoPendingImage),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
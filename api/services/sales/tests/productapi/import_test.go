@@ -0,0 +1,98 @@
+package product_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func import200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/products/import",
+			Token:      sd.Users[0].Token,
+			Headers:    map[string]string{"Content-Type": "application/x-ndjson"},
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:      "IMP-001",
+				Name:     "Imported",
+				Cost:     "5.00",
+				Quantity: dbtest.IntPointer(3),
+			},
+			GotResp: &productapp.ImportResult{},
+			ExpResp: &productapp.ImportResult{Line: 1},
+			ExpHeaders: map[string]string{
+				"Content-Type": "application/x-ndjson",
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.ImportResult)
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.ID == "" {
+					return "the id of the created product is missing"
+				}
+
+				expResp := exp.(*productapp.ImportResult)
+				expResp.ID = gotResp.ID
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "invalid-line",
+			URL:        "/v1/products/import",
+			Token:      sd.Users[0].Token,
+			Headers:    map[string]string{"Content-Type": "application/x-ndjson"},
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.NewProduct{
+				SKU:      "IMP-002",
+				Cost:     "5.00",
+				Quantity: dbtest.IntPointer(3),
+			},
+			GotResp: &productapp.ImportResult{},
+			ExpResp: &productapp.ImportResult{
+				Line:  1,
+				Error: "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]",
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func import400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "content-type",
+			URL:        "/v1/products/import",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.NewProduct{
+				SKU:      "IMP-003",
+				Name:     "Imported",
+				Cost:     "5.00",
+				Quantity: dbtest.IntPointer(3),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "content type must be application/x-ndjson"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -8,15 +8,27 @@ import (
 )
 
 func toAppProduct(prd productbus.Product) productapp.Product {
-	return productapp.Product{
+	app := productapp.Product{
 		ID:          prd.ID.String(),
 		UserID:      prd.UserID.String(),
+		SKU:         prd.SKU.String(),
 		Name:        prd.Name.String(),
-		Cost:        prd.Cost.Value(),
+		Description: prd.Description,
+		Cost:        prd.Cost.String(),
 		Quantity:    prd.Quantity.Value(),
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
 	}
+
+	if prd.DateDeleted != nil {
+		app.DateDeleted = prd.DateDeleted.Format(time.RFC3339)
+	}
+
+	for _, t := range prd.Tags {
+		app.Tags = append(app.Tags, t.String())
+	}
+
+	return app
 }
 
 func toAppProductPtr(prd productbus.Product) *productapp.Product {
@@ -32,3 +44,22 @@ func toAppProducts(prds []productbus.Product) []productapp.Product {
 
 	return items
 }
+
+func toAppProductV2Ptr(prd productbus.Product) *productapp.ProductV2 {
+	app := productapp.ProductV2{
+		ID:          prd.ID.String(),
+		UserID:      prd.UserID.String(),
+		SKU:         prd.SKU.String(),
+		Name:        prd.Name.String(),
+		Description: prd.Description,
+		Cost: &productapp.MoneyV2{
+			Amount: prd.Cost.String(),
+			Cents:  prd.Cost.Cents(),
+		},
+		Quantity:    prd.Quantity.Value(),
+		DateCreated: prd.DateCreated.UTC().Format(time.RFC3339),
+		DateUpdated: prd.DateUpdated.UTC().Format(time.RFC3339),
+	}
+
+	return &app
+}
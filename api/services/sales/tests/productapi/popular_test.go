@@ -0,0 +1,67 @@
+package product_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+)
+
+func popular200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "none-viewed",
+			URL:        "/v1/products/popular?page=1&rows=10",
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &query.Result[productapp.PopularProduct]{},
+			ExpResp: &query.Result[productapp.PopularProduct]{
+				Page:        1,
+				RowsPerPage: 10,
+				Items:       []productapp.PopularProduct{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func refreshPopular204(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "admin",
+			URL:        "/v1/products/popular/refresh",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNoContent,
+		},
+	}
+
+	return table
+}
+
+func refreshPopular401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "notadmin",
+			URL:        "/v1/products/popular/refresh",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,146 @@
+package product_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func bulkAdjustPrice200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "amount",
+			URL:        fmt.Sprintf("/v1/products/prices?product_id=%s&confirm=true", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.PriceAdjustment{
+				Amount: dbtest.StringPointer("+1.00"),
+			},
+			GotResp: &productapp.BulkPriceResult{},
+			ExpResp: &productapp.BulkPriceResult{Updated: 1},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "amount-audited",
+			URL:        fmt.Sprintf("/v1/products/%s/audit?page=1&rows=100", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &query.Result[productapp.AuditEntry]{},
+			ExpResp:    &query.Result[productapp.AuditEntry]{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.AuditEntry])
+				if !exists {
+					return "error occurred"
+				}
+
+				for _, entry := range gotResp.Items {
+					if entry.ActorID != sd.Admins[0].ID.String() || entry.Action != "updated" {
+						continue
+					}
+
+					if entry.Before != nil && entry.After != nil && entry.Before.Cost != entry.After.Cost {
+						return ""
+					}
+				}
+
+				return "the price change should be in the audit trail"
+			},
+		},
+		{
+			Name:       "no-match",
+			URL:        fmt.Sprintf("/v1/products/prices?product_id=%s&confirm=true", sd.Users[0].Products[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.PriceAdjustment{
+				Amount: dbtest.StringPointer("1.00"),
+			},
+			GotResp: &productapp.BulkPriceResult{},
+			ExpResp: &productapp.BulkPriceResult{Updated: 0},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkAdjustPrice400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "not-confirmed",
+			URL:        fmt.Sprintf("/v1/products/prices?product_id=%s", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.PriceAdjustment{
+				Amount: dbtest.StringPointer("1.00"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "[{\"field\":\"confirm\",\"error\":\"bulk price update must be confirmed with confirm=true\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "both",
+			URL:        fmt.Sprintf("/v1/products/prices?product_id=%s&confirm=true", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.PriceAdjustment{
+				Percent: dbtest.FloatPointer(-10),
+				Amount:  dbtest.StringPointer("1.00"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "[{\"field\":\"amount\",\"error\":\"value can't be combined with percent\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "unknown-field",
+			URL:        fmt.Sprintf("/v1/products/prices?product_id=%s&confirm=true", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: map[string]any{
+				"percnt": 10,
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.NewFieldErrors("percnt", errors.New("unknown field")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "negative",
+			URL:        fmt.Sprintf("/v1/products/prices?product_id=%s&confirm=true", sd.Users[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.PriceAdjustment{
+				Amount: dbtest.StringPointer("-1000000.00"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "the adjustment would make a cost negative"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
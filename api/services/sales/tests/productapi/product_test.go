@@ -22,16 +22,84 @@ func Test_Product(t *testing.T) {
 
 	test.Run(t, query200(sd), "query-200")
 	test.Run(t, query400(sd), "query-400")
+	test.Run(t, count200(sd), "count-200")
+	test.Run(t, summary200(sd), "summary-200")
+	test.Run(t, search200(sd), "search-200")
+	test.Run(t, search400(sd), "search-400")
+	test.Run(t, export200(sd), "export-200")
+	test.Run(t, export400(sd), "export-400")
 	test.Run(t, queryByID200(sd), "querybyid-200")
+	test.Run(t, queryByID304(sd), "querybyid-304")
+	test.Run(t, queryByID406(sd), "querybyid-406")
+	test.Run(t, queryByID404(sd), "querybyid-404")
+	test.Run(t, queryBySKU200(sd), "querybysku-200")
+	test.Run(t, queryBySKU400(sd), "querybysku-400")
+	test.Run(t, queryByIDs200(sd), "querybyids-200")
+	test.Run(t, queryByIDs400(sd), "querybyids-400")
+	test.Run(t, nameAvailable200(sd), "nameavailable-200")
+	test.Run(t, recentlyViewed200(sd), "recentlyviewed-200")
+	test.Run(t, recentlyViewed400(sd), "recentlyviewed-400")
+	test.Run(t, graphQL200(sd), "graphql-200")
+	test.Run(t, graphQLErrors(sd), "graphql-errors")
 
 	test.Run(t, create200(sd), "create-200")
 	test.Run(t, create401(sd), "create-401")
 	test.Run(t, create400(sd), "create-400")
+	test.Run(t, create413(sd), "create-413")
+	test.Run(t, create409(sd), "create-409")
+
+	test.Run(t, bulkCreate200(sd), "bulkcreate-200")
+	test.Run(t, bulkCreate207(sd), "bulkcreate-207")
+	test.Run(t, bulkCreate400(sd), "bulkcreate-400")
+
+	test.Run(t, import200(sd), "import-200")
+	test.Run(t, import400(sd), "import-400")
 
 	test.Run(t, update200(sd), "update-200")
+	test.Run(t, priceHistory200(sd), "pricehistory-200")
+	test.Run(t, auditTrail200(sd), "audittrail-200")
+	test.Run(t, auditTrail401(sd), "audittrail-401")
+	test.Run(t, auditTrail404(sd), "audittrail-404")
+	test.Run(t, diff200(sd), "diff-200")
+	test.Run(t, diff400(sd), "diff-400")
+	test.Run(t, diff404(sd), "diff-404")
+	test.Run(t, update412(sd), "update-412")
 	test.Run(t, update401(sd), "update-401")
 	test.Run(t, update400(sd), "update-400")
+	test.Run(t, update409(sd), "update-409")
+
+	test.Run(t, patch200(sd), "patch-200")
+	test.Run(t, patch400(sd), "patch-400")
+	test.Run(t, patch422(sd), "patch-422")
+
+	test.Run(t, adjustStock200(sd), "adjuststock-200")
+	test.Run(t, adjustStock409(sd), "adjuststock-409")
+	test.Run(t, touch200(sd), "touch-200")
+	test.Run(t, touch404(sd), "touch-404")
+	test.Run(t, clone200(sd), "clone-200")
+	test.Run(t, clone404(sd), "clone-404")
+	test.Run(t, similar200(sd), "similar-200")
+	test.Run(t, similar400(sd), "similar-400")
+	test.Run(t, similar404(sd), "similar-404")
+	test.Run(t, popular200(sd), "popular-200")
+	test.Run(t, refreshPopular204(sd), "refreshpopular-204")
+	test.Run(t, refreshPopular401(sd), "refreshpopular-401")
 
+	test.Run(t, bulkUpdate207(sd), "bulkupdate-207")
+	test.Run(t, bulkUpdate400(sd), "bulkupdate-400")
+	test.Run(t, upsert207(sd), "upsert-207")
+
+	test.Run(t, imageUploadURL200(sd), "imageuploadurl-200")
+	test.Run(t, imageUploadURL400(sd), "imageuploadurl-400")
+
+	test.Run(t, readOnly(sd), "readonly")
 	test.Run(t, delete200(sd), "delete-200")
+	test.Run(t, auditTrailDelete200(sd), "audittrail-delete-200")
+	test.Run(t, restore200(sd), "restore-200")
 	test.Run(t, delete401(sd), "delete-401")
+	test.Run(t, bulkDelete400(sd), "bulkdelete-400")
+	test.Run(t, bulkDelete200(sd), "bulkdelete-200")
+
+	test.Run(t, bulkAdjustPrice200(sd), "bulkadjustprice-200")
+	test.Run(t, bulkAdjustPrice400(sd), "bulkadjustprice-400")
 }
@@ -1,16 +1,25 @@
 package product_test
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 func query200(sd apitest.SeedData) []apitest.Table {
@@ -22,19 +31,1178 @@ func query200(sd apitest.SeedData) []apitest.Table {
 		return prds[i].ID.String() <= prds[j].ID.String()
 	})
 
+	multi := make([]productbus.Product, len(prds))
+	copy(multi, prds)
+
+	sort.Slice(multi, func(i, j int) bool {
+		if multi[i].UserID != multi[j].UserID {
+			return multi[i].UserID.String() < multi[j].UserID.String()
+		}
+		return multi[i].ID.String() > multi[j].ID.String()
+	})
+
+	var lowStock []productbus.Product
+	for _, prd := range prds {
+		if qnt := prd.Quantity.Value(); qnt >= 10 && qnt <= 30 {
+			lowStock = append(lowStock, prd)
+		}
+	}
+
+	var lowStockPages int
+	if len(lowStock) > 0 {
+		lowStockPages = 1
+	}
+
+	fields := make([]map[string]any, len(prds))
+	for i, prd := range prds {
+		fields[i] = map[string]any{
+			"id":   prd.ID.String(),
+			"name": prd.Name.String(),
+		}
+	}
+
+	redacted := toAppProducts(prds)
+	for i := range redacted {
+		redacted[i].Cost = ""
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/products?page=1&rows=10&orderBy=product_id,ASC",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(prds),
+				Pages:       1,
+				Items:       toAppProducts(prds),
+			},
+			ExpHeaders: map[string]string{
+				"Link": `</v1/products?orderBy=product_id%2CASC&page=1&rows=10>; rel="first", </v1/products?orderBy=product_id%2CASC&page=1&rows=10>; rel="last"`,
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "nulls-last",
+			URL:        "/v1/products?page=1&rows=10&orderBy=product_id,ASC,nulls_last",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(prds),
+				Pages:       1,
+				Items:       toAppProducts(prds),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "buyer-redacted",
+			URL:        "/v1/products?page=1&rows=10&orderBy=product_id,ASC",
+			Token:      sd.Users[2].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(prds),
+				Pages:       1,
+				Items:       redacted,
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "other-tenant",
+			URL:        "/v1/products?page=1&rows=10",
+			Token:      sd.Admins[1].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Items:       []productapp.Product{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "rows-clamped",
+			URL:        "/v1/products?page=1&rows=1000&orderBy=product_id,ASC",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"X-Max-Rows-Per-Page": "100",
+			},
+			GotResp: &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 100,
+				Total:       len(prds),
+				Pages:       1,
+				Items:       toAppProducts(prds),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "quantity-range",
+			URL:        "/v1/products?page=1&rows=10&orderBy=product_id,ASC&quantity_min=10&quantity_max=30",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(lowStock),
+				Pages:       lowStockPages,
+				Items:       toAppProducts(lowStock),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "ids",
+			URL:        fmt.Sprintf("/v1/products?page=1&rows=10&orderBy=product_id,ASC&ids=%s,%s", prds[2].ID, prds[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       2,
+				Pages:       1,
+				Items:       toAppProducts([]productbus.Product{prds[0], prds[2]}),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "active",
+			URL:        fmt.Sprintf("/v1/products?page=1&rows=10&orderBy=product_id,ASC&active=true&ids=%s,%s", prds[2].ID, prds[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: func() *query.Result[productapp.Product] {
+				var active []productbus.Product
+				for _, prd := range []productbus.Product{prds[0], prds[2]} {
+					if prd.Quantity.Value() > 0 {
+						active = append(active, prd)
+					}
+				}
+
+				return &query.Result[productapp.Product]{
+					Page:        1,
+					RowsPerPage: 10,
+					Total:       len(active),
+					Pages:       min(len(active), 1),
+					Items:       toAppProducts(active),
+				}
+			}(),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "next-cursor",
+			URL:        "/v1/products?page=1&rows=2&orderBy=product_id,ASC",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 2,
+				Total:       len(prds),
+				Pages:       2,
+				HasNext:     true,
+				Items:       toAppProducts(prds[:2]),
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.Product])
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.NextCursor == "" {
+					return "expected a next cursor"
+				}
+
+				if gotResp.Snapshot == "" {
+					return "expected a snapshot"
+				}
+
+				expResp := exp.(*query.Result[productapp.Product])
+				expResp.NextCursor = gotResp.NextCursor
+				expResp.Snapshot = gotResp.Snapshot
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "limit-offset",
+			URL:        "/v1/products?limit=2&offset=2&orderBy=product_id,ASC",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        2,
+				RowsPerPage: 2,
+				Total:       len(prds),
+				Pages:       2,
+				HasPrev:     true,
+				Items:       toAppProducts(prds[2:]),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "cursor-start",
+			URL:        "/v1/products?rows=2&cursor=" + productCursor(uuid.Nil),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 2,
+				Total:       len(prds),
+				Pages:       2,
+				HasNext:     true,
+				NextCursor:  productCursor(prds[1].ID),
+				Items:       toAppProducts(prds[:2]),
+			},
+			ExpHeaders: map[string]string{
+				"Link": `</v1/products?cursor=` + productCursor(prds[1].ID) + `&rows=2>; rel="next"`,
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "cursor-next",
+			URL:        "/v1/products?rows=2&cursor=" + productCursor(prds[1].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 2,
+				Total:       len(prds),
+				Pages:       2,
+				HasPrev:     true,
+				Items:       toAppProducts(prds[2:]),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "cursor-end",
+			URL:        "/v1/products?rows=2&cursor=" + productCursor(prds[len(prds)-1].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 2,
+				Total:       len(prds),
+				Pages:       2,
+				HasPrev:     true,
+				Items:       []productapp.Product{},
+			},
+			ExpHeaders: map[string]string{
+				"Link": "",
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "snapshot",
+			URL:        "/v1/products?page=1&rows=10&snapshot=" + productapp.NewSnapshot(time.Unix(0, 0)),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Items:       []productapp.Product{},
+				Snapshot:    productapp.NewSnapshot(time.Unix(0, 0)),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "sku",
+			URL:        fmt.Sprintf("/v1/products?page=1&rows=10&sku=%s", sd.Users[0].Products[1].SKU),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Pages:       1,
+				Items:       toAppProducts(sd.Users[0].Products[1:2]),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "name-like-literal",
+			URL:        "/v1/products?page=1&rows=10&name_like=%25",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       0,
+				Items:       []productapp.Product{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:  "csv",
+			URL:   "/v1/products?page=1&rows=10",
+			Token: sd.Admins[0].Token,
+			Headers: map[string]string{
+				"Accept": "text/csv",
+			},
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"Content-Type":        "text/csv",
+				"Content-Disposition": `attachment; filename="products.csv"`,
+			},
+		},
+		{
+			Name:       "fields",
+			URL:        "/v1/products?page=1&rows=10&orderBy=product_id,ASC&fields=id,name",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[map[string]any]{},
+			ExpResp: &query.Result[map[string]any]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(prds),
+				Pages:       1,
+				Items:       fields,
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "multi-orderby",
+			URL:        "/v1/products?page=1&rows=10&orderBy=user_id,asc%3Bproduct_id,desc",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(multi),
+				Pages:       1,
+				Items:       toAppProducts(multi),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func count200(sd apitest.SeedData) []apitest.Table {
+	total := strconv.Itoa(len(sd.Admins[0].Products) + len(sd.Users[0].Products))
+
+	table := []apitest.Table{
+		{
+			Name:       "head",
+			URL:        "/v1/products",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodHead,
+			ExpHeaders: map[string]string{
+				"X-Total-Count": total,
+			},
+		},
+		{
+			Name:       "count-only",
+			URL:        "/v1/products?count_only=true",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"X-Total-Count": total,
+			},
+		},
+		{
+			Name:       "filtered",
+			URL:        "/v1/products?count_only=true&name_like=%25",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"X-Total-Count": "0",
+			},
+		},
+	}
+
+	return table
+}
+
+func summary200(sd apitest.SeedData) []apitest.Table {
+	prds := make([]productbus.Product, 0, len(sd.Admins[0].Products)+len(sd.Users[0].Products))
+	prds = append(prds, sd.Admins[0].Products...)
+	prds = append(prds, sd.Users[0].Products...)
+
+	var value, costs int64
+	var outOfStock int
+	for _, prd := range prds {
+		value += prd.Cost.Cents() * int64(prd.Quantity.Value())
+		costs += prd.Cost.Cents()
+		if prd.Quantity.Value() == 0 {
+			outOfStock++
+		}
+	}
+
+	// The average is rounded half up to the cent like the database does.
+	n := int64(len(prds))
+	average := (2*costs + n) / (2 * n)
+
+	cents := func(v int64) string {
+		return fmt.Sprintf("%d.%02d", v/100, v%100)
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/products/summary",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.Summary{},
+			ExpResp: &productapp.Summary{
+				Count:          len(prds),
+				InventoryValue: cents(value),
+				AverageCost:    cents(average),
+				OutOfStock:     outOfStock,
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "no-match",
+			URL:        "/v1/products/summary?name_like=%25",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.Summary{},
+			ExpResp: &productapp.Summary{
+				InventoryValue: "0.00",
+				AverageCost:    "0.00",
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "buyer",
+			URL:        "/v1/products/summary?name_like=%25",
+			Token:      sd.Users[2].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.Summary{},
+			ExpResp:    &productapp.Summary{},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func query400(sd apitest.SeedData) []apitest.Table {
+	_, timeErr := time.Parse(time.RFC3339, "yesterday")
+
+	table := []apitest.Table{
+		{
+			Name:       "cost-filter-buyer",
+			URL:        "/v1/products?page=1&rows=10&price_min=1",
+			Token:      sd.Users[2].Token,
+			StatusCode: http.StatusForbidden,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "cost-order-buyer",
+			URL:        "/v1/products?page=1&rows=10&orderBy=cost,DESC",
+			Token:      sd.Users[2].Token,
+			StatusCode: http.StatusForbidden,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.PermissionDenied, "ordering by cost is restricted to the roles that can see costs"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-rows",
+			URL:        "/v1/products?page=1&rows=0",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("rows", errors.New("invalid rows: value too small, must be larger than 0")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-rows-request-id",
+			URL:        "/v1/products?page=1&rows=0",
+			Token:      sd.Admins[0].Token,
+			Headers:    map[string]string{"X-Request-ID": "support-1234"},
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("rows", errors.New("invalid rows: value too small, must be larger than 0")),
+			ExpHeaders: map[string]string{
+				"X-Request-ID": "support-1234",
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp := got.(*errs.Error)
+				if gotResp.RequestID != "support-1234" {
+					return fmt.Sprintf("Should get back the request id, got %q", gotResp.RequestID)
+				}
+
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:  "bad-rows-problem",
+			URL:   "/v1/products?page=1&rows=0",
+			Token: sd.Admins[0].Token,
+			Headers: map[string]string{
+				"Accept": errs.ProblemMediaType,
+			},
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"Content-Type": errs.ProblemMediaType,
+			},
+			GotResp: &errs.Problem{},
+			ExpResp: errs.NewFieldErrors("rows", errors.New("invalid rows: value too small, must be larger than 0")).Problem(),
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*errs.Problem)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*errs.Problem)
+				if len(gotResp.Errors) != 1 || gotResp.Errors[0].Field != "rows" {
+					return fmt.Sprintf("got errors %+v, exp a rows error", gotResp.Errors)
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "bad-ids",
+			URL:        fmt.Sprintf("/v1/products?page=1&rows=10&ids=%s,abc", sd.Admins[0].Products[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("ids", errors.New(`invalid id "abc"`)),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-active",
+			URL:        "/v1/products?page=1&rows=10&active=true&out_of_stock=true",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("active", errors.New("value can't be combined with out_of_stock")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-page",
+			URL:        "/v1/products?page=0&rows=10",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("page", errors.New("invalid page: value too small, must be larger than 0")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-offset",
+			URL:        "/v1/products?limit=10&offset=-1",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("offset", errors.New("invalid offset: value too small, must not be negative")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "page-and-offset",
+			URL:        "/v1/products?page=1&offset=10",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("offset", errors.New("limit and offset can't be combined with page and rows")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-created-after",
+			URL:        "/v1/products?page=1&rows=10&created_after=yesterday",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("created_after", timeErr),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-query-filter",
+			URL:        "/v1/products?page=1&rows=10&name=$#!",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"name\",\"error\":\"invalid name \\\"$#!\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-price-range",
+			URL:        "/v1/products?page=1&rows=10&price_min=-1",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"price_min\",\"error\":\"value can't be negative\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-snapshot",
+			URL:        "/v1/products?page=1&rows=10&snapshot=$$$",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"snapshot\",\"error\":\"invalid snapshot\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-quantity-range",
+			URL:        "/v1/products?page=1&rows=10&quantity_min=20&quantity_max=10",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"quantity_min\",\"error\":\"value can't be greater than quantity_max\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-out-of-stock",
+			URL:        "/v1/products?page=1&rows=10&out_of_stock=true&quantity_min=1",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"out_of_stock\",\"error\":\"value can't be combined with a quantity_min greater than 0\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-price-range",
+			URL:        "/v1/products?page=1&rows=10&price_min=20&price_max=10",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"price_min\",\"error\":\"value can't be greater than price_max\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-tag-match",
+			URL:        "/v1/products?page=1&rows=10&tag=sale&tag_match=some",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"tag_match\",\"error\":\"value must be all or any\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-name-like",
+			URL:        "/v1/products?page=1&rows=10&name_like=" + strings.Repeat("a", 51),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"name_like\",\"error\":\"value can't be longer than 50 characters\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-fields",
+			URL:        "/v1/products?page=1&rows=10&fields=id,secret",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"fields\",\"error\":\"unknown field: \\\"secret\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-cursor",
+			URL:        "/v1/products?rows=10&cursor=bogus",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"cursor\",\"error\":\"invalid cursor\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-orderby-value",
+			URL:        "/v1/products?page=1&rows=10&orderBy=roduct_id,ASC",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: roduct_id, must be one of cost, date_created, date_updated, name, product_id, quantity, user_id\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-orderby-nulls",
+			URL:        "/v1/products?page=1&rows=10&orderBy=name,ASC,NULLS_MIDDLE",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("order", errors.New("unknown nulls placement: NULLS_MIDDLE, must be NULLS_FIRST or NULLS_LAST")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.Product{},
+			ExpResp:    toAppProductPtr(sd.Users[0].Products[0]),
+			ExpHeaders: map[string]string{
+				"ETag":          productapp.ETag(sd.Users[0].Products[0]),
+				"Last-Modified": sd.Users[0].Products[0].DateUpdated.UTC().Format(http.TimeFormat),
+				"Cache-Control": "max-age=60",
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:  "v2",
+			URL:   fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"Accept": "application/vnd.myapp.v2+json",
+			},
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.ProductV2{},
+			ExpResp:    toAppProductV2Ptr(sd.Users[0].Products[0]),
+			ExpHeaders: map[string]string{
+				"Content-Type": "application/vnd.myapp.v2+json",
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:  "snake-case",
+			URL:   fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"X-Field-Naming": "snake_case",
+			},
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &map[string]any{},
+			ExpResp: &map[string]any{
+				"id":           sd.Users[0].Products[0].ID.String(),
+				"user_id":      sd.Users[0].Products[0].UserID.String(),
+				"date_created": sd.Users[0].Products[0].DateCreated.Format(time.RFC3339),
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp := *got.(*map[string]any)
+				expResp := *exp.(*map[string]any)
+
+				if _, exists := gotResp["userID"]; exists {
+					return "should not hold the camelCase userID field"
+				}
+
+				sub := make(map[string]any, len(expResp))
+				for k := range expResp {
+					sub[k] = gotResp[k]
+				}
+
+				return cmp.Diff(sub, expResp)
+			},
+		},
+		{
+			Name:       "fields",
+			URL:        fmt.Sprintf("/v1/products/%s?fields=id,cost", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &map[string]any{},
+			ExpResp: &map[string]any{
+				"id":   sd.Users[0].Products[0].ID.String(),
+				"cost": sd.Users[0].Products[0].Cost.String(),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID406(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:  "unknown-version",
+			URL:   fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"Accept": "application/vnd.myapp.v9+json",
+			},
+			StatusCode: http.StatusNotAcceptable,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"Vary": "Accept-Encoding",
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID404(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "other-tenant",
+			URL:        fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:      sd.Admins[1].Token,
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "query: productID[%s]: db: product not found", prd.ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID304(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:  "if-none-match",
+			URL:   fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"If-None-Match": productapp.ETag(sd.Users[0].Products[0]),
+			},
+			StatusCode: http.StatusNotModified,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"ETag": productapp.ETag(sd.Users[0].Products[0]),
+			},
+		},
+		{
+			Name:  "if-modified-since",
+			URL:   fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"If-Modified-Since": sd.Users[0].Products[0].DateUpdated.UTC().Format(http.TimeFormat),
+			},
+			StatusCode: http.StatusNotModified,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"Last-Modified": sd.Users[0].Products[0].DateUpdated.UTC().Format(http.TimeFormat),
+			},
+		},
+	}
+
+	return table
+}
+
+func queryBySKU200(sd apitest.SeedData) []apitest.Table {
 	table := []apitest.Table{
 		{
 			Name:       "basic",
-			URL:        "/v1/products?page=1&rows=10&orderBy=product_id,ASC",
-			Token:      sd.Admins[0].Token,
+			URL:        fmt.Sprintf("/v1/products/lookup?sku=%s", sd.Users[0].Products[0].SKU),
+			Token:      sd.Users[0].Token,
 			StatusCode: http.StatusOK,
 			Method:     http.MethodGet,
-			GotResp:    &query.Result[productapp.Product]{},
-			ExpResp: &query.Result[productapp.Product]{
+			GotResp:    &productapp.Product{},
+			ExpResp:    toAppProductPtr(sd.Users[0].Products[0]),
+			ExpHeaders: map[string]string{
+				"ETag": productapp.ETag(sd.Users[0].Products[0]),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryBySKU400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-sku",
+			URL:        "/v1/products/lookup?sku=bad%20sku",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"sku\",\"error\":\"invalid sku \\\"bad sku\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByIDs200(sd apitest.SeedData) []apitest.Table {
+	missing := uuid.New()
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/batch?ids=%s,%s", sd.Users[0].Products[0].ID, missing),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.BatchResult{},
+			ExpResp: &productapp.BatchResult{
+				Items:    toAppProducts(sd.Users[0].Products[:1]),
+				NotFound: []string{missing.String()},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByIDs400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-id",
+			URL:        "/v1/products/batch?ids=abc",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"ids[0]\",\"error\":\"invalid UUID length: 3\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func search200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "name",
+			URL:        "/v1/products/search?page=1&rows=10&q=" + prd.Name.String(),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.SearchResult]{},
+			ExpResp: &query.Result[productapp.SearchResult]{
 				Page:        1,
 				RowsPerPage: 10,
-				Total:       len(prds),
-				Items:       toAppProducts(prds),
+				Total:       1,
+				Pages:       1,
+				Items: []productapp.SearchResult{
+					{Product: toAppProduct(prd)},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.SearchResult])
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*query.Result[productapp.SearchResult])
+
+				for i := range gotResp.Items {
+					if gotResp.Items[i].Rank <= 0 {
+						return fmt.Sprintf("item[%d]: rank should be positive: %f", i, gotResp.Items[i].Rank)
+					}
+					expResp.Items[i].Rank = gotResp.Items[i].Rank
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "fuzzy",
+			URL:        "/v1/products/search?fuzzy=true&page=1&rows=10&q=" + prd.Name.String() + "x",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.SearchResult]{},
+			ExpResp:    &query.Result[productapp.SearchResult]{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.SearchResult])
+				if !exists {
+					return "error occurred"
+				}
+
+				var found bool
+				for i, item := range gotResp.Items {
+					if i > 0 && item.Rank > gotResp.Items[i-1].Rank {
+						return fmt.Sprintf("item[%d]: should be ordered by similarity: %f > %f", i, item.Rank, gotResp.Items[i-1].Rank)
+					}
+
+					if item.Rank < productbus.DefaultSimilarityThreshold {
+						return fmt.Sprintf("item[%d]: similarity should be at least the threshold: %f", i, item.Rank)
+					}
+
+					if item.Product.ID == prd.ID.String() {
+						found = true
+					}
+				}
+
+				if !found {
+					return "the misspelled product should match"
+				}
+
+				return ""
+			},
+		},
+		{
+			Name:       "nomatch",
+			URL:        "/v1/products/search?page=1&rows=10&q=nosuchproduct",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[productapp.SearchResult]{},
+			ExpResp: &query.Result[productapp.SearchResult]{
+				Page:        1,
+				RowsPerPage: 10,
+				Items:       []productapp.SearchResult{},
 			},
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
@@ -45,28 +1213,40 @@ func query200(sd apitest.SeedData) []apitest.Table {
 	return table
 }
 
-func query400(sd apitest.SeedData) []apitest.Table {
+func search400(sd apitest.SeedData) []apitest.Table {
 	table := []apitest.Table{
 		{
-			Name:       "bad-query-filter",
-			URL:        "/v1/products?page=1&rows=10&name=$#!",
-			Token:      sd.Admins[0].Token,
+			Name:       "empty",
+			URL:        "/v1/products/search?q=",
+			Token:      sd.Users[0].Token,
 			StatusCode: http.StatusBadRequest,
 			Method:     http.MethodGet,
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"name\",\"error\":\"invalid name \\\"$#!\\\"\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"q\",\"error\":\"search query can't be empty\"}]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
 		},
 		{
-			Name:       "bad-orderby-value",
-			URL:        "/v1/products?page=1&rows=10&orderBy=roduct_id,ASC",
-			Token:      sd.Admins[0].Token,
+			Name:       "whitespace",
+			URL:        "/v1/products/search?q=%20%20",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"q\",\"error\":\"search query can't be empty\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-fuzzy",
+			URL:        "/v1/products/search?q=guitar&fuzzy=maybe",
+			Token:      sd.Users[0].Token,
 			StatusCode: http.StatusBadRequest,
 			Method:     http.MethodGet,
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: roduct_id\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"fuzzy\",\"error\":\"strconv.ParseBool: parsing \\\"maybe\\\": invalid syntax\"}]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -76,16 +1256,106 @@ func query400(sd apitest.SeedData) []apitest.Table {
 	return table
 }
 
-func queryByID200(sd apitest.SeedData) []apitest.Table {
+func export200(sd apitest.SeedData) []apitest.Table {
 	table := []apitest.Table{
 		{
 			Name:       "basic",
-			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			URL:        "/v1/products/export",
 			Token:      sd.Users[0].Token,
 			StatusCode: http.StatusOK,
 			Method:     http.MethodGet,
-			GotResp:    &productapp.Product{},
-			ExpResp:    toAppProductPtr(sd.Users[0].Products[0]),
+			ExpHeaders: map[string]string{
+				"Content-Type": "application/x-ndjson",
+			},
+		},
+		{
+			Name:       "json-fields",
+			URL:        "/v1/products/export?format=json&fields=id,name",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			GotResp: &[]map[string]any{},
+			ExpResp: &[]map[string]any{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp := *got.(*[]map[string]any)
+				if len(gotResp) == 0 {
+					return "should export the products"
+				}
+
+				for i, item := range gotResp {
+					if len(item) != 2 || item["id"] == nil || item["name"] == nil {
+						return fmt.Sprintf("item[%d]: should only hold the id and name, got %v", i, item)
+					}
+				}
+
+				return ""
+			},
+		},
+		{
+			Name:       "csv",
+			URL:        "/v1/products/export?format=csv&fields=sku,name,quantity",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			ExpHeaders: map[string]string{
+				"Content-Type":        "text/csv",
+				"Content-Disposition": `attachment; filename="products.csv"`,
+			},
+		},
+	}
+
+	return table
+}
+
+func export400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-filter",
+			URL:        "/v1/products/export?product_id=123",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"product_id\",\"error\":\"invalid UUID length: 3\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-format",
+			URL:        "/v1/products/export?format=xml",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("format", errors.New(`unknown format "xml", must be one of ndjson, json or csv`)),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-fields",
+			URL:        "/v1/products/export?fields=id,color",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("fields", errors.New(`unknown field: "color"`)),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "include-deleted-user",
+			URL:        "/v1/products/export?include_deleted=true",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusForbidden,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -94,3 +1364,106 @@ func queryByID200(sd apitest.SeedData) []apitest.Table {
 
 	return table
 }
+
+func nameAvailable200(sd apitest.SeedData) []apitest.Table {
+	taken := strings.ToUpper(sd.Users[0].Products[0].Name.String())
+
+	table := []apitest.Table{
+		{
+			Name:       "taken-ignoring-case",
+			URL:        "/v1/products/name-availability?name=" + url.QueryEscape(taken),
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.NameAvailability{},
+			ExpResp:    &productapp.NameAvailability{Available: false},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "other-tenant",
+			URL:        "/v1/products/name-availability?name=" + url.QueryEscape(taken),
+			Token:      sd.Admins[1].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.NameAvailability{},
+			ExpResp:    &productapp.NameAvailability{Available: true},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-name",
+			URL:        "/v1/products/name-availability?name=x",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("name", errors.New(`invalid name "x"`)),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func recentlyViewed200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "none",
+			URL:        "/v1/products/recently-viewed",
+			Token:      sd.Users[2].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &productapp.RecentlyViewed{},
+			ExpResp:    &productapp.RecentlyViewed{Items: []productapp.Product{}},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func recentlyViewed400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-limit",
+			URL:        "/v1/products/recently-viewed?limit=1000",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.NewFieldErrors("limit", fmt.Errorf("must be between 1 and %d", productbus.MaxViewsPerUser)),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+// productCursor returns the cursor that continues a product id ordered scroll
+// after the specified product. A nil id starts the scroll at the first product.
+func productCursor(id uuid.UUID) string {
+	tkn := struct {
+		Field     string `json:"f"`
+		Direction string `json:"d"`
+		Value     string `json:"v"`
+		ID        string `json:"id"`
+	}{
+		Field:     productbus.OrderByProductID,
+		Direction: order.ASC,
+		Value:     id.String(),
+		ID:        id.String(),
+	}
+
+	data, _ := json.Marshal(tkn)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
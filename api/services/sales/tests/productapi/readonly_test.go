@@ -0,0 +1,84 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/maintenanceapp"
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func readOnly(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:       "toggle-asuser",
+			URL:        "/v1/maintenance",
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusUnauthorized,
+			Input:      &maintenanceapp.Maintenance{ReadOnly: true},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "enable",
+			URL:        "/v1/maintenance",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input:      &maintenanceapp.Maintenance{ReadOnly: true},
+			GotResp:    &maintenanceapp.Maintenance{},
+			ExpResp:    &maintenanceapp.Maintenance{ReadOnly: true},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "write",
+			URL:        fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodDelete,
+			StatusCode: http.StatusServiceUnavailable,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unavailable, "the service is read-only for maintenance, writes are rejected until it's over"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "read",
+			URL:        fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.Product{},
+			ExpResp:    &productapp.Product{ID: prd.ID.String()},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got.(*productapp.Product).ID, exp.(*productapp.Product).ID)
+			},
+		},
+		{
+			Name:       "disable",
+			URL:        "/v1/maintenance",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input:      &maintenanceapp.Maintenance{ReadOnly: false},
+			GotResp:    &maintenanceapp.Maintenance{},
+			ExpResp:    &maintenanceapp.Maintenance{ReadOnly: false},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
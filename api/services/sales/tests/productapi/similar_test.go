@@ -0,0 +1,79 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+)
+
+func similar200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "no-category",
+			URL:        fmt.Sprintf("/v1/products/%s/similar?page=1&rows=10&band=50", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &query.Result[productapp.Product]{},
+			ExpResp: &query.Result[productapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Items:       []productapp.Product{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func similar400(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "bad-band",
+			URL:        fmt.Sprintf("/v1/products/%s/similar?band=150", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusBadRequest,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"band\",\"error\":\"band must be between 0 and 100, got 150\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func similar404(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "other-tenant",
+			URL:        fmt.Sprintf("/v1/products/%s/similar", prd.ID),
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "query: productID[%s]: db: product not found", prd.ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,75 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func adjustStock200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:       "increment",
+			URL:        fmt.Sprintf("/v1/products/%s/stock", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &productapp.AdjustStock{
+				Delta: 1,
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{
+				ID:          prd.ID.String(),
+				UserID:      prd.UserID.String(),
+				SKU:         prd.SKU.String(),
+				Name:        prd.Name.String(),
+				Cost:        prd.Cost.String(),
+				Quantity:    prd.Quantity.Value() + 1,
+				DateCreated: toAppProduct(prd).DateCreated,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func adjustStock409(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:       "insufficient",
+			URL:        fmt.Sprintf("/v1/products/%s/stock", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusConflict,
+			Input: &productapp.AdjustStock{
+				Delta: -(prd.Quantity.Value() + 2),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Aborted, "insufficient stock"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,74 @@
+package product_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func touch200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/%s/touch", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.Product{},
+			ExpResp:    &productapp.Product{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.ID != prd.ID.String() || gotResp.Name != prd.Name.String() {
+					return fmt.Sprintf("should get back the touched product, got %s %s", gotResp.ID, gotResp.Name)
+				}
+
+				updated, err := time.Parse(time.RFC3339, gotResp.DateUpdated)
+				if err != nil {
+					return err.Error()
+				}
+
+				// Dates are returned to the second so a touch made within
+				// the second of the seed looks unchanged.
+				if updated.Before(prd.DateUpdated.Truncate(time.Second)) {
+					return fmt.Sprintf("dateUpdated should not move back, got %s", gotResp.DateUpdated)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func touch404(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:       "other-tenant",
+			URL:        fmt.Sprintf("/v1/products/%s/touch", prd.ID),
+			Token:      sd.Admins[1].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNotFound,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.NotFound, "query: productID[%s]: db: product not found", prd.ID),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -1,6 +1,8 @@
 package product_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -8,12 +10,49 @@ import (
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/apitest"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/foundation/jsonpatch"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 func update200(sd apitest.SeedData) []apitest.Table {
 	table := []apitest.Table{
+		{
+			Name:       "dry-run",
+			URL:        fmt.Sprintf("/v1/products/%s?dry_run=true", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input: &productapp.UpdateProduct{
+				Name: dbtest.StringPointer("Preview"),
+			},
+			GotResp: &productapp.UpdatePreview{},
+			ExpResp: &productapp.UpdatePreview{
+				DryRun: true,
+				Product: func() productapp.Product {
+					prd := toAppProduct(sd.Users[0].Products[0])
+					prd.Name = "Preview"
+					return prd
+				}(),
+			},
+			ExpHeaders: map[string]string{
+				"ETag": "",
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.UpdatePreview)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.UpdatePreview)
+				expResp.Product.DateUpdated = gotResp.Product.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
 		{
 			Name:       "basic",
 			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
@@ -21,20 +60,26 @@ func update200(sd apitest.SeedData) []apitest.Table {
 			Method:     http.MethodPut,
 			StatusCode: http.StatusOK,
 			Input: &productapp.UpdateProduct{
+				SKU:      dbtest.StringPointer("GTR-100"),
 				Name:     dbtest.StringPointer("Guitar"),
-				Cost:     dbtest.FloatPointer(10.34),
+				Cost:     dbtest.StringPointer("10.34"),
 				Quantity: dbtest.IntPointer(10),
 			},
 			GotResp: &productapp.Product{},
 			ExpResp: &productapp.Product{
 				ID:          sd.Users[0].Products[0].ID.String(),
 				UserID:      sd.Users[0].ID.String(),
+				SKU:         "GTR-100",
 				Name:        "Guitar",
-				Cost:        10.34,
+				Cost:        "10.34",
 				Quantity:    10,
 				DateCreated: sd.Users[0].Products[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Users[0].Products[0].DateCreated.Format(time.RFC3339),
 			},
+			ExpHeaders: map[string]string{
+				"Last-Modified": "",
+				"Cache-Control": "",
+			},
 			CmpFunc: func(got any, exp any) string {
 				gotResp, exists := got.(*productapp.Product)
 				if !exists {
@@ -47,6 +92,118 @@ func update200(sd apitest.SeedData) []apitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:       "delta",
+			URL:        fmt.Sprintf("/v1/products/%s?return=delta", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input: &productapp.UpdateProduct{
+				Name:     dbtest.StringPointer("Guitar V2"),
+				Quantity: dbtest.IntPointer(10),
+			},
+			GotResp: &productapp.PartialProduct{},
+			ExpResp: &productapp.PartialProduct{
+				"name":        json.RawMessage(`"Guitar V2"`),
+				"dateUpdated": nil,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.PartialProduct)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.PartialProduct)
+				(*expResp)["dateUpdated"] = (*gotResp)["dateUpdated"]
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "zero-quantity",
+			URL:        fmt.Sprintf("/v1/products/%s?return=delta", sd.Admins[0].Products[1].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusOK,
+			Input: &productapp.UpdateProduct{
+				Quantity: dbtest.IntPointer(0),
+			},
+			GotResp: &productapp.PartialProduct{},
+			ExpResp: &productapp.PartialProduct{
+				"quantity":    json.RawMessage(`0`),
+				"dateUpdated": nil,
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.PartialProduct)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.PartialProduct)
+				(*expResp)["dateUpdated"] = (*gotResp)["dateUpdated"]
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func priceHistory200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Users[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/products/%s/price-history?page=1&rows=10", prd.ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &query.Result[productapp.PriceChange]{},
+			ExpResp: &query.Result[productapp.PriceChange]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Pages:       1,
+				Items: []productapp.PriceChange{
+					{
+						ProductID: prd.ID.String(),
+						OldCost:   prd.Cost.String(),
+						NewCost:   "10.34",
+					},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[productapp.PriceChange])
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*query.Result[productapp.PriceChange])
+
+				for i := range gotResp.Items {
+					if i < len(expResp.Items) {
+						expResp.Items[i].ID = gotResp.Items[i].ID
+						expResp.Items[i].DateChanged = gotResp.Items[i].DateChanged
+					}
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "wronguser",
+			URL:        fmt.Sprintf("/v1/products/%s/price-history", prd.ID),
+			Token:      sd.Users[1].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_or_subject]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
@@ -61,11 +218,72 @@ func update400(sd apitest.SeedData) []apitest.Table {
 			Method:     http.MethodPut,
 			StatusCode: http.StatusBadRequest,
 			Input: &productapp.UpdateProduct{
-				Cost:     dbtest.FloatPointer(-1.0),
 				Quantity: dbtest.IntPointer(0),
 			},
 			GotResp: &errs.Error{},
-			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"cost\",\"error\":\"cost must be 0 or greater\"},{\"field\":\"quantity\",\"error\":\"quantity must be 1 or greater\"}]"),
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"quantity\",\"error\":\"quantity must be 1 or greater\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-cost",
+			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusBadRequest,
+			Input: &productapp.UpdateProduct{
+				Cost: dbtest.StringPointer("10.345"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.New(errs.InvalidArgument, fmt.Errorf("parse: %w", errs.NewFieldErrors("cost", errors.New("invalid money \"10.345\": more than two decimal places")))),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func update409(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "duplicate-sku",
+			URL:        fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[1].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusConflict,
+			Input: &productapp.UpdateProduct{
+				SKU: dbtest.StringPointer(sd.Admins[0].Products[0].SKU.String()),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.New(errs.Aborted, productbus.ErrDuplicateSKU),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func update412(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:  "stale-etag",
+			URL:   fmt.Sprintf("/v1/products/%s", sd.Users[0].Products[0].ID),
+			Token: sd.Users[0].Token,
+			Headers: map[string]string{
+				"If-Match": productapp.ETag(sd.Users[0].Products[0]),
+			},
+			Method:     http.MethodPut,
+			StatusCode: http.StatusPreconditionFailed,
+			Input: &productapp.UpdateProduct{
+				Name: dbtest.StringPointer("Banjo"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PreconditionFailed, "product version conflict"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -109,7 +327,7 @@ func update401(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusUnauthorized,
 			Input: &productapp.UpdateProduct{
 				Name:     dbtest.StringPointer("Guitar"),
-				Cost:     dbtest.FloatPointer(10.34),
+				Cost:     dbtest.StringPointer("10.34"),
 				Quantity: dbtest.IntPointer(10),
 			},
 			GotResp: &errs.Error{},
@@ -122,3 +340,310 @@ func update401(sd apitest.SeedData) []apitest.Table {
 
 	return table
 }
+
+func patch200(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Admins[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:   "basic",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Admins[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": jsonpatch.ContentType,
+			},
+			StatusCode: http.StatusOK,
+			Input: []map[string]any{
+				{"op": "test", "path": "/name", "value": prd.Name.String()},
+				{"op": "replace", "path": "/cost", "value": "99.50"},
+			},
+			GotResp: &productapp.Product{},
+			ExpResp: &productapp.Product{
+				ID:          prd.ID.String(),
+				UserID:      prd.UserID.String(),
+				SKU:         prd.SKU.String(),
+				Name:        prd.Name.String(),
+				Cost:        "99.50",
+				Quantity:    prd.Quantity.Value(),
+				DateCreated: prd.DateCreated.Format(time.RFC3339),
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.Product)
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch400(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Admins[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "content-type",
+			URL:        fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPatch,
+			StatusCode: http.StatusBadRequest,
+			Input: []map[string]any{
+				{"op": "replace", "path": "/cost", "value": "1.00"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "content type must be application/json-patch+json"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:   "invalid-result",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Admins[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": jsonpatch.ContentType,
+			},
+			StatusCode: http.StatusBadRequest,
+			Input: []map[string]any{
+				{"op": "replace", "path": "/cost", "value": ""},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"cost\",\"error\":\"cost is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func patch422(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Admins[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:   "immutable",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Admins[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": jsonpatch.ContentType,
+			},
+			StatusCode: http.StatusUnprocessableEntity,
+			Input: []map[string]any{
+				{"op": "replace", "path": "/dateCreated", "value": "2020-01-01T00:00:00Z"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.UnprocessableEntity, "path \"/dateCreated\" can't be modified"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:   "immutable-from",
+			URL:    fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:  sd.Admins[0].Token,
+			Method: http.MethodPatch,
+			Headers: map[string]string{
+				"Content-Type": jsonpatch.ContentType,
+			},
+			StatusCode: http.StatusUnprocessableEntity,
+			Input: []map[string]any{
+				{"op": "move", "from": "/id", "path": "/name"},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.UnprocessableEntity, "path \"/id\" can't be modified"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkUpdate207(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Admins[0].Products[1]
+	missing := uuid.NewString()
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/products/bulk",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPatch,
+			StatusCode: http.StatusMultiStatus,
+			Input: productapp.BulkUpdateItems{
+				{ID: prd.ID.String(), Fields: productapp.UpdateProduct{Description: dbtest.StringPointer("Bulk updated")}},
+				{ID: missing, Fields: productapp.UpdateProduct{Description: dbtest.StringPointer("Bulk updated")}},
+			},
+			GotResp: &productapp.BulkMultiStatus{},
+			ExpResp: &productapp.BulkMultiStatus{
+				Mode:      "atomic",
+				Atomic:    true,
+				Succeeded: 1,
+				Failed:    1,
+				Items: []productapp.BulkItemResult{
+					{Index: 0, Status: http.StatusOK, ID: prd.ID.String()},
+					{Index: 1, Status: http.StatusNotFound, ID: missing, Error: productbus.ErrNotFound.Error()},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.BulkMultiStatus)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.BulkMultiStatus)
+				expResp.Note = gotResp.Note
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkUpdate400(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Admins[0].Products[1]
+
+	table := []apitest.Table{
+		{
+			Name:       "duplicate-id",
+			URL:        "/v1/products/bulk",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPatch,
+			StatusCode: http.StatusBadRequest,
+			Input: productapp.BulkUpdateItems{
+				{ID: prd.ID.String(), Fields: productapp.UpdateProduct{Description: dbtest.StringPointer("First")}},
+				{ID: prd.ID.String(), Fields: productapp.UpdateProduct{Description: dbtest.StringPointer("Second")}},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.NewFieldErrors("[1]", errors.New("id: duplicate of index[0]")),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func upsert207(sd apitest.SeedData) []apitest.Table {
+	prd := sd.Admins[0].Products[0]
+
+	table := []apitest.Table{
+		{
+			Name:       "by-sku",
+			URL:        "/v1/products/upsert",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusMultiStatus,
+			Input: productapp.UpsertItems{
+				{Product: productapp.NewProduct{SKU: prd.SKU.String(), Name: "Upserted", Cost: "12.50", Quantity: dbtest.IntPointer(3)}},
+				{Product: productapp.NewProduct{SKU: "UPS-001", Name: "Upserted", Cost: "7.25", Quantity: dbtest.IntPointer(1)}},
+				{Product: productapp.NewProduct{SKU: "UPS-001", Name: "Again", Cost: "7.25", Quantity: dbtest.IntPointer(1)}},
+			},
+			GotResp: &productapp.BulkMultiStatus{},
+			ExpResp: &productapp.BulkMultiStatus{
+				Mode:      "partial",
+				Succeeded: 2,
+				Failed:    1,
+				Items: []productapp.BulkItemResult{
+					{Index: 0, Status: http.StatusOK, ID: prd.ID.String(), Action: "updated"},
+					{Index: 1, Status: http.StatusCreated, Action: "created"},
+					{Index: 2, Status: http.StatusBadRequest, Error: "sku: duplicate of index[1]"},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.BulkMultiStatus)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.BulkMultiStatus)
+				expResp.Note = gotResp.Note
+
+				if len(gotResp.Items) == len(expResp.Items) {
+					expResp.Items[1].ID = gotResp.Items[1].ID
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "by-sku-tags",
+			URL:        "/v1/products/upsert",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusMultiStatus,
+			Input: productapp.UpsertItems{
+				{Product: productapp.NewProduct{SKU: prd.SKU.String(), Name: "Upserted", Cost: "12.50", Quantity: dbtest.IntPointer(3), Tags: []string{"clearance", "summer"}}},
+			},
+			GotResp: &productapp.BulkMultiStatus{},
+			ExpResp: &productapp.BulkMultiStatus{
+				Mode:      "partial",
+				Succeeded: 1,
+				Items: []productapp.BulkItemResult{
+					{Index: 0, Status: http.StatusOK, ID: prd.ID.String(), Action: "updated"},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.BulkMultiStatus)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*productapp.BulkMultiStatus)
+				expResp.Note = gotResp.Note
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:       "by-sku-tags-stored",
+			URL:        fmt.Sprintf("/v1/products/%s", prd.ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodGet,
+			StatusCode: http.StatusOK,
+			GotResp:    &productapp.Product{},
+			ExpResp:    &productapp.Product{Tags: []string{"clearance", "summer"}},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*productapp.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				return cmp.Diff(gotResp.Tags, exp.(*productapp.Product).Tags)
+			},
+		},
+		{
+			Name:       "bad-conflict",
+			URL:        "/v1/products/upsert?conflict=name",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: productapp.UpsertItems{
+				{Product: productapp.NewProduct{SKU: prd.SKU.String(), Name: "Upserted", Cost: "12.50", Quantity: dbtest.IntPointer(3)}},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.NewFieldErrors("conflict", errors.New(`unknown conflict target "name", must be one of sku, id`)),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
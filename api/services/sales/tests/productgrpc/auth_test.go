@@ -0,0 +1,71 @@
+package productgrpc_test
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"google.golang.org/grpc/codes"
+)
+
+func authFailures(sd apitest.SeedData) []table {
+	table := []table{
+		{
+			name: "emptytoken",
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Query(ctx, &productpb.QueryRequest{})
+			},
+			code: codes.Unauthenticated.String(),
+		},
+		{
+			name:  "badtoken",
+			token: sd.Users[0].Token[:10],
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Query(ctx, &productpb.QueryRequest{})
+			},
+			code: codes.Unauthenticated.String(),
+		},
+		{
+			name:  "wrongrole",
+			token: sd.Users[2].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Create(ctx, &productpb.NewProductRequest{Name: "Guitar", Cost: 10.34, Quantity: 10})
+			},
+			code: codes.Unauthenticated.String(),
+		},
+		{
+			name:  "wronguser",
+			token: sd.Users[1].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.QueryByID(ctx, &productpb.QueryByIDRequest{ProductId: sd.Users[0].Products[0].ID.String()})
+			},
+			code: codes.Unauthenticated.String(),
+		},
+		{
+			name:  "badid",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.QueryByID(ctx, &productpb.QueryByIDRequest{ProductId: "abc"})
+			},
+			code: codes.Unauthenticated.String(),
+		},
+		{
+			name:  "other-tenant",
+			token: sd.Admins[1].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.QueryByID(ctx, &productpb.QueryByIDRequest{ProductId: sd.Users[0].Products[0].ID.String()})
+			},
+			code: codes.NotFound.String(),
+		},
+		{
+			name:  "other-tenant-delete",
+			token: sd.Admins[1].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Delete(ctx, &productpb.DeleteProductRequest{ProductId: sd.Users[0].Products[0].ID.String()})
+			},
+			code: codes.NotFound.String(),
+		},
+	}
+
+	return table
+}
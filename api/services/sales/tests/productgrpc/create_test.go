@@ -0,0 +1,55 @@
+package productgrpc_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"google.golang.org/grpc/codes"
+)
+
+func create(sd apitest.SeedData) []table {
+	table := []table{
+		{
+			name:  "basic",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Create(ctx, &productpb.NewProductRequest{Name: "Guitar", Cost: 10.34, Quantity: 10})
+			},
+			code: codes.OK.String(),
+			check: func(resp any) string {
+				got, ok := resp.(*productpb.Product)
+				if !ok {
+					return "error occurred"
+				}
+
+				exp := &productpb.Product{
+					Id:          got.GetId(),
+					UserId:      sd.Users[0].ID.String(),
+					Name:        "Guitar",
+					Cost:        10.34,
+					Quantity:    10,
+					DateCreated: got.GetDateCreated(),
+					DateUpdated: got.GetDateUpdated(),
+				}
+
+				if got.GetId() == "" {
+					return fmt.Sprintf("got an empty id for %v", got)
+				}
+
+				return cmpProduct(got, exp)
+			},
+		},
+		{
+			name:  "bad-input",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Create(ctx, &productpb.NewProductRequest{Name: "", Cost: -1, Quantity: 10})
+			},
+			code: codes.InvalidArgument.String(),
+		},
+	}
+
+	return table
+}
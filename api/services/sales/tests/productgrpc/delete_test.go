@@ -0,0 +1,48 @@
+package productgrpc_test
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"google.golang.org/grpc/codes"
+)
+
+func deleteProduct(sd apitest.SeedData) []table {
+	table := []table{
+		{
+			name:  "asuser",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Delete(ctx, &productpb.DeleteProductRequest{ProductId: sd.Users[0].Products[1].ID.String()})
+			},
+			code: codes.OK.String(),
+		},
+		{
+			name:  "asuser-again",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Delete(ctx, &productpb.DeleteProductRequest{ProductId: sd.Users[0].Products[1].ID.String()})
+			},
+			code: codes.OK.String(),
+		},
+		{
+			name:  "querybyid-deleted",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.QueryByID(ctx, &productpb.QueryByIDRequest{ProductId: sd.Users[0].Products[1].ID.String()})
+			},
+			code: codes.NotFound.String(),
+		},
+		{
+			name:  "asadmin",
+			token: sd.Admins[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Delete(ctx, &productpb.DeleteProductRequest{ProductId: sd.Admins[0].Products[0].ID.String()})
+			},
+			code: codes.OK.String(),
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,182 @@
+package productgrpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authbuild "github.com/ardanlabs/service/api/services/auth/build/all"
+	"github.com/ardanlabs/service/app/domain/productgrpc"
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/foundation/maintenance"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func Test_ProductGRPC(t *testing.T) {
+	t.Parallel()
+
+	test := newTest(t, "Test_ProductGRPC")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.db, test.auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.run(t, authFailures(sd), "auth")
+	test.run(t, query(sd), "query")
+	test.run(t, queryByID(sd), "querybyid")
+	test.run(t, create(sd), "create")
+	test.run(t, update(sd), "update")
+	test.run(t, readOnly(sd, test.mode), "readonly")
+	test.run(t, deleteProduct(sd), "delete")
+}
+
+// =============================================================================
+
+// table represents a call to the product service and its expected outcome.
+type table struct {
+	name  string
+	token string
+	call  func(ctx context.Context, client productpb.ProductServiceClient) (any, error)
+	code  string
+	check func(resp any) string
+}
+
+type grpcTest struct {
+	db     *dbtest.Database
+	auth   *auth.Auth
+	mode   *maintenance.Mode
+	client productpb.ProductServiceClient
+}
+
+// newTest starts the product gRPC server against a test database with the
+// auth service running behind it, the same way apitest does for REST.
+func newTest(t *testing.T, testName string) *grpcTest {
+	db := dbtest.New(t, testName)
+
+	ath, err := auth.New(auth.Config{
+		Log:       db.Log,
+		UserBus:   db.BusDomain.User,
+		KeyLookup: &apitest.KeyStore{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authServer := httptest.NewServer(mux.WebAPI(mux.Config{
+		Log: db.Log,
+		DB:  db.DB,
+		BusConfig: mux.BusConfig{
+			UserBus: db.BusDomain.User,
+		},
+		AuthConfig: mux.AuthConfig{
+			Auth: ath,
+		},
+	}, authbuild.Routes()))
+	t.Cleanup(authServer.Close)
+
+	mode := maintenance.New(false)
+
+	srv := productgrpc.NewServer(productgrpc.Config{
+		Log:        db.Log,
+		ProductBus: db.BusDomain.Product,
+		AuthClient: authclient.New(db.Log, authServer.URL),
+		ReadOnly:   mode,
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &grpcTest{
+		db:     db,
+		auth:   ath,
+		mode:   mode,
+		client: productpb.NewProductServiceClient(conn),
+	}
+}
+
+func (gt *grpcTest) run(t *testing.T, table []table, testName string) {
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := tt.call(ctx, gt.client)
+
+			if got := status.Code(err).String(); got != tt.code {
+				t.Fatalf("%s: Should receive a code of %s for the call : %s : %v", tt.name, tt.code, got, err)
+			}
+
+			if tt.check == nil {
+				return
+			}
+
+			if diff := tt.check(resp); diff != "" {
+				t.Log("DIFF")
+				t.Logf("%s", diff)
+				t.Fatalf("Should get the expected response")
+			}
+		}
+
+		t.Run(testName+"-"+tt.name, f)
+	}
+}
+
+// =============================================================================
+
+func toPBProduct(prd productbus.Product) *productpb.Product {
+	pb := productpb.Product{
+		Id:          prd.ID.String(),
+		UserId:      prd.UserID.String(),
+		Name:        prd.Name.String(),
+		Cost:        prd.Cost.Value(),
+		Quantity:    int64(prd.Quantity.Value()),
+		DateCreated: prd.DateCreated.Format(time.RFC3339),
+		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+	}
+
+	return &pb
+}
+
+func cmpProduct(got any, exp *productpb.Product) string {
+	gotPrd, ok := got.(*productpb.Product)
+	if !ok {
+		return "error occurred"
+	}
+
+	if !proto.Equal(gotPrd, exp) {
+		return fmt.Sprintf("got %v\nexp %v", gotPrd, exp)
+	}
+
+	return ""
+}
@@ -0,0 +1,113 @@
+package productgrpc_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"google.golang.org/grpc/codes"
+)
+
+func query(sd apitest.SeedData) []table {
+	prd := sd.Users[0].Products[0]
+	productID := prd.ID.String()
+
+	table := []table{
+		{
+			name:  "basic",
+			token: sd.Admins[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Query(ctx, &productpb.QueryRequest{ProductId: &productID})
+			},
+			code: codes.OK.String(),
+			check: func(resp any) string {
+				got, ok := resp.(*productpb.QueryResponse)
+				if !ok {
+					return "error occurred"
+				}
+
+				if got.GetTotal() != 1 || len(got.GetItems()) != 1 {
+					return fmt.Sprintf("got %d items of %d, exp 1 of 1", len(got.GetItems()), got.GetTotal())
+				}
+
+				return cmpProduct(got.GetItems()[0], toPBProduct(prd))
+			},
+		},
+		{
+			name:  "buyer-redacted",
+			token: sd.Users[2].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Query(ctx, &productpb.QueryRequest{ProductId: &productID})
+			},
+			code: codes.OK.String(),
+			check: func(resp any) string {
+				got, ok := resp.(*productpb.QueryResponse)
+				if !ok || len(got.GetItems()) != 1 {
+					return "error occurred"
+				}
+
+				exp := toPBProduct(prd)
+				exp.Cost = 0
+
+				return cmpProduct(got.GetItems()[0], exp)
+			},
+		},
+		{
+			name:  "bad-order",
+			token: sd.Admins[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Query(ctx, &productpb.QueryRequest{OrderBy: "ser,DESC"})
+			},
+			code: codes.InvalidArgument.String(),
+		},
+		{
+			name:  "buyer-cost-order",
+			token: sd.Users[2].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Query(ctx, &productpb.QueryRequest{OrderBy: "cost,DESC"})
+			},
+			code: codes.PermissionDenied.String(),
+		},
+		{
+			name:  "user-include-deleted",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				includeDeleted := true
+				return client.Query(ctx, &productpb.QueryRequest{IncludeDeleted: &includeDeleted})
+			},
+			code: codes.PermissionDenied.String(),
+		},
+	}
+
+	return table
+}
+
+func queryByID(sd apitest.SeedData) []table {
+	table := []table{
+		{
+			name:  "basic",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.QueryByID(ctx, &productpb.QueryByIDRequest{ProductId: sd.Users[0].Products[0].ID.String()})
+			},
+			code: codes.OK.String(),
+			check: func(resp any) string {
+				return cmpProduct(resp, toPBProduct(sd.Users[0].Products[0]))
+			},
+		},
+		{
+			name:  "asadmin",
+			token: sd.Admins[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.QueryByID(ctx, &productpb.QueryByIDRequest{ProductId: sd.Users[0].Products[0].ID.String()})
+			},
+			code: codes.OK.String(),
+			check: func(resp any) string {
+				return cmpProduct(resp, toPBProduct(sd.Users[0].Products[0]))
+			},
+		},
+	}
+
+	return table
+}
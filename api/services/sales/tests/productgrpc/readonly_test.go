@@ -0,0 +1,70 @@
+package productgrpc_test
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/foundation/maintenance"
+	"google.golang.org/grpc/codes"
+)
+
+// readOnly calls the service while it's in maintenance. Writes are rejected
+// and reads are still served.
+func readOnly(sd apitest.SeedData, mode *maintenance.Mode) []table {
+	inMaintenance := func(call func(ctx context.Context, client productpb.ProductServiceClient) (any, error)) func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+		f := func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+			mode.SetReadOnly(true)
+			defer mode.SetReadOnly(false)
+
+			return call(ctx, client)
+		}
+
+		return f
+	}
+
+	table := []table{
+		{
+			name:  "create",
+			token: sd.Users[0].Token,
+			call: inMaintenance(func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Create(ctx, &productpb.NewProductRequest{Name: "Drums", Cost: 10.34, Quantity: 10})
+			}),
+			code: codes.Unavailable.String(),
+		},
+		{
+			name:  "update",
+			token: sd.Users[0].Token,
+			call: inMaintenance(func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				name := "Drums"
+				return client.Update(ctx, &productpb.UpdateProductRequest{ProductId: sd.Users[0].Products[0].ID.String(), Name: &name})
+			}),
+			code: codes.Unavailable.String(),
+		},
+		{
+			name:  "delete",
+			token: sd.Users[0].Token,
+			call: inMaintenance(func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Delete(ctx, &productpb.DeleteProductRequest{ProductId: sd.Users[0].Products[0].ID.String()})
+			}),
+			code: codes.Unavailable.String(),
+		},
+		{
+			name:  "querybyid",
+			token: sd.Users[0].Token,
+			call: inMaintenance(func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.QueryByID(ctx, &productpb.QueryByIDRequest{ProductId: sd.Users[0].Products[0].ID.String()})
+			}),
+			code: codes.OK.String(),
+		},
+		{
+			name: "unauthenticated",
+			call: inMaintenance(func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				return client.Create(ctx, &productpb.NewProductRequest{Name: "Drums", Cost: 10.34, Quantity: 10})
+			}),
+			code: codes.Unauthenticated.String(),
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,95 @@
+package productgrpc_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/uuid"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds, err := productbus.TestGenerateSeedProducts(ctx, 2, busDomain.Product, usrs[0].ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:     usrs[0],
+		Products: prds,
+		Token:    apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	prds, err = productbus.TestGenerateSeedProducts(ctx, 1, busDomain.Product, usrs[0].ID)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:     usrs[0],
+		Products: prds,
+		Token:    apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// The same admin acting on behalf of another tenant, which owns none of
+	// the seeded products.
+	tu3 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.TenantToken(db.BusDomain.User, ath, usrs[0].Email.Address, uuid.New()),
+	}
+
+	// -------------------------------------------------------------------------
+
+	// A second user that owns no products, used to check the products of
+	// another user are out of reach.
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu4 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Buyer, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu5 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := apitest.SeedData{
+		Admins: []apitest.User{tu2, tu3},
+		Users:  []apitest.User{tu1, tu4, tu5},
+	}
+
+	return sd, nil
+}
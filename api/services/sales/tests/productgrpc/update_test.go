@@ -0,0 +1,57 @@
+package productgrpc_test
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"google.golang.org/grpc/codes"
+)
+
+func update(sd apitest.SeedData) []table {
+	table := []table{
+		{
+			name:  "basic",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				name := "Guitar"
+				quantity := int64(12)
+
+				return client.Update(ctx, &productpb.UpdateProductRequest{
+					ProductId: sd.Users[0].Products[0].ID.String(),
+					Name:      &name,
+					Quantity:  &quantity,
+				})
+			},
+			code: codes.OK.String(),
+			check: func(resp any) string {
+				got, ok := resp.(*productpb.Product)
+				if !ok {
+					return "error occurred"
+				}
+
+				exp := toPBProduct(sd.Users[0].Products[0])
+				exp.Name = "Guitar"
+				exp.Quantity = 12
+				exp.DateUpdated = got.GetDateUpdated()
+
+				return cmpProduct(got, exp)
+			},
+		},
+		{
+			name:  "bad-input",
+			token: sd.Users[0].Token,
+			call: func(ctx context.Context, client productpb.ProductServiceClient) (any, error) {
+				cost := -1.0
+
+				return client.Update(ctx, &productpb.UpdateProductRequest{
+					ProductId: sd.Users[0].Products[0].ID.String(),
+					Cost:      &cost,
+				})
+			},
+			code: codes.InvalidArgument.String(),
+		},
+	}
+
+	return table
+}
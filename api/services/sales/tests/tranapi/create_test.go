@@ -19,6 +19,7 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusOK,
 			Input: &tranapp.NewTran{
 				Product: tranapp.NewProduct{
+					SKU:      "GTR-001",
 					Name:     "Guitar",
 					Cost:     10.34,
 					Quantity: 10,
@@ -34,6 +35,7 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			},
 			GotResp: &tranapp.Product{},
 			ExpResp: &tranapp.Product{
+				SKU:      "GTR-001",
 				Name:     "Guitar",
 				Cost:     10.34,
 				Quantity: 10,
@@ -69,7 +71,7 @@ func create400(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusBadRequest,
 			Input:      &tranapp.NewTran{},
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"cost\",\"error\":\"cost is a required field\"},{\"field\":\"quantity\",\"error\":\"quantity is a required field\"},{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"email\",\"error\":\"email is a required field\"},{\"field\":\"roles\",\"error\":\"roles is a required field\"},{\"field\":\"password\",\"error\":\"password is a required field\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"sku\",\"error\":\"sku is a required field\"},{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"cost\",\"error\":\"cost is a required field\"},{\"field\":\"quantity\",\"error\":\"quantity is a required field\"},{\"field\":\"name\",\"error\":\"name is a required field\"},{\"field\":\"email\",\"error\":\"email is a required field\"},{\"field\":\"roles\",\"error\":\"roles is a required field\"},{\"field\":\"password\",\"error\":\"password is a required field\"}]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -82,6 +84,7 @@ func create400(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusBadRequest,
 			Input: &tranapp.NewTran{
 				Product: tranapp.NewProduct{
+					SKU:      "GTR-001",
 					Name:     "Gu",
 					Cost:     10.34,
 					Quantity: 10,
@@ -40,6 +40,7 @@ func query200(sd apitest.SeedData) []apitest.Table {
 				Page:        1,
 				RowsPerPage: 10,
 				Total:       len(usrs),
+				Pages:       1,
 				Items:       toAppUsers(usrs),
 			},
 			CmpFunc: func(got any, exp any) string {
@@ -72,7 +73,7 @@ func query400(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusBadRequest,
 			Method:     http.MethodGet,
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: ser_id\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: ser_id, must be one of email, enabled, name, roles, user_id\"}]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/domain/vproductapp"
+	"github.com/ardanlabs/service/app/sdk/decimal"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 )
@@ -13,7 +14,7 @@ func toAppVProduct(usr userbus.User, prd productbus.Product) vproductapp.Product
 		ID:          prd.ID.String(),
 		UserID:      prd.UserID.String(),
 		Name:        prd.Name.String(),
-		Cost:        prd.Cost.Value(),
+		Cost:        decimal.Cost(prd.Cost.Value()),
 		Quantity:    prd.Quantity.Value(),
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
@@ -31,12 +31,29 @@ func query200(sd apitest.SeedData) []apitest.Table {
 				Page:        1,
 				RowsPerPage: 10,
 				Total:       len(prds),
+				Pages:       1,
 				Items:       prds,
 			},
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:       "other-tenant",
+			URL:        "/v1/vproducts?page=1&rows=10&orderBy=product_id,ASC",
+			Token:      sd.Admins[1].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[vproductapp.Product]{},
+			ExpResp: &query.Result[vproductapp.Product]{
+				Page:        1,
+				RowsPerPage: 10,
+				Items:       []vproductapp.Product{},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
@@ -63,7 +80,7 @@ func query400(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusBadRequest,
 			Method:     http.MethodGet,
 			GotResp:    &errs.Error{},
-			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: roduct_id\"}]"),
+			ExpResp:    errs.Newf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: roduct_id, must be one of cost, name, product_id, quantity, user_id, user_name\"}]"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
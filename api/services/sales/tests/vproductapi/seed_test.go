@@ -10,6 +10,7 @@ import (
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/uuid"
 )
 
 func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
@@ -50,10 +51,17 @@ func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, erro
 		Token:    apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
 	}
 
+	// The same admin acting on behalf of another tenant, which owns none of
+	// the seeded products.
+	tu3 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.TenantToken(db.BusDomain.User, ath, usrs[0].Email.Address, uuid.New()),
+	}
+
 	// -------------------------------------------------------------------------
 
 	sd := apitest.SeedData{
-		Admins: []apitest.User{tu2},
+		Admins: []apitest.User{tu2, tu3},
 		Users:  []apitest.User{tu1},
 	}
 
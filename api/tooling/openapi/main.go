@@ -0,0 +1,838 @@
+// This program generates the OpenAPI 3 document describing the product and
+// category endpoints of the sales service.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/ardanlabs/service/app/domain/categoryapp"
+	"github.com/ardanlabs/service/app/domain/maintenanceapp"
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/foundation/graphql"
+	"github.com/ardanlabs/service/foundation/jsonpatch"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+var out string
+
+func init() {
+	flag.StringVar(&out, "out", "swagger.json", "file to write the document to")
+}
+
+func main() {
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	data, err := json.MarshalIndent(document(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	if err := os.WriteFile(out, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+var schemas = map[string]reflect.Type{
+	"Product":              reflect.TypeFor[productapp.Product](),
+	"ProductV2":            reflect.TypeFor[productapp.ProductV2](),
+	"NewProduct":           reflect.TypeFor[productapp.NewProduct](),
+	"NewProducts":          reflect.TypeFor[productapp.NewProducts](),
+	"BulkUpdateItems":      reflect.TypeFor[productapp.BulkUpdateItems](),
+	"UpsertItems":          reflect.TypeFor[productapp.UpsertItems](),
+	"UpdateProduct":        reflect.TypeFor[productapp.UpdateProduct](),
+	"AdjustStock":          reflect.TypeFor[productapp.AdjustStock](),
+	"NewImageUpload":       reflect.TypeFor[productapp.NewImageUpload](),
+	"ImageUploadURL":       reflect.TypeFor[productapp.ImageUploadURL](),
+	"ConfirmImage":         reflect.TypeFor[productapp.ConfirmImage](),
+	"ProductIDs":           reflect.TypeFor[productapp.ProductIDs](),
+	"BatchResult":          reflect.TypeFor[productapp.BatchResult](),
+	"NameAvailability":     reflect.TypeFor[productapp.NameAvailability](),
+	"Summary":              reflect.TypeFor[productapp.Summary](),
+	"ProductEvent":         reflect.TypeFor[productapp.ProductEvent](),
+	"RecentlyViewed":       reflect.TypeFor[productapp.RecentlyViewed](),
+	"BulkResult":           reflect.TypeFor[productapp.BulkResult](),
+	"BulkDeleteResult":     reflect.TypeFor[productapp.BulkDeleteResult](),
+	"ImportResult":         reflect.TypeFor[productapp.ImportResult](),
+	"BulkMultiStatus":      reflect.TypeFor[productapp.BulkMultiStatus](),
+	"PriceAdjustment":      reflect.TypeFor[productapp.PriceAdjustment](),
+	"BulkPriceResult":      reflect.TypeFor[productapp.BulkPriceResult](),
+	"UpdatePreview":        reflect.TypeFor[productapp.UpdatePreview](),
+	"QueryResponse":        reflect.TypeFor[query.Result[productapp.Product]](),
+	"SearchResponse":       reflect.TypeFor[query.Result[productapp.SearchResult]](),
+	"PopularResponse":      reflect.TypeFor[query.Result[productapp.PopularProduct]](),
+	"PriceHistoryResponse": reflect.TypeFor[query.Result[productapp.PriceChange]](),
+	"AuditTrailResponse":   reflect.TypeFor[query.Result[productapp.AuditEntry]](),
+	"ProductDiff":          reflect.TypeFor[productapp.ProductDiff](),
+	"Error":                reflect.TypeFor[errs.Error](),
+	"Problem":              reflect.TypeFor[errs.Problem](),
+	"GraphQLRequest":       reflect.TypeFor[graphql.Request](),
+	"GraphQLError":         reflect.TypeFor[graphql.Error](),
+	"JSONPatch":            reflect.TypeFor[jsonpatch.Patch](),
+
+	"Category":              reflect.TypeFor[categoryapp.Category](),
+	"NewCategory":           reflect.TypeFor[categoryapp.NewCategory](),
+	"CategoryQueryResponse": reflect.TypeFor[query.Result[categoryapp.Category]](),
+
+	"Maintenance": reflect.TypeFor[maintenanceapp.Maintenance](),
+}
+
+func document() map[string]any {
+	components := map[string]any{}
+	for name, typ := range schemas {
+		components[name] = schemaFor(typ)
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Sales Product API",
+			"version": "v1",
+		},
+		"paths": map[string]any{
+			"/v1/products": map[string]any{
+				"get": operation("Query products", append(queryParams(), expandParam(), headerParam("If-None-Match")), nil,
+					linkedResponse("QueryResponse"),
+					noContent(http.StatusNotModified, "Not Modified"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)),
+				"head": operation("Count products", queryParams(), nil,
+					countResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)),
+				"post": operation("Create a product", []any{idempotencyKeyParam()}, body("NewProduct"),
+					createdResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusTooManyRequests, http.StatusRequestEntityTooLarge)),
+				"delete": operation("Delete the products matching a filter", append(filterParams(), confirmParam("the products to be deleted"), reasonParam()), nil,
+					response(http.StatusOK, "BulkDeleteResult"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/prices": map[string]any{
+				"post": operation("Adjust the cost of the products matching a filter, admins only", append(filterParams(), confirmParam("the costs to be changed")), body("PriceAdjustment"),
+					response(http.StatusOK, "BulkPriceResult"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/search": map[string]any{
+				"get": operation("Search products by name and description", searchParams(), nil,
+					pagedResponse("SearchResponse"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/summary": map[string]any{
+				"get": operation("Summarize the products matching a filter", filterParams(), nil,
+					response(http.StatusOK, "Summary"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)),
+			},
+			"/v1/products/export": map[string]any{
+				"get": operation("Export products as newline delimited JSON, a JSON array or CSV", append(filterParams(), exportFormatParam(), fieldsParam()), nil,
+					exportResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)),
+			},
+			"/v1/products/stream": map[string]any{
+				"get": operation("Stream the changes made to the products of the tenant as server-sent events, admins only", []any{
+					param("category_id", "query", "only stream the changes of the products of the category", str("uuid")),
+				}, nil,
+					streamResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/import": map[string]any{
+				"post": operation("Import products from newline delimited JSON, one NewProduct per line. Deployments can require signed imports instead of a bearer token", signatureParams(), importBody(),
+					importResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusRequestEntityTooLarge)),
+			},
+			"/v1/products/lookup": map[string]any{
+				"get": operation("Query a product by sku", []any{skuParam(), fieldsParam(), expandParam()}, nil,
+					cachedResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/batch": map[string]any{
+				"get": operation("Query products by ids", []any{idsParam()}, nil,
+					response(http.StatusOK, "BatchResult"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+				"post": operation("Query products by ids", nil, body("ProductIDs"),
+					response(http.StatusOK, "BatchResult"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/name-availability": map[string]any{
+				"get": operation("Check that no product uses a name, ignoring case", []any{nameParam()}, nil,
+					response(http.StatusOK, "NameAvailability"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/recently-viewed": map[string]any{
+				"get": operation("Query the products the caller viewed most recently, most recent first", []any{
+					param("limit", "query", "the number of products to return, 10 by default and at most 50", map[string]any{"type": "integer", "minimum": 1, "maximum": 50}),
+				}, nil,
+					response(http.StatusOK, "RecentlyViewed"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/popular": map[string]any{
+				"get": operation("Query the products viewed by the most users, most viewed first. The ranking is refreshed on a schedule and counted live once it's too old", pageParams(), nil,
+					pagedResponse("PopularResponse"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/popular/refresh": map[string]any{
+				"post": operation("Refresh the ranking of the popular products of every tenant now, admins only", nil, nil,
+					noContent(http.StatusNoContent, "No Content"),
+					errResponses(http.StatusUnauthorized)),
+			},
+			"/v1/products/bulk": map[string]any{
+				"post": operation("Create a batch of products", []any{modeParam()}, body("NewProducts"),
+					bulkCreateResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusTooManyRequests, http.StatusRequestEntityTooLarge)),
+				"patch": operation("Update a batch of products, admins only", []any{bulkUpdateModeParam()}, body("BulkUpdateItems"),
+					response(http.StatusMultiStatus, "BulkMultiStatus"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusRequestEntityTooLarge)),
+			},
+			"/v1/products/upsert": map[string]any{
+				"post": operation("Create or update a batch of products matched by sku or id, admins only", []any{conflictParam()}, body("UpsertItems"),
+					response(http.StatusMultiStatus, "BulkMultiStatus"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/{product_id}": map[string]any{
+				"parameters": []any{productIDParam()},
+				"get": operation("Query a product by id", []any{headerParam("Accept"), headerParam("If-None-Match"), ifModifiedSinceParam(), fieldsParam(), expandParam()}, nil,
+					cachedResponse(),
+					noContent(http.StatusNotModified, "Not Modified"),
+					noContent(http.StatusNotAcceptable, "Not Acceptable"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound)),
+				"put": operation("Update a product", []any{headerParam("If-Match"), dryRunParam(), returnParam()}, body("UpdateProduct"),
+					response(http.StatusOK, "Product"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusRequestEntityTooLarge)),
+				"patch": operation("Patch a product", []any{headerParam("If-Match")}, patchBody(),
+					response(http.StatusOK, "Product"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge)),
+				"delete": operation("Delete a product", []any{reasonParam(), strictParam()}, nil,
+					noContent(http.StatusNoContent, "No Content"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound)),
+			},
+			"/v1/products/{product_id}/stock": map[string]any{
+				"parameters": []any{productIDParam()},
+				"post": operation("Adjust the stock of a product", nil, body("AdjustStock"),
+					response(http.StatusOK, "Product"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict)),
+			},
+			"/v1/products/{product_id}/clone": map[string]any{
+				"parameters": []any{productIDParam()},
+				"post": operation("Create a product from a copy of another, the optional body overrides the copied fields", nil, optionalBody("UpdateProduct"),
+					createdResponse(),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge)),
+			},
+			"/v1/products/{product_id}/touch": map[string]any{
+				"parameters": []any{productIDParam()},
+				"post": operation("Bump the update date of a product without changing it so caches keyed on it are refreshed", nil, nil,
+					response(http.StatusOK, "Product"),
+					errResponses(http.StatusUnauthorized, http.StatusNotFound)),
+			},
+			"/v1/products/{product_id}/image/upload-url": map[string]any{
+				"parameters": []any{productIDParam()},
+				"post": operation("Get a pre-signed url to upload an image of a product, only available when object storage is configured", nil, body("NewImageUpload"),
+					response(http.StatusOK, "ImageUploadURL"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound)),
+			},
+			"/v1/products/{product_id}/image/confirm": map[string]any{
+				"parameters": []any{productIDParam()},
+				"post": operation("Confirm an uploaded image as the image of a product", nil, body("ConfirmImage"),
+					response(http.StatusOK, "Product"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound)),
+			},
+			"/v1/products/{product_id}/audit": map[string]any{
+				"parameters": []any{productIDParam()},
+				"get": operation("Query the audit trail of a product, oldest first, admins only", append(pageParams(),
+					param("since", "query", "only return changes made at or after this time", str("date-time")),
+					param("until", "query", "only return changes made at or before this time", str("date-time"))), nil,
+					pagedResponse("AuditTrailResponse"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+			"/v1/products/{product_id}/diff": map[string]any{
+				"parameters": []any{productIDParam()},
+				"get": operation("Compare two versions of a product from its audit trail, admins only", versionParams(), nil,
+					response(http.StatusOK, "ProductDiff"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound)),
+			},
+			"/v1/products/{product_id}/price-history": map[string]any{
+				"parameters": []any{productIDParam()},
+				"get": operation("Query the cost changes of a product, oldest first", pageParams(), nil,
+					pagedResponse("PriceHistoryResponse"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)),
+			},
+			"/v1/products/{product_id}/similar": map[string]any{
+				"parameters": []any{productIDParam()},
+				"get": operation("Query the other products of the category of a product whose cost is close to its cost, closest first", append(pageParams(),
+					param("band", "query", "the price band in percent of the cost of the product, 20 by default", map[string]any{"type": "number", "format": "double", "minimum": 0, "maximum": 100})), nil,
+					pagedResponse("QueryResponse"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound)),
+			},
+			"/v1/products/{product_id}/restore": map[string]any{
+				"parameters": []any{productIDParam()},
+				"post": operation("Restore a deleted product", nil, nil,
+					response(http.StatusOK, "Product"),
+					errResponses(http.StatusUnauthorized, http.StatusNotFound)),
+			},
+			"/graphql": map[string]any{
+				"post": operation("Execute a GraphQL query or mutation on products", nil, body("GraphQLRequest"),
+					graphqlResponse(),
+					errResponses(http.StatusUnauthorized)),
+			},
+			"/v1/categories": map[string]any{
+				"get": operation("Query categories", categoryQueryParams(), nil,
+					response(http.StatusOK, "CategoryQueryResponse"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+				"post": operation("Create a category", nil, body("NewCategory"),
+					response(http.StatusOK, "Category"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict)),
+			},
+			"/v1/categories/{category_id}": map[string]any{
+				"parameters": []any{param("category_id", "path", "the id of the category", str("uuid"))},
+				"delete": operation("Delete a category without products", nil, nil,
+					noContent(http.StatusNoContent, "No Content"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict)),
+			},
+			"/v1/maintenance": map[string]any{
+				"get": operation("Report whether the service is read-only, admins only", nil, nil,
+					response(http.StatusOK, "Maintenance"),
+					errResponses(http.StatusUnauthorized)),
+				"put": operation("Turn the read-only mode of the service instance on or off, admins only. Product writes are rejected with 503 while it's on", nil, body("Maintenance"),
+					response(http.StatusOK, "Maintenance"),
+					errResponses(http.StatusBadRequest, http.StatusUnauthorized)),
+			},
+		},
+		"components": map[string]any{
+			"schemas": components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []any{}},
+		},
+	}
+
+	addNamingParam(doc["paths"].(map[string]any))
+
+	return doc
+}
+
+// addNamingParam lists the naming header on every product path but the
+// stream, which has no json body to rename.
+func addNamingParam(paths map[string]any) {
+	for path, item := range paths {
+		if !strings.HasPrefix(path, "/v1/products") || path == "/v1/products/stream" {
+			continue
+		}
+
+		item := item.(map[string]any)
+		params, _ := item["parameters"].([]any)
+		item["parameters"] = append(params, namingParam())
+	}
+}
+
+// =============================================================================
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func content(name string) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": ref(name)},
+	}
+}
+
+func body(name string) map[string]any {
+	return map[string]any{
+		"required": true,
+		"content":  content(name),
+	}
+}
+
+func optionalBody(name string) map[string]any {
+	return map[string]any{
+		"required": false,
+		"content":  content(name),
+	}
+}
+
+func patchBody() map[string]any {
+	return map[string]any{
+		"required": true,
+		"content": map[string]any{
+			jsonpatch.ContentType: map[string]any{"schema": ref("JSONPatch")},
+		},
+	}
+}
+
+func operation(summary string, params []any, requestBody map[string]any, responses ...map[string]any) map[string]any {
+	op := map[string]any{
+		"summary": summary,
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if requestBody != nil {
+		op["requestBody"] = requestBody
+	}
+
+	all := map[string]any{}
+	for _, resp := range responses {
+		for code, r := range resp {
+			all[code] = r
+		}
+	}
+	op["responses"] = all
+
+	return op
+}
+
+func response(status int, name string) map[string]any {
+	return map[string]any{
+		fmt.Sprint(status): map[string]any{
+			"description": http.StatusText(status),
+			"content":     content(name),
+		},
+	}
+}
+
+func noContent(status int, description string) map[string]any {
+	return map[string]any{
+		fmt.Sprint(status): map[string]any{
+			"description": description,
+		},
+	}
+}
+
+func createdResponse() map[string]any {
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": http.StatusText(http.StatusOK),
+			"content":     content("Product"),
+			"headers": map[string]any{
+				"Location": map[string]any{
+					"description": "the canonical URL of the product",
+					"schema":      map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+}
+
+func cachedResponse() map[string]any {
+	versioned := content("Product")
+	versioned["application/vnd.myapp.v2+json"] = map[string]any{"schema": ref("ProductV2")}
+
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": http.StatusText(http.StatusOK),
+			"content":     versioned,
+			"headers": map[string]any{
+				"ETag": map[string]any{
+					"description": "the entity tag of the current version of the product",
+					"schema":      map[string]any{"type": "string"},
+				},
+				"Last-Modified": map[string]any{
+					"description": "the time the product was last updated",
+					"schema":      map[string]any{"type": "string"},
+				},
+				"Cache-Control": map[string]any{
+					"description": "how long the product can be cached",
+					"schema":      map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+}
+
+func pagedResponse(name string) map[string]any {
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": http.StatusText(http.StatusOK),
+			"content":     content(name),
+			"headers": map[string]any{
+				"X-Max-Rows-Per-Page": map[string]any{
+					"description": "the largest rows value honored, larger values are lowered to it",
+					"schema":      map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+}
+
+func linkedResponse(name string) map[string]any {
+	resp := pagedResponse(name)
+
+	headers := resp[fmt.Sprint(http.StatusOK)].(map[string]any)["headers"].(map[string]any)
+	headers["Link"] = map[string]any{
+		"description": "the first, prev, next and last pages with the same parameters, prev and next only when those pages exist, only next in cursor mode",
+		"schema":      map[string]any{"type": "string"},
+	}
+	headers["ETag"] = map[string]any{
+		"description": "the weak entity tag of the page, sent back in If-None-Match to get a 304 while the page is unchanged",
+		"schema":      map[string]any{"type": "string"},
+	}
+
+	return resp
+}
+
+func exportResponse() map[string]any {
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": "one product per line for ndjson, an array of products for json, one row per product for csv",
+			"content": map[string]any{
+				"application/x-ndjson": map[string]any{
+					"schema": ref("Product"),
+				},
+				"application/json": map[string]any{
+					"schema": map[string]any{"type": "array", "items": ref("Product")},
+				},
+				"text/csv": map[string]any{
+					"schema": map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+}
+
+func streamResponse() map[string]any {
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": "one event per change, named after its type, with heartbeat comments while idle",
+			"content": map[string]any{
+				"text/event-stream": map[string]any{
+					"schema": ref("ProductEvent"),
+				},
+			},
+		},
+	}
+}
+
+func importBody() map[string]any {
+	return map[string]any{
+		"required": true,
+		"content": map[string]any{
+			"application/x-ndjson": map[string]any{"schema": ref("NewProduct")},
+		},
+	}
+}
+
+func importResponse() map[string]any {
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": "one result per line of the import, in the order they complete",
+			"content": map[string]any{
+				"application/x-ndjson": map[string]any{
+					"schema": ref("ImportResult"),
+				},
+			},
+		},
+	}
+}
+
+func bulkCreateResponse() map[string]any {
+	resp := response(http.StatusOK, "BulkResult")
+	resp[fmt.Sprint(http.StatusMultiStatus)] = map[string]any{
+		"description": "the outcome of every product when mode is partial",
+		"content":     content("BulkMultiStatus"),
+	}
+
+	return resp
+}
+
+func graphqlResponse() map[string]any {
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": "the data of the fields that resolved and the errors of the fields that failed",
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"data":   map[string]any{"type": "object"},
+							"errors": map[string]any{"type": "array", "items": ref("GraphQLError")},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func countResponse() map[string]any {
+	return map[string]any{
+		fmt.Sprint(http.StatusOK): map[string]any{
+			"description": "the number of matching products is returned without a body",
+			"headers": map[string]any{
+				"X-Total-Count": map[string]any{
+					"schema": map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+}
+
+func errResponses(statuses ...int) map[string]any {
+	resp := map[string]any{}
+	for _, status := range statuses {
+		for code, r := range response(status, "Error") {
+			// Clients listing application/problem+json in Accept get the
+			// error as problem details.
+			r.(map[string]any)["content"].(map[string]any)[errs.ProblemMediaType] = map[string]any{"schema": ref("Problem")}
+			resp[code] = r
+		}
+	}
+
+	return resp
+}
+
+// =============================================================================
+
+func param(name string, in string, description string, schema map[string]any) map[string]any {
+	p := map[string]any{
+		"name":        name,
+		"in":          in,
+		"description": description,
+		"schema":      schema,
+	}
+
+	if in == "path" {
+		p["required"] = true
+	}
+
+	return p
+}
+
+func str(format string) map[string]any {
+	schema := map[string]any{"type": "string"}
+	if format != "" {
+		schema["format"] = format
+	}
+
+	return schema
+}
+
+func productIDParam() map[string]any {
+	return param("product_id", "path", "the id of the product", str("uuid"))
+}
+
+func skuParam() map[string]any {
+	p := param("sku", "query", "the sku of the product", str(""))
+	p["required"] = true
+
+	return p
+}
+
+func versionParams() []any {
+	from := param("from", "query", "the audit entry id or the RFC 3339 time of the older version", str(""))
+	from["required"] = true
+
+	to := param("to", "query", "the audit entry id or the RFC 3339 time of the newer version", str(""))
+	to["required"] = true
+
+	return []any{from, to}
+}
+
+func headerParam(name string) map[string]any {
+	return param(name, "header", "an ETag previously returned for the product", str(""))
+}
+
+func signatureParams() []any {
+	return []any{
+		param("X-Signature", "header", "sha256= followed by the hex HMAC-SHA256 of the method, the path with its query, the timestamp and the nonce, each followed by a newline, and the body, when signed imports are required", str("")),
+		param("X-Signature-Timestamp", "header", "the unix time the request was signed at, within 5 minutes of the service clock by default", str("")),
+		param("X-Signature-Nonce", "header", "a value of up to 128 characters used once, so the request can't be replayed", str("")),
+	}
+}
+
+func namingParam() map[string]any {
+	return param(web.NamingHeader, "header", "camelCase or snake_case, the naming of the response fields. Request bodies are accepted in either naming", map[string]any{
+		"type": "string",
+		"enum": []string{string(web.NamingCamel), string(web.NamingSnake)},
+	})
+}
+
+func ifModifiedSinceParam() map[string]any {
+	return param("If-Modified-Since", "header", "a Last-Modified time previously returned for the product", str(""))
+}
+
+func dryRunParam() map[string]any {
+	return param("dry_run", "query", "when true nothing is stored and an UpdatePreview of the resulting product is returned", map[string]any{"type": "boolean"})
+}
+
+func returnParam() map[string]any {
+	return param("return", "query", "set to delta to only return the fields the update changed and the new dateUpdated", map[string]any{
+		"type": "string",
+		"enum": []string{"full", "delta"},
+	})
+}
+
+func confirmParam(effect string) map[string]any {
+	return param("confirm", "query", "must be true for "+effect, map[string]any{"type": "boolean"})
+}
+
+func reasonParam() map[string]any {
+	return param("reason", "query", "why the products are deleted, up to 500 characters, recorded in the audit trail and required when the deployment asks for it", str(""))
+}
+
+func idempotencyKeyParam() map[string]any {
+	return param("Idempotency-Key", "header", "a key of up to 255 characters making retries of the request safe", str(""))
+}
+
+func fieldsParam() map[string]any {
+	return param("fields", "query", "a comma separated list of the product fields to return", str(""))
+}
+
+func exportFormatParam() map[string]any {
+	return param("format", "query", "the format of the export, ndjson when not set", map[string]any{
+		"type": "string",
+		"enum": []string{"ndjson", "json", "csv"},
+	})
+}
+
+func expandParam() map[string]any {
+	return param("expand", "query", "set to category to include the name of the product category", map[string]any{
+		"type": "string",
+		"enum": []string{"category"},
+	})
+}
+
+func strictParam() map[string]any {
+	return param("strict", "query", "set to true to get a 404 when the product is already deleted, or to false to get a 204, the deployment decides by default", map[string]any{"type": "boolean"})
+}
+
+func nameParam() map[string]any {
+	p := param("name", "query", "the name to check", str(""))
+	p["required"] = true
+
+	return p
+}
+
+func idsParam() map[string]any {
+	return param("ids", "query", "a comma separated list of product ids", str(""))
+}
+
+func modeParam() map[string]any {
+	return param("mode", "query", "set to atomic to reject the whole batch when any product is invalid, or to partial to store every product independently and get a 207 response with the outcome of each one", map[string]any{
+		"type": "string",
+		"enum": []string{"atomic", "partial"},
+	})
+}
+
+func bulkUpdateModeParam() map[string]any {
+	return param("mode", "query", "set to atomic to reject the whole batch when any product is invalid or can't be updated, or to partial to update every product independently, products that aren't found are reported in both modes", map[string]any{
+		"type": "string",
+		"enum": []string{"atomic", "partial"},
+	})
+}
+
+func conflictParam() map[string]any {
+	return param("conflict", "query", "what existing products are matched by, sku by default or id, in which case every product needs an id", map[string]any{
+		"type": "string",
+		"enum": []string{"sku", "id"},
+	})
+}
+
+func queryParams() []any {
+	integer := map[string]any{"type": "integer", "minimum": 1}
+
+	params := []any{
+		param("page", "query", "the page number, starting at 1", integer),
+		param("rows", "query", "the number of rows per page, lowered to the X-Max-Rows-Per-Page maximum", integer),
+		param("limit", "query", "the number of rows to return, an alternative to rows that can't be combined with page or rows", integer),
+		param("offset", "query", "the number of rows to skip, an alternative to page that can't be combined with page, rows or cursor", map[string]any{"type": "integer", "minimum": 0}),
+		param("orderBy", "query", "semicolon separated list of field[,ASC|DESC[,NULLS_FIRST|NULLS_LAST]] clauses using product_id, name, cost, quantity, user_id, date_created or date_updated", str("")),
+		param("cursor", "query", "the nextCursor value of a previous page, can't be combined with page", str("")),
+		snapshotParam(),
+	}
+
+	params = append(params, filterParams()...)
+
+	return append(params,
+		fieldsParam(),
+		param("count_only", "query", "only return the number of matching products in the X-Total-Count header", map[string]any{"type": "boolean"}),
+		param("fresh_count", "query", "count the total again instead of reusing the total of an earlier page, which can be a few seconds old", map[string]any{"type": "boolean"}),
+	)
+}
+
+func filterParams() []any {
+	number := map[string]any{"type": "number", "format": "double"}
+
+	return []any{
+		param("product_id", "query", "filter by product id", str("uuid")),
+		param("ids", "query", "filter by a comma separated list of up to 100 product ids", str("")),
+		param("sku", "query", "filter by exact sku", str("")),
+		param("name", "query", "filter by exact name", str("")),
+		param("name_like", "query", "filter by a case insensitive substring of the name, up to 50 characters", str("")),
+		param("cost", "query", "filter by exact cost", number),
+		param("quantity", "query", "filter by exact quantity", map[string]any{"type": "integer"}),
+		param("price_min", "query", "filter by a minimum cost", number),
+		param("price_max", "query", "filter by a maximum cost", number),
+		param("quantity_min", "query", "filter by a minimum quantity", map[string]any{"type": "integer", "minimum": 0}),
+		param("quantity_max", "query", "filter by a maximum quantity", map[string]any{"type": "integer", "minimum": 0}),
+		param("out_of_stock", "query", "only return products with a quantity of 0", map[string]any{"type": "boolean"}),
+		param("created_after", "query", "filter by a minimum creation date", str("date-time")),
+		param("created_before", "query", "filter by a maximum creation date", str("date-time")),
+		param("updated_after", "query", "filter by a minimum update date", str("date-time")),
+		param("updated_before", "query", "filter by a maximum update date", str("date-time")),
+		param("category_id", "query", "filter by category id", str("uuid")),
+		param("tag", "query", "filter by tag, repeat it for up to 20 tags", map[string]any{"type": "array", "items": str("")}),
+		param("tag_match", "query", "whether a product needs all the tags or any of them, all by default", map[string]any{
+			"type": "string",
+			"enum": []string{"all", "any"},
+		}),
+		param("include_deleted", "query", "include deleted products, admins only", map[string]any{"type": "boolean"}),
+		param("active", "query", "only return the products that aren't deleted and are in stock", map[string]any{"type": "boolean"}),
+	}
+}
+
+func pageParams() []any {
+	integer := map[string]any{"type": "integer", "minimum": 1}
+
+	return []any{
+		param("page", "query", "the page number, starting at 1", integer),
+		param("rows", "query", "the number of rows per page, lowered to the X-Max-Rows-Per-Page maximum", integer),
+	}
+}
+
+func snapshotParam() map[string]any {
+	return param("snapshot", "query", "the snapshot returned with the first page, keeps products created later out of the pages", str(""))
+}
+
+func searchParams() []any {
+	params := []any{
+		param("q", "query", "the words to search for in the product name and description", str("")),
+		param("fuzzy", "query", "set to true to match the product names similar to q instead, ranked by similarity, so typos are tolerated", map[string]any{"type": "boolean"}),
+	}
+
+	return append(params, pageParams()...)
+}
+
+func categoryQueryParams() []any {
+	integer := map[string]any{"type": "integer", "minimum": 1}
+
+	return []any{
+		param("page", "query", "the page number, starting at 1", integer),
+		param("rows", "query", "the number of rows per page", integer),
+		param("orderBy", "query", "field[,ASC|DESC] using category_id or name", str("")),
+		param("category_id", "query", "filter by category id", str("uuid")),
+		param("name", "query", "filter by a substring of the name", str("")),
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+)
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// schemaFor builds the JSON schema for a value of type t using the same json
+// struct tags the encoding/json package uses, so the document always matches
+// what the handlers actually marshal.
+func schemaFor(t reflect.Type) map[string]any {
+	if t.Implements(textMarshalerType) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := schemaFor(t.Elem())
+		schema["nullable"] = true
+		return schema
+
+	case reflect.String:
+		return map[string]any{"type": "string"}
+
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": schemaFor(t.Elem()),
+		}
+
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem()),
+		}
+
+	case reflect.Struct:
+		return structSchema(t)
+	}
+
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaFor(field.Type)
+
+		if isRequired(field, opts) {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// isRequired reports whether the field is always present. Input models
+// declare it with the validate tag, output models by not omitting the field.
+func isRequired(field reflect.StructField, jsonOpts string) bool {
+	for rule := range strings.SplitSeq(field.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+
+	if field.Tag.Get("validate") != "" || field.Type.Kind() == reflect.Pointer {
+		return false
+	}
+
+	return !strings.Contains(jsonOpts, "omitempty")
+}
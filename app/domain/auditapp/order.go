@@ -1,11 +1,15 @@
 package auditapp
 
-import "github.com/ardanlabs/service/business/domain/auditbus"
+import (
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
 
-var orderByFields = map[string]string{
+var orderByFields = order.AllowList{
 	"obj_id":     auditbus.OrderByObjID,
 	"obj_domain": auditbus.OrderByObjDomain,
 	"obj_name":   auditbus.OrderByObjName,
 	"actor_id":   auditbus.OrderByActorID,
 	"action":     auditbus.OrderByAction,
+	"timestamp":  auditbus.OrderByTimestamp,
 }
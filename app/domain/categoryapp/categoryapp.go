@@ -0,0 +1,103 @@
+// Package categoryapp maintains the app layer api for the category domain.
+package categoryapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type app struct {
+	categoryBus *categorybus.Business
+}
+
+func newApp(categoryBus *categorybus.Business) *app {
+	return &app{
+		categoryBus: categoryBus,
+	}
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewCategory
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nc, err := toBusNewCategory(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cat, err := a.categoryBus.Create(ctx, nc)
+	if err != nil {
+		if errors.Is(err, categorybus.ErrUniqueName) {
+			return errs.New(errs.Aborted, categorybus.ErrUniqueName)
+		}
+		return errs.Newf(errs.Internal, "create: cat[%+v]: %s", cat, err)
+	}
+
+	return toAppCategory(cat)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	categoryID, err := uuid.Parse(web.Param(r, "category_id"))
+	if err != nil {
+		return errs.NewFieldErrors("category_id", err)
+	}
+
+	cat, err := a.categoryBus.QueryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, categorybus.ErrNotFound) {
+			return errs.New(errs.NotFound, categorybus.ErrNotFound)
+		}
+		return errs.Newf(errs.Internal, "querybyid: categoryID[%s]: %s", categoryID, err)
+	}
+
+	if err := a.categoryBus.Delete(ctx, cat); err != nil {
+		if errors.Is(err, categorybus.ErrInUse) {
+			return errs.New(errs.Aborted, categorybus.ErrInUse)
+		}
+		return errs.Newf(errs.Internal, "delete: categoryID[%s]: %s", cat.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, categorybus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	cats, err := a.categoryBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.categoryBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppCategories(cats), total, page)
+}
@@ -0,0 +1,63 @@
+package categoryapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/uuid"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+	ID      string
+	Name    string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:    values.Get("page"),
+		Rows:    values.Get("rows"),
+		OrderBy: values.Get("orderBy"),
+		ID:      values.Get("category_id"),
+		Name:    values.Get("name"),
+	}
+
+	return filter
+}
+
+func parseFilter(qp queryParams) (categorybus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter categorybus.QueryFilter
+
+	if qp.ID != "" {
+		id, err := uuid.Parse(qp.ID)
+		switch err {
+		case nil:
+			filter.ID = &id
+		default:
+			fieldErrors.Add("category_id", err)
+		}
+	}
+
+	if qp.Name != "" {
+		name, err := name.Parse(qp.Name)
+		switch err {
+		case nil:
+			filter.Name = &name
+		default:
+			fieldErrors.Add("name", err)
+		}
+	}
+
+	if fieldErrors != nil {
+		return categorybus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
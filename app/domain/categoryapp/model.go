@@ -0,0 +1,77 @@
+package categoryapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/types/name"
+)
+
+// Category represents information about an individual category.
+type Category struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DateCreated string `json:"dateCreated"`
+	DateUpdated string `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app Category) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppCategory(cat categorybus.Category) Category {
+	return Category{
+		ID:          cat.ID.String(),
+		Name:        cat.Name.String(),
+		DateCreated: cat.DateCreated.Format(time.RFC3339),
+		DateUpdated: cat.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppCategories(cats []categorybus.Category) []Category {
+	app := make([]Category, len(cats))
+	for i, cat := range cats {
+		app[i] = toAppCategory(cat)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// NewCategory defines the data needed to add a new category.
+type NewCategory struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewCategory) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewCategory) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewCategory(app NewCategory) (categorybus.NewCategory, error) {
+	name, err := name.Parse(app.Name)
+	if err != nil {
+		return categorybus.NewCategory{}, fmt.Errorf("parse: %w", err)
+	}
+
+	bus := categorybus.NewCategory{
+		Name: name,
+	}
+
+	return bus, nil
+}
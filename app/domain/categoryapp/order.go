@@ -0,0 +1,11 @@
+package categoryapp
+
+import (
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
+
+var orderByFields = order.AllowList{
+	"category_id": categorybus.OrderByID,
+	"name":        categorybus.OrderByName,
+}
@@ -0,0 +1,34 @@
+package categoryapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log         *logger.Logger
+	CategoryBus *categorybus.Business
+	AuthClient  *authclient.Client
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	ruleAny := mid.Authorize(cfg.AuthClient, auth.RuleAny)
+	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
+
+	api := newApp(cfg.CategoryBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/categories", api.query, authen, ruleAny)
+	app.HandlerFunc(http.MethodPost, version, "/categories", api.create, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodDelete, version, "/categories/{category_id}", api.delete, authen, ruleAdmin)
+}
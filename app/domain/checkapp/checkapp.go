@@ -9,36 +9,69 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/breaker"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type app struct {
-	build string
-	log   *logger.Logger
-	db    *sqlx.DB
+	build          string
+	log            *logger.Logger
+	db             *sqlx.DB
+	productBus     *productbus.Business
+	productBreaker mid.CircuitBreaker
 }
 
-func newApp(build string, log *logger.Logger, db *sqlx.DB) *app {
+func newApp(build string, log *logger.Logger, db *sqlx.DB, productBus *productbus.Business, productBreaker mid.CircuitBreaker) *app {
 	return &app{
-		build: build,
-		log:   log,
-		db:    db,
+		build:          build,
+		log:            log,
+		db:             db,
+		productBus:     productBus,
+		productBreaker: productBreaker,
 	}
 }
 
-// readiness checks if the database is ready and if not will return a 500 status.
-// Do not respond by just returning an error because further up in the call
-// stack it will interpret that as a non-trusted error.
+// readinessTimeout bounds the time the readiness checks can take so a hung
+// database fails the probe instead of wedging it.
+const readinessTimeout = time.Second
+
+// readiness checks if the database is ready and, for services that serve
+// products, that the product store can be read. If not it will return a 503
+// status. Do not respond by just returning an error because further up in the
+// call stack it will interpret that as a non-trusted error.
 func (a *app) readiness(ctx context.Context, r *http.Request) web.Encoder {
-	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
 	defer cancel()
 
 	if err := sqldb.StatusCheck(ctx, a.db); err != nil {
 		a.log.Info(ctx, "readiness failure", "ERROR", err)
-		return errs.New(errs.Internal, err)
+		return errs.New(errs.Unavailable, err)
+	}
+
+	// An open breaker doesn't let the product store be read, there is no
+	// point in trying until it's probing the database again.
+	if a.productBreaker != nil && a.productBreaker.State() == breaker.Open {
+		a.log.Info(ctx, "readiness failure", "CHECK", "productbreaker", "STATE", breaker.Open)
+		return errs.Newf(errs.Unavailable, "product store unavailable: %s", breaker.ErrOpen)
+	}
+
+	if a.productBus != nil {
+		// Counting by the nil id is answered by the primary key index so the
+		// check stays cheap no matter how many products are stored.
+		filter := productbus.QueryFilter{
+			ID: &uuid.Nil,
+		}
+
+		if _, err := a.productBus.Count(ctx, filter); err != nil {
+			a.log.Info(ctx, "readiness failure", "CHECK", "productstore", "ERROR", err)
+			return errs.Newf(errs.Unavailable, "product store unavailable: %s", err)
+		}
 	}
 
 	return nil
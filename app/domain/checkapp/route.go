@@ -3,6 +3,8 @@ package checkapp
 import (
 	"net/http"
 
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/jmoiron/sqlx"
@@ -13,13 +15,21 @@ type Config struct {
 	Build string
 	Log   *logger.Logger
 	DB    *sqlx.DB
+
+	// ProductBus is checked by readiness when it's set so the service isn't
+	// marked ready while the product store can't be read.
+	ProductBus *productbus.Business
+
+	// ProductBreaker is checked by readiness when it's set so the service
+	// isn't marked ready while the product store fails fast.
+	ProductBreaker mid.CircuitBreaker
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
-	api := newApp(cfg.Build, cfg.Log, cfg.DB)
+	api := newApp(cfg.Build, cfg.Log, cfg.DB, cfg.ProductBus, cfg.ProductBreaker)
 
 	app.HandlerFuncNoMid(http.MethodGet, version, "/readiness", api.readiness)
 	app.HandlerFuncNoMid(http.MethodGet, version, "/liveness", api.liveness)
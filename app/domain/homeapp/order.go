@@ -2,9 +2,10 @@ package homeapp
 
 import (
 	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/sdk/order"
 )
 
-var orderByFields = map[string]string{
+var orderByFields = order.AllowList{
 	"home_id": homebus.OrderByID,
 	"type":    homebus.OrderByType,
 	"user_id": homebus.OrderByUserID,
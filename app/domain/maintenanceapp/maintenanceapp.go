@@ -0,0 +1,51 @@
+// Package maintenanceapp maintains the app layer api for putting the service
+// in maintenance.
+package maintenanceapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type app struct {
+	log  *logger.Logger
+	mode Switch
+}
+
+func newApp(log *logger.Logger, mode Switch) *app {
+	return &app{
+		log:  log,
+		mode: mode,
+	}
+}
+
+// query returns whether the service is read-only.
+func (a *app) query(ctx context.Context, _ *http.Request) web.Encoder {
+	return Maintenance{
+		ReadOnly: a.mode.ReadOnly(),
+	}
+}
+
+// update turns the read-only mode on or off. Only the instance serving the
+// request is changed.
+func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
+	var app Maintenance
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getuserid: %s", err)
+	}
+
+	a.mode.SetReadOnly(app.ReadOnly)
+	a.log.Info(ctx, "maintenance", "read_only", app.ReadOnly, "user_id", userID)
+
+	return app
+}
@@ -0,0 +1,21 @@
+package maintenanceapp
+
+import (
+	"encoding/json"
+)
+
+// Maintenance represents the maintenance state of the service.
+type Maintenance struct {
+	ReadOnly bool `json:"readOnly"`
+}
+
+// Encode implements the encoder interface.
+func (app Maintenance) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Decode implements the decoder interface.
+func (app *Maintenance) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
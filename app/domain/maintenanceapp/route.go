@@ -0,0 +1,37 @@
+package maintenanceapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Switch reports and changes whether the service is read-only.
+type Switch interface {
+	ReadOnly() bool
+	SetReadOnly(readOnly bool)
+}
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	AuthClient *authclient.Client
+	Switch     Switch
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
+
+	api := newApp(cfg.Log, cfg.Switch)
+
+	app.HandlerFunc(http.MethodGet, version, "/maintenance", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/maintenance", api.update, authen, ruleAdmin)
+}
@@ -0,0 +1,103 @@
+package productapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/types/domain"
+)
+
+// Set of actions recorded in the audit trail of a product.
+const (
+	auditCreated  = "created"
+	auditUpdated  = "updated"
+	auditDeleted  = "deleted"
+	auditRestored = "restored"
+	auditTouched  = "touched"
+)
+
+// maxReasonLength bounds the reason a client can give for a change since
+// it's kept with the audit trail forever.
+const maxReasonLength = 500
+
+// auditSnapshot is the data stored with an audit record. Before is nil for a
+// created product and After is nil for a deleted one.
+type auditSnapshot struct {
+	Before *Product `json:"before"`
+	After  *Product `json:"after"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// audit records the change made to a product by the user of the request. The
+// caller is expected to run inside the same transaction as the change so the
+// trail can't diverge from the stored data.
+func (a *app) audit(ctx context.Context, action string, before *productbus.Product, after *productbus.Product) error {
+	return a.auditReason(ctx, action, before, after, "")
+}
+
+// auditReason records the change like audit along with the reason the user
+// gave for it.
+func (a *app) auditReason(ctx context.Context, action string, before *productbus.Product, after *productbus.Product, reason string) error {
+	actorID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return fmt.Errorf("getuserid: %w", err)
+	}
+
+	snapshot := auditSnapshot{
+		Reason: reason,
+	}
+
+	prd := after
+	if after != nil {
+		app := toAppProduct(*after)
+		snapshot.After = &app
+	}
+
+	if before != nil {
+		app := toAppProduct(*before)
+		snapshot.Before = &app
+		prd = before
+	}
+
+	na := auditbus.NewAudit{
+		ObjID:     prd.ID,
+		ObjDomain: domain.Product,
+		ObjName:   prd.Name,
+		ActorID:   actorID,
+		Action:    action,
+		Data:      snapshot,
+		Message:   "product " + action,
+	}
+
+	if reason != "" {
+		na.Message += ": " + reason
+	}
+
+	if _, err := a.auditBus.Create(ctx, na); err != nil {
+		return fmt.Errorf("audit: productID[%s] action[%s]: %w", prd.ID, action, err)
+	}
+
+	return nil
+}
+
+// parseReason returns the reason given for a change. The reason is required
+// when required is set.
+func parseReason(value string, required bool) (string, error) {
+	reason := strings.TrimSpace(value)
+
+	switch {
+	case reason == "" && required:
+		return "", errs.NewFieldErrors("reason", errors.New("a reason is required"))
+
+	case len(reason) > maxReasonLength:
+		return "", errs.NewFieldErrors("reason", fmt.Errorf("must be at most %d characters", maxReasonLength))
+	}
+
+	return reason, nil
+}
@@ -0,0 +1,129 @@
+package productapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// clone creates a new product from the product of the request. The fields
+// of the source are copied and the optional body overrides them, with the
+// same fields as an update. The source's SKU can't be reused so the clone
+// gets a generated one unless the body gives one.
+func (a *app) clone(ctx context.Context, r *http.Request) web.Encoder {
+	var overrides cloneOverrides
+	if err := web.Decode(r, &overrides); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	src, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	np, err := toBusNewProduct(ctx, skuPolicy{DefaultsPolicy: a.defaults, generate: true}, toCloneNewProduct(src, overrides))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	prd, err := a.productBus.Create(ctx, np)
+	if err != nil {
+		if errors.Is(err, productbus.ErrDuplicateSKU) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+		}
+		if errors.Is(err, productbus.ErrDuplicateName) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateName)
+		}
+		if errors.Is(err, productbus.ErrCategoryNotFound) {
+			return errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+		}
+		return errs.Newf(errs.Internal, "clone: src[%s]: %s", src.ID, err)
+	}
+
+	if err := a.audit(ctx, auditCreated, nil, &prd); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	web.SetHeader(ctx, "Location", location(prd))
+
+	resp := toAppProduct(prd)
+	resp.Warnings = checkWarnings(&np.Cost, &np.Quantity)
+
+	return resp
+}
+
+// toCloneNewProduct copies the source into a new product and applies the
+// overrides on top of it.
+func toCloneNewProduct(src productbus.Product, overrides cloneOverrides) NewProduct {
+	quantity := src.Quantity.Value()
+
+	np := NewProduct{
+		Name:        src.Name.String(),
+		Description: src.Description,
+		Cost:        src.Cost.String(),
+		Quantity:    &quantity,
+	}
+
+	if src.CategoryID != nil {
+		categoryID := src.CategoryID.String()
+		np.CategoryID = &categoryID
+	}
+
+	if overrides.SKU != nil {
+		np.SKU = *overrides.SKU
+	}
+
+	if overrides.Name != nil {
+		np.Name = *overrides.Name
+	}
+
+	if overrides.Description != nil {
+		np.Description = *overrides.Description
+	}
+
+	if overrides.Cost != nil {
+		np.Cost = *overrides.Cost
+	}
+
+	if overrides.Quantity != nil {
+		np.Quantity = overrides.Quantity
+	}
+
+	if overrides.CategoryID != nil {
+		np.CategoryID = overrides.CategoryID
+	}
+
+	return np
+}
+
+// =============================================================================
+
+// cloneOverrides decodes the optional body of a clone. An empty body
+// overrides nothing.
+type cloneOverrides UpdateProduct
+
+// Decode implements the decoder interface.
+func (app *cloneOverrides) Decode(data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app cloneOverrides) Validate() error {
+	return UpdateProduct(app).Validate()
+}
@@ -0,0 +1,94 @@
+package productapp
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// csvHeader uses the json names of the fields. Warnings are only returned by
+// writes so they don't get a column.
+var csvHeader = slices.DeleteFunc(slices.Clone(productFields), func(field string) bool {
+	return field == "warnings"
+})
+
+// respondQuery returns the query result as CSV when the client asked for it
+// and as JSON otherwise. When fields are provided only those are returned.
+func respondQuery(ctx context.Context, r *http.Request, result query.Result[Product], fields []string) web.Encoder {
+	if !web.Accepts(r, "text/csv") {
+		if fields == nil {
+			return result
+		}
+
+		items, err := toPartialProducts(result.Items, fields)
+		if err != nil {
+			return errs.Newf(errs.Internal, "partial: %s", err)
+		}
+
+		return query.Result[PartialProduct]{
+			Items:       items,
+			Total:       result.Total,
+			Page:        result.Page,
+			RowsPerPage: result.RowsPerPage,
+			Pages:       result.Pages,
+			HasNext:     result.HasNext,
+			HasPrev:     result.HasPrev,
+			NextCursor:  result.NextCursor,
+			Snapshot:    result.Snapshot,
+		}
+	}
+
+	header := csvHeader
+	if fields != nil {
+		header = fields
+	}
+
+	if err := web.RespondCSV(ctx, web.GetWriter(ctx), "products.csv", header, csvRows(result.Items, header)); err != nil {
+		return errs.Newf(errs.Internal, "respondcsv: %s", err)
+	}
+
+	return web.NewNoResponse()
+}
+
+func csvRows(prds []Product, header []string) iter.Seq[[]string] {
+	return func(yield func([]string) bool) {
+		for _, prd := range prds {
+			if !yield(csvRow(prd, header)) {
+				return
+			}
+		}
+	}
+}
+
+// csvRow returns the columns of the product listed in the header. Fields
+// without a column are left empty.
+func csvRow(prd Product, header []string) []string {
+	all := map[string]string{
+		"id":           prd.ID,
+		"userID":       prd.UserID,
+		"sku":          prd.SKU,
+		"name":         prd.Name,
+		"description":  prd.Description,
+		"cost":         prd.Cost,
+		"quantity":     strconv.Itoa(prd.Quantity),
+		"categoryID":   prd.CategoryID,
+		"categoryName": prd.CategoryName,
+		"imageURL":     prd.ImageURL,
+		"dateCreated":  prd.DateCreated,
+		"dateUpdated":  prd.DateUpdated,
+		"dateDeleted":  prd.DateDeleted,
+	}
+
+	row := make([]string, len(header))
+	for i, field := range header {
+		row[i] = all[field]
+	}
+
+	return row
+}
@@ -0,0 +1,98 @@
+package productapp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/google/uuid"
+)
+
+// cursorToken is the data carried inside the opaque cursor handed to clients.
+type cursorToken struct {
+	Field     string `json:"f"`
+	Direction string `json:"d"`
+	Nulls     string `json:"n,omitempty"`
+	Value     string `json:"v"`
+	ID        string `json:"id"`
+}
+
+func encodeCursor(c productbus.Cursor) (string, error) {
+	tkn := cursorToken{
+		Field:     c.OrderBy.Field,
+		Direction: c.OrderBy.Direction,
+		Nulls:     c.OrderBy.Nulls,
+		Value:     c.Value,
+		ID:        c.ID.String(),
+	}
+
+	data, err := json.Marshal(tkn)
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (productbus.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return productbus.Cursor{}, errors.New("invalid cursor")
+	}
+
+	var tkn cursorToken
+	if err := json.Unmarshal(data, &tkn); err != nil {
+		return productbus.Cursor{}, errors.New("invalid cursor")
+	}
+
+	if !isOrderField(tkn.Field) {
+		return productbus.Cursor{}, errors.New("invalid cursor")
+	}
+
+	if tkn.Direction != order.ASC && tkn.Direction != order.DESC {
+		return productbus.Cursor{}, errors.New("invalid cursor")
+	}
+
+	if tkn.Nulls != "" && tkn.Nulls != order.NullsFirst && tkn.Nulls != order.NullsLast {
+		return productbus.Cursor{}, errors.New("invalid cursor")
+	}
+
+	id, err := uuid.Parse(tkn.ID)
+	if err != nil {
+		return productbus.Cursor{}, errors.New("invalid cursor")
+	}
+
+	orderBy := order.NewBy(tkn.Field, tkn.Direction)
+	orderBy.Nulls = tkn.Nulls
+
+	c := productbus.Cursor{
+		OrderBy: orderBy,
+		Value:   tkn.Value,
+		ID:      id,
+	}
+
+	return c, nil
+}
+
+// nextCursor returns the cursor a client can use to continue after the
+// specified product. Cursors only support a single sort column so an empty
+// string is returned when the results were ordered by more than one.
+func nextCursor(prd productbus.Product, orderBy []order.By) (string, error) {
+	if len(orderBy) != 1 {
+		return "", nil
+	}
+
+	c, err := productbus.NewCursor(prd, orderBy[0])
+	if err != nil {
+		return "", err
+	}
+
+	return encodeCursor(c)
+}
+
+func isOrderField(field string) bool {
+	return productbus.OrderByFields.Allows(field)
+}
@@ -0,0 +1,78 @@
+package productapp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// DefaultsPolicy fills the fields of a new product the client left unset.
+// It runs before the product is validated, so a policy can provide values
+// for fields that are otherwise required. Deployments that need different
+// defaults provide their own policy through the Config.
+type DefaultsPolicy interface {
+	Apply(ctx context.Context, np NewProduct) NewProduct
+}
+
+// Defaults is the built-in DefaultsPolicy. The zero value doesn't default
+// anything, so every required field has to be provided by the client.
+type Defaults struct {
+	// Quantity is used for products created without a quantity. Nil keeps
+	// the quantity required. A quantity of zero provided by the client is
+	// kept as is.
+	Quantity *int
+
+	// GenerateSKU gives products created without a SKU one generated by the
+	// business layer from its sku pattern.
+	GenerateSKU bool
+}
+
+// Apply implements the DefaultsPolicy interface.
+func (d Defaults) Apply(ctx context.Context, np NewProduct) NewProduct {
+	if np.Quantity == nil && d.Quantity != nil {
+		qnt := *d.Quantity
+		np.Quantity = &qnt
+	}
+
+	return np
+}
+
+// GeneratesSKU implements the skuGenerator interface.
+func (d Defaults) GeneratesSKU() bool {
+	return d.GenerateSKU
+}
+
+// skuGenerator is implemented by the policies that let products be created
+// without a SKU, leaving the business layer to generate one.
+type skuGenerator interface {
+	GeneratesSKU() bool
+}
+
+// generatesSKU reports whether the policy lets products be created without
+// a SKU.
+func generatesSKU(defaults DefaultsPolicy) bool {
+	g, ok := defaults.(skuGenerator)
+	return ok && g.GeneratesSKU()
+}
+
+// skuPolicy overrides whether a policy lets products be created without a
+// SKU. Clones always get a new SKU, while upserts need one to match on.
+type skuPolicy struct {
+	DefaultsPolicy
+	generate bool
+}
+
+// GeneratesSKU implements the skuGenerator interface.
+func (p skuPolicy) GeneratesSKU() bool {
+	return p.generate
+}
+
+// =============================================================================
+
+// rawNewProduct decodes a new product without validating it, so the
+// defaults policy gets to run before the validation.
+type rawNewProduct NewProduct
+
+// Decode implements the decoder interface.
+func (app *rawNewProduct) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
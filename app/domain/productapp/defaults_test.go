@@ -0,0 +1,45 @@
+package productapp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/productapp"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_DefaultsApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults productapp.Defaults
+		quantity *int
+		exp      *int
+	}{
+		{name: "unset-no-default", defaults: productapp.Defaults{}, quantity: nil, exp: nil},
+		{name: "unset-default", defaults: productapp.Defaults{Quantity: dbtest.IntPointer(5)}, quantity: nil, exp: dbtest.IntPointer(5)},
+		{name: "zero-kept", defaults: productapp.Defaults{Quantity: dbtest.IntPointer(5)}, quantity: dbtest.IntPointer(0), exp: dbtest.IntPointer(0)},
+		{name: "set-kept", defaults: productapp.Defaults{Quantity: dbtest.IntPointer(5)}, quantity: dbtest.IntPointer(3), exp: dbtest.IntPointer(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			np := tt.defaults.Apply(context.Background(), productapp.NewProduct{Quantity: tt.quantity})
+
+			if diff := cmp.Diff(np.Quantity, tt.exp); diff != "" {
+				t.Fatalf("Should get the expected quantity:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DefaultsApplyCopies(t *testing.T) {
+	defaults := productapp.Defaults{Quantity: dbtest.IntPointer(5)}
+
+	np := defaults.Apply(context.Background(), productapp.NewProduct{})
+	*np.Quantity = 10
+
+	if *defaults.Quantity != 5 {
+		t.Fatalf("Should not change the default through the product: got %d", *defaults.Quantity)
+	}
+}
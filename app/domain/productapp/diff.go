@@ -0,0 +1,287 @@
+package productapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/types/domain"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+// errVersionNotFound is returned when the audit trail of a product holds no
+// version matching the request.
+var errVersionNotFound = errors.New("version not found")
+
+// FieldChange represents a field that holds a different value in two
+// versions of a product.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ProductDiff represents the changes made to a product between two versions.
+type ProductDiff struct {
+	ProductID string        `json:"productID"`
+	From      Product       `json:"from"`
+	To        Product       `json:"to"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// Encode implements the encoder interface.
+func (app ProductDiff) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// diffProducts compares the fields a user can change. The id and timestamps
+// maintained by the store are left out since they differ between versions
+// without anyone changing them.
+func diffProducts(from Product, to Product) []FieldChange {
+	fields := []struct {
+		name string
+		old  string
+		new  string
+	}{
+		{"userID", from.UserID, to.UserID},
+		{"sku", from.SKU, to.SKU},
+		{"name", from.Name, to.Name},
+		{"description", from.Description, to.Description},
+		{"cost", from.Cost, to.Cost},
+		{"quantity", strconv.Itoa(from.Quantity), strconv.Itoa(to.Quantity)},
+		{"categoryID", from.CategoryID, to.CategoryID},
+		{"imageURL", from.ImageURL, to.ImageURL},
+		{"dateDeleted", from.DateDeleted, to.DateDeleted},
+	}
+
+	changes := []FieldChange{}
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, FieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+
+	return changes
+}
+
+// =============================================================================
+
+// The forms of the product an update can respond with, selected with the
+// return query parameter.
+const (
+	returnFull  = "full"
+	returnDelta = "delta"
+)
+
+func parseReturn(value string) (string, error) {
+	switch value {
+	case "", returnFull:
+		return returnFull, nil
+	case returnDelta:
+		return returnDelta, nil
+	}
+
+	return "", fmt.Errorf("must be %s or %s", returnFull, returnDelta)
+}
+
+// toDeltaProduct limits the updated product to the fields the update changed
+// and the new update date, for clients that already hold the product. A field
+// the update cleared is returned as null. Warnings are kept since the client
+// can't derive them from the product it holds.
+func toDeltaProduct(before Product, after Product) (PartialProduct, error) {
+	changes := diffProducts(before, after)
+
+	fields := make([]string, 0, len(changes)+2)
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	fields = append(fields, "dateUpdated", "warnings")
+
+	delta, err := toPartialProduct(after, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if _, exists := delta[change.Field]; !exists {
+			delta[change.Field] = json.RawMessage("null")
+		}
+	}
+
+	return delta, nil
+}
+
+// =============================================================================
+
+// productVersion identifies a version of a product by the id of an audit
+// entry or by a point in time.
+type productVersion struct {
+	entryID *uuid.UUID
+	at      time.Time
+}
+
+func parseProductVersion(value string) (productVersion, error) {
+	if id, err := uuid.Parse(value); err == nil {
+		return productVersion{entryID: &id}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return productVersion{}, errors.New("must be an audit entry id or an RFC 3339 time")
+	}
+
+	return productVersion{at: t}, nil
+}
+
+// diff returns the field by field changes of a product between two versions
+// taken from its audit trail.
+func (a *app) diff(ctx context.Context, r *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	productID := prd.ID
+
+	values := r.URL.Query()
+
+	var fieldErrors errs.FieldErrors
+
+	from, err := parseProductVersion(values.Get("from"))
+	if err != nil {
+		fieldErrors.Add("from", err)
+	}
+
+	to, err := parseProductVersion(values.Get("to"))
+	if err != nil {
+		fieldErrors.Add("to", err)
+	}
+
+	if fieldErrors != nil {
+		return fieldErrors.ToError()
+	}
+
+	fromPrd, err := a.queryVersion(ctx, prd, from)
+	if err != nil {
+		if errors.Is(err, errVersionNotFound) {
+			return errs.Newf(errs.NotFound, "from: productID[%s]: %s", productID, err)
+		}
+		return errs.Newf(errs.Internal, "from: productID[%s]: %s", productID, err)
+	}
+
+	toPrd, err := a.queryVersion(ctx, prd, to)
+	if err != nil {
+		if errors.Is(err, errVersionNotFound) {
+			return errs.Newf(errs.NotFound, "to: productID[%s]: %s", productID, err)
+		}
+		return errs.Newf(errs.Internal, "to: productID[%s]: %s", productID, err)
+	}
+
+	diff := ProductDiff{
+		ProductID: productID.String(),
+		From:      fromPrd,
+		To:        toPrd,
+		Changes:   diffProducts(fromPrd, toPrd),
+	}
+
+	return diff
+}
+
+// queryVersion returns the product as recorded by its audit trail. An audit
+// entry id names the product right after the change. A point in time names
+// the product after the last change made at or before it, or the product
+// before the first change when every change was made later. A product
+// created after the point in time has no version. Only the audit trail of the
+// product, already authorized for the caller, is read.
+func (a *app) queryVersion(ctx context.Context, prd productbus.Product, v productVersion) (Product, error) {
+	objDomain := domain.Product
+	filter := auditbus.QueryFilter{
+		ObjID:     &prd.ID,
+		ObjDomain: &objDomain,
+	}
+
+	if v.entryID != nil {
+		filter.ID = v.entryID
+
+		entry, err := a.queryAuditEntry(ctx, filter, order.DESC)
+		if err != nil {
+			return Product{}, err
+		}
+
+		return entryVersion(entry)
+	}
+
+	until := filter
+	until.Until = &v.at
+
+	entry, err := a.queryAuditEntry(ctx, until, order.DESC)
+	switch {
+	case err == nil:
+		return entryVersion(entry)
+	case !errors.Is(err, errVersionNotFound):
+		return Product{}, err
+	}
+
+	since := filter
+	since.Since = &v.at
+
+	entry, err = a.queryAuditEntry(ctx, since, order.ASC)
+	if err != nil {
+		return Product{}, err
+	}
+
+	if entry.Before == nil {
+		return Product{}, errVersionNotFound
+	}
+
+	return *entry.Before, nil
+}
+
+// queryAuditEntry returns the first audit entry matching the filter in the
+// specified timestamp order.
+func (a *app) queryAuditEntry(ctx context.Context, filter auditbus.QueryFilter, direction string) (AuditEntry, error) {
+	adts, err := a.auditBus.Query(ctx, filter, order.NewBy(auditbus.OrderByTimestamp, direction), page.MustParse("1", "1"))
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("audit.query: %w", err)
+	}
+
+	if len(adts) == 0 {
+		return AuditEntry{}, errVersionNotFound
+	}
+
+	entries, err := toAppAuditEntries(adts)
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("toappauditentries: %w", err)
+	}
+
+	return entries[0], nil
+}
+
+// entryVersion returns the product right after the change recorded by the
+// entry. A delete only records the product before it, so the date of the
+// entry is used as the date the product was deleted.
+func entryVersion(entry AuditEntry) (Product, error) {
+	switch {
+	case entry.After != nil:
+		return *entry.After, nil
+
+	case entry.Before != nil:
+		prd := *entry.Before
+		prd.DateDeleted = entry.Timestamp
+		return prd, nil
+	}
+
+	return Product{}, errVersionNotFound
+}
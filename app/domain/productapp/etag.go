@@ -0,0 +1,36 @@
+package productapp
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ETag returns the entity tag that identifies the current version of the
+// product. The update time is truncated to microseconds to match the
+// precision the database stores.
+func ETag(prd productbus.Product) string {
+	return web.NewETag(prd.ID.String(), strconv.FormatInt(prd.DateUpdated.UTC().UnixMicro(), 10))
+}
+
+// QueryETag returns the weak entity tag of a page of products. It changes
+// when the page holds other products or any of them is updated, since that
+// moves the latest update time of the page, and when the total changes. The
+// parts identify the request the page answers, like its filter and paging.
+func QueryETag(prds []productbus.Product, total int, parts ...string) string {
+	var latest time.Time
+	ids := make([]string, len(prds))
+	for i, prd := range prds {
+		ids[i] = prd.ID.String()
+		if prd.DateUpdated.After(latest) {
+			latest = prd.DateUpdated
+		}
+	}
+
+	parts = append(parts, strings.Join(ids, ","), strconv.Itoa(total), strconv.FormatInt(latest.UTC().UnixMicro(), 10))
+
+	return web.NewWeakETag(parts...)
+}
@@ -0,0 +1,115 @@
+package productapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// productFields holds the names a client can select with the fields query
+// parameter. They come from the json tags so they can't drift from the
+// Product model.
+var productFields = jsonFields(reflect.TypeFor[Product]())
+
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// parseFields validates the comma separated list of field names. A nil
+// slice is returned when no fields were requested.
+func parseFields(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for field := range strings.SplitSeq(value, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(productFields, field) {
+			return nil, fmt.Errorf("unknown field: %q", field)
+		}
+
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+// expandCategory is the relation a client can ask to be expanded with the
+// expand query parameter.
+const expandCategory = "category"
+
+// parseExpand validates the comma separated list of relations to expand.
+func parseExpand(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var expand []string
+	for rel := range strings.SplitSeq(value, ",") {
+		rel = strings.TrimSpace(rel)
+		if rel != expandCategory {
+			return nil, fmt.Errorf("unknown relation: %q", rel)
+		}
+		expand = append(expand, rel)
+	}
+
+	return expand, nil
+}
+
+// PartialProduct represents a product limited to the fields requested by the
+// client.
+type PartialProduct map[string]json.RawMessage
+
+// Encode implements the encoder interface.
+func (app PartialProduct) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toPartialProduct(app Product, fields []string) (PartialProduct, error) {
+	data, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	partial := make(PartialProduct, len(fields))
+	for _, field := range fields {
+		if v, exists := all[field]; exists {
+			partial[field] = v
+		}
+	}
+
+	return partial, nil
+}
+
+func toPartialProducts(app []Product, fields []string) ([]PartialProduct, error) {
+	partials := make([]PartialProduct, len(app))
+	for i, prd := range app {
+		partial, err := toPartialProduct(prd, fields)
+		if err != nil {
+			return nil, err
+		}
+		partials[i] = partial
+	}
+
+	return partials, nil
+}
@@ -1,41 +1,100 @@
 package productapp
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/business/domain/productbus"
-	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/sku"
 	"github.com/google/uuid"
 )
 
 type queryParams struct {
-	Page     string
-	Rows     string
-	OrderBy  string
-	ID       string
-	Name     string
-	Cost     string
-	Quantity string
+	Page           string
+	Rows           string
+	Limit          string
+	Offset         string
+	Cursor         string
+	Snapshot       string
+	OrderBy        string
+	ID             string
+	IDs            string
+	SKU            string
+	Name           string
+	NameLike       string
+	Cost           string
+	Quantity       string
+	PriceMin       string
+	PriceMax       string
+	QuantityMin    string
+	QuantityMax    string
+	OutOfStock     string
+	CreatedAfter   string
+	CreatedBefore  string
+	UpdatedAfter   string
+	UpdatedBefore  string
+	IncludeDeleted string
+	Active         string
+	CategoryID     string
+	Tags           []string
+	TagMatch       string
+	Fields         string
+	Expand         string
+	CountOnly      string
+	FreshCount     string
 }
 
 func parseQueryParams(r *http.Request) queryParams {
 	values := r.URL.Query()
 
 	filter := queryParams{
-		Page:     values.Get("page"),
-		Rows:     values.Get("rows"),
-		OrderBy:  values.Get("orderBy"),
-		ID:       values.Get("product_id"),
-		Name:     values.Get("name"),
-		Cost:     values.Get("cost"),
-		Quantity: values.Get("quantity"),
+		Page:           values.Get("page"),
+		Rows:           values.Get("rows"),
+		Limit:          values.Get("limit"),
+		Offset:         values.Get("offset"),
+		Cursor:         values.Get("cursor"),
+		Snapshot:       values.Get("snapshot"),
+		OrderBy:        values.Get("orderBy"),
+		ID:             values.Get("product_id"),
+		IDs:            values.Get("ids"),
+		SKU:            values.Get("sku"),
+		Name:           values.Get("name"),
+		NameLike:       values.Get("name_like"),
+		Cost:           values.Get("cost"),
+		Quantity:       values.Get("quantity"),
+		PriceMin:       values.Get("price_min"),
+		PriceMax:       values.Get("price_max"),
+		QuantityMin:    values.Get("quantity_min"),
+		QuantityMax:    values.Get("quantity_max"),
+		OutOfStock:     values.Get("out_of_stock"),
+		CreatedAfter:   values.Get("created_after"),
+		CreatedBefore:  values.Get("created_before"),
+		UpdatedAfter:   values.Get("updated_after"),
+		UpdatedBefore:  values.Get("updated_before"),
+		IncludeDeleted: values.Get("include_deleted"),
+		Active:         values.Get("active"),
+		CategoryID:     values.Get("category_id"),
+		Tags:           values["tag"],
+		TagMatch:       values.Get("tag_match"),
+		Fields:         values.Get("fields"),
+		Expand:         values.Get("expand"),
+		CountOnly:      values.Get("count_only"),
+		FreshCount:     values.Get("fresh_count"),
 	}
 
 	return filter
 }
 
+// maxNameLike is the longest search term accepted for a name_like filter.
+const maxNameLike = 50
+
 func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 	var fieldErrors errs.FieldErrors
 	var filter productbus.QueryFilter
@@ -50,8 +109,28 @@ func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 		}
 	}
 
+	if qp.IDs != "" {
+		ids, err := parseIDs(qp.IDs)
+		switch err {
+		case nil:
+			filter.IDs = ids
+		default:
+			fieldErrors.Add("ids", err)
+		}
+	}
+
+	if qp.SKU != "" {
+		sku, err := sku.Parse(qp.SKU)
+		switch err {
+		case nil:
+			filter.SKU = &sku
+		default:
+			fieldErrors.Add("sku", err)
+		}
+	}
+
 	if qp.Name != "" {
-		name, err := name.Parse(qp.Name)
+		name, err := productbus.ParseName(qp.Name)
 		switch err {
 		case nil:
 			filter.Name = &name
@@ -60,8 +139,17 @@ func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 		}
 	}
 
+	if qp.NameLike != "" {
+		switch {
+		case utf8.RuneCountInString(qp.NameLike) > maxNameLike:
+			fieldErrors.Add("name_like", fmt.Errorf("value can't be longer than %d characters", maxNameLike))
+		default:
+			filter.NameLike = &qp.NameLike
+		}
+	}
+
 	if qp.Cost != "" {
-		cst, err := strconv.ParseFloat(qp.Cost, 64)
+		cst, err := money.ParseString(qp.Cost)
 		switch err {
 		case nil:
 			filter.Cost = &cst
@@ -81,9 +169,330 @@ func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 		}
 	}
 
+	if qp.PriceMin != "" {
+		cst, err := parseCost(qp.PriceMin)
+		switch err {
+		case nil:
+			filter.MinCost = &cst
+		default:
+			fieldErrors.Add("price_min", err)
+		}
+	}
+
+	if qp.PriceMax != "" {
+		cst, err := parseCost(qp.PriceMax)
+		switch err {
+		case nil:
+			filter.MaxCost = &cst
+		default:
+			fieldErrors.Add("price_max", err)
+		}
+	}
+
+	if qp.QuantityMin != "" {
+		qua, err := parseQuantity(qp.QuantityMin)
+		switch err {
+		case nil:
+			filter.MinQuantity = &qua
+		default:
+			fieldErrors.Add("quantity_min", err)
+		}
+	}
+
+	if qp.QuantityMax != "" {
+		qua, err := parseQuantity(qp.QuantityMax)
+		switch err {
+		case nil:
+			filter.MaxQuantity = &qua
+		default:
+			fieldErrors.Add("quantity_max", err)
+		}
+	}
+
+	var outOfStock bool
+	if qp.OutOfStock != "" {
+		oos, err := strconv.ParseBool(qp.OutOfStock)
+		switch err {
+		case nil:
+			outOfStock = oos
+		default:
+			fieldErrors.Add("out_of_stock", err)
+		}
+	}
+
+	if qp.CategoryID != "" {
+		id, err := uuid.Parse(qp.CategoryID)
+		switch err {
+		case nil:
+			filter.CategoryID = &id
+		default:
+			fieldErrors.Add("category_id", err)
+		}
+	}
+
+	if len(qp.Tags) > 0 {
+		tags, err := parseTags(qp.Tags)
+		switch err {
+		case nil:
+			filter.Tags = tags
+		default:
+			fieldErrors.Add("tag", err)
+		}
+	}
+
+	if qp.TagMatch != "" {
+		switch m := productbus.TagMatch(qp.TagMatch); m {
+		case productbus.TagMatchAll, productbus.TagMatchAny:
+			filter.TagMatch = m
+		default:
+			fieldErrors.Add("tag_match", fmt.Errorf("value must be %s or %s", productbus.TagMatchAll, productbus.TagMatchAny))
+		}
+	}
+
+	if qp.CreatedAfter != "" {
+		t, err := time.Parse(time.RFC3339, qp.CreatedAfter)
+		switch err {
+		case nil:
+			filter.CreatedAfter = &t
+		default:
+			fieldErrors.Add("created_after", err)
+		}
+	}
+
+	if qp.CreatedBefore != "" {
+		t, err := time.Parse(time.RFC3339, qp.CreatedBefore)
+		switch err {
+		case nil:
+			filter.CreatedBefore = &t
+		default:
+			fieldErrors.Add("created_before", err)
+		}
+	}
+
+	if qp.UpdatedAfter != "" {
+		t, err := time.Parse(time.RFC3339, qp.UpdatedAfter)
+		switch err {
+		case nil:
+			filter.UpdatedAfter = &t
+		default:
+			fieldErrors.Add("updated_after", err)
+		}
+	}
+
+	if qp.UpdatedBefore != "" {
+		t, err := time.Parse(time.RFC3339, qp.UpdatedBefore)
+		switch err {
+		case nil:
+			filter.UpdatedBefore = &t
+		default:
+			fieldErrors.Add("updated_before", err)
+		}
+	}
+
+	if qp.IncludeDeleted != "" {
+		incl, err := strconv.ParseBool(qp.IncludeDeleted)
+		switch err {
+		case nil:
+			filter.IncludeDeleted = &incl
+		default:
+			fieldErrors.Add("include_deleted", err)
+		}
+	}
+
+	if qp.Active != "" {
+		active, err := strconv.ParseBool(qp.Active)
+		switch err {
+		case nil:
+			filter.Active = &active
+		default:
+			fieldErrors.Add("active", err)
+		}
+	}
+
 	if fieldErrors != nil {
 		return productbus.QueryFilter{}, fieldErrors.ToError()
 	}
 
+	for _, fc := range filterConflicts {
+		if fc.conflicts(filter, outOfStock) {
+			return productbus.QueryFilter{}, errs.NewFieldErrors(fc.field, fc.err)
+		}
+	}
+
+	// out_of_stock=true is shorthand for quantity=0.
+	if outOfStock {
+		zero := 0
+		filter.Quantity = &zero
+	}
+
 	return filter, nil
 }
+
+// filterConflict describes a combination of filters no product can match.
+// The error is reported on the field, naming the filter it conflicts with.
+type filterConflict struct {
+	field     string
+	err       error
+	conflicts func(filter productbus.QueryFilter, outOfStock bool) bool
+}
+
+// filterConflicts lists the combinations of filters that are rejected rather
+// than returning an empty result. Only the first conflict found is reported.
+var filterConflicts = []filterConflict{
+	{
+		field: "quantity_min",
+		err:   errors.New("value can't be greater than quantity_max"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.MinQuantity != nil && f.MaxQuantity != nil && *f.MinQuantity > *f.MaxQuantity
+		},
+	},
+	{
+		field: "quantity",
+		err:   errors.New("value can't be less than quantity_min"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.Quantity != nil && f.MinQuantity != nil && *f.Quantity < *f.MinQuantity
+		},
+	},
+	{
+		field: "quantity",
+		err:   errors.New("value can't be greater than quantity_max"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.Quantity != nil && f.MaxQuantity != nil && *f.Quantity > *f.MaxQuantity
+		},
+	},
+	{
+		field: "out_of_stock",
+		err:   errors.New("value can't be combined with a quantity_min greater than 0"),
+		conflicts: func(f productbus.QueryFilter, oos bool) bool {
+			return oos && f.MinQuantity != nil && *f.MinQuantity > 0
+		},
+	},
+	{
+		field: "out_of_stock",
+		err:   errors.New("value can't be combined with a quantity other than 0"),
+		conflicts: func(f productbus.QueryFilter, oos bool) bool {
+			return oos && f.Quantity != nil && *f.Quantity != 0
+		},
+	},
+	{
+		field: "price_min",
+		err:   errors.New("value can't be greater than price_max"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.MinCost != nil && f.MaxCost != nil && f.MinCost.Cents() > f.MaxCost.Cents()
+		},
+	},
+	{
+		field: "cost",
+		err:   errors.New("value can't be less than price_min"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.Cost != nil && f.MinCost != nil && f.Cost.Cents() < f.MinCost.Cents()
+		},
+	},
+	{
+		field: "cost",
+		err:   errors.New("value can't be greater than price_max"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.Cost != nil && f.MaxCost != nil && f.Cost.Cents() > f.MaxCost.Cents()
+		},
+	},
+	{
+		field: "created_after",
+		err:   errors.New("value can't be later than created_before"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.CreatedAfter != nil && f.CreatedBefore != nil && f.CreatedAfter.After(*f.CreatedBefore)
+		},
+	},
+	{
+		field: "updated_after",
+		err:   errors.New("value can't be later than updated_before"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return f.UpdatedAfter != nil && f.UpdatedBefore != nil && f.UpdatedAfter.After(*f.UpdatedBefore)
+		},
+	},
+	{
+		field: "active",
+		err:   errors.New("value can't be combined with out_of_stock"),
+		conflicts: func(f productbus.QueryFilter, oos bool) bool {
+			return isActive(f) && oos
+		},
+	},
+	{
+		field: "active",
+		err:   errors.New("value can't be combined with a quantity of 0"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return isActive(f) && ((f.Quantity != nil && *f.Quantity == 0) || (f.MaxQuantity != nil && *f.MaxQuantity == 0))
+		},
+	},
+	{
+		field: "active",
+		err:   errors.New("value can't be combined with include_deleted"),
+		conflicts: func(f productbus.QueryFilter, _ bool) bool {
+			return isActive(f) && f.IncludeDeleted != nil && *f.IncludeDeleted
+		},
+	},
+}
+
+// isActive reports whether the filter is limited to the active products.
+func isActive(f productbus.QueryFilter) bool {
+	return f.Active != nil && *f.Active
+}
+
+// parseFreshCount reports whether the client asked for the total to be
+// counted again rather than reusing the total of an earlier page.
+func parseFreshCount(qp queryParams) (bool, error) {
+	if qp.FreshCount == "" {
+		return false, nil
+	}
+
+	fresh, err := strconv.ParseBool(qp.FreshCount)
+	if err != nil {
+		return false, errs.NewFieldErrors("fresh_count", err)
+	}
+
+	return fresh, nil
+}
+
+// parseCost parses a cost bound provided as a query parameter. Costs can't be
+// negative so those values are rejected.
+func parseCost(value string) (money.Money, error) {
+	if strings.HasPrefix(value, "-") {
+		return money.Money{}, errors.New("value can't be negative")
+	}
+
+	return money.ParseString(value)
+}
+
+// parseQuantity parses a quantity bound provided as a query parameter.
+// Quantities can't be negative so those values are rejected.
+func parseQuantity(value string) (int, error) {
+	qua, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if qua < 0 {
+		return 0, errors.New("value can't be negative")
+	}
+
+	return qua, nil
+}
+
+// parseIDs parses the comma separated list of product ids of the ids filter.
+// Up to maxQueryByIDs ids are accepted, like for a batch query.
+func parseIDs(value string) ([]uuid.UUID, error) {
+	values := strings.Split(value, ",")
+	if len(values) > maxQueryByIDs {
+		return nil, fmt.Errorf("at most %d ids can be given", maxQueryByIDs)
+	}
+
+	ids := make([]uuid.UUID, len(values))
+	for i, v := range values {
+		id, err := uuid.Parse(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", v)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,543 @@
+package productapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/foundation/graphql"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+// Set of types a client can select fields from. The fields come from the
+// json tags of the REST models so both apis represent products the same way.
+var (
+	warningType = &graphql.Type{
+		Name:   "Warning",
+		Fields: scalarFields(jsonFields(reflect.TypeFor[Warning]())),
+	}
+
+	productType = func() *graphql.Type {
+		t := graphql.Type{Name: "Product", Fields: scalarFields(productFields)}
+		t.Fields["warnings"] = warningType
+		return &t
+	}()
+
+	productPageType = &graphql.Type{
+		Name: "ProductPage",
+		Fields: map[string]*graphql.Type{
+			"items":       productType,
+			"total":       nil,
+			"page":        nil,
+			"rowsPerPage": nil,
+			"pages":       nil,
+			"hasNext":     nil,
+			"hasPrev":     nil,
+		},
+	}
+)
+
+func scalarFields(names []string) map[string]*graphql.Type {
+	fields := make(map[string]*graphql.Type, len(names))
+	for _, name := range names {
+		fields[name] = nil
+	}
+
+	return fields
+}
+
+// filterArgs maps the fields of the filter argument of the products query to
+// the query parameters of the REST api they stand for.
+var filterArgs = map[string]func(qp *queryParams) *string{
+	"productID":      func(qp *queryParams) *string { return &qp.ID },
+	"sku":            func(qp *queryParams) *string { return &qp.SKU },
+	"name":           func(qp *queryParams) *string { return &qp.Name },
+	"nameLike":       func(qp *queryParams) *string { return &qp.NameLike },
+	"cost":           func(qp *queryParams) *string { return &qp.Cost },
+	"quantity":       func(qp *queryParams) *string { return &qp.Quantity },
+	"priceMin":       func(qp *queryParams) *string { return &qp.PriceMin },
+	"priceMax":       func(qp *queryParams) *string { return &qp.PriceMax },
+	"quantityMin":    func(qp *queryParams) *string { return &qp.QuantityMin },
+	"quantityMax":    func(qp *queryParams) *string { return &qp.QuantityMax },
+	"outOfStock":     func(qp *queryParams) *string { return &qp.OutOfStock },
+	"categoryID":     func(qp *queryParams) *string { return &qp.CategoryID },
+	"createdAfter":   func(qp *queryParams) *string { return &qp.CreatedAfter },
+	"createdBefore":  func(qp *queryParams) *string { return &qp.CreatedBefore },
+	"updatedAfter":   func(qp *queryParams) *string { return &qp.UpdatedAfter },
+	"updatedBefore":  func(qp *queryParams) *string { return &qp.UpdatedBefore },
+	"includeDeleted": func(qp *queryParams) *string { return &qp.IncludeDeleted },
+	"active":         func(qp *queryParams) *string { return &qp.Active },
+}
+
+// graphQL executes GraphQL requests against the product api. Every field is
+// authorized with the rule of the REST route it stands for.
+type graphQL struct {
+	log        *logger.Logger
+	app        *app
+	authClient *authclient.Client
+	readOnly   mid.ReadOnlyMode
+}
+
+func newGraphQL(log *logger.Logger, app *app, authClient *authclient.Client, readOnly mid.ReadOnlyMode) *graphQL {
+	return &graphQL{
+		log:        log,
+		app:        app,
+		authClient: authClient,
+		readOnly:   readOnly,
+	}
+}
+
+// execute handles a GraphQL request. Root fields are resolved in order and a
+// field that fails is null with its error reported, so the response is
+// always a GraphQL response.
+func (g *graphQL) execute(ctx context.Context, r *http.Request) web.Encoder {
+	var req graphql.Request
+	if err := web.Decode(r, &req); err != nil {
+		return graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}}
+	}
+
+	op, err := graphql.Parse(req)
+	if err != nil {
+		return graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}}
+	}
+
+	locale := errs.ParseLocale(r.Header.Get("Accept-Language"))
+
+	var resp graphql.Response
+	for _, field := range op.Selections {
+		v, err := g.resolve(ctx, op.Type, field)
+		if err != nil {
+			v = nil
+			resp.Errors = append(resp.Errors, g.toGraphQLError(ctx, err, field, locale))
+		}
+
+		resp.Data = append(resp.Data, graphql.Member{Key: field.Key(), Value: v})
+	}
+
+	return resp
+}
+
+func (g *graphQL) resolve(ctx context.Context, opType string, field graphql.Field) (any, error) {
+	if field.Name == "__typename" {
+		if opType == graphql.OperationMutation {
+			return "Mutation", nil
+		}
+		return "Query", nil
+	}
+
+	switch opType {
+	case graphql.OperationQuery:
+		switch field.Name {
+		case "products":
+			return g.products(ctx, field)
+		case "product":
+			return g.product(ctx, field)
+		}
+
+	case graphql.OperationMutation:
+		if err := mid.CheckReadOnly(g.readOnly); err != nil {
+			return nil, err
+		}
+
+		switch field.Name {
+		case "createProduct":
+			return g.createProduct(ctx, field)
+		case "updateProduct":
+			return g.updateProduct(ctx, field)
+		case "deleteProduct":
+			return g.deleteProduct(ctx, field)
+		}
+	}
+
+	return nil, fmt.Errorf("cannot query field %q on the %s type", field.Name, opType)
+}
+
+// products resolves products(filter, page, rows, orderBy). The arguments are
+// parsed like the query parameters of GET /v1/products.
+func (g *graphQL) products(ctx context.Context, field graphql.Field) (any, error) {
+	if err := g.authorize(ctx, auth.RuleAny, uuid.UUID{}); err != nil {
+		return nil, err
+	}
+
+	var qp queryParams
+	for name, v := range field.Arguments {
+		var err error
+		switch name {
+		case "page":
+			qp.Page, err = argString(v)
+		case "rows":
+			qp.Rows, err = argString(v)
+		case "orderBy":
+			qp.OrderBy, err = argString(v)
+		case "filter":
+			err = filterParams(v, &qp)
+		default:
+			err = fmt.Errorf("unknown argument %q", name)
+		}
+
+		if err != nil {
+			return nil, errs.NewFieldErrors(name, err)
+		}
+	}
+
+	page, err := g.app.parsePage(ctx, qp.Page, qp.Rows)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.IncludeDeleted != nil && *filter.IncludeDeleted && !isAdmin(ctx) {
+		return nil, errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins")
+	}
+
+	if costFiltered(filter) && !canSeeCost(ctx) {
+		return nil, errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs")
+	}
+
+	orderBy, err := order.ParseMany(productbus.OrderByFields, qp.OrderBy, productbus.DefaultOrderBy)
+	if err != nil {
+		return nil, errs.NewFieldErrors("orderBy", err)
+	}
+
+	if costOrdered(orderBy) && !canSeeCost(ctx) {
+		return nil, errs.Newf(errs.PermissionDenied, "ordering by cost is restricted to the roles that can see costs")
+	}
+
+	prds, err := g.app.productBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := g.app.productBus.Count(ctx, filter)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	result := query.NewResult(redactProducts(ctx, toAppProducts(prds)), total, page)
+
+	return selectFields(productPageType, field, result)
+}
+
+// product resolves product(id).
+func (g *graphQL) product(ctx context.Context, field graphql.Field) (any, error) {
+	prd, err := g.authorizeProduct(ctx, field, g.app.productBus.QueryByID)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectFields(productType, field, redactProduct(ctx, toAppProduct(prd)))
+}
+
+// createProduct resolves createProduct(input).
+func (g *graphQL) createProduct(ctx context.Context, field graphql.Field) (any, error) {
+	if err := g.authorize(ctx, auth.RuleUserOnly, uuid.UUID{}); err != nil {
+		return nil, err
+	}
+
+	var app NewProduct
+	if err := argInput(field, (*rawNewProduct)(&app)); err != nil {
+		return nil, err
+	}
+
+	np, err := toBusNewProduct(ctx, g.app.defaults, app)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := g.app.createInTx(ctx, np)
+	if err != nil {
+		if errors.Is(err, productbus.ErrDuplicateSKU) {
+			return nil, errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+		}
+		if errors.Is(err, productbus.ErrDuplicateName) {
+			return nil, errs.New(errs.Aborted, productbus.ErrDuplicateName)
+		}
+		if errors.Is(err, productbus.ErrCategoryNotFound) {
+			return nil, errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+		}
+		return nil, errs.Newf(errs.Internal, "create: %s", err)
+	}
+
+	resp := toAppProduct(prd)
+	resp.Warnings = checkWarnings(&np.Cost, &np.Quantity)
+
+	return selectFields(productType, field, resp)
+}
+
+// updateProduct resolves updateProduct(id, input).
+func (g *graphQL) updateProduct(ctx context.Context, field graphql.Field) (any, error) {
+	prd, err := g.authorizeProduct(ctx, field, g.app.productBus.QueryByID)
+	if err != nil {
+		return nil, err
+	}
+
+	var input UpdateProduct
+	if err := argInput(field, &input); err != nil {
+		return nil, err
+	}
+
+	if err := input.Validate(); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	up, err := toBusUpdateProduct(input)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	var updPrd productbus.Product
+	err = g.app.inTx(func(a *app) error {
+		var err error
+		if updPrd, err = a.productBus.Update(ctx, prd, up); err != nil {
+			return err
+		}
+
+		return a.audit(ctx, auditUpdated, &prd, &updPrd)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, productbus.ErrVersionConflict), errors.Is(err, productbus.ErrDuplicateSKU):
+			return nil, errs.New(errs.Aborted, err)
+		case errors.Is(err, productbus.ErrCategoryNotFound):
+			return nil, errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+		}
+		return nil, errs.Newf(errs.Internal, "update: productID[%s]: %s", prd.ID, err)
+	}
+
+	resp := toAppProduct(updPrd)
+	resp.Warnings = checkWarnings(up.Cost, up.Quantity)
+
+	return selectFields(productType, field, resp)
+}
+
+// deleteProduct resolves deleteProduct(id). Deleting a deleted product is a
+// no-op that isn't audited, like with the REST api.
+func (g *graphQL) deleteProduct(ctx context.Context, field graphql.Field) (any, error) {
+	if field.Selections != nil {
+		return nil, fmt.Errorf("field %q can't have a selection of subfields", field.Name)
+	}
+
+	prd, err := g.authorizeProduct(ctx, field, g.app.productBus.QueryByIDWithDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	if prd.DateDeleted != nil {
+		return true, nil
+	}
+
+	err = g.app.inTx(func(a *app) error {
+		if err := a.productBus.Delete(ctx, prd); err != nil {
+			return err
+		}
+
+		return a.audit(ctx, auditDeleted, &prd, nil)
+	})
+
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "delete: productID[%s]: %s", prd.ID, err)
+	}
+
+	return true, nil
+}
+
+// =============================================================================
+
+// authorize applies the rule to the caller like the Authorize middleware.
+func (g *graphQL) authorize(ctx context.Context, rule string, userID uuid.UUID) error {
+	if userID == (uuid.UUID{}) {
+		var err error
+		if userID, err = mid.GetUserID(ctx); err != nil {
+			return errs.New(errs.Unauthenticated, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	auth := authclient.Authorize{
+		Claims: mid.GetClaims(ctx),
+		UserID: userID,
+		Rule:   rule,
+	}
+
+	if err := g.authClient.Authorize(ctx, auth); err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	return nil
+}
+
+// authorizeProduct looks up the product identified by the id argument and
+// authorizes the caller against its owner like the AuthorizeProduct
+// middleware.
+func (g *graphQL) authorizeProduct(ctx context.Context, field graphql.Field, queryByID func(ctx context.Context, productID uuid.UUID) (productbus.Product, error)) (productbus.Product, error) {
+	id, err := argString(field.Arguments["id"])
+	if err != nil {
+		return productbus.Product{}, errs.NewFieldErrors("id", err)
+	}
+
+	productID, err := uuid.Parse(id)
+	if err != nil {
+		return productbus.Product{}, errs.NewFieldErrors("id", err)
+	}
+
+	prd, err := queryByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, productbus.ErrNotFound) {
+			return productbus.Product{}, errs.New(errs.NotFound, err)
+		}
+		return productbus.Product{}, errs.Newf(errs.Internal, "querybyid: productID[%s]: %s", productID, err)
+	}
+
+	if err := g.authorize(ctx, auth.RuleAdminOrSubject, prd.UserID); err != nil {
+		return productbus.Product{}, err
+	}
+
+	return prd, nil
+}
+
+// toGraphQLError converts the error of a root field. Internal errors are
+// logged and reported without their details like with the REST api.
+func (g *graphQL) toGraphQLError(ctx context.Context, err error, field graphql.Field, locale string) graphql.Error {
+	var appErr *errs.Error
+	if !errors.As(err, &appErr) {
+		appErr = errs.New(errs.InvalidArgument, err)
+	}
+
+	if appErr.Code == errs.Internal || appErr.Code == errs.InternalOnlyLog {
+		g.log.Error(ctx, "graphql", "field", field.Name, "err", err)
+		appErr = errs.Newf(errs.Internal, "Internal Server Error")
+	}
+
+	appErr = appErr.Localize(locale)
+
+	gqlErr := graphql.Error{
+		Message:    appErr.Message,
+		Path:       []string{field.Key()},
+		Extensions: map[string]any{"code": appErr.Code.String()},
+	}
+
+	if len(appErr.Fields) > 0 {
+		gqlErr.Extensions["fields"] = appErr.Fields
+	}
+
+	return gqlErr
+}
+
+// inTx runs the function with an app value using a new transaction that's
+// committed when the function succeeds.
+func (a *app) inTx(fn func(a *app) error) error {
+	tx, err := a.beginner.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	txApp, err := a.withTx(tx)
+	if err != nil {
+		return fmt.Errorf("withtx: %w", err)
+	}
+
+	if err := fn(txApp); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// selectFields returns the value of the selection set of the field.
+func selectFields(t *graphql.Type, field graphql.Field, v any) (any, error) {
+	if field.Selections == nil {
+		return nil, fmt.Errorf("field %q of type %q must have a selection of subfields", field.Name, t.Name)
+	}
+
+	return graphql.Select(t, field.Selections, v)
+}
+
+// argString converts a scalar argument to the string form of a query
+// parameter.
+func argString(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+
+	return "", errors.New("value must be a scalar")
+}
+
+// filterParams sets the query parameters described by the filter argument.
+func filterParams(v any, qp *queryParams) error {
+	if v == nil {
+		return nil
+	}
+
+	filter, ok := v.(map[string]any)
+	if !ok {
+		return errors.New("value must be an object")
+	}
+
+	for name, v := range filter {
+		param, exists := filterArgs[name]
+		if !exists {
+			return fmt.Errorf("unknown field %q", name)
+		}
+
+		s, err := argString(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		*param(qp) = s
+	}
+
+	return nil
+}
+
+// argInput decodes the input argument into the model the REST api decodes
+// its request body into.
+func argInput(field graphql.Field, v web.Decoder) error {
+	input, ok := field.Arguments["input"].(map[string]any)
+	if !ok {
+		return errs.NewFieldErrors("input", errors.New("value must be an object"))
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return errs.NewFieldErrors("input", err)
+	}
+
+	if err := v.Decode(data); err != nil {
+		return errs.NewFieldErrors("input", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package productapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// defaultImageUploadTTL is how long an upload url stays valid when the
+// configuration doesn't say.
+const defaultImageUploadTTL = 15 * time.Minute
+
+// ImageSigner pre-signs the uploads of product images to object storage.
+type ImageSigner interface {
+	PresignPut(key string, contentType string, size int64, expires time.Duration, now time.Time) (string, error)
+	PublicURL(key string) string
+}
+
+type images struct {
+	app    *app
+	signer ImageSigner
+	ttl    time.Duration
+}
+
+func newImages(app *app, signer ImageSigner, ttl time.Duration) *images {
+	if ttl <= 0 {
+		ttl = defaultImageUploadTTL
+	}
+
+	return &images{
+		app:    app,
+		signer: signer,
+		ttl:    ttl,
+	}
+}
+
+// createUploadURL starts the upload of an image for the product and returns
+// the pre-signed url the client uploads it to. The content type and size are
+// part of the signature so storage rejects an upload that doesn't match.
+func (img *images) createUploadURL(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewImageUpload
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	upload, err := img.app.productBus.StartImageUpload(ctx, prd, app.ContentType, app.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, productbus.ErrImageType):
+			return errs.NewFieldErrors("contentType", productbus.ErrImageType)
+		case errors.Is(err, productbus.ErrImageSize):
+			return errs.NewFieldErrors("size", fmt.Errorf("value must be at most %d bytes", productbus.MaxImageSize))
+		}
+		return errs.Newf(errs.Internal, "startimageupload: productID[%s]: %s", prd.ID, err)
+	}
+
+	now := time.Now()
+
+	uploadURL, err := img.signer.PresignPut(upload.Key, upload.ContentType, upload.Size, img.ttl, now)
+	if err != nil {
+		return errs.Newf(errs.Internal, "presignput: productID[%s]: %s", prd.ID, err)
+	}
+
+	resp := ImageUploadURL{
+		Method:    http.MethodPut,
+		UploadURL: uploadURL,
+		Headers: map[string]string{
+			"Content-Type":   upload.ContentType,
+			"Content-Length": strconv.FormatInt(upload.Size, 10),
+		},
+		Key:       upload.Key,
+		ImageURL:  img.signer.PublicURL(upload.Key),
+		ExpiresAt: now.Add(img.ttl).UTC().Format(time.RFC3339),
+	}
+
+	return resp
+}
+
+// confirmImage is called once the upload completed and makes the uploaded
+// image the image of the product.
+func (img *images) confirmImage(ctx context.Context, r *http.Request) web.Encoder {
+	var app ConfirmImage
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	a, err := img.app.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	updPrd, err := a.productBus.ConfirmImage(ctx, prd, app.Key, img.signer.PublicURL(app.Key))
+	if err != nil {
+		if errors.Is(err, productbus.ErrNoPendingImage) {
+			return errs.New(errs.FailedPrecondition, productbus.ErrNoPendingImage)
+		}
+		return errs.Newf(errs.Internal, "confirmimage: productID[%s]: %s", prd.ID, err)
+	}
+
+	if err := a.audit(ctx, auditUpdated, &prd, &updPrd); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	web.SetHeader(ctx, "ETag", ETag(updPrd))
+
+	return toAppProduct(updPrd)
+}
@@ -0,0 +1,203 @@
+package productapp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// importContentType is the media type of the body of an import.
+const importContentType = "application/x-ndjson"
+
+// importWorkers is the number of lines of an import stored concurrently.
+const importWorkers = 4
+
+// maxImportLine is the longest line accepted by an import.
+const maxImportLine = 1 << 20
+
+// ImportResult reports the outcome of a line of an import. ID is set when
+// the product was created and Error when the line was rejected.
+type ImportResult struct {
+	Line  int    `json:"line"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// importLine is a line of an import waiting to be stored.
+type importLine struct {
+	number int
+	data   []byte
+}
+
+// importProducts creates a product for every line of a newline delimited
+// JSON body where each line holds a NewProduct. The body is read as a stream
+// and the result of every line is streamed back as soon as it's known, so
+// results don't come back in the order of the lines. Every line is stored in
+// a transaction of its own so a rejected line doesn't undo the others. The
+// import stops when the client disconnects.
+func (a *app) importProducts(ctx context.Context, r *http.Request) web.Encoder {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != importContentType {
+		return errs.Newf(errs.InvalidArgument, "content type must be %s", importContentType)
+	}
+
+	w := web.GetWriter(ctx)
+
+	// Results are written while the body is still being read and a large
+	// import outlives the server timeouts meant for regular requests. Not
+	// every writer supports this, which only matters for large imports.
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan importLine)
+	results := make(chan ImportResult)
+
+	// The reader records the error that ended the body, if any, before it
+	// closes the lines channel so it's safe to read once results is closed.
+	var readErr error
+	var readLine int
+
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, maxImportLine)
+
+		for scanner.Scan() {
+			readLine++
+
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			select {
+			case lines <- importLine{number: readLine, data: slices.Clone(data)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		readErr = scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	for range importWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for line := range lines {
+				select {
+				case results <- a.importLine(ctx, line):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	seq := func(yield func(ImportResult, error) bool) {
+		for res := range results {
+			if !yield(res, nil) {
+				return
+			}
+		}
+
+		if readErr != nil {
+			yield(ImportResult{Line: readLine + 1, Error: fmt.Sprintf("read: %s", readErr)}, nil)
+		}
+	}
+
+	if err := web.RespondNDJSON(ctx, w, seq); err != nil {
+		return errs.Newf(errs.Internal, "respondndjson: %s", err)
+	}
+
+	return web.NewNoResponse()
+}
+
+// importErrors are the errors of a rejected line reported to the client.
+// Other errors are internal and only reported as a failure to store.
+var importErrors = []error{
+	productbus.ErrDuplicateSKU,
+	productbus.ErrDuplicateName,
+	productbus.ErrCategoryNotFound,
+	productbus.ErrUserDisabled,
+}
+
+// importLine decodes, validates and stores a single line of an import.
+func (a *app) importLine(ctx context.Context, line importLine) ImportResult {
+	var app NewProduct
+	if err := json.Unmarshal(line.data, &app); err != nil {
+		return ImportResult{Line: line.number, Error: fmt.Sprintf("decode: %s", err)}
+	}
+
+	np, err := toBusNewProduct(ctx, a.defaults, app)
+	if err != nil {
+		return ImportResult{Line: line.number, Error: err.Error()}
+	}
+
+	prd, err := a.createInTx(ctx, np)
+	if err != nil {
+		for _, target := range importErrors {
+			if errors.Is(err, target) {
+				return ImportResult{Line: line.number, Error: target.Error()}
+			}
+		}
+
+		return ImportResult{Line: line.number, Error: "unable to store the product"}
+	}
+
+	return ImportResult{Line: line.number, ID: prd.ID.String()}
+}
+
+// createInTx stores the product along with its audit record in a
+// transaction of their own.
+func (a *app) createInTx(ctx context.Context, np productbus.NewProduct) (productbus.Product, error) {
+	tx, err := a.beginner.Begin()
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	a, err = a.withTx(tx)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("withtx: %w", err)
+	}
+
+	prd, err := a.productBus.Create(ctx, np)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("create: %w", err)
+	}
+
+	if err := a.audit(ctx, auditCreated, nil, &prd); err != nil {
+		return productbus.Product{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return productbus.Product{}, fmt.Errorf("commit: %w", err)
+	}
+
+	return prd, nil
+}
@@ -3,26 +3,43 @@ package productapp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/business/types/tag"
+	"github.com/google/uuid"
 )
 
 // Product represents information about an individual product.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID           string   `json:"id"`
+	UserID       string   `json:"userID"`
+	SKU          string   `json:"sku"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Cost         string   `json:"cost,omitempty"`
+	Quantity     int      `json:"quantity"`
+	CategoryID   string   `json:"categoryID,omitempty"`
+	CategoryName string   `json:"categoryName,omitempty"`
+	ImageURL     string   `json:"imageURL,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	DateCreated  string   `json:"dateCreated"`
+	DateUpdated  string   `json:"dateUpdated"`
+	DateDeleted  string   `json:"dateDeleted,omitempty"`
+
+	// Warnings is only provided by the endpoints that change a product.
+	Warnings []Warning `json:"warnings,omitempty"`
 }
 
 // Encode implements the encoder interface.
@@ -32,15 +49,35 @@ func (app Product) Encode() ([]byte, string, error) {
 }
 
 func toAppProduct(prd productbus.Product) Product {
-	return Product{
+	app := Product{
 		ID:          prd.ID.String(),
 		UserID:      prd.UserID.String(),
+		SKU:         prd.SKU.String(),
 		Name:        prd.Name.String(),
-		Cost:        prd.Cost.Value(),
+		Description: prd.Description,
+		Cost:        prd.Cost.String(),
 		Quantity:    prd.Quantity.Value(),
+		ImageURL:    prd.ImageURL,
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
 	}
+
+	if prd.CategoryID != nil {
+		app.CategoryID = prd.CategoryID.String()
+	}
+
+	if prd.DateDeleted != nil {
+		app.DateDeleted = prd.DateDeleted.Format(time.RFC3339)
+	}
+
+	if len(prd.Tags) > 0 {
+		app.Tags = make([]string, len(prd.Tags))
+		for i, t := range prd.Tags {
+			app.Tags[i] = t.String()
+		}
+	}
+
+	return app
 }
 
 func toAppProducts(prds []productbus.Product) []Product {
@@ -54,11 +91,126 @@ func toAppProducts(prds []productbus.Product) []Product {
 
 // =============================================================================
 
+// PriceChange represents a change of the cost of a product.
+type PriceChange struct {
+	ID          string `json:"id"`
+	ProductID   string `json:"productID"`
+	OldCost     string `json:"oldCost"`
+	NewCost     string `json:"newCost"`
+	DateChanged string `json:"dateChanged"`
+}
+
+func toAppPriceChanges(history []productbus.PriceChange) []PriceChange {
+	app := make([]PriceChange, len(history))
+	for i, pc := range history {
+		app[i] = PriceChange{
+			ID:          pc.ID.String(),
+			ProductID:   pc.ProductID.String(),
+			OldCost:     pc.OldCost.String(),
+			NewCost:     pc.NewCost.String(),
+			DateChanged: pc.DateChanged.Format(time.RFC3339),
+		}
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// AuditEntry represents a change recorded in the audit trail of a product.
+type AuditEntry struct {
+	ID        string   `json:"id"`
+	ActorID   string   `json:"actorID"`
+	Action    string   `json:"action"`
+	Before    *Product `json:"before"`
+	After     *Product `json:"after"`
+	Reason    string   `json:"reason,omitempty"`
+	Timestamp string   `json:"timestamp"`
+}
+
+func toAppAuditEntries(adts []auditbus.Audit) ([]AuditEntry, error) {
+	app := make([]AuditEntry, len(adts))
+	for i, adt := range adts {
+		var snapshot auditSnapshot
+		if err := json.Unmarshal(adt.Data, &snapshot); err != nil {
+			return nil, fmt.Errorf("unmarshal: auditID[%s]: %w", adt.ID, err)
+		}
+
+		app[i] = AuditEntry{
+			ID:        adt.ID.String(),
+			ActorID:   adt.ActorID.String(),
+			Action:    adt.Action,
+			Before:    snapshot.Before,
+			After:     snapshot.After,
+			Reason:    snapshot.Reason,
+			Timestamp: adt.Timestamp.Format(time.RFC3339),
+		}
+	}
+
+	return app, nil
+}
+
+// =============================================================================
+
+// ProductEvent represents a change of a product sent on the event stream.
+type ProductEvent struct {
+	Type       string `json:"type"`
+	ProductID  string `json:"productID"`
+	CategoryID string `json:"categoryID,omitempty"`
+	Timestamp  string `json:"timestamp"`
+}
+
+// =============================================================================
+
+// SearchResult represents a product matching a search along with the rank of
+// the match. A higher rank is a better match.
+type SearchResult struct {
+	Product Product `json:"product"`
+	Rank    float64 `json:"rank"`
+}
+
+func toAppSearchResults(results []productbus.SearchResult) []SearchResult {
+	app := make([]SearchResult, len(results))
+	for i, res := range results {
+		app[i] = SearchResult{
+			Product: toAppProduct(res.Product),
+			Rank:    res.Rank,
+		}
+	}
+
+	return app
+}
+
+// PopularProduct represents a product along with the number of users who
+// viewed it.
+type PopularProduct struct {
+	Product Product `json:"product"`
+	Views   int     `json:"views"`
+}
+
+func toAppPopularProducts(prds []productbus.Popular) []PopularProduct {
+	app := make([]PopularProduct, len(prds))
+	for i, prd := range prds {
+		app[i] = PopularProduct{
+			Product: toAppProduct(prd.Product),
+			Views:   prd.Views,
+		}
+	}
+
+	return app
+}
+
+// =============================================================================
+
 // NewProduct defines the data needed to add a new product.
 type NewProduct struct {
-	Name     string  `json:"name" validate:"required"`
-	Cost     float64 `json:"cost" validate:"required,gte=0"`
-	Quantity int     `json:"quantity" validate:"required,gte=1"`
+	SKU         string   `json:"sku" validate:"required"`
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description"`
+	Cost        string   `json:"cost" validate:"required"`
+	Quantity    *int     `json:"quantity" validate:"required,gte=0"`
+	CategoryID  *string  `json:"categoryID" validate:"omitempty,uuid"`
+	Tags        []string `json:"tags"`
 }
 
 // Decode implements the decoder interface.
@@ -75,44 +227,316 @@ func (app NewProduct) Validate() error {
 	return nil
 }
 
-func toBusNewProduct(ctx context.Context, app NewProduct) (productbus.NewProduct, error) {
+// validateWithoutSKU checks the data in the model is considered clean, except
+// for the SKU which is going to be generated.
+func (app NewProduct) validateWithoutSKU() error {
+	if err := errs.CheckExcept(app, "SKU"); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// toBusNewProduct applies the defaults policy to the new product before it's
+// validated and converted. The SKU is left unset for the business layer to
+// generate when the client didn't provide one and the policy allows it.
+func toBusNewProduct(ctx context.Context, defaults DefaultsPolicy, app NewProduct) (productbus.NewProduct, error) {
+	app = defaults.Apply(ctx, app)
+
+	generateSKU := app.SKU == "" && generatesSKU(defaults)
+
+	validate := app.Validate
+	if generateSKU {
+		validate = app.validateWithoutSKU
+	}
+
+	if err := validate(); err != nil {
+		return productbus.NewProduct{}, err
+	}
+
 	userID, err := mid.GetUserID(ctx)
 	if err != nil {
 		return productbus.NewProduct{}, fmt.Errorf("getuserid: %w", err)
 	}
 
-	name, err := name.Parse(app.Name)
+	var fieldErrors errs.FieldErrors
+
+	var prdSKU sku.SKU
+	if !generateSKU {
+		prdSKU, err = sku.Parse(app.SKU)
+		if err != nil {
+			fieldErrors.Add("sku", err)
+		}
+	}
+
+	name, err := productbus.ParseName(app.Name)
 	if err != nil {
-		return productbus.NewProduct{}, fmt.Errorf("parse name: %w", err)
+		fieldErrors.Add("name", err)
 	}
 
-	cost, err := money.Parse(app.Cost)
+	cost, err := money.ParseString(app.Cost)
 	if err != nil {
-		return productbus.NewProduct{}, fmt.Errorf("parse cost: %w", err)
+		fieldErrors.Add("cost", err)
+	}
+
+	quantity, err := quantity.Parse(*app.Quantity)
+	if err != nil {
+		fieldErrors.Add("quantity", err)
+	}
+
+	var categoryID *uuid.UUID
+	if app.CategoryID != nil {
+		id, err := uuid.Parse(*app.CategoryID)
+		if err != nil {
+			fieldErrors.Add("categoryID", err)
+		}
+		categoryID = &id
 	}
 
-	quantity, err := quantity.Parse(app.Quantity)
+	tags, err := parseTags(app.Tags)
 	if err != nil {
-		return productbus.NewProduct{}, fmt.Errorf("parse quantity: %w", err)
+		fieldErrors.Add("tags", err)
+	}
+
+	if fieldErrors != nil {
+		return productbus.NewProduct{}, fmt.Errorf("parse: %w", fieldErrors)
 	}
 
 	bus := productbus.NewProduct{
-		UserID:   userID,
-		Name:     name,
-		Cost:     cost,
-		Quantity: quantity,
+		UserID:      userID,
+		SKU:         prdSKU,
+		Name:        name,
+		Description: app.Description,
+		Cost:        cost,
+		Quantity:    quantity,
+		CategoryID:  categoryID,
+		Tags:        tags,
 	}
 
 	return bus, nil
 }
 
+// maxProductTags is the largest number of tags a product can be given, and
+// of tags a query can filter on.
+const maxProductTags = 20
+
+// parseTags parses the tags of a product. The same tag given more than once
+// is kept once by the business layer.
+func parseTags(values []string) ([]tag.Tag, error) {
+	if len(values) > maxProductTags {
+		return nil, fmt.Errorf("at most %d tags can be given", maxProductTags)
+	}
+
+	var tags []tag.Tag
+	for _, v := range values {
+		t, err := tag.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+}
+
+// =============================================================================
+
+// NewProducts defines the data needed to add a batch of new products.
+type NewProducts []NewProduct
+
+// Decode implements the decoder interface.
+func (app *NewProducts) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// BulkError describes why an element of a bulk request was rejected.
+type BulkError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// BulkResult represents the outcome of a bulk create request.
+type BulkResult struct {
+	Items  []Product   `json:"items"`
+	Total  int         `json:"total"`
+	Errors []BulkError `json:"errors"`
+}
+
+// Encode implements the encoder interface.
+func (app BulkResult) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// BulkItemResult describes the outcome of one product of a partial bulk
+// create. Status is the HTTP status the product would get from a single
+// create, with the ID of a created product or the error of a failed one.
+// Action is only set by an upsert and tells whether the product was created
+// or updated.
+type BulkItemResult struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Action string `json:"action,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkPartialNote explains the partial mode to clients reading a response.
+const bulkPartialNote = "each product was stored independently: products with a 201 status were created even if others failed, unlike mode=atomic where any failure rejects the whole batch"
+
+// bulkUpdateNote explains the atomic mode of a bulk update to clients reading
+// a response.
+const bulkUpdateNote = "the updates were applied together: products that weren't found are reported and skipped, any other failure rejects the whole batch, unlike mode=partial where each product is updated independently"
+
+// bulkUpsertNote explains an upsert to clients reading a response.
+const bulkUpsertNote = "each product was stored independently: products with a 201 status were created and products with a 200 status were updated even if others failed"
+
+// bulkUpdatePartialNote explains the partial mode of a bulk update to clients
+// reading a response.
+const bulkUpdatePartialNote = "each product was updated independently: products with a 200 status were updated even if others failed, unlike mode=atomic where any failure rejects the whole batch"
+
+// BulkMultiStatus represents the outcome of a bulk create in partial mode or
+// of a bulk update. It's sent with a 207 status since products can succeed
+// and fail in the same request.
+type BulkMultiStatus struct {
+	Mode      string           `json:"mode"`
+	Atomic    bool             `json:"atomic"`
+	Note      string           `json:"note"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+func newBulkMultiStatus(mode string, note string, items []BulkItemResult) BulkMultiStatus {
+	ms := BulkMultiStatus{
+		Mode:   mode,
+		Atomic: mode == "atomic",
+		Note:   note,
+		Items:  items,
+	}
+
+	for _, item := range items {
+		switch {
+		case item.Status >= 200 && item.Status < 300:
+			ms.Succeeded++
+		default:
+			ms.Failed++
+		}
+	}
+
+	return ms
+}
+
+// Encode implements the encoder interface.
+func (app BulkMultiStatus) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// HTTPStatus implements the web package httpStatus interface.
+func (app BulkMultiStatus) HTTPStatus() int {
+	return http.StatusMultiStatus
+}
+
+// =============================================================================
+
+// UpdatePreview represents the product an update would produce when the
+// update is requested as a dry run. Nothing is stored.
+type UpdatePreview struct {
+	DryRun  bool    `json:"dryRun"`
+	Product Product `json:"product"`
+}
+
+// Encode implements the encoder interface.
+func (app UpdatePreview) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// BulkDeleteResult represents the outcome of a bulk delete request.
+type BulkDeleteResult struct {
+	Deleted int `json:"deleted"`
+}
+
+// Encode implements the encoder interface.
+func (app BulkDeleteResult) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// PriceAdjustment defines the change applied to the cost of every product
+// matching a bulk price update. Exactly one of Percent and Amount must be
+// provided. Percent scales the cost, so -10 takes 10% off, and Amount is a
+// signed decimal like "-5.00" added to the cost.
+type PriceAdjustment struct {
+	Percent *float64 `json:"percent" validate:"omitempty,min=-100,max=1000"`
+	Amount  *string  `json:"amount"`
+}
+
+// Decode implements the decoder interface.
+func (app *PriceAdjustment) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app PriceAdjustment) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusPriceAdjustment(app PriceAdjustment) (productbus.PriceAdjustment, error) {
+	switch {
+	case app.Percent == nil && app.Amount == nil:
+		return productbus.PriceAdjustment{}, errs.NewFieldErrors("percent", errors.New("percent or amount is required"))
+	case app.Percent != nil && app.Amount != nil:
+		return productbus.PriceAdjustment{}, errs.NewFieldErrors("amount", errors.New("value can't be combined with percent"))
+	case app.Percent != nil:
+		return productbus.PriceAdjustment{Percent: *app.Percent}, nil
+	}
+
+	value, negative := strings.CutPrefix(*app.Amount, "-")
+	amount, err := money.ParseString(strings.TrimPrefix(value, "+"))
+	if err != nil {
+		return productbus.PriceAdjustment{}, errs.NewFieldErrors("amount", err)
+	}
+
+	cents := amount.Cents()
+	if negative {
+		cents = -cents
+	}
+
+	return productbus.PriceAdjustment{AmountCents: cents}, nil
+}
+
+// BulkPriceResult represents the outcome of a bulk price update.
+type BulkPriceResult struct {
+	Updated int `json:"updated"`
+}
+
+// Encode implements the encoder interface.
+func (app BulkPriceResult) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
 // =============================================================================
 
 // UpdateProduct defines the data needed to update a product.
 type UpdateProduct struct {
-	Name     *string  `json:"name"`
-	Cost     *float64 `json:"cost" validate:"omitempty,gte=0"`
-	Quantity *int     `json:"quantity" validate:"omitempty,gte=1"`
+	SKU         *string   `json:"sku"`
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Cost        *string   `json:"cost"`
+	Quantity    *int      `json:"quantity" validate:"omitempty,gte=0"`
+	CategoryID  *string   `json:"categoryID" validate:"omitempty,uuid"`
+	Tags        *[]string `json:"tags"`
 }
 
 // Decode implements the decoder interface.
@@ -129,10 +553,49 @@ func (app UpdateProduct) Validate() error {
 	return nil
 }
 
+// BulkUpdateItem defines the change to apply to one product of a bulk
+// update. Only the fields that are set are changed.
+type BulkUpdateItem struct {
+	ID     string        `json:"id"`
+	Fields UpdateProduct `json:"fields"`
+}
+
+// BulkUpdateItems is the set of changes of a bulk update.
+type BulkUpdateItems []BulkUpdateItem
+
+// Decode implements the decoder interface.
+func (app *BulkUpdateItems) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// UpsertItem defines one product of an upsert. ID identifies the product
+// when upserting by id and is ignored when upserting by sku.
+type UpsertItem struct {
+	ID      string     `json:"id,omitempty"`
+	Product NewProduct `json:"product"`
+}
+
+// UpsertItems is the set of products of an upsert.
+type UpsertItems []UpsertItem
+
+// Decode implements the decoder interface.
+func (app *UpsertItems) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
 func toBusUpdateProduct(app UpdateProduct) (productbus.UpdateProduct, error) {
+	var sk *sku.SKU
+	if app.SKU != nil {
+		s, err := sku.Parse(*app.SKU)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", errs.NewFieldErrors("sku", err))
+		}
+		sk = &s
+	}
+
 	var nme *name.Name
 	if app.Name != nil {
-		nm, err := name.Parse(*app.Name)
+		nm, err := productbus.ParseName(*app.Name)
 		if err != nil {
 			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
 		}
@@ -141,15 +604,15 @@ func toBusUpdateProduct(app UpdateProduct) (productbus.UpdateProduct, error) {
 
 	var cost *money.Money
 	if app.Cost != nil {
-		cst, err := money.Parse(*app.Cost)
+		cst, err := money.ParseString(*app.Cost)
 		if err != nil {
-			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", errs.NewFieldErrors("cost", err))
 		}
 		cost = &cst
 	}
 
 	var qnt *quantity.Quantity
-	if app.Cost != nil {
+	if app.Quantity != nil {
 		qn, err := quantity.Parse(*app.Quantity)
 		if err != nil {
 			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
@@ -157,11 +620,190 @@ func toBusUpdateProduct(app UpdateProduct) (productbus.UpdateProduct, error) {
 		qnt = &qn
 	}
 
+	var categoryID *uuid.UUID
+	if app.CategoryID != nil {
+		id, err := uuid.Parse(*app.CategoryID)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		categoryID = &id
+	}
+
+	var tags *[]tag.Tag
+	if app.Tags != nil {
+		tgs, err := parseTags(*app.Tags)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", errs.NewFieldErrors("tags", err))
+		}
+		tags = &tgs
+	}
+
 	bus := productbus.UpdateProduct{
-		Name:     nme,
-		Cost:     cost,
-		Quantity: qnt,
+		SKU:         sk,
+		Name:        nme,
+		Description: app.Description,
+		Cost:        cost,
+		Quantity:    qnt,
+		CategoryID:  categoryID,
+		Tags:        tags,
 	}
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// NewImageUpload describes the image a client is about to upload for a
+// product.
+type NewImageUpload struct {
+	ContentType string `json:"contentType" validate:"required"`
+	Size        int64  `json:"size" validate:"required,gte=1"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewImageUpload) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewImageUpload) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// ImageUploadURL tells the client how to upload an image. The upload is a
+// request with the method to the upload url sending the headers unchanged,
+// after which the key is confirmed. The image is served from the image url
+// once confirmed.
+type ImageUploadURL struct {
+	Method    string            `json:"method"`
+	UploadURL string            `json:"uploadURL"`
+	Headers   map[string]string `json:"headers"`
+	Key       string            `json:"key"`
+	ImageURL  string            `json:"imageURL"`
+	ExpiresAt string            `json:"expiresAt"`
+}
+
+// Encode implements the encoder interface.
+func (app ImageUploadURL) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// ConfirmImage identifies the uploaded image that becomes the image of a
+// product.
+type ConfirmImage struct {
+	Key string `json:"key" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *ConfirmImage) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app ConfirmImage) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// AdjustStock defines the data needed to change the quantity of a product.
+type AdjustStock struct {
+	Delta int `json:"delta" validate:"required,min=-1000000,max=1000000"`
+}
+
+// Decode implements the decoder interface.
+func (app *AdjustStock) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AdjustStock) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// ProductIDs defines a set of product ids provided in a request body.
+type ProductIDs []string
+
+// Decode implements the decoder interface.
+func (app *ProductIDs) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// RecentlyViewed represents the products a user viewed most recently, the
+// most recent first.
+type RecentlyViewed struct {
+	Items []Product `json:"items"`
+}
+
+// Encode implements the encoder interface.
+func (app RecentlyViewed) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// NameAvailability reports whether a name is free to use for a new product.
+type NameAvailability struct {
+	Available bool `json:"available"`
+}
+
+// Encode implements the encoder interface.
+func (app NameAvailability) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Summary represents the totals of the products matching a filter. The
+// amounts are left out for the roles that can't see costs.
+type Summary struct {
+	Count          int    `json:"count"`
+	InventoryValue string `json:"inventoryValue,omitempty"`
+	AverageCost    string `json:"averageCost,omitempty"`
+	OutOfStock     int    `json:"outOfStock"`
+}
+
+// Encode implements the encoder interface.
+func (app Summary) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSummary(sum productbus.Summary) Summary {
+	return Summary{
+		Count:          sum.Count,
+		InventoryValue: formatCents(sum.InventoryValueCents),
+		AverageCost:    formatCents(sum.AverageCostCents),
+		OutOfStock:     sum.OutOfStock,
+	}
+}
+
+// formatCents formats an amount the way costs are formatted.
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// BatchResult represents the outcome of a query by a set of ids.
+type BatchResult struct {
+	Items    []Product `json:"items"`
+	NotFound []string  `json:"notFound"`
+}
+
+// Encode implements the encoder interface.
+func (app BatchResult) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
@@ -0,0 +1,48 @@
+package productapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// popular returns the products viewed by the most users, most viewed first.
+// The views are counted live when the ranking is too old.
+func (a *app) popular(ctx context.Context, r *http.Request) web.Encoder {
+	values := r.URL.Query()
+
+	page, err := a.parsePage(ctx, values.Get("page"), values.Get("rows"))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	prds, err := a.productBus.QueryPopular(ctx, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querypopular: %s", err)
+	}
+
+	total, err := a.productBus.CountPopular(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "countpopular: %s", err)
+	}
+
+	items := toAppPopularProducts(prds)
+	for i := range items {
+		items[i].Product = redactProduct(ctx, items[i].Product)
+	}
+
+	return query.NewResult(items, total, page)
+}
+
+// refreshPopular recounts the views of the popular products of every tenant
+// without waiting for the scheduled refresh.
+func (a *app) refreshPopular(ctx context.Context, r *http.Request) web.Encoder {
+	if err := a.productBus.RefreshPopular(ctx); err != nil {
+		return errs.Newf(errs.Internal, "refreshpopular: %s", err)
+	}
+
+	return nil
+}
@@ -3,50 +3,680 @@ package productapp
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/loader"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/categorybus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/domain"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/jsonpatch"
+	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
 )
 
 type app struct {
-	productBus *productbus.Business
+	productBus  *productbus.Business
+	categoryBus *categorybus.Business
+	auditBus    *auditbus.Business
+	cacheMaxAge time.Duration
+
+	// maxRowsPerPage is the largest rows per page value a client gets back.
+	maxRowsPerPage int
+
+	// defaults fills the unset fields of new products.
+	defaults DefaultsPolicy
+
+	// beginner starts the transactions of handlers that can't run in a
+	// single request transaction, like an import.
+	beginner sqldb.Beginner
+
+	// requireDeleteReason rejects deletes that don't say why the products
+	// are deleted.
+	requireDeleteReason bool
+
+	// strictDelete reports deleting a product that's already deleted as not
+	// found unless the client asks otherwise.
+	strictDelete bool
+
+	// tolerateCountErrors returns the page of a query with an unknown total
+	// when the total can't be counted, instead of failing the query.
+	tolerateCountErrors bool
+	log                 *logger.Logger
 }
 
-func newApp(productBus *productbus.Business) *app {
+func newApp(log *logger.Logger, productBus *productbus.Business, categoryBus *categorybus.Business, auditBus *auditbus.Business, beginner sqldb.Beginner, cacheMaxAge time.Duration, maxRowsPerPage int, defaults DefaultsPolicy, requireDeleteReason bool, strictDelete bool, tolerateCountErrors bool) *app {
+	if maxRowsPerPage <= 0 {
+		maxRowsPerPage = page.DefaultMaxRowsPerPage
+	}
+
+	if defaults == nil {
+		defaults = Defaults{}
+	}
+
 	return &app{
-		productBus: productBus,
+		productBus:          productBus,
+		categoryBus:         categoryBus,
+		auditBus:            auditBus,
+		cacheMaxAge:         cacheMaxAge,
+		maxRowsPerPage:      maxRowsPerPage,
+		defaults:            defaults,
+		beginner:            beginner,
+		requireDeleteReason: requireDeleteReason,
+		strictDelete:        strictDelete,
+		tolerateCountErrors: tolerateCountErrors,
+		log:                 log,
+	}
+}
+
+// newWithTx constructs a new app value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.withTx(tx)
+}
+
+// withTx constructs a new app value with the domain apis using the
+// specified store transaction.
+func (a *app) withTx(tx sqldb.CommitRollbacker) (*app, error) {
+	productBus, err := a.productBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	auditBus, err := a.auditBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := app{
+		productBus:     productBus,
+		categoryBus:    a.categoryBus,
+		auditBus:       auditBus,
+		cacheMaxAge:    a.cacheMaxAge,
+		maxRowsPerPage: a.maxRowsPerPage,
+		defaults:       a.defaults,
+		beginner:       a.beginner,
+
+		requireDeleteReason: a.requireDeleteReason,
+		strictDelete:        a.strictDelete,
+		tolerateCountErrors: a.tolerateCountErrors,
+		log:                 a.log,
+	}
+
+	return &app, nil
+}
+
+// decodeError returns the error for a body that couldn't be decoded. A field
+// the model doesn't have is reported as an error of that field.
+func decodeError(err error) *errs.Error {
+	var ufe *web.UnknownFieldError
+	if errors.As(err, &ufe) {
+		return errs.NewFieldErrors(ufe.Field, errors.New("unknown field"))
 	}
+
+	return errs.New(errs.InvalidArgument, err)
 }
 
 func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 	var app NewProduct
-	if err := web.Decode(r, &app); err != nil {
-		return errs.New(errs.InvalidArgument, err)
+	if err := web.DecodeStrict(r, (*rawNewProduct)(&app)); err != nil {
+		return decodeError(err)
 	}
 
-	np, err := toBusNewProduct(ctx, app)
+	np, err := toBusNewProduct(ctx, a.defaults, app)
 	if err != nil {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		return a.createIdempotent(ctx, key, np)
+	}
+
 	prd, err := a.productBus.Create(ctx, np)
 	if err != nil {
+		if errors.Is(err, productbus.ErrDuplicateSKU) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+		}
+		if errors.Is(err, productbus.ErrDuplicateName) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateName)
+		}
+		if errors.Is(err, productbus.ErrCategoryNotFound) {
+			return errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+		}
 		return errs.Newf(errs.Internal, "create: prd[%+v]: %s", prd, err)
 	}
 
-	return toAppProduct(prd)
+	if err := a.audit(ctx, auditCreated, nil, &prd); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	web.SetHeader(ctx, "Location", location(prd))
+
+	resp := toAppProduct(prd)
+	resp.Warnings = checkWarnings(&np.Cost, &np.Quantity)
+
+	return resp
+}
+
+// location returns the canonical URL of the specified product.
+func location(prd productbus.Product) string {
+	return "/v1/products/" + prd.ID.String()
+}
+
+// maxIdempotencyKey is the longest Idempotency-Key header value accepted.
+const maxIdempotencyKey = 255
+
+// createIdempotent adds the product unless the same key was already used by
+// the user, in which case the original product is returned so the client
+// receives the same response it would have gotten the first time. The app
+// is expected to be using the request transaction.
+func (a *app) createIdempotent(ctx context.Context, key string, np productbus.NewProduct) web.Encoder {
+	if len(key) > maxIdempotencyKey {
+		return errs.Newf(errs.InvalidArgument, "idempotency key can't be longer than %d characters", maxIdempotencyKey)
+	}
+
+	prd, replayed, err := a.productBus.CreateIdempotent(ctx, key, np)
+	if err != nil {
+		if errors.Is(err, productbus.ErrIdempotencyKeyInUse) {
+			return errs.New(errs.Aborted, err)
+		}
+		if errors.Is(err, productbus.ErrDuplicateSKU) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+		}
+		if errors.Is(err, productbus.ErrDuplicateName) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateName)
+		}
+		if errors.Is(err, productbus.ErrCategoryNotFound) {
+			return errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+		}
+		return errs.Newf(errs.Internal, "createidempotent: key[%s]: %s", key, err)
+	}
+
+	// A replayed request returns the product created by the original
+	// request and there is nothing new to audit.
+	if !replayed {
+		if err := a.audit(ctx, auditCreated, nil, &prd); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	web.SetHeader(ctx, "Location", location(prd))
+
+	resp := toAppProduct(prd)
+	resp.Warnings = checkWarnings(&np.Cost, &np.Quantity)
+
+	return resp
+}
+
+// maxBulkCreate is the maximum number of products accepted in a single
+// bulk create request.
+const maxBulkCreate = 100
+
+// bulkCreate adds a batch of products inside a single transaction. By default
+// elements that fail validation are skipped and reported by index in the
+// response while the valid elements are stored. When mode=atomic is provided
+// any invalid element rejects the entire batch and nothing is stored.
+func (a *app) bulkCreate(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewProducts
+	if err := web.DecodeStrict(r, &app); err != nil {
+		return decodeError(err)
+	}
+
+	switch {
+	case len(app) == 0:
+		return errs.Newf(errs.InvalidArgument, "no products provided")
+	case len(app) > maxBulkCreate:
+		return errs.Newf(errs.InvalidArgument, "too many products provided: max[%d]", maxBulkCreate)
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "partial" {
+		return a.bulkCreatePartial(ctx, app)
+	}
+
+	atomic := mode == "atomic"
+
+	nps := make([]productbus.NewProduct, 0, len(app))
+	bulkErrs := []BulkError{}
+
+	for i, anp := range app {
+		np, err := toBusNewProduct(ctx, a.defaults, anp)
+		if err != nil {
+			bulkErrs = append(bulkErrs, BulkError{Index: i, Error: err.Error()})
+			continue
+		}
+
+		nps = append(nps, np)
+	}
+
+	if atomic && len(bulkErrs) > 0 {
+		var fieldErrors errs.FieldErrors
+		for _, be := range bulkErrs {
+			fieldErrors.Add(fmt.Sprintf("[%d]", be.Index), errors.New(be.Error))
+		}
+
+		return fieldErrors.ToError()
+	}
+
+	prds := []productbus.Product{}
+	if len(nps) > 0 {
+		a, err := a.newWithTx(ctx)
+		if err != nil {
+			return errs.New(errs.Internal, err)
+		}
+
+		prds, err = a.productBus.BulkCreate(ctx, nps)
+		if err != nil {
+			if errors.Is(err, productbus.ErrUserDisabled) {
+				return errs.New(errs.FailedPrecondition, err)
+			}
+			if errors.Is(err, productbus.ErrDuplicateSKU) {
+				return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+			}
+			if errors.Is(err, productbus.ErrDuplicateName) {
+				return errs.New(errs.Aborted, productbus.ErrDuplicateName)
+			}
+			if errors.Is(err, productbus.ErrCategoryNotFound) {
+				return errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+			}
+			return errs.Newf(errs.Internal, "bulkcreate: count[%d]: %s", len(nps), err)
+		}
+
+		for _, prd := range prds {
+			if err := a.audit(ctx, auditCreated, nil, &prd); err != nil {
+				return errs.New(errs.Internal, err)
+			}
+		}
+	}
+
+	result := BulkResult{
+		Items:  toAppProducts(prds),
+		Total:  len(prds),
+		Errors: bulkErrs,
+	}
+
+	return result
+}
+
+// bulkCreatePartial stores every valid product independently and reports
+// the outcome of each one with a 207 response.
+func (a *app) bulkCreatePartial(ctx context.Context, app NewProducts) web.Encoder {
+	items := make([]BulkItemResult, len(app))
+
+	var nps []productbus.NewProduct
+	var indexes []int
+
+	for i, anp := range app {
+		np, err := toBusNewProduct(ctx, a.defaults, anp)
+		if err != nil {
+			items[i] = BulkItemResult{Index: i, Status: http.StatusBadRequest, Error: err.Error()}
+			continue
+		}
+
+		nps = append(nps, np)
+		indexes = append(indexes, i)
+	}
+
+	if len(nps) > 0 {
+		a, err := a.newWithTx(ctx)
+		if err != nil {
+			return errs.New(errs.Internal, err)
+		}
+
+		outcomes, err := a.productBus.BulkCreatePartial(ctx, nps)
+		if err != nil {
+			return errs.Newf(errs.Internal, "bulkcreatepartial: count[%d]: %s", len(nps), err)
+		}
+
+		for j, outcome := range outcomes {
+			i := indexes[j]
+
+			if outcome.Err != nil {
+				appErr := toBulkItemError(outcome.Err)
+				items[i] = BulkItemResult{Index: i, Status: appErr.HTTPStatus(), Error: appErr.Message}
+				continue
+			}
+
+			if err := a.audit(ctx, auditCreated, nil, &outcome.Product); err != nil {
+				return errs.New(errs.Internal, err)
+			}
+
+			items[i] = BulkItemResult{Index: i, Status: http.StatusCreated, ID: outcome.Product.ID.String()}
+		}
+	}
+
+	return newBulkMultiStatus("partial", bulkPartialNote, items)
+}
+
+// toBulkItemError maps the error of a product that couldn't be stored the
+// way create and update do. The details of unexpected errors aren't reported.
+func toBulkItemError(err error) *errs.Error {
+	switch {
+	case errors.Is(err, productbus.ErrUserDisabled):
+		return errs.New(errs.FailedPrecondition, productbus.ErrUserDisabled)
+	case errors.Is(err, productbus.ErrDuplicateSKU):
+		return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+	case errors.Is(err, productbus.ErrDuplicateName):
+		return errs.New(errs.Aborted, productbus.ErrDuplicateName)
+	case errors.Is(err, productbus.ErrCategoryNotFound):
+		return errs.New(errs.InvalidArgument, productbus.ErrCategoryNotFound)
+	case errors.Is(err, productbus.ErrVersionConflict):
+		return errs.New(errs.Aborted, productbus.ErrVersionConflict)
+	case errors.Is(err, productbus.ErrNotFound):
+		return errs.New(errs.NotFound, productbus.ErrNotFound)
+	}
+
+	return errs.Newf(errs.Internal, "Internal Server Error")
+}
+
+// maxBulkUpdate is the maximum number of products accepted in a single bulk
+// update request.
+const maxBulkUpdate = 100
+
+// bulkUpdate applies a set of partial updates and reports the outcome of
+// every product with a 207 response. Every item is validated before any
+// product is changed. Products that aren't found are reported and skipped.
+// By default any other failure rejects the whole batch, with mode=partial
+// every product is updated independently.
+func (a *app) bulkUpdate(ctx context.Context, r *http.Request) web.Encoder {
+	var app BulkUpdateItems
+	if err := web.DecodeStrict(r, &app); err != nil {
+		return decodeError(err)
+	}
+
+	switch {
+	case len(app) == 0:
+		return errs.Newf(errs.InvalidArgument, "no products provided")
+	case len(app) > maxBulkUpdate:
+		return errs.Newf(errs.InvalidArgument, "too many products provided: max[%d]", maxBulkUpdate)
+	}
+
+	partial := r.URL.Query().Get("mode") == "partial"
+
+	items := make([]BulkItemResult, len(app))
+	var fieldErrors errs.FieldErrors
+
+	reject := func(i int, err error) {
+		if partial {
+			items[i] = BulkItemResult{Index: i, Status: http.StatusBadRequest, ID: app[i].ID, Error: err.Error()}
+			return
+		}
+
+		fieldErrors.Add(fmt.Sprintf("[%d]", i), err)
+	}
+
+	ups := make(map[int]productbus.UpdateProduct, len(app))
+	ids := make(map[uuid.UUID]int, len(app))
+	idOf := make(map[int]uuid.UUID, len(app))
+
+	for i, item := range app {
+		id, err := uuid.Parse(item.ID)
+		if err != nil {
+			reject(i, fmt.Errorf("id: %w", err))
+			continue
+		}
+
+		// A second change of the same product would be based on a stale
+		// version, so every product can only be listed once.
+		if j, exists := ids[id]; exists {
+			reject(i, fmt.Errorf("id: duplicate of index[%d]", j))
+			continue
+		}
+
+		if err := item.Fields.Validate(); err != nil {
+			reject(i, err)
+			continue
+		}
+
+		up, err := toBusUpdateProduct(item.Fields)
+		if err != nil {
+			reject(i, err)
+			continue
+		}
+
+		ids[id] = i
+		idOf[i] = id
+		ups[i] = up
+	}
+
+	if fieldErrors != nil {
+		return fieldErrors.ToError()
+	}
+
+	prdIDs := make([]uuid.UUID, 0, len(ids))
+	for id := range ids {
+		prdIDs = append(prdIDs, id)
+	}
+
+	prds, err := a.productBus.QueryByIDs(ctx, prdIDs)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyids: %s", err)
+	}
+
+	found := make(map[uuid.UUID]productbus.Product, len(prds))
+	for _, prd := range prds {
+		found[prd.ID] = prd
+	}
+
+	var pus []productbus.ProductUpdate
+	var indexes []int
+
+	for i, item := range app {
+		up, exists := ups[i]
+		if !exists {
+			continue
+		}
+
+		prd, exists := found[idOf[i]]
+		if !exists {
+			items[i] = BulkItemResult{Index: i, Status: http.StatusNotFound, ID: item.ID, Error: productbus.ErrNotFound.Error()}
+			continue
+		}
+
+		pus = append(pus, productbus.ProductUpdate{Product: prd, Update: up})
+		indexes = append(indexes, i)
+	}
+
+	mode, note := "atomic", bulkUpdateNote
+	if partial {
+		mode, note = "partial", bulkUpdatePartialNote
+	}
+
+	if len(pus) == 0 {
+		return newBulkMultiStatus(mode, note, items)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	outcomes := make([]productbus.BulkOutcome, len(pus))
+
+	switch partial {
+	case true:
+		outcomes, err = a.productBus.BulkUpdatePartial(ctx, pus)
+		if err != nil {
+			return errs.Newf(errs.Internal, "bulkupdatepartial: count[%d]: %s", len(pus), err)
+		}
+
+	default:
+		updPrds, err := a.productBus.BulkUpdate(ctx, pus)
+		if err != nil {
+			appErr := toBulkItemError(err)
+			if appErr.Code == errs.Internal {
+				return errs.Newf(errs.Internal, "bulkupdate: count[%d]: %s", len(pus), err)
+			}
+
+			return errs.Newf(appErr.Code, "%s", err)
+		}
+
+		for j, prd := range updPrds {
+			outcomes[j].Product = prd
+		}
+	}
+
+	for j, outcome := range outcomes {
+		i := indexes[j]
+
+		if outcome.Err != nil {
+			appErr := toBulkItemError(outcome.Err)
+			items[i] = BulkItemResult{Index: i, Status: appErr.HTTPStatus(), ID: app[i].ID, Error: appErr.Message}
+			continue
+		}
+
+		before := pus[j].Product
+		if err := a.audit(ctx, auditUpdated, &before, &outcome.Product); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+
+		items[i] = BulkItemResult{Index: i, Status: http.StatusOK, ID: app[i].ID}
+	}
+
+	return newBulkMultiStatus(mode, note, items)
+}
+
+// maxUpsert is the maximum number of products accepted in a single upsert
+// request.
+const maxUpsert = 100
+
+// upsert creates the products that don't exist yet and updates the ones that
+// do, matching them by sku or, with conflict=id, by id. Every product is
+// stored independently and the outcome of each one is reported with a 207
+// response.
+func (a *app) upsert(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpsertItems
+	if err := web.DecodeStrict(r, &app); err != nil {
+		return decodeError(err)
+	}
+
+	switch {
+	case len(app) == 0:
+		return errs.Newf(errs.InvalidArgument, "no products provided")
+	case len(app) > maxUpsert:
+		return errs.Newf(errs.InvalidArgument, "too many products provided: max[%d]", maxUpsert)
+	}
+
+	var target productbus.ConflictTarget
+	switch conflict := r.URL.Query().Get("conflict"); conflict {
+	case "", string(productbus.ConflictSKU):
+		target = productbus.ConflictSKU
+	case string(productbus.ConflictID):
+		target = productbus.ConflictID
+	default:
+		return errs.NewFieldErrors("conflict", fmt.Errorf("unknown conflict target %q, must be one of sku, id", conflict))
+	}
+
+	items := make([]BulkItemResult, len(app))
+	keys := make(map[string]int, len(app))
+
+	var ups []productbus.UpsertProduct
+	var indexes []int
+
+	// Products are matched on their SKU, so it can't be left to be generated.
+	defaults := skuPolicy{DefaultsPolicy: a.defaults, generate: false}
+
+	for i, item := range app {
+		np, err := toBusNewProduct(ctx, defaults, item.Product)
+		if err != nil {
+			items[i] = BulkItemResult{Index: i, Status: http.StatusBadRequest, ID: item.ID, Error: err.Error()}
+			continue
+		}
+
+		up := productbus.UpsertProduct{NewProduct: np}
+		key := np.SKU.String()
+
+		if target == productbus.ConflictID {
+			id, err := uuid.Parse(item.ID)
+			if err != nil {
+				items[i] = BulkItemResult{Index: i, Status: http.StatusBadRequest, ID: item.ID, Error: fmt.Sprintf("id: %s", err)}
+				continue
+			}
+
+			up.ID = id
+			key = id.String()
+		}
+
+		// A second row for the same product would overwrite the first one
+		// within the same request, so every product can only be listed once.
+		if j, exists := keys[key]; exists {
+			items[i] = BulkItemResult{Index: i, Status: http.StatusBadRequest, ID: item.ID, Error: fmt.Sprintf("%s: duplicate of index[%d]", target, j)}
+			continue
+		}
+
+		keys[key] = i
+		ups = append(ups, up)
+		indexes = append(indexes, i)
+	}
+
+	if len(ups) == 0 {
+		return newBulkMultiStatus("partial", bulkUpsertNote, items)
+	}
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	outcomes, err := a.productBus.BulkUpsert(ctx, target, ups)
+	if err != nil {
+		return errs.Newf(errs.Internal, "bulkupsert: count[%d]: %s", len(ups), err)
+	}
+
+	for j, outcome := range outcomes {
+		i := indexes[j]
+
+		if outcome.Err != nil {
+			appErr := toBulkItemError(outcome.Err)
+			items[i] = BulkItemResult{Index: i, Status: appErr.HTTPStatus(), ID: app[i].ID, Error: appErr.Message}
+			continue
+		}
+
+		action, status := auditCreated, http.StatusCreated
+		if !outcome.Created {
+			action, status = auditUpdated, http.StatusOK
+		}
+
+		if err := a.audit(ctx, action, outcome.Before, &outcome.Product); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+
+		items[i] = BulkItemResult{Index: i, Status: status, ID: outcome.Product.ID.String(), Action: action}
+	}
+
+	return newBulkMultiStatus("partial", bulkUpsertNote, items)
 }
 
 func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 	var app UpdateProduct
-	if err := web.Decode(r, &app); err != nil {
-		return errs.New(errs.InvalidArgument, err)
+	if err := web.DecodeStrict(r, &app); err != nil {
+		return decodeError(err)
 	}
 
 	up, err := toBusUpdateProduct(app)
@@ -59,63 +689,1387 @@ func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && !web.MatchETag(ifMatch, ETag(prd)) {
+		return errs.New(errs.PreconditionFailed, productbus.ErrVersionConflict)
+	}
+
+	form, err := parseReturn(r.URL.Query().Get("return"))
+	if err != nil {
+		return errs.NewFieldErrors("return", err)
+	}
+
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return errs.NewFieldErrors("dry_run", err)
+		}
+
+		if dryRun {
+			return a.previewUpdate(ctx, prd, up)
+		}
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
 	updPrd, err := a.productBus.Update(ctx, prd, up)
 	if err != nil {
+		if errors.Is(err, productbus.ErrVersionConflict) {
+			if ifMatch != "" {
+				return errs.New(errs.PreconditionFailed, err)
+			}
+			return errs.New(errs.Aborted, err)
+		}
+		if errors.Is(err, productbus.ErrDuplicateSKU) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+		}
+		if errors.Is(err, productbus.ErrCategoryNotFound) {
+			return errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+		}
 		return errs.Newf(errs.Internal, "update: productID[%s] up[%+v]: %s", prd.ID, app, err)
 	}
 
-	return toAppProduct(updPrd)
-}
+	if err := a.audit(ctx, auditUpdated, &prd, &updPrd); err != nil {
+		return errs.New(errs.Internal, err)
+	}
 
-func (a *app) delete(ctx context.Context, _ *http.Request) web.Encoder {
-	prd, err := mid.GetProduct(ctx)
-	if err != nil {
-		return errs.Newf(errs.Internal, "productID missing in context: %s", err)
+	web.SetHeader(ctx, "ETag", ETag(updPrd))
+
+	resp := toAppProduct(updPrd)
+	resp.Warnings = checkWarnings(up.Cost, up.Quantity)
+
+	if form == returnDelta {
+		delta, err := toDeltaProduct(toAppProduct(prd), resp)
+		if err != nil {
+			return errs.Newf(errs.Internal, "todeltaproduct: productID[%s]: %s", prd.ID, err)
+		}
+
+		return delta
 	}
 
-	if err := a.productBus.Delete(ctx, prd); err != nil {
-		return errs.Newf(errs.Internal, "delete: productID[%s]: %s", prd.ID, err)
+	return resp
+}
+
+// previewUpdate returns the product the update would produce without storing
+// it. The category is checked here since the store isn't involved.
+func (a *app) previewUpdate(ctx context.Context, prd productbus.Product, up productbus.UpdateProduct) web.Encoder {
+	if up.CategoryID != nil {
+		if _, err := a.categoryBus.QueryByID(ctx, *up.CategoryID); err != nil {
+			if errors.Is(err, categorybus.ErrNotFound) {
+				return errs.NewFieldErrors("categoryID", productbus.ErrCategoryNotFound)
+			}
+			return errs.Newf(errs.Internal, "category.querybyid: categoryID[%s]: %s", up.CategoryID, err)
+		}
 	}
 
-	return nil
+	app := toAppProduct(a.productBus.PreviewUpdate(ctx, prd, up))
+	app.Warnings = checkWarnings(up.Cost, up.Quantity)
+
+	return UpdatePreview{
+		DryRun:  true,
+		Product: app,
+	}
 }
 
-func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
-	qp := parseQueryParams(r)
+// patchPaths are the only locations a JSON Patch is allowed to touch. Every
+// other field of the product is immutable.
+var patchPaths = []string{"/sku", "/name", "/description", "/cost", "/quantity"}
+
+// patch applies a JSON Patch (RFC 6902) document to the current
+// representation of the product and stores the result.
+func (a *app) patch(ctx context.Context, r *http.Request) web.Encoder {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != jsonpatch.ContentType {
+		return errs.Newf(errs.InvalidArgument, "content type must be %s", jsonpatch.ContentType)
+	}
+
+	var patch jsonpatch.Patch
+	if err := web.Decode(r, &patch); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	for _, path := range patch.Paths() {
+		if !slices.Contains(patchPaths, path) {
+			return errs.Newf(errs.UnprocessableEntity, "path %q can't be modified", path)
+		}
+	}
 
-	page, err := page.Parse(qp.Page, qp.Rows)
+	prd, err := mid.GetProduct(ctx)
 	if err != nil {
-		return errs.NewFieldErrors("page", err)
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
-	filter, err := parseFilter(qp)
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && !web.MatchETag(ifMatch, ETag(prd)) {
+		return errs.New(errs.PreconditionFailed, productbus.ErrVersionConflict)
+	}
+
+	doc, err := json.Marshal(toAppProduct(prd))
 	if err != nil {
-		return err.(*errs.Error)
+		return errs.Newf(errs.Internal, "marshal: %s", err)
 	}
 
-	orderBy, err := order.Parse(orderByFields, qp.OrderBy, productbus.DefaultOrderBy)
+	patched, err := patch.Apply(doc)
 	if err != nil {
-		return errs.NewFieldErrors("order", err)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return errs.New(errs.Aborted, err)
+		}
+		return errs.New(errs.UnprocessableEntity, err)
 	}
 
-	prds, err := a.productBus.Query(ctx, filter, orderBy, page)
+	// The patched document must still describe a valid product.
+	var app NewProduct
+	if err := app.Decode(patched); err != nil {
+		return errs.New(errs.UnprocessableEntity, err)
+	}
+
+	if err := app.Validate(); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	up, err := toBusUpdateProduct(UpdateProduct{
+		SKU:         &app.SKU,
+		Name:        &app.Name,
+		Description: &app.Description,
+		Cost:        &app.Cost,
+		Quantity:    app.Quantity,
+	})
 	if err != nil {
-		return errs.Newf(errs.Internal, "query: %s", err)
+		return errs.New(errs.InvalidArgument, err)
 	}
 
-	total, err := a.productBus.Count(ctx, filter)
+	a, err = a.newWithTx(ctx)
 	if err != nil {
-		return errs.Newf(errs.Internal, "count: %s", err)
+		return errs.New(errs.Internal, err)
+	}
+
+	updPrd, err := a.productBus.Update(ctx, prd, up)
+	if err != nil {
+		if errors.Is(err, productbus.ErrVersionConflict) {
+			if ifMatch != "" {
+				return errs.New(errs.PreconditionFailed, err)
+			}
+			return errs.New(errs.Aborted, err)
+		}
+		if errors.Is(err, productbus.ErrDuplicateSKU) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+		}
+		return errs.Newf(errs.Internal, "patch: productID[%s] up[%+v]: %s", prd.ID, app, err)
+	}
+
+	if err := a.audit(ctx, auditUpdated, &prd, &updPrd); err != nil {
+		return errs.New(errs.Internal, err)
 	}
 
-	return query.NewResult(toAppProducts(prds), total, page)
+	web.SetHeader(ctx, "ETag", ETag(updPrd))
+
+	resp := toAppProduct(updPrd)
+	resp.Warnings = checkWarnings(up.Cost, up.Quantity)
+
+	return resp
 }
 
-func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	reason, err := parseReason(r.URL.Query().Get("reason"), a.requireDeleteReason)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	strict, err := parseStrict(r.URL.Query().Get("strict"), a.strictDelete)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
 	prd, err := mid.GetProduct(ctx)
 	if err != nil {
-		return errs.Newf(errs.Internal, "querybyid: %s", err)
+		return errs.Newf(errs.Internal, "productID missing in context: %s", err)
+	}
+
+	// Deleting a deleted product is a no-op that isn't audited. It's reported
+	// as a success so a retried delete succeeds, or as not found, like a
+	// product that never existed, to the clients asking for strict deletes.
+	if prd.DateDeleted != nil {
+		if strict {
+			return errs.New(errs.NotFound, productbus.ErrNotFound)
+		}
+		return nil
 	}
 
-	return toAppProduct(prd)
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	if err := a.productBus.Delete(ctx, prd); err != nil {
+		return errs.Newf(errs.Internal, "delete: productID[%s]: %s", prd.ID, err)
+	}
+
+	if err := a.auditReason(ctx, auditDeleted, &prd, nil, reason); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return nil
+}
+
+// parseStrict reports whether a delete is strict, the strict query parameter
+// overriding the default of the deployment.
+func parseStrict(value string, def bool) (bool, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	strict, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errs.NewFieldErrors("strict", err)
+	}
+
+	return strict, nil
+}
+
+// bulkDelete deletes every product matching the same filter parameters
+// accepted by query. Since a mistake is costly the request must carry
+// confirm=true and at least one filter.
+func (a *app) bulkDelete(ctx context.Context, r *http.Request) web.Encoder {
+	if confirm, _ := strconv.ParseBool(r.URL.Query().Get("confirm")); !confirm {
+		return errs.NewFieldErrors("confirm", errors.New("bulk delete must be confirmed with confirm=true"))
+	}
+
+	reason, err := parseReason(r.URL.Query().Get("reason"), a.requireDeleteReason)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	filter, err := parseFilter(parseQueryParams(r))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	// Deleted products are never touched so include_deleted on its own
+	// doesn't narrow down the products to delete.
+	filter.IncludeDeleted = nil
+	if filter.IsEmpty() {
+		return errs.Newf(errs.InvalidArgument, "at least one filter is required")
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	prds, err := a.productBus.DeleteByFilter(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "deletebyfilter: filter[%+v]: %s", filter, err)
+	}
+
+	for _, prd := range prds {
+		if err := a.auditReason(ctx, auditDeleted, &prd, nil, reason); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return BulkDeleteResult{Deleted: len(prds)}
+}
+
+// bulkAdjustPrice changes the cost of every product matching the same filter
+// parameters accepted by query. All the costs change together or, when the
+// adjustment would make any cost negative, none of them do. Since a mistake
+// is costly the request must carry confirm=true. Each change is recorded in
+// the price history and the audit trail of its product.
+func (a *app) bulkAdjustPrice(ctx context.Context, r *http.Request) web.Encoder {
+	if confirm, _ := strconv.ParseBool(r.URL.Query().Get("confirm")); !confirm {
+		return errs.NewFieldErrors("confirm", errors.New("bulk price update must be confirmed with confirm=true"))
+	}
+
+	var app PriceAdjustment
+	if err := web.DecodeStrict(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	adj, err := toBusPriceAdjustment(app)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	filter, err := parseFilter(parseQueryParams(r))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	// Deleted products are never touched so include_deleted doesn't apply.
+	filter.IncludeDeleted = nil
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	adjusted, err := a.productBus.AdjustPriceByFilter(ctx, filter, adj)
+	if err != nil {
+		if errors.Is(err, productbus.ErrInvalidCost) {
+			return errs.Newf(errs.InvalidArgument, "the adjustment would make a cost negative")
+		}
+		return errs.Newf(errs.Internal, "adjustpricebyfilter: filter[%+v]: %s", filter, err)
+	}
+
+	for _, pa := range adjusted {
+		if err := a.audit(ctx, auditUpdated, &pa.Before, &pa.Product); err != nil {
+			return errs.New(errs.Internal, err)
+		}
+	}
+
+	return BulkPriceResult{Updated: len(adjusted)}
+}
+
+func (a *app) adjustStock(ctx context.Context, r *http.Request) web.Encoder {
+	var app AdjustStock
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	adjPrd, err := a.productBus.AdjustStock(ctx, prd, app.Delta)
+	if err != nil {
+		if errors.Is(err, productbus.ErrNotFound) {
+			return errs.New(errs.NotFound, productbus.ErrNotFound)
+		}
+		if errors.Is(err, productbus.ErrInsufficientStock) {
+			return errs.New(errs.Aborted, productbus.ErrInsufficientStock)
+		}
+		return errs.Newf(errs.Internal, "adjuststock: productID[%s] delta[%d]: %s", prd.ID, app.Delta, err)
+	}
+
+	if err := a.audit(ctx, auditUpdated, &prd, &adjPrd); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	web.SetHeader(ctx, "ETag", ETag(adjPrd))
+
+	return toAppProduct(adjPrd)
+}
+
+// touch bumps the update date of the product without changing it, so
+// caches keyed on it are refreshed, and returns the product.
+func (a *app) touch(ctx context.Context, _ *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	touched, err := a.productBus.Touch(ctx, prd)
+	if err != nil {
+		if errors.Is(err, productbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "touch: productID[%s]: %s", prd.ID, err)
+	}
+
+	if err := a.audit(ctx, auditTouched, &prd, &touched); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	web.SetHeader(ctx, "ETag", ETag(touched))
+
+	return toAppProduct(touched)
+}
+
+// maxQueryByIDs is the maximum number of ids accepted in a single batch query.
+const maxQueryByIDs = 100
+
+func (a *app) queryByIDs(ctx context.Context, r *http.Request) web.Encoder {
+	var ids ProductIDs
+
+	switch r.Method {
+	case http.MethodPost:
+		if err := web.Decode(r, &ids); err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+
+	default:
+		if v := r.URL.Query().Get("ids"); v != "" {
+			ids = strings.Split(v, ",")
+		}
+	}
+
+	switch {
+	case len(ids) == 0:
+		return errs.NewFieldErrors("ids", errors.New("at least one id is required"))
+	case len(ids) > maxQueryByIDs:
+		return errs.NewFieldErrors("ids", fmt.Errorf("too many ids provided: max[%d]", maxQueryByIDs))
+	}
+
+	var fieldErrors errs.FieldErrors
+
+	prdIDs := make([]uuid.UUID, 0, len(ids))
+	for i, id := range ids {
+		prdID, err := uuid.Parse(strings.TrimSpace(id))
+		if err != nil {
+			fieldErrors.Add(fmt.Sprintf("ids[%d]", i), err)
+			continue
+		}
+
+		prdIDs = append(prdIDs, prdID)
+	}
+
+	if fieldErrors != nil {
+		return fieldErrors.ToError()
+	}
+
+	prds, err := a.productBus.QueryByIDs(ctx, prdIDs)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyids: %s", err)
+	}
+
+	found := make(map[uuid.UUID]struct{}, len(prds))
+	for _, prd := range prds {
+		found[prd.ID] = struct{}{}
+	}
+
+	notFound := []string{}
+	for _, prdID := range prdIDs {
+		if _, exists := found[prdID]; !exists {
+			notFound = append(notFound, prdID.String())
+		}
+	}
+
+	result := BatchResult{
+		Items:    redactProducts(ctx, toAppProducts(prds)),
+		NotFound: notFound,
+	}
+
+	return result
+}
+
+func (a *app) restore(ctx context.Context, _ *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	// Restoring a product that isn't deleted is a no-op that isn't audited.
+	if prd.DateDeleted == nil {
+		return toAppProduct(prd)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	rstPrd, err := a.productBus.Restore(ctx, prd)
+	if err != nil {
+		if errors.Is(err, productbus.ErrVersionConflict) {
+			return errs.New(errs.Aborted, err)
+		}
+		return errs.Newf(errs.Internal, "restore: productID[%s]: %s", prd.ID, err)
+	}
+
+	if err := a.audit(ctx, auditRestored, &prd, &rstPrd); err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return toAppProduct(rstPrd)
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	if qp.CountOnly != "" {
+		countOnly, err := strconv.ParseBool(qp.CountOnly)
+		if err != nil {
+			return errs.NewFieldErrors("count_only", err)
+		}
+
+		if countOnly {
+			return a.count(ctx, r)
+		}
+	}
+
+	if qp.Cursor != "" {
+		return a.queryByCursor(ctx, r, qp)
+	}
+
+	page, err := a.parsePaging(ctx, qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	fields, err := parseFields(qp.Fields)
+	if err != nil {
+		return errs.NewFieldErrors("fields", err)
+	}
+
+	expand, err := parseExpand(qp.Expand)
+	if err != nil {
+		return errs.NewFieldErrors("expand", err)
+	}
+
+	freshCount, err := parseFreshCount(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if filter.IncludeDeleted != nil && *filter.IncludeDeleted && !isAdmin(ctx) {
+		return errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins")
+	}
+
+	if costFiltered(filter) && !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs")
+	}
+
+	orderBy, err := order.ParseMany(productbus.OrderByFields, qp.OrderBy, productbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	if costOrdered(orderBy) && !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "ordering by cost is restricted to the roles that can see costs")
+	}
+
+	snapshot := time.Now().Truncate(time.Microsecond)
+	if qp.Snapshot != "" {
+		snapshot, err = decodeSnapshot(qp.Snapshot)
+		if err != nil {
+			return errs.NewFieldErrors("snapshot", err)
+		}
+	}
+
+	// The tag is keyed on the filter of the client, the snapshot applied
+	// by default is the time of the request and would change it every time.
+	tagParts := queryTagParts(ctx, r, filter, qp.Snapshot, page, orderBy, fields, expand)
+
+	filter = applySnapshot(filter, snapshot)
+
+	prds, err := a.productBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.pageTotal(ctx, filter, freshCount)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	etag := QueryETag(prds, total, tagParts...)
+	web.SetHeader(ctx, "ETag", etag)
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && web.MatchWeakETag(inm, etag) {
+		return web.NewNotModified()
+	}
+
+	items := redactProducts(ctx, toAppProducts(prds))
+
+	if slices.Contains(expand, expandCategory) {
+		if err := a.expandCategories(ctx, items); err != nil {
+			return errs.Newf(errs.Internal, "expand: %s", err)
+		}
+	}
+
+	result := query.NewResult(items, total, page)
+
+	// A snapshot is only handed out when there are more pages to request.
+	if qp.Snapshot != "" || result.HasNext {
+		result.Snapshot = NewSnapshot(snapshot)
+	}
+
+	// The links carry the snapshot so following them pages through the
+	// same set of products.
+	links := r.URL.Query()
+	if result.Snapshot != "" {
+		links.Set("snapshot", result.Snapshot)
+	}
+	web.SetHeader(ctx, "Link", query.Links(r.URL.Path, links, page, total))
+
+	if len(prds) > 0 && result.HasNext {
+		result.NextCursor, err = nextCursor(prds[len(prds)-1], orderBy)
+		if err != nil {
+			return errs.Newf(errs.Internal, "cursor: %s", err)
+		}
+	}
+
+	return respondQuery(ctx, r, result, fields)
+}
+
+// pageTotal returns the total of the filter reported with a page of products.
+// A total counted for an earlier page is reused when the business caches
+// counts, unless the client asked for a fresh count. A failed count gives
+// UnknownTotal when count errors are tolerated, so the page can still be
+// returned.
+func (a *app) pageTotal(ctx context.Context, filter productbus.QueryFilter, fresh bool) (int, error) {
+	count := a.productBus.CountCached
+	if fresh {
+		count = a.productBus.Count
+	}
+
+	total, err := count(ctx, filter)
+	if err != nil {
+		if !a.tolerateCountErrors || ctx.Err() != nil {
+			return 0, err
+		}
+
+		a.log.Error(ctx, "query: count unavailable", "err", err)
+		return query.UnknownTotal, nil
+	}
+
+	return total, nil
+}
+
+// priceHistory returns the cost changes of the product in the order they
+// happened.
+func (a *app) priceHistory(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := a.parsePage(ctx, qp.Page, qp.Rows)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "price history is restricted to the roles that can see costs")
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	history, err := a.productBus.QueryPriceHistory(ctx, prd.ID, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querypricehistory: productID[%s]: %s", prd.ID, err)
+	}
+
+	total, err := a.productBus.CountPriceHistory(ctx, prd.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "countpricehistory: productID[%s]: %s", prd.ID, err)
+	}
+
+	return query.NewResult(toAppPriceChanges(history), total, page)
+}
+
+// auditTrail returns the recorded changes of the product in the order they
+// happened. The trail can be limited to a date range with since and until.
+func (a *app) auditTrail(ctx context.Context, r *http.Request) web.Encoder {
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	productID := prd.ID
+
+	values := r.URL.Query()
+
+	page, err := a.parsePage(ctx, values.Get("page"), values.Get("rows"))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	objDomain := domain.Product
+	filter := auditbus.QueryFilter{
+		ObjID:     &productID,
+		ObjDomain: &objDomain,
+	}
+
+	var fieldErrors errs.FieldErrors
+
+	if v := values.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		switch err {
+		case nil:
+			filter.Since = &t
+		default:
+			fieldErrors.Add("since", err)
+		}
+	}
+
+	if v := values.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		switch err {
+		case nil:
+			filter.Until = &t
+		default:
+			fieldErrors.Add("until", err)
+		}
+	}
+
+	if fieldErrors != nil {
+		return fieldErrors.ToError()
+	}
+
+	orderBy := order.NewBy(auditbus.OrderByTimestamp, order.ASC)
+
+	adts, err := a.auditBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "audit.query: productID[%s]: %s", productID, err)
+	}
+
+	total, err := a.auditBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "audit.count: productID[%s]: %s", productID, err)
+	}
+
+	entries, err := toAppAuditEntries(adts)
+	if err != nil {
+		return errs.Newf(errs.Internal, "toappauditentries: %s", err)
+	}
+
+	return query.NewResult(entries, total, page)
+}
+
+// exportBatchSize is the number of products read from the store at a time
+// while an export is streamed. It's lowered to the configured maximum rows
+// per page so an export never reads more rows at once than a query can.
+const exportBatchSize = 500
+
+// Set of formats an export can be streamed in.
+const (
+	exportNDJSON = "ndjson"
+	exportJSON   = "json"
+	exportCSV    = "csv"
+)
+
+// export streams every product matching the filter in the format requested
+// with the format parameter, newline delimited JSON by default. When fields
+// are provided only those are exported. The stream ends early when the
+// client disconnects since the request context is canceled.
+func (a *app) export(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	format := exportNDJSON
+	if v := r.URL.Query().Get("format"); v != "" {
+		format = strings.ToLower(v)
+	}
+
+	if format != exportNDJSON && format != exportJSON && format != exportCSV {
+		return errs.NewFieldErrors("format", fmt.Errorf("unknown format %q, must be one of %s, %s or %s", format, exportNDJSON, exportJSON, exportCSV))
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	fields, err := parseFields(qp.Fields)
+	if err != nil {
+		return errs.NewFieldErrors("fields", err)
+	}
+
+	if filter.IncludeDeleted != nil && *filter.IncludeDeleted && !isAdmin(ctx) {
+		return errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins")
+	}
+
+	if costFiltered(filter) && !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs")
+	}
+
+	prds := func(yield func(Product, error) bool) {
+		for prd, err := range a.productBus.Export(ctx, filter, min(exportBatchSize, a.maxRowsPerPage)) {
+			if !yield(redactProduct(ctx, toAppProduct(prd)), err) {
+				return
+			}
+		}
+	}
+
+	w := web.GetWriter(ctx)
+
+	if format == exportCSV {
+		header := csvHeader
+		if fields != nil {
+			header = fields
+		}
+
+		// Rows can't carry an error so the first one ends the rows and is
+		// logged once the stream is written.
+		var exportErr error
+		rows := func(yield func([]string) bool) {
+			for prd, err := range prds {
+				if err != nil {
+					exportErr = err
+					return
+				}
+
+				if !yield(csvRow(prd, header)) {
+					return
+				}
+			}
+		}
+
+		if err := web.RespondCSV(ctx, w, "products.csv", header, rows); err != nil {
+			return a.exportFailed(ctx, fmt.Errorf("respondcsv: %w", err))
+		}
+
+		if exportErr != nil {
+			return a.exportFailed(ctx, exportErr)
+		}
+
+		return web.NewNoResponse()
+	}
+
+	values := func(yield func(any, error) bool) {
+		for prd, err := range prds {
+			if err != nil || fields == nil {
+				if !yield(prd, err) {
+					return
+				}
+				continue
+			}
+
+			partial, err := toPartialProduct(prd, fields)
+			if !yield(partial, err) {
+				return
+			}
+		}
+	}
+
+	switch format {
+	case exportJSON:
+		if err := web.RespondJSONArray(ctx, w, values); err != nil {
+			return a.exportFailed(ctx, fmt.Errorf("respondjsonarray: %w", err))
+		}
+
+	default:
+		if err := web.RespondNDJSON(ctx, w, values); err != nil {
+			return a.exportFailed(ctx, fmt.Errorf("respondndjson: %w", err))
+		}
+	}
+
+	return web.NewNoResponse()
+}
+
+// exportFailed logs an error that ended an export once it started to
+// stream. The status and part of the body are already written so the error
+// can't be sent to the client, who is left with the truncated stream.
+func (a *app) exportFailed(ctx context.Context, err error) web.Encoder {
+	a.log.Error(ctx, "export: stream", "err", err)
+
+	return web.NewNoResponse()
+}
+
+// search returns the products whose name or description match the q
+// parameter using full text search, best matches first. With fuzzy=true the
+// names similar to q match instead, so typos in q are tolerated, and the
+// rank is the similarity.
+func (a *app) search(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		return errs.NewFieldErrors("q", errors.New("search query can't be empty"))
+	}
+
+	var fuzzy bool
+	if v := r.URL.Query().Get("fuzzy"); v != "" {
+		var err error
+		if fuzzy, err = strconv.ParseBool(v); err != nil {
+			return errs.NewFieldErrors("fuzzy", err)
+		}
+	}
+
+	page, err := a.parsePage(ctx, qp.Page, qp.Rows)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	search, count := a.productBus.Search, a.productBus.SearchCount
+	if fuzzy {
+		search, count = a.productBus.FuzzySearch, a.productBus.FuzzySearchCount
+	}
+
+	results, err := search(ctx, q, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "search: %s", err)
+	}
+
+	total, err := count(ctx, q)
+	if err != nil {
+		return errs.Newf(errs.Internal, "searchcount: %s", err)
+	}
+
+	items := toAppSearchResults(results)
+	for i := range items {
+		items[i].Product = redactProduct(ctx, items[i].Product)
+	}
+
+	return query.NewResult(items, total, page)
+}
+
+// count returns the number of products matching the filter in the
+// X-Total-Count header without a body so no items need to be retrieved.
+func (a *app) count(ctx context.Context, r *http.Request) web.Encoder {
+	filter, err := parseFilter(parseQueryParams(r))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if filter.IncludeDeleted != nil && *filter.IncludeDeleted && !isAdmin(ctx) {
+		return errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins")
+	}
+
+	if costFiltered(filter) && !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs")
+	}
+
+	total, err := a.productBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	w := web.GetWriter(ctx)
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.WriteHeader(http.StatusOK)
+
+	return web.NewNoResponse()
+}
+
+// summary returns the totals of the products matching the same filter a
+// query accepts.
+func (a *app) summary(ctx context.Context, r *http.Request) web.Encoder {
+	filter, err := parseFilter(parseQueryParams(r))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if filter.IncludeDeleted != nil && *filter.IncludeDeleted && !isAdmin(ctx) {
+		return errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins")
+	}
+
+	if costFiltered(filter) && !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs")
+	}
+
+	sum, err := a.productBus.Summarize(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "summarize: %s", err)
+	}
+
+	resp := toAppSummary(sum)
+	if !canSeeCost(ctx) {
+		resp.InventoryValue = ""
+		resp.AverageCost = ""
+	}
+
+	return resp
+}
+
+// hasPrev reports if a product comes before the window. A cursor without a
+// position starts at the first product. Otherwise the ordering is reversed
+// from the first product of the window, or from the cursor when the window
+// is empty, and one product is asked for.
+func (a *app) hasPrev(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, prds []productbus.Product) (bool, error) {
+	if cursor.ID == uuid.Nil {
+		return false, nil
+	}
+
+	if len(prds) > 0 {
+		c, err := productbus.NewCursor(prds[0], cursor.OrderBy)
+		if err != nil {
+			return false, fmt.Errorf("cursor: %w", err)
+		}
+		cursor = c
+	}
+
+	cursor.OrderBy = reverseOrder(cursor.OrderBy)
+
+	before, err := a.productBus.QueryByCursor(ctx, filter, cursor, 1)
+	if err != nil {
+		return false, fmt.Errorf("querybycursor: %w", err)
+	}
+
+	return len(before) > 0, nil
+}
+
+// reverseOrder returns the ordering that walks the rows the other way.
+func reverseOrder(ob order.By) order.By {
+	switch ob.Direction {
+	case order.ASC:
+		ob.Direction = order.DESC
+	case order.DESC:
+		ob.Direction = order.ASC
+	}
+
+	switch ob.Nulls {
+	case order.NullsFirst:
+		ob.Nulls = order.NullsLast
+	case order.NullsLast:
+		ob.Nulls = order.NullsFirst
+	}
+
+	return ob
+}
+
+// queryByCursor returns the window of products that follows the position
+// carried by the cursor. The ordering is taken from the cursor so every
+// window of a scroll uses the ordering the scroll started with.
+func (a *app) queryByCursor(ctx context.Context, r *http.Request, qp queryParams) web.Encoder {
+	if qp.Page != "" {
+		return errs.NewFieldErrors("cursor", errors.New("cursor and page can't be used together"))
+	}
+
+	if qp.Offset != "" {
+		return errs.NewFieldErrors("cursor", errors.New("cursor and offset can't be used together"))
+	}
+
+	page, err := a.parsePage(ctx, "", qp.Rows)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	fields, err := parseFields(qp.Fields)
+	if err != nil {
+		return errs.NewFieldErrors("fields", err)
+	}
+
+	expand, err := parseExpand(qp.Expand)
+	if err != nil {
+		return errs.NewFieldErrors("expand", err)
+	}
+
+	freshCount, err := parseFreshCount(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	if filter.IncludeDeleted != nil && *filter.IncludeDeleted && !isAdmin(ctx) {
+		return errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins")
+	}
+
+	if costFiltered(filter) && !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs")
+	}
+
+	cursor, err := decodeCursor(qp.Cursor)
+	if err != nil {
+		return errs.NewFieldErrors("cursor", err)
+	}
+
+	if costOrdered([]order.By{cursor.OrderBy}) && !canSeeCost(ctx) {
+		return errs.Newf(errs.PermissionDenied, "ordering by cost is restricted to the roles that can see costs")
+	}
+
+	// Ask for one extra row to know if there is another window to fetch.
+	prds, err := a.productBus.QueryByCursor(ctx, filter, cursor, page.RowsPerPage()+1)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybycursor: %s", err)
+	}
+
+	total, err := a.pageTotal(ctx, filter, freshCount)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	var next string
+	if len(prds) > page.RowsPerPage() {
+		prds = prds[:page.RowsPerPage()]
+
+		next, err = nextCursor(prds[len(prds)-1], []order.By{cursor.OrderBy})
+		if err != nil {
+			return errs.Newf(errs.Internal, "cursor: %s", err)
+		}
+	}
+
+	tagParts := queryTagParts(ctx, r, filter, qp.Cursor, page, []order.By{cursor.OrderBy}, fields, expand)
+
+	etag := QueryETag(prds, total, tagParts...)
+	web.SetHeader(ctx, "ETag", etag)
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && web.MatchWeakETag(inm, etag) {
+		return web.NewNotModified()
+	}
+
+	prev, err := a.hasPrev(ctx, filter, cursor, prds)
+	if err != nil {
+		return errs.Newf(errs.Internal, "prev: %s", err)
+	}
+
+	items := redactProducts(ctx, toAppProducts(prds))
+
+	if slices.Contains(expand, expandCategory) {
+		if err := a.expandCategories(ctx, items); err != nil {
+			return errs.Newf(errs.Internal, "expand: %s", err)
+		}
+	}
+
+	result := query.NewResult(items, total, page)
+	result.NextCursor = next
+	result.HasNext = next != ""
+	result.HasPrev = prev
+
+	if next != "" {
+		web.SetHeader(ctx, "Link", query.CursorLinks(r.URL.Path, r.URL.Query(), next))
+	}
+
+	return respondQuery(ctx, r, result, fields)
+}
+
+// queryTagParts returns what, beside the products, a page of products is
+// tagged with. The position is where the page starts, the snapshot of an
+// offset page or the cursor of a window.
+func queryTagParts(ctx context.Context, r *http.Request, filter productbus.QueryFilter, position string, pg page.Page, orderBy []order.By, fields []string, expand []string) []string {
+	return []string{
+		filter.String(),
+		position,
+		pg.String(),
+		fmt.Sprint(orderBy),
+		strings.Join(fields, ","),
+		strings.Join(expand, ","),
+		strconv.FormatBool(canSeeCost(ctx)),
+		strconv.FormatBool(web.Accepts(r, "text/csv")),
+	}
+}
+
+// expandCategories fills in the category names of the products. The categories
+// of every product are looked up with one query, whatever the number of
+// products.
+func (a *app) expandCategories(ctx context.Context, prds []Product) error {
+	ld := loader.New(a.queryCategories)
+
+	for _, prd := range prds {
+		if id, err := uuid.Parse(prd.CategoryID); err == nil {
+			ld.Add(id)
+		}
+	}
+
+	cats, err := ld.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+
+	for i, prd := range prds {
+		if id, err := uuid.Parse(prd.CategoryID); err == nil {
+			prds[i].CategoryName = cats[id].Name.String()
+		}
+	}
+
+	return nil
+}
+
+// queryCategories looks up the categories with the ids.
+func (a *app) queryCategories(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]categorybus.Category, error) {
+	pg, err := page.ParseClamped("1", strconv.Itoa(len(ids)), len(ids))
+	if err != nil {
+		return nil, fmt.Errorf("page: %w", err)
+	}
+
+	cats, err := a.categoryBus.Query(ctx, categorybus.QueryFilter{IDs: ids}, categorybus.DefaultOrderBy, pg)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	m := make(map[uuid.UUID]categorybus.Category, len(cats))
+	for _, cat := range cats {
+		m[cat.ID] = cat
+	}
+
+	return m, nil
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		return errs.NewFieldErrors("fields", err)
+	}
+
+	expand, err := parseExpand(r.URL.Query().Get("expand"))
+	if err != nil {
+		return errs.NewFieldErrors("expand", err)
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyid: %s", err)
+	}
+
+	// A revalidated read is a view too, so it's recorded before the
+	// conditional headers are checked.
+	if userID, err := mid.GetUserID(ctx); err == nil {
+		a.productBus.RecordView(ctx, userID, prd.ID)
+	}
+
+	etag := ETag(prd)
+	web.SetHeader(ctx, "ETag", etag)
+	web.SetHeader(ctx, "Last-Modified", prd.DateUpdated.UTC().Format(http.TimeFormat))
+
+	if a.cacheMaxAge > 0 {
+		web.SetHeader(ctx, "Cache-Control", fmt.Sprintf("max-age=%d", int(a.cacheMaxAge.Seconds())))
+	}
+
+	if notModified(r, prd, etag) {
+		return web.NewNotModified()
+	}
+
+	app := redactProduct(ctx, toAppProduct(prd))
+
+	if slices.Contains(expand, expandCategory) && prd.CategoryID != nil {
+		cat, err := a.categoryBus.QueryByID(ctx, *prd.CategoryID)
+		if err != nil {
+			return errs.Newf(errs.Internal, "category.querybyid: categoryID[%s]: %s", prd.CategoryID, err)
+		}
+		app.CategoryName = cat.Name.String()
+	}
+
+	if fields != nil {
+		partial, err := toPartialProduct(app, fields)
+		if err != nil {
+			return errs.Newf(errs.Internal, "partial: %s", err)
+		}
+		return partial
+	}
+
+	return app
+}
+
+// queryBySKU returns the product matching the sku query parameter. The
+// product is looked up by the authorization middleware, so the response is
+// the same one queryByID gives for the product.
+func (a *app) queryBySKU(ctx context.Context, r *http.Request) web.Encoder {
+	return a.queryByID(ctx, r)
+}
+
+// defaultRecentlyViewed is the number of recently viewed products returned
+// when the client doesn't ask for a number.
+const defaultRecentlyViewed = 10
+
+// recentlyViewed returns the products the caller viewed most recently, the
+// most recent first.
+func (a *app) recentlyViewed(ctx context.Context, r *http.Request) web.Encoder {
+	limit := defaultRecentlyViewed
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errs.NewFieldErrors("limit", err)
+		}
+
+		if n < 1 || n > productbus.MaxViewsPerUser {
+			return errs.NewFieldErrors("limit", fmt.Errorf("must be between 1 and %d", productbus.MaxViewsPerUser))
+		}
+
+		limit = n
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	prds, err := a.productBus.QueryRecentlyViewed(ctx, userID, limit)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryrecentlyviewed: %s", err)
+	}
+
+	return RecentlyViewed{Items: redactProducts(ctx, toAppProducts(prds))}
+}
+
+// checkNameAvailable reports whether the name is free to use for a new
+// product. It's a hint for forms, nothing stops the name being taken before
+// the product is created.
+func (a *app) checkNameAvailable(ctx context.Context, r *http.Request) web.Encoder {
+	v := r.URL.Query().Get("name")
+	if v == "" {
+		return errs.NewFieldErrors("name", errors.New("a name is required"))
+	}
+
+	nme, err := productbus.ParseName(v)
+	if err != nil {
+		return errs.NewFieldErrors("name", err)
+	}
+
+	exists, err := a.productBus.NameExists(ctx, nme)
+	if err != nil {
+		return errs.Newf(errs.Internal, "nameexists: %s", err)
+	}
+
+	return NameAvailability{Available: !exists}
+}
+
+// notModified reports whether the client already holds the current version
+// of the product. If-Modified-Since is only consulted when If-None-Match is
+// absent, since entity tags are the more precise validator.
+func notModified(r *http.Request, prd productbus.Product, etag string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return web.MatchETag(inm, etag)
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates only have second precision.
+	return !prd.DateUpdated.Truncate(time.Second).After(ims)
+}
+
+// parsePage parses the paging parameters. A rows value above the configured
+// maximum is lowered to the maximum, which is advertised to the client in the
+// X-Max-Rows-Per-Page header.
+func (a *app) parsePage(ctx context.Context, number string, rows string) (page.Page, error) {
+	if w := web.GetWriter(ctx); w != nil {
+		w.Header().Set("X-Max-Rows-Per-Page", strconv.Itoa(a.maxRowsPerPage))
+	}
+
+	pg, err := page.ParseClamped(number, rows, a.maxRowsPerPage)
+	if err != nil {
+		if errors.Is(err, page.ErrInvalidRows) {
+			return page.Page{}, errs.NewFieldErrors("rows", err)
+		}
+
+		return page.Page{}, errs.NewFieldErrors("page", err)
+	}
+
+	return pg, nil
+}
+
+// parsePaging parses either the page and rows parameters or the limit and
+// offset parameters into a page. Mixing the two styles is rejected since it
+// isn't clear which rows the client wants.
+func (a *app) parsePaging(ctx context.Context, qp queryParams) (page.Page, error) {
+	if qp.Limit == "" && qp.Offset == "" {
+		return a.parsePage(ctx, qp.Page, qp.Rows)
+	}
+
+	if qp.Page != "" || qp.Rows != "" {
+		return page.Page{}, errs.NewFieldErrors("offset", errors.New("limit and offset can't be combined with page and rows"))
+	}
+
+	if w := web.GetWriter(ctx); w != nil {
+		w.Header().Set("X-Max-Rows-Per-Page", strconv.Itoa(a.maxRowsPerPage))
+	}
+
+	pg, err := page.ParseOffsetClamped(qp.Limit, qp.Offset, a.maxRowsPerPage)
+	if err != nil {
+		if errors.Is(err, page.ErrInvalidRows) {
+			return page.Page{}, errs.NewFieldErrors("limit", err)
+		}
+
+		return page.Page{}, errs.NewFieldErrors("offset", err)
+	}
+
+	return pg, nil
+}
+
+func isAdmin(ctx context.Context) bool {
+	return slices.Contains(mid.GetClaims(ctx).Roles, role.Admin.String())
 }
@@ -0,0 +1,59 @@
+package productapp
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/types/role"
+)
+
+// costRoles are the roles allowed to see the cost of a product. Users keep
+// seeing costs since they manage the costs of their own products.
+var costRoles = []string{role.Admin.String(), role.User.String(), role.Sales.String()}
+
+// canSeeCost reports if the claims of the caller grant access to costs.
+func canSeeCost(ctx context.Context) bool {
+	return slices.ContainsFunc(mid.GetClaims(ctx).Roles, func(r string) bool {
+		return slices.Contains(costRoles, r)
+	})
+}
+
+// redactProduct strips the fields of the product the caller isn't allowed to
+// see. A stripped field is left out of the response.
+func redactProduct(ctx context.Context, app Product) Product {
+	if !canSeeCost(ctx) {
+		app.Cost = ""
+	}
+
+	return app
+}
+
+// redactProducts applies redactProduct to every product.
+func redactProducts(ctx context.Context, app []Product) []Product {
+	if canSeeCost(ctx) {
+		return app
+	}
+
+	for i := range app {
+		app[i] = redactProduct(ctx, app[i])
+	}
+
+	return app
+}
+
+// costOrdered reports if the products are ordered by cost, which would let a
+// caller that can't see costs rank them by it.
+func costOrdered(orderBy []order.By) bool {
+	return slices.ContainsFunc(orderBy, func(by order.By) bool {
+		return by.Field == productbus.OrderByCost
+	})
+}
+
+// costFiltered reports if the filter constrains the cost, which would let a
+// caller that can't see costs work them out.
+func costFiltered(filter productbus.QueryFilter) bool {
+	return filter.Cost != nil || filter.MinCost != nil || filter.MaxCost != nil
+}
@@ -2,20 +2,126 @@ package productapp
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/categorybus"
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/eventstream"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
+// compressMinSize is the smallest response body worth compressing. Streamed
+// responses like the export aren't compressed since they write directly.
+const compressMinSize = 1 << 10
+
+// defaultMaxBodySize is the largest request body accepted when the
+// configuration doesn't say.
+const defaultMaxBodySize = 1 << 20
+
+// defaultImportMaxBodySize is the largest import accepted when the
+// configuration doesn't say. Imports hold many products so they get a limit
+// of their own.
+const defaultImportMaxBodySize = 64 << 20
+
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Log        *logger.Logger
-	ProductBus *productbus.Business
-	AuthClient *authclient.Client
+	Log         *logger.Logger
+	DB          *sqlx.DB
+	ProductBus  *productbus.Business
+	CategoryBus *categorybus.Business
+	AuditBus    *auditbus.Business
+	AuthClient  *authclient.Client
+
+	// CreateLimiter throttles product creation per user. Creation isn't
+	// throttled when it's nil.
+	CreateLimiter mid.RateLimiter
+
+	// CacheMaxAge is advertised in the Cache-Control header of product
+	// reads. Reads aren't marked as cacheable when it's zero.
+	CacheMaxAge time.Duration
+
+	// MaxRowsPerPage is the largest number of rows a paged read returns.
+	// Larger requests are lowered to it. It defaults to 100 when zero.
+	MaxRowsPerPage int
+
+	// Defaults fills the fields of new products the client left unset. The
+	// built-in Defaults policy without any defaults is used when it's nil.
+	Defaults DefaultsPolicy
+
+	// ImageSigner pre-signs the uploads of product images. The image routes
+	// aren't registered when it's nil.
+	ImageSigner ImageSigner
+
+	// ImageUploadTTL is how long an image upload url stays valid. It
+	// defaults to 15 minutes when zero.
+	ImageUploadTTL time.Duration
+
+	// RequireDeleteReason rejects deletes made without a reason. The reason
+	// is recorded in the audit trail either way.
+	RequireDeleteReason bool
+
+	// StrictDelete returns a 404 instead of a 204 when the product deleted
+	// is already deleted. Clients can ask for either with the strict query
+	// parameter.
+	StrictDelete bool
+
+	// Events streams the changes made to products. The stream route isn't
+	// registered when it's nil.
+	Events *eventstream.Hub
+
+	// StreamHeartbeat is how often an idle stream sends a heartbeat so
+	// proxies keep the connection open. It defaults to 15 seconds when zero.
+	StreamHeartbeat time.Duration
+
+	// ReadOnly rejects the writes of products while the service is in
+	// maintenance. Writes are always accepted when it's nil.
+	ReadOnly mid.ReadOnlyMode
+
+	// MaxBodySize is the largest request body accepted by the routes taking
+	// one, in bytes. Larger bodies are rejected with a 413. It defaults to
+	// 1MB when zero.
+	MaxBodySize int64
+
+	// ImportMaxBodySize is the largest body accepted by the import, in bytes.
+	// It defaults to 64MB when zero.
+	ImportMaxBodySize int64
+
+	// QueryTimeout is how long a request has to complete before its queries
+	// are canceled and it fails with a 504. BulkQueryTimeout is used instead
+	// by the export and the bulk routes. Requests aren't given a timeout
+	// when they're zero.
+	QueryTimeout     time.Duration
+	BulkQueryTimeout time.Duration
+
+	// ImportSignature authenticates the imports with a signature of the
+	// request made with a shared secret instead of a bearer token, for
+	// server to server callers. Imports take a bearer token when it's nil.
+	ImportSignature *mid.SignatureConfig
+
+	// TolerateCountErrors returns the page of a query with a total of -1 when
+	// the total can't be counted, instead of failing the query with a 500.
+	TolerateCountErrors bool
+
+	// Breaker is the circuit breaker in front of the product store. The
+	// product routes fail fast with a 503 while it's open. They never do
+	// when it's nil.
+	Breaker mid.CircuitBreaker
+
+	// ValidateSchema checks the bodies of the requests creating and updating
+	// a product against their JSON Schema before they're decoded, rejecting
+	// unknown fields and values of the wrong type.
+	ValidateSchema bool
+
+	// FieldNaming is the naming of the fields of the documents sent to a
+	// client that doesn't ask for one in the X-Field-Naming header.
+	FieldNaming web.Naming
 }
 
 // Routes adds specific routes for this group.
@@ -25,13 +131,102 @@ func Routes(app *web.App, cfg Config) {
 	authen := mid.Authenticate(cfg.AuthClient)
 	ruleAny := mid.Authorize(cfg.AuthClient, auth.RuleAny)
 	ruleUserOnly := mid.Authorize(cfg.AuthClient, auth.RuleUserOnly)
+	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
 	ruleAuthorizeProduct := mid.AuthorizeProduct(cfg.AuthClient, cfg.ProductBus)
+	ruleAuthorizeProductWithDeleted := mid.AuthorizeProductWithDeleted(cfg.AuthClient, cfg.ProductBus)
+	ruleAuthorizeProductBySKU := mid.AuthorizeProductBySKU(cfg.AuthClient, cfg.ProductBus)
+	beginner := sqldb.NewBeginner(cfg.DB)
+	transaction := mid.BeginCommitRollback(cfg.Log, beginner)
+	compress := web.Compress(compressMinSize)
+	readOnly := mid.ReadOnly(cfg.ReadOnly)
+	naming := web.FieldNaming(cfg.FieldNaming)
+	failFast := mid.FailFast(cfg.Breaker)
+	schemas := loadSchemas(cfg.ValidateSchema)
+
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+
+	importMaxBodySize := cfg.ImportMaxBodySize
+	if importMaxBodySize <= 0 {
+		importMaxBodySize = defaultImportMaxBodySize
+	}
+
+	limitBody := mid.MaxBodySize(maxBodySize)
+
+	// The import and the stream outlive any timeout so they don't get one.
+	timeout := mid.Timeout(cfg.QueryTimeout)
+	bulkTimeout := mid.Timeout(cfg.BulkQueryTimeout)
+
+	createMW := []web.MidFunc{ruleUserOnly}
+	if cfg.CreateLimiter != nil {
+		createMW = append(createMW, mid.RateLimit(cfg.CreateLimiter))
+	}
+	createMW = append(createMW, readOnly)
+	importMW := append([]web.MidFunc{authen, failFast}, createMW...)
+	importMW = append(importMW, mid.MaxBodySize(importMaxBodySize))
+	if cfg.ImportSignature != nil {
+		// The signature covers the body, so the body is limited before it's
+		// read to check the signature.
+		importMW = append([]web.MidFunc{mid.MaxBodySize(importMaxBodySize), mid.VerifySignature(*cfg.ImportSignature), failFast}, createMW...)
+	}
+	importMW = append(importMW, naming)
+
+	// The timeout comes before the authorization so the lookups made to
+	// authorize a request are canceled with its queries.
+	bulkCreateMW := append([]web.MidFunc{authen, failFast, bulkTimeout}, createMW...)
+	bulkCreateMW = append(bulkCreateMW, limitBody, transaction, naming)
+	createMW = append([]web.MidFunc{authen, failFast, timeout}, createMW...)
+	createMW = append(createMW, limitBody, transaction, naming, mid.ValidateSchema(schemas.newProduct))
+
+	api := newApp(cfg.Log, cfg.ProductBus, cfg.CategoryBus, cfg.AuditBus, beginner, cfg.CacheMaxAge, cfg.MaxRowsPerPage, cfg.Defaults, cfg.RequireDeleteReason, cfg.StrictDelete, cfg.TolerateCountErrors)
+
+	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, failFast, timeout, ruleAny, compress, naming)
+	app.HandlerFunc(http.MethodHead, version, "/products", api.count, authen, failFast, timeout, ruleAny, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/search", api.search, authen, failFast, timeout, ruleAny, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/summary", api.summary, authen, failFast, timeout, ruleAny, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/export", api.export, authen, failFast, bulkTimeout, ruleAny, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/lookup", api.queryBySKU, authen, failFast, timeout, ruleAuthorizeProductBySKU, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/batch", api.queryByIDs, authen, failFast, timeout, ruleAny, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/name-availability", api.checkNameAvailable, authen, failFast, timeout, ruleAny, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/recently-viewed", api.recentlyViewed, authen, failFast, timeout, ruleAny, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/popular", api.popular, authen, failFast, timeout, ruleAny, compress, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/popular/refresh", api.refreshPopular, authen, failFast, bulkTimeout, ruleAdmin, readOnly, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/batch", api.queryByIDs, authen, failFast, timeout, ruleAny, limitBody, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, failFast, timeout, ruleAuthorizeProduct, compress, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products", api.create, createMW...)
+	app.HandlerFunc(http.MethodPost, version, "/products/bulk", api.bulkCreate, bulkCreateMW...)
+	app.HandlerFunc(http.MethodPost, version, "/products/import", api.importProducts, importMW...)
+	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, failFast, timeout, ruleAuthorizeProduct, readOnly, limitBody, transaction, naming, mid.ValidateSchema(schemas.updateProduct))
+	app.HandlerFunc(http.MethodPatch, version, "/products/{product_id}", api.patch, authen, failFast, timeout, ruleAuthorizeProduct, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodPatch, version, "/products/bulk", api.bulkUpdate, authen, failFast, bulkTimeout, ruleAdmin, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/upsert", api.upsert, authen, failFast, bulkTimeout, ruleAdmin, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}/price-history", api.priceHistory, authen, failFast, timeout, ruleAuthorizeProduct, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}/similar", api.similar, authen, failFast, timeout, ruleAuthorizeProduct, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}/audit", api.auditTrail, authen, failFast, timeout, ruleAuthorizeProductWithDeleted, ruleAdmin, compress, naming)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}/diff", api.diff, authen, failFast, timeout, ruleAuthorizeProductWithDeleted, ruleAdmin, compress, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/{product_id}/clone", api.clone, authen, failFast, timeout, ruleAuthorizeProduct, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/{product_id}/touch", api.touch, authen, failFast, timeout, ruleAuthorizeProduct, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/{product_id}/stock", api.adjustStock, authen, failFast, timeout, ruleAuthorizeProduct, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodDelete, version, "/products", api.bulkDelete, authen, failFast, bulkTimeout, ruleAdmin, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/prices", api.bulkAdjustPrice, authen, failFast, bulkTimeout, ruleAdmin, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, failFast, timeout, ruleAuthorizeProductWithDeleted, readOnly, limitBody, transaction, naming)
+	app.HandlerFunc(http.MethodPost, version, "/products/{product_id}/restore", api.restore, authen, failFast, timeout, ruleAuthorizeProductWithDeleted, readOnly, limitBody, transaction, naming)
+
+	if cfg.ImageSigner != nil {
+		img := newImages(api, cfg.ImageSigner, cfg.ImageUploadTTL)
+		app.HandlerFunc(http.MethodPost, version, "/products/{product_id}/image/upload-url", img.createUploadURL, authen, failFast, timeout, ruleAuthorizeProduct, readOnly, limitBody, naming)
+		app.HandlerFunc(http.MethodPost, version, "/products/{product_id}/image/confirm", img.confirmImage, authen, failFast, timeout, ruleAuthorizeProduct, readOnly, limitBody, transaction, naming)
+	}
 
-	api := newApp(cfg.ProductBus)
+	if cfg.Events != nil {
+		stm := newStream(cfg.Events, cfg.StreamHeartbeat)
+		app.HandlerFunc(http.MethodGet, version, "/products/stream", stm.subscribe, authen, ruleAdmin)
+	}
 
-	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, ruleAny)
-	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, ruleAuthorizeProduct)
+	// The GraphQL endpoint authorizes every field itself so only the caller
+	// is authenticated here.
+	gql := newGraphQL(cfg.Log, api, cfg.AuthClient, cfg.ReadOnly)
+	app.HandlerFunc(http.MethodPost, "", "/graphql", gql.execute, authen, failFast, timeout, limitBody, compress)
 }
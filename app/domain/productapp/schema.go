@@ -0,0 +1,40 @@
+package productapp
+
+import (
+	_ "embed"
+
+	"github.com/ardanlabs/service/foundation/jsonschema"
+)
+
+// The JSON Schemas of the bodies of the requests creating and updating a
+// product. They're the contract of the product routes and are checked
+// before a body is decoded when the deployment asks for it, so fields the Go
+// decoder would ignore or coerce are reported.
+var (
+	//go:embed schema/newproduct.json
+	newProductSchema []byte
+
+	//go:embed schema/updateproduct.json
+	updateProductSchema []byte
+)
+
+// schemas holds the parsed schemas of the product bodies.
+type schemas struct {
+	newProduct    *jsonschema.Schema
+	updateProduct *jsonschema.Schema
+}
+
+// loadSchemas parses the schemas of the product bodies when validate is set.
+// The schemas are left nil, which skips the validation, otherwise. The
+// schemas are part of the build so one that doesn't parse panics when the
+// routes are added at startup.
+func loadSchemas(validate bool) schemas {
+	if !validate {
+		return schemas{}
+	}
+
+	return schemas{
+		newProduct:    jsonschema.MustParse(newProductSchema),
+		updateProduct: jsonschema.MustParse(updateProductSchema),
+	}
+}
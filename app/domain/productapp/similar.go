@@ -0,0 +1,69 @@
+package productapp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// defaultSimilarBand is the price band, in percent of the cost of the
+// product, of the similar products when the client doesn't say.
+const defaultSimilarBand = 20
+
+// similar returns the other products of the category of the product whose
+// cost is within the band, in percent, of its cost, closest in cost first.
+func (a *app) similar(ctx context.Context, r *http.Request) web.Encoder {
+	values := r.URL.Query()
+
+	page, err := a.parsePage(ctx, values.Get("page"), values.Get("rows"))
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	band, err := parseSimilarBand(values.Get("band"))
+	if err != nil {
+		return errs.NewFieldErrors("band", err)
+	}
+
+	prd, err := mid.GetProduct(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	prds, err := a.productBus.QuerySimilar(ctx, prd, band/100, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querysimilar: productID[%s]: %s", prd.ID, err)
+	}
+
+	total, err := a.productBus.CountSimilar(ctx, prd, band/100)
+	if err != nil {
+		return errs.Newf(errs.Internal, "countsimilar: productID[%s]: %s", prd.ID, err)
+	}
+
+	return query.NewResult(redactProducts(ctx, toAppProducts(prds)), total, page)
+}
+
+// parseSimilarBand parses the price band in percent, defaulting it when
+// it's empty.
+func parseSimilarBand(value string) (float64, error) {
+	if value == "" {
+		return defaultSimilarBand, nil
+	}
+
+	band, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if !(band >= 0 && band <= 100) {
+		return 0, fmt.Errorf("band must be between 0 and 100, got %s", value)
+	}
+
+	return band, nil
+}
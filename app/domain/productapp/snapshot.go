@@ -0,0 +1,43 @@
+package productapp
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+)
+
+// NewSnapshot returns the opaque snapshot token for the specified point in
+// time. Paging with the token only returns products created at or before it.
+func NewSnapshot(t time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(t.UTC().UnixMicro(), 10)))
+}
+
+func decodeSnapshot(snapshot string) (time.Time, error) {
+	data, err := base64.RawURLEncoding.DecodeString(snapshot)
+	if err != nil {
+		return time.Time{}, errors.New("invalid snapshot")
+	}
+
+	micro, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("invalid snapshot")
+	}
+
+	return time.UnixMicro(micro).UTC(), nil
+}
+
+// applySnapshot limits the filter to the products that existed at the time
+// of the snapshot. This keeps rows created while a client is paging from
+// shifting the pages. The trade-off is that new products don't show up until
+// the client starts over with a new snapshot. Updates and deletes made after
+// the snapshot are still visible.
+func applySnapshot(filter productbus.QueryFilter, snapshot time.Time) productbus.QueryFilter {
+	if filter.CreatedBefore == nil || snapshot.Before(*filter.CreatedBefore) {
+		filter.CreatedBefore = &snapshot
+	}
+
+	return filter
+}
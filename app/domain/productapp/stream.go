@@ -0,0 +1,122 @@
+package productapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/eventstream"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+// defaultStreamHeartbeat is how often an idle stream sends a heartbeat when
+// the configuration doesn't say.
+const defaultStreamHeartbeat = 15 * time.Second
+
+// streamBuffer is the number of events held for a client that's slow to
+// read them. Events past it are dropped for that client.
+const streamBuffer = 64
+
+type stream struct {
+	hub       *eventstream.Hub
+	heartbeat time.Duration
+}
+
+func newStream(hub *eventstream.Hub, heartbeat time.Duration) *stream {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+
+	return &stream{
+		hub:       hub,
+		heartbeat: heartbeat,
+	}
+}
+
+// subscribe streams the changes made to the products of the tenant of the
+// caller as server-sent events until the client goes away. The stream can
+// be limited to the products of a category with category_id.
+func (s *stream) subscribe(ctx context.Context, r *http.Request) web.Encoder {
+	var categoryID *uuid.UUID
+	if v := r.URL.Query().Get("category_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return errs.NewFieldErrors("category_id", err)
+		}
+		categoryID = &id
+	}
+
+	tenantID := tenant.Get(ctx)
+
+	sub := s.hub.Subscribe(streamBuffer)
+	defer sub.Close()
+
+	events := make(chan web.Event)
+
+	go func() {
+		defer close(events)
+
+		for data := range sub.Events() {
+			event, ok := toStreamEvent(data, tenantID, categoryID)
+			if !ok {
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := web.RespondSSE(ctx, web.GetWriter(ctx), events, s.heartbeat); err != nil {
+		return errs.Newf(errs.Internal, "respondsse: %s", err)
+	}
+
+	return web.NewNoResponse()
+}
+
+// toStreamEvent converts a product event into the event sent to the client.
+// False is returned for events the client didn't subscribe to.
+func toStreamEvent(data delegate.Data, tenantID uuid.UUID, categoryID *uuid.UUID) (web.Event, bool) {
+	if data.Domain != productbus.DomainName {
+		return web.Event{}, false
+	}
+
+	var params productbus.ActionParms
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return web.Event{}, false
+	}
+
+	if params.TenantID != tenantID {
+		return web.Event{}, false
+	}
+
+	if categoryID != nil && (params.CategoryID == nil || *params.CategoryID != *categoryID) {
+		return web.Event{}, false
+	}
+
+	event := ProductEvent{
+		Type:      data.Domain + "." + data.Action,
+		ProductID: params.ProductID.String(),
+		Timestamp: params.Timestamp.Format(time.RFC3339),
+	}
+
+	if params.CategoryID != nil {
+		event.CategoryID = params.CategoryID.String()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return web.Event{}, false
+	}
+
+	return web.Event{Name: event.Type, Data: body}, true
+}
@@ -0,0 +1,104 @@
+package productapp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Set of media types a client can list in the Accept header to select the
+// representation of a product. The default application/json representation
+// is the same as v1.
+const (
+	mediaTypeV1 = "application/vnd.myapp.v1+json"
+	mediaTypeV2 = "application/vnd.myapp.v2+json"
+)
+
+// Negotiate implements the web.Negotiator interface.
+func (app Product) Negotiate(mediaType string) (web.Encoder, bool) {
+	switch mediaType {
+	case "application/json", "application/*", mediaTypeV1:
+		return app, true
+	case mediaTypeV2:
+		return toProductV2(app), true
+	}
+
+	return nil, false
+}
+
+// =============================================================================
+
+// MoneyV2 represents an amount of money in the v2 representation. Cents
+// holds the exact amount so clients don't need to parse the decimal.
+type MoneyV2 struct {
+	Amount string `json:"amount"`
+	Cents  int64  `json:"cents"`
+}
+
+// ProductV2 is the v2 representation of a product. Costs are nested money
+// objects, left out when the cost is redacted, and timestamps are ISO 8601
+// values in UTC.
+type ProductV2 struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"userID"`
+	SKU          string    `json:"sku"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Cost         *MoneyV2  `json:"cost,omitempty"`
+	Quantity     int       `json:"quantity"`
+	CategoryID   string    `json:"categoryID,omitempty"`
+	CategoryName string    `json:"categoryName,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	DateCreated  string    `json:"dateCreated"`
+	DateUpdated  string    `json:"dateUpdated"`
+	DateDeleted  string    `json:"dateDeleted,omitempty"`
+	Warnings     []Warning `json:"warnings,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (app ProductV2) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, mediaTypeV2, err
+}
+
+func toProductV2(app Product) ProductV2 {
+	var cost *MoneyV2
+
+	// The v1 values were produced by toAppProduct so they always parse.
+	if app.Cost != "" {
+		c, _ := money.ParseString(app.Cost)
+		cost = &MoneyV2{
+			Amount: c.String(),
+			Cents:  c.Cents(),
+		}
+	}
+
+	return ProductV2{
+		ID:           app.ID,
+		UserID:       app.UserID,
+		SKU:          app.SKU,
+		Name:         app.Name,
+		Description:  app.Description,
+		Cost:         cost,
+		Quantity:     app.Quantity,
+		CategoryID:   app.CategoryID,
+		CategoryName: app.CategoryName,
+		Tags:         app.Tags,
+		DateCreated:  toISOTime(app.DateCreated),
+		DateUpdated:  toISOTime(app.DateUpdated),
+		DateDeleted:  toISOTime(app.DateDeleted),
+		Warnings:     app.Warnings,
+	}
+}
+
+// toISOTime converts an RFC 3339 timestamp into UTC. Empty values stay empty.
+func toISOTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,32 @@
+package productapp
+
+import (
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/quantity"
+)
+
+// maxUsualQuantity is the largest quantity that isn't reported as suspicious.
+const maxUsualQuantity = 100_000
+
+// Warning describes input that's legal but suspicious. Unlike a validation
+// error a warning never blocks the request.
+type Warning struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// checkWarnings applies the soft validation rules for a product. Values that
+// are nil aren't checked so an update only reports on the fields it changes.
+func checkWarnings(cost *money.Money, qnt *quantity.Quantity) []Warning {
+	var warnings []Warning
+
+	if cost != nil && cost.Cents() == 0 {
+		warnings = append(warnings, Warning{Field: "cost", Message: "cost is zero"})
+	}
+
+	if qnt != nil && qnt.Value() > maxUsualQuantity {
+		warnings = append(warnings, Warning{Field: "quantity", Message: "quantity is unusually large"})
+	}
+
+	return warnings
+}
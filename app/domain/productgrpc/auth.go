@@ -0,0 +1,213 @@
+package productgrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrInvalidID represents a condition where the id is not a uuid.
+var ErrInvalidID = errors.New("ID is not in its proper form")
+
+// authRule describes how a method is authorized. Methods that act on a
+// single product are authorized against the owner of that product. Methods
+// that write are rejected while the service is read-only.
+type authRule struct {
+	rule        string
+	product     bool
+	withDeleted bool
+	write       bool
+}
+
+// methodRules mirrors the authorization applied to the v1 REST routes.
+var methodRules = map[string]authRule{
+	productpb.ProductService_Create_FullMethodName:    {rule: auth.RuleUserOnly, write: true},
+	productpb.ProductService_Query_FullMethodName:     {rule: auth.RuleAny},
+	productpb.ProductService_QueryByID_FullMethodName: {rule: auth.RuleAdminOrSubject, product: true},
+	productpb.ProductService_Update_FullMethodName:    {rule: auth.RuleAdminOrSubject, product: true, write: true},
+	productpb.ProductService_Delete_FullMethodName:    {rule: auth.RuleAdminOrSubject, product: true, withDeleted: true, write: true},
+}
+
+// productRequest is implemented by the requests that identify a product.
+type productRequest interface {
+	GetProductId() string
+}
+
+// authInterceptor authenticates the bearer token found in the authorization
+// metadata and authorizes the call using the rule defined for the method.
+func authInterceptor(client *authclient.Client, productBus *productbus.Business) grpc.UnaryServerInterceptor {
+	i := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		rule, exists := methodRules[info.FullMethod]
+		if !exists {
+			return nil, errs.Newf(errs.Unimplemented, "method %s is not supported", info.FullMethod)
+		}
+
+		ctx, err := authenticate(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		var userID uuid.UUID
+
+		if rule.product {
+			pr, ok := req.(productRequest)
+			if !ok {
+				return nil, errs.Newf(errs.Internal, "method %s doesn't identify a product", info.FullMethod)
+			}
+
+			productID, err := uuid.Parse(pr.GetProductId())
+			if err != nil {
+				return nil, errs.New(errs.Unauthenticated, ErrInvalidID)
+			}
+
+			queryByID := productBus.QueryByID
+			if rule.withDeleted {
+				queryByID = productBus.QueryByIDWithDeleted
+			}
+
+			prd, err := queryByID(ctx, productID)
+			if err != nil {
+				switch {
+				case errors.Is(err, productbus.ErrNotFound):
+					// Products of other tenants are reported as not found
+					// so their existence isn't leaked.
+					return nil, errs.New(errs.NotFound, err)
+				default:
+					return nil, errs.Newf(errs.Internal, "querybyid: productID[%s]: %s", productID, err)
+				}
+			}
+
+			userID = prd.UserID
+			ctx = setProduct(ctx, prd)
+		}
+
+		if err := authorize(ctx, client, userID, rule.rule); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+
+	return i
+}
+
+// readOnlyInterceptor rejects the calls of the methods that write while the
+// mode is read-only, the same way the ReadOnly middleware does. It runs after
+// the authentication so unauthenticated callers learn nothing of the mode.
+func readOnlyInterceptor(mode mid.ReadOnlyMode) grpc.UnaryServerInterceptor {
+	i := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if methodRules[info.FullMethod].write {
+			if err := mid.CheckReadOnly(mode); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+
+	return i
+}
+
+func authenticate(ctx context.Context, client *authclient.Client) (context.Context, error) {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+
+	authCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := client.Authenticate(authCtx, authorization)
+	if err != nil {
+		return ctx, errs.New(errs.Unauthenticated, err)
+	}
+
+	tenantID, err := resp.Claims.Tenant()
+	if err != nil {
+		return ctx, errs.New(errs.Unauthenticated, err)
+	}
+
+	ctx = tenant.Set(ctx, tenantID)
+	ctx = setUserID(ctx, resp.UserID)
+	ctx = setClaims(ctx, resp.Claims)
+
+	return ctx, nil
+}
+
+func authorize(ctx context.Context, client *authclient.Client, userID uuid.UUID, rule string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	auth := authclient.Authorize{
+		UserID: userID,
+		Claims: getClaims(ctx),
+		Rule:   rule,
+	}
+
+	if err := client.Authorize(ctx, auth); err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const (
+	claimKey ctxKey = iota + 1
+	userIDKey
+	productKey
+)
+
+func setClaims(ctx context.Context, claims auth.Claims) context.Context {
+	return context.WithValue(ctx, claimKey, claims)
+}
+
+func getClaims(ctx context.Context) auth.Claims {
+	v, ok := ctx.Value(claimKey).(auth.Claims)
+	if !ok {
+		return auth.Claims{}
+	}
+	return v
+}
+
+func setUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+func getUserID(ctx context.Context) (uuid.UUID, error) {
+	v, ok := ctx.Value(userIDKey).(uuid.UUID)
+	if !ok {
+		return uuid.UUID{}, errors.New("user id not found in context")
+	}
+
+	return v, nil
+}
+
+func setProduct(ctx context.Context, prd productbus.Product) context.Context {
+	return context.WithValue(ctx, productKey, prd)
+}
+
+func getProduct(ctx context.Context) (productbus.Product, error) {
+	v, ok := ctx.Value(productKey).(productbus.Product)
+	if !ok {
+		return productbus.Product{}, errors.New("product not found in context")
+	}
+
+	return v, nil
+}
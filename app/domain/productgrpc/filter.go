@@ -0,0 +1,127 @@
+package productgrpc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/google/uuid"
+)
+
+// maxNameLike is the longest search term accepted for a name_like filter.
+const maxNameLike = 50
+
+func parseFilter(req *productpb.QueryRequest) (productbus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter productbus.QueryFilter
+
+	if req.ProductId != nil {
+		id, err := uuid.Parse(req.GetProductId())
+		switch err {
+		case nil:
+			filter.ID = &id
+		default:
+			fieldErrors.Add("product_id", err)
+		}
+	}
+
+	if req.Name != nil {
+		name, err := productbus.ParseName(req.GetName())
+		switch err {
+		case nil:
+			filter.Name = &name
+		default:
+			fieldErrors.Add("name", err)
+		}
+	}
+
+	if req.NameLike != nil {
+		switch {
+		case utf8.RuneCountInString(req.GetNameLike()) > maxNameLike:
+			fieldErrors.Add("name_like", fmt.Errorf("value can't be longer than %d characters", maxNameLike))
+		default:
+			filter.NameLike = req.NameLike
+		}
+	}
+
+	if req.Cost != nil {
+		cst, err := money.Parse(req.GetCost())
+		switch err {
+		case nil:
+			filter.Cost = &cst
+		default:
+			fieldErrors.Add("cost", err)
+		}
+	}
+
+	if req.Quantity != nil {
+		qua := int(req.GetQuantity())
+		filter.Quantity = &qua
+	}
+
+	if req.PriceMin != nil {
+		switch {
+		case req.GetPriceMin() < 0:
+			fieldErrors.Add("price_min", errors.New("value can't be negative"))
+		default:
+			cst, err := money.Parse(req.GetPriceMin())
+			if err != nil {
+				fieldErrors.Add("price_min", err)
+			}
+			filter.MinCost = &cst
+		}
+	}
+
+	if req.PriceMax != nil {
+		switch {
+		case req.GetPriceMax() < 0:
+			fieldErrors.Add("price_max", errors.New("value can't be negative"))
+		default:
+			cst, err := money.Parse(req.GetPriceMax())
+			if err != nil {
+				fieldErrors.Add("price_max", err)
+			}
+			filter.MaxCost = &cst
+		}
+	}
+
+	dates := []struct {
+		field string
+		value *string
+		dest  **time.Time
+	}{
+		{"created_after", req.CreatedAfter, &filter.CreatedAfter},
+		{"created_before", req.CreatedBefore, &filter.CreatedBefore},
+		{"updated_after", req.UpdatedAfter, &filter.UpdatedAfter},
+		{"updated_before", req.UpdatedBefore, &filter.UpdatedBefore},
+	}
+
+	for _, d := range dates {
+		if d.value == nil {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, *d.value)
+		switch err {
+		case nil:
+			*d.dest = &t
+		default:
+			fieldErrors.Add(d.field, err)
+		}
+	}
+
+	if req.IncludeDeleted != nil {
+		filter.IncludeDeleted = req.IncludeDeleted
+	}
+
+	if fieldErrors != nil {
+		return productbus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,127 @@
+package productgrpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/quantity"
+)
+
+func toPBProduct(prd productbus.Product) *productpb.Product {
+	pb := productpb.Product{
+		Id:          prd.ID.String(),
+		UserId:      prd.UserID.String(),
+		Name:        prd.Name.String(),
+		Cost:        prd.Cost.Value(),
+		Quantity:    int64(prd.Quantity.Value()),
+		DateCreated: prd.DateCreated.Format(time.RFC3339),
+		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
+	}
+
+	if prd.DateDeleted != nil {
+		pb.DateDeleted = prd.DateDeleted.Format(time.RFC3339)
+	}
+
+	return &pb
+}
+
+func toPBProducts(prds []productbus.Product) []*productpb.Product {
+	pb := make([]*productpb.Product, len(prds))
+	for i, prd := range prds {
+		pb[i] = toPBProduct(prd)
+	}
+
+	return pb
+}
+
+func toPBQueryResponse(prds []productbus.Product, total int, pg page.Page) *productpb.QueryResponse {
+	result := query.NewResult(toPBProducts(prds), total, pg)
+
+	return &productpb.QueryResponse{
+		Items:       result.Items,
+		Total:       int64(result.Total),
+		Page:        int64(result.Page),
+		RowsPerPage: int64(result.RowsPerPage),
+		Pages:       int64(result.Pages),
+		HasNext:     result.HasNext,
+		HasPrev:     result.HasPrev,
+	}
+}
+
+// =============================================================================
+
+func toBusNewProduct(ctx context.Context, req *productpb.NewProductRequest) (productbus.NewProduct, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return productbus.NewProduct{}, fmt.Errorf("getuserid: %w", err)
+	}
+
+	var fieldErrors errs.FieldErrors
+
+	name, err := productbus.ParseName(req.GetName())
+	if err != nil {
+		fieldErrors.Add("name", err)
+	}
+
+	cost, err := money.Parse(req.GetCost())
+	if err != nil {
+		fieldErrors.Add("cost", err)
+	}
+
+	quantity, err := quantity.Parse(int(req.GetQuantity()))
+	if err != nil {
+		fieldErrors.Add("quantity", err)
+	}
+
+	if fieldErrors != nil {
+		return productbus.NewProduct{}, fmt.Errorf("parse: %w", fieldErrors)
+	}
+
+	// The protocol doesn't carry a sku so the business generates one from the
+	// configured pattern.
+	bus := productbus.NewProduct{
+		UserID:   userID,
+		Name:     name,
+		Cost:     cost,
+		Quantity: quantity,
+	}
+
+	return bus, nil
+}
+
+func toBusUpdateProduct(req *productpb.UpdateProductRequest) (productbus.UpdateProduct, error) {
+	var bus productbus.UpdateProduct
+
+	if req.Name != nil {
+		nm, err := productbus.ParseName(req.GetName())
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		bus.Name = &nm
+	}
+
+	if req.Cost != nil {
+		cst, err := money.Parse(req.GetCost())
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		bus.Cost = &cst
+	}
+
+	if req.Quantity != nil {
+		qn, err := quantity.Parse(int(req.GetQuantity()))
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		bus.Quantity = &qn
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,280 @@
+// Package productgrpc maintains the gRPC server for the product domain. It
+// mirrors the v1 REST product endpoints.
+package productgrpc
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"strconv"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config contains all the mandatory systems required by the server.
+type Config struct {
+	Log        *logger.Logger
+	ProductBus *productbus.Business
+	AuthClient *authclient.Client
+
+	// ReadOnly rejects the writes of products while the service is in
+	// maintenance. It's optional.
+	ReadOnly mid.ReadOnlyMode
+}
+
+// NewServer constructs a gRPC server with the product service registered
+// and the authentication interceptor installed.
+func NewServer(cfg Config, options ...grpc.ServerOption) *grpc.Server {
+	options = append(options, grpc.ChainUnaryInterceptor(
+		errorInterceptor(cfg.Log),
+		authInterceptor(cfg.AuthClient, cfg.ProductBus),
+		readOnlyInterceptor(cfg.ReadOnly),
+	))
+
+	srv := grpc.NewServer(options...)
+	productpb.RegisterProductServiceServer(srv, newServer(cfg.ProductBus))
+
+	return srv
+}
+
+type server struct {
+	productpb.UnimplementedProductServiceServer
+	productBus *productbus.Business
+}
+
+func newServer(productBus *productbus.Business) *server {
+	return &server{
+		productBus: productBus,
+	}
+}
+
+func (s *server) Create(ctx context.Context, req *productpb.NewProductRequest) (*productpb.Product, error) {
+	np, err := toBusNewProduct(ctx, req)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := s.productBus.Create(ctx, np)
+	if err != nil {
+		if appErr := toAppError(err); appErr != nil {
+			return nil, appErr
+		}
+		return nil, errs.Newf(errs.Internal, "create: req[%+v]: %s", req, err)
+	}
+
+	return toPBProduct(prd), nil
+}
+
+func (s *server) Update(ctx context.Context, req *productpb.UpdateProductRequest) (*productpb.Product, error) {
+	up, err := toBusUpdateProduct(req)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	prd, err := getProduct(ctx)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "product missing in context: %s", err)
+	}
+
+	updPrd, err := s.productBus.Update(ctx, prd, up)
+	if err != nil {
+		if appErr := toAppError(err); appErr != nil {
+			return nil, appErr
+		}
+		return nil, errs.Newf(errs.Internal, "update: productID[%s] up[%+v]: %s", prd.ID, req, err)
+	}
+
+	return toPBProduct(updPrd), nil
+}
+
+func (s *server) Delete(ctx context.Context, _ *productpb.DeleteProductRequest) (*productpb.DeleteProductResponse, error) {
+	prd, err := getProduct(ctx)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "productID missing in context: %s", err)
+	}
+
+	if err := s.productBus.Delete(ctx, prd); err != nil {
+		if appErr := toAppError(err); appErr != nil {
+			return nil, appErr
+		}
+		return nil, errs.Newf(errs.Internal, "delete: productID[%s]: %s", prd.ID, err)
+	}
+
+	return &productpb.DeleteProductResponse{}, nil
+}
+
+func (s *server) Query(ctx context.Context, req *productpb.QueryRequest) (*productpb.QueryResponse, error) {
+	pg, err := parsePage(req)
+	if err != nil {
+		return nil, errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.IncludeDeleted != nil && *filter.IncludeDeleted && !isAdmin(ctx) {
+		return nil, errs.Newf(errs.PermissionDenied, "include_deleted is restricted to admins")
+	}
+
+	if costFiltered(filter) && !canSeeCost(ctx) {
+		return nil, errs.Newf(errs.PermissionDenied, "filtering by cost is restricted to the roles that can see costs")
+	}
+
+	orderBy, err := order.ParseMany(productbus.OrderByFields, req.GetOrderBy(), productbus.DefaultOrderBy)
+	if err != nil {
+		return nil, errs.NewFieldErrors("order", err)
+	}
+
+	if costOrdered(orderBy) && !canSeeCost(ctx) {
+		return nil, errs.Newf(errs.PermissionDenied, "ordering by cost is restricted to the roles that can see costs")
+	}
+
+	prds, err := s.productBus.Query(ctx, filter, orderBy, pg)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := s.productBus.Count(ctx, filter)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	resp := toPBQueryResponse(prds, total, pg)
+	for _, pb := range resp.Items {
+		redactProduct(ctx, pb)
+	}
+
+	return resp, nil
+}
+
+func (s *server) QueryByID(ctx context.Context, _ *productpb.QueryByIDRequest) (*productpb.Product, error) {
+	prd, err := getProduct(ctx)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "querybyid: %s", err)
+	}
+
+	return redactProduct(ctx, toPBProduct(prd)), nil
+}
+
+// toAppError maps the errors of the business the clients can act on the way
+// the v1 REST endpoints do. It returns nil for the other errors, which are
+// internal.
+func toAppError(err error) *errs.Error {
+	switch {
+	case errors.Is(err, productbus.ErrNotFound):
+		return errs.New(errs.NotFound, productbus.ErrNotFound)
+	case errors.Is(err, productbus.ErrDuplicateSKU):
+		return errs.New(errs.AlreadyExists, productbus.ErrDuplicateSKU)
+	case errors.Is(err, productbus.ErrDuplicateName):
+		return errs.New(errs.AlreadyExists, productbus.ErrDuplicateName)
+	case errors.Is(err, productbus.ErrCategoryNotFound):
+		return errs.NewFieldErrors("category_id", productbus.ErrCategoryNotFound)
+	case errors.Is(err, productbus.ErrVersionConflict):
+		return errs.New(errs.Aborted, productbus.ErrVersionConflict)
+	case errors.Is(err, productbus.ErrUserDisabled):
+		return errs.New(errs.FailedPrecondition, productbus.ErrUserDisabled)
+	}
+
+	return nil
+}
+
+func isAdmin(ctx context.Context) bool {
+	return slices.Contains(getClaims(ctx).Roles, role.Admin.String())
+}
+
+// =============================================================================
+
+// errorInterceptor handles errors coming out of the call chain the same way
+// the Errors middleware does and converts them into gRPC status errors.
+func errorInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	i := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		var appErr *errs.Error
+		if !errors.As(err, &appErr) {
+			appErr = errs.Newf(errs.Internal, "Internal Server Error")
+		}
+
+		log.Error(ctx, "handled error during grpc request",
+			"method", info.FullMethod,
+			"err", err,
+			"source_err_file", path.Base(appErr.FileName),
+			"source_err_func", path.Base(appErr.FuncName))
+
+		if appErr.Code == errs.InternalOnlyLog {
+			appErr = errs.Newf(errs.Internal, "Internal Server Error")
+		}
+
+		return nil, status.Error(toGRPCCode(appErr.Code), appErr.Message)
+	}
+
+	return i
+}
+
+// toGRPCCode maps an app error code to its gRPC equivalent.
+func toGRPCCode(code errs.ErrCode) codes.Code {
+	switch code {
+	case errs.Canceled:
+		return codes.Canceled
+	case errs.InvalidArgument, errs.UnprocessableEntity:
+		return codes.InvalidArgument
+	case errs.DeadlineExceeded:
+		return codes.DeadlineExceeded
+	case errs.NotFound:
+		return codes.NotFound
+	case errs.AlreadyExists:
+		return codes.AlreadyExists
+	case errs.PermissionDenied:
+		return codes.PermissionDenied
+	case errs.ResourceExhausted, errs.TooManyRequests, errs.PayloadTooLarge:
+		return codes.ResourceExhausted
+	case errs.FailedPrecondition, errs.PreconditionFailed:
+		return codes.FailedPrecondition
+	case errs.Aborted:
+		return codes.Aborted
+	case errs.OutOfRange:
+		return codes.OutOfRange
+	case errs.Unimplemented:
+		return codes.Unimplemented
+	case errs.Unavailable:
+		return codes.Unavailable
+	case errs.DataLoss:
+		return codes.DataLoss
+	case errs.Unauthenticated:
+		return codes.Unauthenticated
+	case errs.Unknown:
+		return codes.Unknown
+	default:
+		return codes.Internal
+	}
+}
+
+// parsePage applies the same defaults and limits as the REST query.
+func parsePage(req *productpb.QueryRequest) (page.Page, error) {
+	var number, rows string
+	if req.GetPage() != 0 {
+		number = strconv.FormatInt(req.GetPage(), 10)
+	}
+	if req.GetRows() != 0 {
+		rows = strconv.FormatInt(req.GetRows(), 10)
+	}
+
+	return page.Parse(number, rows)
+}
@@ -0,0 +1,789 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: product.proto
+
+// Package productpb defines the gRPC contract for the product service. It
+// mirrors the v1 REST product endpoints.
+
+package productpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Product represents information about an individual product. Dates are
+// formatted as RFC3339 strings, the same as the REST representation. The
+// cost is left unset for the callers whose roles can't see costs.
+type Product struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Cost          float64                `protobuf:"fixed64,4,opt,name=cost,proto3" json:"cost,omitempty"`
+	Quantity      int64                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	DateCreated   string                 `protobuf:"bytes,6,opt,name=date_created,json=dateCreated,proto3" json:"date_created,omitempty"`
+	DateUpdated   string                 `protobuf:"bytes,7,opt,name=date_updated,json=dateUpdated,proto3" json:"date_updated,omitempty"`
+	DateDeleted   string                 `protobuf:"bytes,8,opt,name=date_deleted,json=dateDeleted,proto3" json:"date_deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_product_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Product) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Product) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Product) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Product) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *Product) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Product) GetDateCreated() string {
+	if x != nil {
+		return x.DateCreated
+	}
+	return ""
+}
+
+func (x *Product) GetDateUpdated() string {
+	if x != nil {
+		return x.DateUpdated
+	}
+	return ""
+}
+
+func (x *Product) GetDateDeleted() string {
+	if x != nil {
+		return x.DateDeleted
+	}
+	return ""
+}
+
+// NewProductRequest defines the data needed to add a new product.
+type NewProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Cost          float64                `protobuf:"fixed64,2,opt,name=cost,proto3" json:"cost,omitempty"`
+	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewProductRequest) Reset() {
+	*x = NewProductRequest{}
+	mi := &file_product_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewProductRequest) ProtoMessage() {}
+
+func (x *NewProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewProductRequest.ProtoReflect.Descriptor instead.
+func (*NewProductRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NewProductRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NewProductRequest) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *NewProductRequest) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+// UpdateProductRequest defines the data needed to update a product. Fields
+// that are not set are left unchanged.
+type UpdateProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Cost          *float64               `protobuf:"fixed64,3,opt,name=cost,proto3,oneof" json:"cost,omitempty"`
+	Quantity      *int64                 `protobuf:"varint,4,opt,name=quantity,proto3,oneof" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductRequest) Reset() {
+	*x = UpdateProductRequest{}
+	mi := &file_product_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductRequest) ProtoMessage() {}
+
+func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UpdateProductRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetCost() float64 {
+	if x != nil && x.Cost != nil {
+		return *x.Cost
+	}
+	return 0
+}
+
+func (x *UpdateProductRequest) GetQuantity() int64 {
+	if x != nil && x.Quantity != nil {
+		return *x.Quantity
+	}
+	return 0
+}
+
+// DeleteProductRequest identifies the product to delete.
+type DeleteProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductRequest) Reset() {
+	*x = DeleteProductRequest{}
+	mi := &file_product_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductRequest) ProtoMessage() {}
+
+func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DeleteProductRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+// DeleteProductResponse is returned when a product is deleted.
+type DeleteProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductResponse) Reset() {
+	*x = DeleteProductResponse{}
+	mi := &file_product_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductResponse) ProtoMessage() {}
+
+func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{4}
+}
+
+// QueryByIDRequest identifies the product to retrieve.
+type QueryByIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryByIDRequest) Reset() {
+	*x = QueryByIDRequest{}
+	mi := &file_product_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryByIDRequest) ProtoMessage() {}
+
+func (x *QueryByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryByIDRequest.ProtoReflect.Descriptor instead.
+func (*QueryByIDRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *QueryByIDRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+// QueryRequest defines the paging, ordering and filtering options for a
+// product query. Dates are RFC3339 strings.
+type QueryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Page           int64                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	Rows           int64                  `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
+	OrderBy        string                 `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	ProductId      *string                `protobuf:"bytes,4,opt,name=product_id,json=productId,proto3,oneof" json:"product_id,omitempty"`
+	Name           *string                `protobuf:"bytes,5,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	NameLike       *string                `protobuf:"bytes,6,opt,name=name_like,json=nameLike,proto3,oneof" json:"name_like,omitempty"`
+	Cost           *float64               `protobuf:"fixed64,7,opt,name=cost,proto3,oneof" json:"cost,omitempty"`
+	Quantity       *int64                 `protobuf:"varint,8,opt,name=quantity,proto3,oneof" json:"quantity,omitempty"`
+	PriceMin       *float64               `protobuf:"fixed64,9,opt,name=price_min,json=priceMin,proto3,oneof" json:"price_min,omitempty"`
+	PriceMax       *float64               `protobuf:"fixed64,10,opt,name=price_max,json=priceMax,proto3,oneof" json:"price_max,omitempty"`
+	CreatedAfter   *string                `protobuf:"bytes,11,opt,name=created_after,json=createdAfter,proto3,oneof" json:"created_after,omitempty"`
+	CreatedBefore  *string                `protobuf:"bytes,12,opt,name=created_before,json=createdBefore,proto3,oneof" json:"created_before,omitempty"`
+	UpdatedAfter   *string                `protobuf:"bytes,13,opt,name=updated_after,json=updatedAfter,proto3,oneof" json:"updated_after,omitempty"`
+	UpdatedBefore  *string                `protobuf:"bytes,14,opt,name=updated_before,json=updatedBefore,proto3,oneof" json:"updated_before,omitempty"`
+	IncludeDeleted *bool                  `protobuf:"varint,15,opt,name=include_deleted,json=includeDeleted,proto3,oneof" json:"include_deleted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *QueryRequest) Reset() {
+	*x = QueryRequest{}
+	mi := &file_product_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRequest) ProtoMessage() {}
+
+func (x *QueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRequest.ProtoReflect.Descriptor instead.
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *QueryRequest) GetPage() int64 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetRows() int64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetProductId() string {
+	if x != nil && x.ProductId != nil {
+		return *x.ProductId
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetNameLike() string {
+	if x != nil && x.NameLike != nil {
+		return *x.NameLike
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetCost() float64 {
+	if x != nil && x.Cost != nil {
+		return *x.Cost
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetQuantity() int64 {
+	if x != nil && x.Quantity != nil {
+		return *x.Quantity
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetPriceMin() float64 {
+	if x != nil && x.PriceMin != nil {
+		return *x.PriceMin
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetPriceMax() float64 {
+	if x != nil && x.PriceMax != nil {
+		return *x.PriceMax
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetCreatedAfter() string {
+	if x != nil && x.CreatedAfter != nil {
+		return *x.CreatedAfter
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetCreatedBefore() string {
+	if x != nil && x.CreatedBefore != nil {
+		return *x.CreatedBefore
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetUpdatedAfter() string {
+	if x != nil && x.UpdatedAfter != nil {
+		return *x.UpdatedAfter
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetUpdatedBefore() string {
+	if x != nil && x.UpdatedBefore != nil {
+		return *x.UpdatedBefore
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetIncludeDeleted() bool {
+	if x != nil && x.IncludeDeleted != nil {
+		return *x.IncludeDeleted
+	}
+	return false
+}
+
+// QueryResponse is the result of a product query, including the same paging
+// information returned by the REST endpoint.
+type QueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Product             `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int64                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	RowsPerPage   int64                  `protobuf:"varint,4,opt,name=rows_per_page,json=rowsPerPage,proto3" json:"rows_per_page,omitempty"`
+	Pages         int64                  `protobuf:"varint,5,opt,name=pages,proto3" json:"pages,omitempty"`
+	HasNext       bool                   `protobuf:"varint,6,opt,name=has_next,json=hasNext,proto3" json:"has_next,omitempty"`
+	HasPrev       bool                   `protobuf:"varint,7,opt,name=has_prev,json=hasPrev,proto3" json:"has_prev,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryResponse) Reset() {
+	*x = QueryResponse{}
+	mi := &file_product_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResponse) ProtoMessage() {}
+
+func (x *QueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResponse.ProtoReflect.Descriptor instead.
+func (*QueryResponse) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *QueryResponse) GetItems() []*Product {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *QueryResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *QueryResponse) GetPage() int64 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *QueryResponse) GetRowsPerPage() int64 {
+	if x != nil {
+		return x.RowsPerPage
+	}
+	return 0
+}
+
+func (x *QueryResponse) GetPages() int64 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+func (x *QueryResponse) GetHasNext() bool {
+	if x != nil {
+		return x.HasNext
+	}
+	return false
+}
+
+func (x *QueryResponse) GetHasPrev() bool {
+	if x != nil {
+		return x.HasPrev
+	}
+	return false
+}
+
+var File_product_proto protoreflect.FileDescriptor
+
+const file_product_proto_rawDesc = "" +
+	"\n" +
+	"\rproduct.proto\x12\tproductpb\"\xdf\x01\n" +
+	"\aProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cost\x18\x04 \x01(\x01R\x04cost\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x03R\bquantity\x12!\n" +
+	"\fdate_created\x18\x06 \x01(\tR\vdateCreated\x12!\n" +
+	"\fdate_updated\x18\a \x01(\tR\vdateUpdated\x12!\n" +
+	"\fdate_deleted\x18\b \x01(\tR\vdateDeleted\"W\n" +
+	"\x11NewProductRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cost\x18\x02 \x01(\x01R\x04cost\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\"\xa7\x01\n" +
+	"\x14UpdateProductRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x17\n" +
+	"\x04cost\x18\x03 \x01(\x01H\x01R\x04cost\x88\x01\x01\x12\x1f\n" +
+	"\bquantity\x18\x04 \x01(\x03H\x02R\bquantity\x88\x01\x01B\a\n" +
+	"\x05_nameB\a\n" +
+	"\x05_costB\v\n" +
+	"\t_quantity\"5\n" +
+	"\x14DeleteProductRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\"\x17\n" +
+	"\x15DeleteProductResponse\"1\n" +
+	"\x10QueryByIDRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\"\xbe\x05\n" +
+	"\fQueryRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x03R\x04page\x12\x12\n" +
+	"\x04rows\x18\x02 \x01(\x03R\x04rows\x12\x19\n" +
+	"\border_by\x18\x03 \x01(\tR\aorderBy\x12\"\n" +
+	"\n" +
+	"product_id\x18\x04 \x01(\tH\x00R\tproductId\x88\x01\x01\x12\x17\n" +
+	"\x04name\x18\x05 \x01(\tH\x01R\x04name\x88\x01\x01\x12 \n" +
+	"\tname_like\x18\x06 \x01(\tH\x02R\bnameLike\x88\x01\x01\x12\x17\n" +
+	"\x04cost\x18\a \x01(\x01H\x03R\x04cost\x88\x01\x01\x12\x1f\n" +
+	"\bquantity\x18\b \x01(\x03H\x04R\bquantity\x88\x01\x01\x12 \n" +
+	"\tprice_min\x18\t \x01(\x01H\x05R\bpriceMin\x88\x01\x01\x12 \n" +
+	"\tprice_max\x18\n" +
+	" \x01(\x01H\x06R\bpriceMax\x88\x01\x01\x12(\n" +
+	"\rcreated_after\x18\v \x01(\tH\aR\fcreatedAfter\x88\x01\x01\x12*\n" +
+	"\x0ecreated_before\x18\f \x01(\tH\bR\rcreatedBefore\x88\x01\x01\x12(\n" +
+	"\rupdated_after\x18\r \x01(\tH\tR\fupdatedAfter\x88\x01\x01\x12*\n" +
+	"\x0eupdated_before\x18\x0e \x01(\tH\n" +
+	"R\rupdatedBefore\x88\x01\x01\x12,\n" +
+	"\x0finclude_deleted\x18\x0f \x01(\bH\vR\x0eincludeDeleted\x88\x01\x01B\r\n" +
+	"\v_product_idB\a\n" +
+	"\x05_nameB\f\n" +
+	"\n" +
+	"_name_likeB\a\n" +
+	"\x05_costB\v\n" +
+	"\t_quantityB\f\n" +
+	"\n" +
+	"_price_minB\f\n" +
+	"\n" +
+	"_price_maxB\x10\n" +
+	"\x0e_created_afterB\x11\n" +
+	"\x0f_created_beforeB\x10\n" +
+	"\x0e_updated_afterB\x11\n" +
+	"\x0f_updated_beforeB\x12\n" +
+	"\x10_include_deleted\"\xd3\x01\n" +
+	"\rQueryResponse\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.productpb.ProductR\x05items\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x03R\x04page\x12\"\n" +
+	"\rrows_per_page\x18\x04 \x01(\x03R\vrowsPerPage\x12\x14\n" +
+	"\x05pages\x18\x05 \x01(\x03R\x05pages\x12\x19\n" +
+	"\bhas_next\x18\x06 \x01(\bR\ahasNext\x12\x19\n" +
+	"\bhas_prev\x18\a \x01(\bR\ahasPrev2\xd2\x02\n" +
+	"\x0eProductService\x12:\n" +
+	"\x06Create\x12\x1c.productpb.NewProductRequest\x1a\x12.productpb.Product\x12=\n" +
+	"\x06Update\x12\x1f.productpb.UpdateProductRequest\x1a\x12.productpb.Product\x12K\n" +
+	"\x06Delete\x12\x1f.productpb.DeleteProductRequest\x1a .productpb.DeleteProductResponse\x12:\n" +
+	"\x05Query\x12\x17.productpb.QueryRequest\x1a\x18.productpb.QueryResponse\x12<\n" +
+	"\tQueryByID\x12\x1b.productpb.QueryByIDRequest\x1a\x12.productpb.ProductB?Z=github.com/ardanlabs/service/app/domain/productgrpc/productpbb\x06proto3"
+
+var (
+	file_product_proto_rawDescOnce sync.Once
+	file_product_proto_rawDescData []byte
+)
+
+func file_product_proto_rawDescGZIP() []byte {
+	file_product_proto_rawDescOnce.Do(func() {
+		file_product_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_product_proto_rawDesc), len(file_product_proto_rawDesc)))
+	})
+	return file_product_proto_rawDescData
+}
+
+var file_product_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_product_proto_goTypes = []any{
+	(*Product)(nil),               // 0: productpb.Product
+	(*NewProductRequest)(nil),     // 1: productpb.NewProductRequest
+	(*UpdateProductRequest)(nil),  // 2: productpb.UpdateProductRequest
+	(*DeleteProductRequest)(nil),  // 3: productpb.DeleteProductRequest
+	(*DeleteProductResponse)(nil), // 4: productpb.DeleteProductResponse
+	(*QueryByIDRequest)(nil),      // 5: productpb.QueryByIDRequest
+	(*QueryRequest)(nil),          // 6: productpb.QueryRequest
+	(*QueryResponse)(nil),         // 7: productpb.QueryResponse
+}
+var file_product_proto_depIdxs = []int32{
+	0, // 0: productpb.QueryResponse.items:type_name -> productpb.Product
+	1, // 1: productpb.ProductService.Create:input_type -> productpb.NewProductRequest
+	2, // 2: productpb.ProductService.Update:input_type -> productpb.UpdateProductRequest
+	3, // 3: productpb.ProductService.Delete:input_type -> productpb.DeleteProductRequest
+	6, // 4: productpb.ProductService.Query:input_type -> productpb.QueryRequest
+	5, // 5: productpb.ProductService.QueryByID:input_type -> productpb.QueryByIDRequest
+	0, // 6: productpb.ProductService.Create:output_type -> productpb.Product
+	0, // 7: productpb.ProductService.Update:output_type -> productpb.Product
+	4, // 8: productpb.ProductService.Delete:output_type -> productpb.DeleteProductResponse
+	7, // 9: productpb.ProductService.Query:output_type -> productpb.QueryResponse
+	0, // 10: productpb.ProductService.QueryByID:output_type -> productpb.Product
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_product_proto_init() }
+func file_product_proto_init() {
+	if File_product_proto != nil {
+		return
+	}
+	file_product_proto_msgTypes[2].OneofWrappers = []any{}
+	file_product_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_product_proto_rawDesc), len(file_product_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_product_proto_goTypes,
+		DependencyIndexes: file_product_proto_depIdxs,
+		MessageInfos:      file_product_proto_msgTypes,
+	}.Build()
+	File_product_proto = out.File
+	file_product_proto_goTypes = nil
+	file_product_proto_depIdxs = nil
+}
@@ -0,0 +1,46 @@
+package productgrpc
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ardanlabs/service/app/domain/productgrpc/productpb"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/types/role"
+)
+
+// costRoles are the roles allowed to see the cost of a product, the same as
+// over REST.
+var costRoles = []string{role.Admin.String(), role.User.String(), role.Sales.String()}
+
+// canSeeCost reports if the claims of the caller grant access to costs.
+func canSeeCost(ctx context.Context) bool {
+	return slices.ContainsFunc(getClaims(ctx).Roles, func(r string) bool {
+		return slices.Contains(costRoles, r)
+	})
+}
+
+// redactProduct strips the fields of the product the caller isn't allowed to
+// see. A stripped field is left at its zero value so it isn't sent.
+func redactProduct(ctx context.Context, pb *productpb.Product) *productpb.Product {
+	if !canSeeCost(ctx) {
+		pb.Cost = 0
+	}
+
+	return pb
+}
+
+// costFiltered reports if the filter constrains the cost, which would let a
+// caller that can't see costs work them out.
+func costFiltered(filter productbus.QueryFilter) bool {
+	return filter.Cost != nil || filter.MinCost != nil || filter.MaxCost != nil
+}
+
+// costOrdered reports if the products are ordered by cost, which would let a
+// caller that can't see costs rank them by it.
+func costOrdered(orderBy []order.By) bool {
+	return slices.ContainsFunc(orderBy, func(by order.By) bool {
+		return by.Field == productbus.OrderByCost
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/ardanlabs/service/app/sdk/decimal"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
@@ -13,17 +14,19 @@ import (
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/quantity"
 	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/business/types/sku"
 )
 
 // Product represents an individual product.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID          string       `json:"id"`
+	UserID      string       `json:"userID"`
+	SKU         string       `json:"sku"`
+	Name        string       `json:"name"`
+	Cost        decimal.Cost `json:"cost"`
+	Quantity    int          `json:"quantity"`
+	DateCreated string       `json:"dateCreated"`
+	DateUpdated string       `json:"dateUpdated"`
 }
 
 // Encode implements the encoder interface.
@@ -36,8 +39,9 @@ func toAppProduct(prd productbus.Product) Product {
 	return Product{
 		ID:          prd.ID.String(),
 		UserID:      prd.UserID.String(),
+		SKU:         prd.SKU.String(),
 		Name:        prd.Name.String(),
-		Cost:        prd.Cost.Value(),
+		Cost:        decimal.Cost(prd.Cost.Value()),
 		Quantity:    prd.Quantity.Value(),
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
@@ -124,6 +128,7 @@ func toBusNewUser(app NewUser) (userbus.NewUser, error) {
 
 // NewProduct is what we require from clients when adding a Product.
 type NewProduct struct {
+	SKU      string  `json:"sku" validate:"required"`
 	Name     string  `json:"name" validate:"required"`
 	Cost     float64 `json:"cost" validate:"required,gte=0"`
 	Quantity int     `json:"quantity" validate:"required,gte=1"`
@@ -139,6 +144,11 @@ func (app NewProduct) Validate() error {
 }
 
 func toBusNewProduct(app NewProduct) (productbus.NewProduct, error) {
+	sku, err := sku.Parse(app.SKU)
+	if err != nil {
+		return productbus.NewProduct{}, fmt.Errorf("parse sku: %w", err)
+	}
+
 	name, err := name.Parse(app.Name)
 	if err != nil {
 		return productbus.NewProduct{}, fmt.Errorf("parse: %w", err)
@@ -155,6 +165,7 @@ func toBusNewProduct(app NewProduct) (productbus.NewProduct, error) {
 	}
 
 	bus := productbus.NewProduct{
+		SKU:      sku,
 		Name:     name,
 		Cost:     cost,
 		Quantity: quantity,
@@ -84,6 +84,9 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 
 	prd, err := a.productBus.Create(ctx, np)
 	if err != nil {
+		if errors.Is(err, productbus.ErrDuplicateSKU) {
+			return errs.New(errs.Aborted, productbus.ErrDuplicateSKU)
+		}
 		return errs.Newf(errs.Internal, "create: prd[%+v]: %s", prd, err)
 	}
 
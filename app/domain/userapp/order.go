@@ -2,9 +2,10 @@ package userapp
 
 import (
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/order"
 )
 
-var orderByFields = map[string]string{
+var orderByFields = order.AllowList{
 	"user_id": userbus.OrderByID,
 	"name":    userbus.OrderByName,
 	"email":   userbus.OrderByEmail,
@@ -4,20 +4,21 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/ardanlabs/service/app/sdk/decimal"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 )
 
 // Product represents information about an individual product with
 // extended information.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
-	UserName    string  `json:"userName"`
+	ID          string       `json:"id"`
+	UserID      string       `json:"userID"`
+	Name        string       `json:"name"`
+	Cost        decimal.Cost `json:"cost"`
+	Quantity    int          `json:"quantity"`
+	DateCreated string       `json:"dateCreated"`
+	DateUpdated string       `json:"dateUpdated"`
+	UserName    string       `json:"userName"`
 }
 
 // Encode implements the encoder interface.
@@ -31,7 +32,7 @@ func toAppProduct(prd vproductbus.Product) Product {
 		ID:          prd.ID.String(),
 		UserID:      prd.UserID.String(),
 		Name:        prd.Name.String(),
-		Cost:        prd.Cost.Value(),
+		Cost:        decimal.Cost(prd.Cost.Value()),
 		Quantity:    prd.Quantity.Value(),
 		DateCreated: prd.DateCreated.Format(time.RFC3339),
 		DateUpdated: prd.DateUpdated.Format(time.RFC3339),
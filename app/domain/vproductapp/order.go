@@ -2,9 +2,10 @@ package vproductapp
 
 import (
 	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/order"
 )
 
-var orderByFields = map[string]string{
+var orderByFields = order.AllowList{
 	"product_id": vproductbus.OrderByProductID,
 	"user_id":    vproductbus.OrderByUserID,
 	"name":       vproductbus.OrderByName,
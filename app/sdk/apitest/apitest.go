@@ -16,6 +16,7 @@ import (
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/types/role"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 // Test contains functions for executing an api test.
@@ -25,6 +26,12 @@ type Test struct {
 	mux  http.Handler
 }
 
+// ServeHTTP passes the request to the routes under test, for the tests that
+// need more control over the request than a table gives.
+func (at *Test) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	at.mux.ServeHTTP(w, r)
+}
+
 // Run performs the actual test logic based on the table data.
 func (at *Test) Run(t *testing.T, table []Table, testName string) {
 	for _, tt := range table {
@@ -42,13 +49,23 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 			}
 
 			r.Header.Set("Authorization", "Bearer "+tt.Token)
+			for k, v := range tt.Headers {
+				r.Header.Set(k, v)
+			}
+
 			at.mux.ServeHTTP(w, r)
 
 			if w.Code != tt.StatusCode {
 				t.Fatalf("%s: Should receive a status code of %d for the response : %d", tt.Name, tt.StatusCode, w.Code)
 			}
 
-			if tt.StatusCode == http.StatusNoContent {
+			for k, v := range tt.ExpHeaders {
+				if got := w.Header().Get(k); got != v {
+					t.Fatalf("%s: Should receive a %s header of %q for the response : %q", tt.Name, k, v, got)
+				}
+			}
+
+			if tt.StatusCode == http.StatusNoContent || tt.StatusCode == http.StatusNotModified || tt.GotResp == nil {
 				return
 			}
 
@@ -76,6 +93,16 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 
 // Token generates an authenticated token for a user.
 func Token(userBus userbus.ExtBusiness, ath *auth.Auth, email string) string {
+	return token(userBus, ath, email, "")
+}
+
+// TenantToken generates an authenticated token for a user acting on behalf
+// of the specified tenant.
+func TenantToken(userBus userbus.ExtBusiness, ath *auth.Auth, email string, tenantID uuid.UUID) string {
+	return token(userBus, ath, email, tenantID.String())
+}
+
+func token(userBus userbus.ExtBusiness, ath *auth.Auth, email string, tenantID string) string {
 	addr, _ := mail.ParseAddress(email)
 
 	dbUsr, err := userBus.QueryByEmail(context.Background(), *addr)
@@ -90,7 +117,8 @@ func Token(userBus userbus.ExtBusiness, ath *auth.Auth, email string) string {
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
-		Roles: role.ParseToString(dbUsr.Roles),
+		Roles:    role.ParseToString(dbUsr.Roles),
+		TenantID: tenantID,
 	}
 
 	token, err := ath.GenerateToken(kid, claims)
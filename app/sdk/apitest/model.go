@@ -2,6 +2,7 @@ package apitest
 
 import (
 	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/categorybus"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
@@ -18,8 +19,9 @@ type User struct {
 
 // SeedData represents users for api tests.
 type SeedData struct {
-	Users  []User
-	Admins []User
+	Users      []User
+	Admins     []User
+	Categories []categorybus.Category
 }
 
 // Table represent fields needed for running an api test.
@@ -27,10 +29,12 @@ type Table struct {
 	Name       string
 	URL        string
 	Token      string
+	Headers    map[string]string
 	Method     string
 	StatusCode int
 	Input      any
 	GotResp    any
 	ExpResp    any
+	ExpHeaders map[string]string
 	CmpFunc    func(got any, exp any) string
 }
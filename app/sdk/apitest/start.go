@@ -3,6 +3,7 @@ package apitest
 import (
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	authbuild "github.com/ardanlabs/service/api/services/auth/build/all"
 	salesbuild "github.com/ardanlabs/service/api/services/sales/build/all"
@@ -10,10 +11,18 @@ import (
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mux"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/foundation/maintenance"
+	"github.com/ardanlabs/service/foundation/objstore"
 )
 
 // New initialized the system to run a test.
 func New(t *testing.T, testName string) *Test {
+	return NewWithRoutes(t, testName, salesbuild.Routes())
+}
+
+// NewWithRoutes initialized the system to run a test against the specified
+// routes, for the tests that need the routes configured differently.
+func NewWithRoutes(t *testing.T, testName string, routes mux.RouteAdder) *Test {
 	db := dbtest.New(t, testName)
 
 	// -------------------------------------------------------------------------
@@ -44,20 +53,36 @@ func New(t *testing.T, testName string) *Test {
 
 	// -------------------------------------------------------------------------
 
+	imageSigner, err := objstore.New(objstore.Config{
+		BucketURL:       "http://objstore.test/products",
+		Region:          "us-east-1",
+		AccessKeyID:     "test",
+		SecretAccessKey: "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// -------------------------------------------------------------------------
+
 	mux := mux.WebAPI(mux.Config{
 		Log: db.Log,
 		DB:  db.DB,
 		BusConfig: mux.BusConfig{
 			AuditBus:    db.BusDomain.Audit,
+			CategoryBus: db.BusDomain.Category,
 			UserBus:     db.BusDomain.User,
 			ProductBus:  db.BusDomain.Product,
 			HomeBus:     db.BusDomain.Home,
 			VProductBus: db.BusDomain.VProduct,
 		},
 		SalesConfig: mux.SalesConfig{
-			AuthClient: authClient,
+			AuthClient:         authClient,
+			ProductCacheMaxAge: time.Minute,
+			ProductImageSigner: imageSigner,
+			Maintenance:        maintenance.New(false),
 		},
-	}, salesbuild.Routes())
+	}, routes)
 
 	return &Test{
 		DB:   db,
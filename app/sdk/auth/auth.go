@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/tenant"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
@@ -22,7 +23,23 @@ var ErrForbidden = errors.New("attempted action is not allowed")
 // Claims represents the authorization claims transmitted via a JWT.
 type Claims struct {
 	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
+	Roles    []string `json:"roles"`
+	TenantID string   `json:"tenant_id,omitempty"`
+}
+
+// Tenant returns the tenant the claims act on behalf of. Claims without a
+// tenant belong to the default tenant.
+func (c Claims) Tenant() (uuid.UUID, error) {
+	if c.TenantID == "" {
+		return tenant.Default, nil
+	}
+
+	tenantID, err := uuid.Parse(c.TenantID)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("parsing tenant: %w", err)
+	}
+
+	return tenantID, nil
 }
 
 // KeyLookup declares a method set of behavior for looking up
@@ -6,7 +6,11 @@ role_user := "USER"
 
 role_admin := "ADMIN"
 
-role_all := {role_admin, role_user}
+role_sales := "SALES"
+
+role_buyer := "BUYER"
+
+role_all := {role_admin, role_user, role_sales, role_buyer}
 
 default rule_any := false
 
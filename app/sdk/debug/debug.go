@@ -7,6 +7,7 @@ import (
 	"net/http/pprof"
 
 	"github.com/arl/statsviz"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Mux registers all the debug routes from the standard library into a new mux
@@ -22,6 +23,7 @@ func Mux() *http.ServeMux {
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	mux.Handle("/debug/vars/", expvar.Handler())
+	mux.Handle("/metrics", promhttp.Handler())
 
 	statsviz.Register(mux)
 
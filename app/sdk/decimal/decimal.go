@@ -0,0 +1,17 @@
+// Package decimal provides support for rendering amounts in JSON documents.
+package decimal
+
+import (
+	"strconv"
+)
+
+// Cost represents a cost in a JSON document. It's marshaled as a plain
+// decimal number with exactly two places, like 0.00 or 12.50, instead of the
+// shortest representation of the float, which drops trailing zeros and uses
+// scientific notation for large and small values.
+type Cost float64
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c Cost) MarshalJSON() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(c), 'f', 2, 64), nil
+}
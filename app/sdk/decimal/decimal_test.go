@@ -0,0 +1,48 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/decimal"
+)
+
+func Test_Cost(t *testing.T) {
+	tests := []struct {
+		cost decimal.Cost
+		exp  string
+	}{
+		{cost: 0, exp: "0.00"},
+		{cost: 12.5, exp: "12.50"},
+		{cost: 10.34, exp: "10.34"},
+		{cost: 1_000_000, exp: "1000000.00"},
+		{cost: 0.000001, exp: "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exp, func(t *testing.T) {
+			data, err := json.Marshal(tt.cost)
+			if err != nil {
+				t.Fatalf("Should be able to marshal: %s", err)
+			}
+
+			if string(data) != tt.exp {
+				t.Fatalf("Should get %s, got %s", tt.exp, data)
+			}
+
+			var got decimal.Cost
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Should be able to unmarshal: %s", err)
+			}
+
+			again, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Should be able to marshal: %s", err)
+			}
+
+			if string(again) != tt.exp {
+				t.Fatalf("Should round trip to %s, got %s", tt.exp, again)
+			}
+		})
+	}
+}
@@ -116,73 +116,96 @@ var (
 	// system has been broken. If you see one of these errors,
 	// something is very broken. The error message is not sent to the client.
 	InternalOnlyLog = ErrCode{value: 19}
+
+	// PreconditionFailed indicates a condition provided by the client, such
+	// as an If-Match header, did not hold for the current state of the
+	// resource.
+	PreconditionFailed = ErrCode{value: 20}
+
+	// UnprocessableEntity indicates the request was well formed but asked
+	// for a change that can't be applied, such as modifying an immutable
+	// field.
+	UnprocessableEntity = ErrCode{value: 21}
+
+	// PayloadTooLarge indicates the body of the request is larger than the
+	// server accepts.
+	PayloadTooLarge = ErrCode{value: 22}
 )
 
 var codeNumbers = map[string]ErrCode{
-	"ok":                  OK,
-	"no_content":          NoContent,
-	"canceled":            Canceled,
-	"unknown":             Unknown,
-	"invalid_argument":    InvalidArgument,
-	"deadline_exceeded":   DeadlineExceeded,
-	"not_found":           NotFound,
-	"already_exists":      AlreadyExists,
-	"permission_denied":   PermissionDenied,
-	"resource_exhausted":  ResourceExhausted,
-	"failed_precondition": FailedPrecondition,
-	"aborted":             Aborted,
-	"out_of_range":        OutOfRange,
-	"unimplemented":       Unimplemented,
-	"internal":            Internal,
-	"unavailable":         Unavailable,
-	"data_loss":           DataLoss,
-	"unauthenticated":     Unauthenticated,
-	"too_many_requests":   TooManyRequests,
-	"internal_only_log":   InternalOnlyLog,
+	"ok":                   OK,
+	"no_content":           NoContent,
+	"canceled":             Canceled,
+	"unknown":              Unknown,
+	"invalid_argument":     InvalidArgument,
+	"deadline_exceeded":    DeadlineExceeded,
+	"not_found":            NotFound,
+	"already_exists":       AlreadyExists,
+	"permission_denied":    PermissionDenied,
+	"resource_exhausted":   ResourceExhausted,
+	"failed_precondition":  FailedPrecondition,
+	"aborted":              Aborted,
+	"out_of_range":         OutOfRange,
+	"unimplemented":        Unimplemented,
+	"internal":             Internal,
+	"unavailable":          Unavailable,
+	"data_loss":            DataLoss,
+	"unauthenticated":      Unauthenticated,
+	"too_many_requests":    TooManyRequests,
+	"internal_only_log":    InternalOnlyLog,
+	"precondition_failed":  PreconditionFailed,
+	"unprocessable_entity": UnprocessableEntity,
+	"payload_too_large":    PayloadTooLarge,
 }
 
 var codeNames = map[ErrCode]string{
-	OK:                 "ok",
-	NoContent:          "ok_no_content",
-	Canceled:           "canceled",
-	Unknown:            "unknown",
-	InvalidArgument:    "invalid_argument",
-	DeadlineExceeded:   "deadline_exceeded",
-	NotFound:           "not_found",
-	AlreadyExists:      "already_exists",
-	PermissionDenied:   "permission_denied",
-	ResourceExhausted:  "resource_exhausted",
-	FailedPrecondition: "failed_precondition",
-	Aborted:            "aborted",
-	OutOfRange:         "out_of_range",
-	Unimplemented:      "unimplemented",
-	Internal:           "internal",
-	Unavailable:        "unavailable",
-	DataLoss:           "data_loss",
-	Unauthenticated:    "unauthenticated",
-	TooManyRequests:    "too_many_requests",
-	InternalOnlyLog:    "internal_only_log",
+	OK:                  "ok",
+	NoContent:           "ok_no_content",
+	Canceled:            "canceled",
+	Unknown:             "unknown",
+	InvalidArgument:     "invalid_argument",
+	DeadlineExceeded:    "deadline_exceeded",
+	NotFound:            "not_found",
+	AlreadyExists:       "already_exists",
+	PermissionDenied:    "permission_denied",
+	ResourceExhausted:   "resource_exhausted",
+	FailedPrecondition:  "failed_precondition",
+	Aborted:             "aborted",
+	OutOfRange:          "out_of_range",
+	Unimplemented:       "unimplemented",
+	Internal:            "internal",
+	Unavailable:         "unavailable",
+	DataLoss:            "data_loss",
+	Unauthenticated:     "unauthenticated",
+	TooManyRequests:     "too_many_requests",
+	InternalOnlyLog:     "internal_only_log",
+	PreconditionFailed:  "precondition_failed",
+	UnprocessableEntity: "unprocessable_entity",
+	PayloadTooLarge:     "payload_too_large",
 }
 
 var httpStatus = map[ErrCode]int{
-	OK:                 http.StatusOK,
-	NoContent:          http.StatusNoContent,
-	Canceled:           http.StatusGatewayTimeout,
-	Unknown:            http.StatusInternalServerError,
-	InvalidArgument:    http.StatusBadRequest,
-	DeadlineExceeded:   http.StatusGatewayTimeout,
-	NotFound:           http.StatusNotFound,
-	AlreadyExists:      http.StatusConflict,
-	PermissionDenied:   http.StatusForbidden,
-	ResourceExhausted:  http.StatusTooManyRequests,
-	FailedPrecondition: http.StatusBadRequest,
-	Aborted:            http.StatusConflict,
-	OutOfRange:         http.StatusBadRequest,
-	Unimplemented:      http.StatusNotImplemented,
-	Internal:           http.StatusInternalServerError,
-	Unavailable:        http.StatusServiceUnavailable,
-	DataLoss:           http.StatusInternalServerError,
-	Unauthenticated:    http.StatusUnauthorized,
-	TooManyRequests:    http.StatusTooManyRequests,
-	InternalOnlyLog:    http.StatusInternalServerError,
+	OK:                  http.StatusOK,
+	NoContent:           http.StatusNoContent,
+	Canceled:            http.StatusGatewayTimeout,
+	Unknown:             http.StatusInternalServerError,
+	InvalidArgument:     http.StatusBadRequest,
+	DeadlineExceeded:    http.StatusGatewayTimeout,
+	NotFound:            http.StatusNotFound,
+	AlreadyExists:       http.StatusConflict,
+	PermissionDenied:    http.StatusForbidden,
+	ResourceExhausted:   http.StatusTooManyRequests,
+	FailedPrecondition:  http.StatusBadRequest,
+	Aborted:             http.StatusConflict,
+	OutOfRange:          http.StatusBadRequest,
+	Unimplemented:       http.StatusNotImplemented,
+	Internal:            http.StatusInternalServerError,
+	Unavailable:         http.StatusServiceUnavailable,
+	DataLoss:            http.StatusInternalServerError,
+	Unauthenticated:     http.StatusUnauthorized,
+	TooManyRequests:     http.StatusTooManyRequests,
+	InternalOnlyLog:     http.StatusInternalServerError,
+	PreconditionFailed:  http.StatusPreconditionFailed,
+	UnprocessableEntity: http.StatusUnprocessableEntity,
+	PayloadTooLarge:     http.StatusRequestEntityTooLarge,
 }
@@ -51,19 +51,29 @@ func (ec ErrCode) Equal(ec2 ErrCode) bool {
 
 // Error represents an error in the system.
 type Error struct {
-	Code     ErrCode `json:"code"`
-	Message  string  `json:"message"`
-	FuncName string  `json:"-"`
-	FileName string  `json:"-"`
+	Code     ErrCode     `json:"code"`
+	Message  string      `json:"message"`
+	Fields   FieldErrors `json:"fields,omitempty"`
+	FuncName string      `json:"-"`
+	FileName string      `json:"-"`
+
+	// RequestID identifies the request in the logs so users can quote it
+	// when reporting the error. It's only set on errors sent to clients.
+	RequestID string `json:"requestID,omitempty"`
 }
 
-// New constructs an error based on an app error.
+// New constructs an error based on an app error. If the error carries a set
+// of field errors, they are provided as a structured list as well.
 func New(code ErrCode, err error) *Error {
 	pc, filename, line, _ := runtime.Caller(1)
 
+	var fields FieldErrors
+	errors.As(err, &fields)
+
 	return &Error{
 		Code:     code,
 		Message:  err.Error(),
+		Fields:   fields,
 		FuncName: runtime.FuncForPC(pc).Name(),
 		FileName: fmt.Sprintf("%s:%d", filename, line),
 	}
@@ -120,6 +130,11 @@ func (e *Error) Equal(e2 *Error) bool {
 type FieldError struct {
 	Field string `json:"field"`
 	Err   string `json:"error"`
+
+	// Tag and Param identify the validation rule that failed so the
+	// message can be localized. They're empty for other errors.
+	Tag   string `json:"-"`
+	Param string `json:"-"`
 }
 
 // FieldErrors represents a collection of field errors.
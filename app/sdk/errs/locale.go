@@ -0,0 +1,132 @@
+package errs
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the locale of the validation messages when the client
+// doesn't ask for a locale with a catalog.
+const DefaultLocale = "en"
+
+// catalog holds the validation messages of the supported locales keyed by
+// validation tag. A message references the field with {0} and the parameter
+// of the tag with {1}. English messages come from the validator.
+var catalog = map[string]map[string]string{
+	"es": {
+		"required":         "{0} es un campo obligatorio",
+		"email":            "{0} debe ser una dirección de correo electrónico válida",
+		"eqfield":          "{0} debe ser igual a {1}",
+		"gte":              "{0} debe ser {1} o mayor",
+		"min":              "{0} debe ser como mínimo {1}",
+		"max":              "{0} debe ser como máximo {1}",
+		"numeric":          "{0} debe ser un valor numérico válido",
+		"uuid":             "{0} debe ser un UUID válido",
+		"iso3166_1_alpha2": "{0} debe ser un código de país válido",
+	},
+	"fr": {
+		"required":         "{0} est un champ obligatoire",
+		"email":            "{0} doit être une adresse e-mail valide",
+		"eqfield":          "{0} doit être égal à {1}",
+		"gte":              "{0} doit être supérieur ou égal à {1}",
+		"min":              "{0} doit être au minimum {1}",
+		"max":              "{0} doit être au maximum {1}",
+		"numeric":          "{0} doit être une valeur numérique valide",
+		"uuid":             "{0} doit être un UUID valide",
+		"iso3166_1_alpha2": "{0} doit être un code de pays valide",
+	},
+	"de": {
+		"required":         "{0} ist ein Pflichtfeld",
+		"email":            "{0} muss eine gültige E-Mail-Adresse sein",
+		"eqfield":          "{0} muss gleich {1} sein",
+		"gte":              "{0} muss {1} oder größer sein",
+		"min":              "{0} muss mindestens {1} sein",
+		"max":              "{0} darf höchstens {1} sein",
+		"numeric":          "{0} muss ein gültiger numerischer Wert sein",
+		"uuid":             "{0} muss eine gültige UUID sein",
+		"iso3166_1_alpha2": "{0} muss ein gültiger Ländercode sein",
+	},
+}
+
+// ParseLocale returns the supported locale that best matches the
+// Accept-Language header. Only the primary language of a tag is considered,
+// so "es-MX" selects "es". DefaultLocale is returned when no supported
+// locale is accepted.
+func ParseLocale(acceptLanguage string) string {
+	type languageRange struct {
+		language string
+		q        float64
+	}
+
+	var ranges []languageRange
+	for v := range strings.SplitSeq(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(v, ";")
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if language == "" {
+			continue
+		}
+
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				f = 0
+			}
+			q = f
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, languageRange{language: language, q: q})
+	}
+
+	slices.SortStableFunc(ranges, func(a, b languageRange) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	for _, r := range ranges {
+		if r.language == DefaultLocale {
+			return DefaultLocale
+		}
+
+		if _, exists := catalog[r.language]; exists {
+			return r.language
+		}
+	}
+
+	return DefaultLocale
+}
+
+// Localize returns the error with the validation messages of its fields
+// translated to the locale, and a message made of the translated fields.
+// Messages that didn't come from a validation tag and locales without a
+// catalog stay in English. The error isn't changed.
+func (e *Error) Localize(locale string) *Error {
+	messages, exists := catalog[locale]
+	if !exists || len(e.Fields) == 0 {
+		return e
+	}
+
+	fields := make(FieldErrors, len(e.Fields))
+	for i, fe := range e.Fields {
+		fields[i] = fe
+		if msg, exists := messages[fe.Tag]; exists {
+			fields[i].Err = strings.NewReplacer("{0}", fe.Field, "{1}", fe.Param).Replace(msg)
+		}
+	}
+
+	localized := *e
+	localized.Message = fields.Error()
+	localized.Fields = fields
+
+	return &localized
+}
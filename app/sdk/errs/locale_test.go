@@ -0,0 +1,167 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ParseLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		exp            string
+	}{
+		{name: "empty", acceptLanguage: "", exp: "en"},
+		{name: "supported", acceptLanguage: "fr", exp: "fr"},
+		{name: "region", acceptLanguage: "es-MX", exp: "es"},
+		{name: "case", acceptLanguage: "DE-at", exp: "de"},
+		{name: "q-order", acceptLanguage: "de;q=0.5, fr;q=0.8", exp: "fr"},
+		{name: "q-default", acceptLanguage: "es;q=0.9, de", exp: "de"},
+		{name: "q-tie", acceptLanguage: "fr;q=0.7, es;q=0.7", exp: "fr"},
+		{name: "q-zero", acceptLanguage: "es;q=0, fr;q=0.1", exp: "fr"},
+		{name: "q-invalid", acceptLanguage: "es;q=abc, fr;q=0.2", exp: "fr"},
+		{name: "unsupported-skipped", acceptLanguage: "it, es;q=0.5", exp: "es"},
+		{name: "english-first", acceptLanguage: "en-US, es;q=0.9", exp: "en"},
+		{name: "unsupported", acceptLanguage: "it, pt-BR", exp: "en"},
+		{name: "wildcard", acceptLanguage: "*", exp: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errs.ParseLocale(tt.acceptLanguage); got != tt.exp {
+				t.Fatalf("Should get the expected locale for %q: got %q, exp %q", tt.acceptLanguage, got, tt.exp)
+			}
+		})
+	}
+}
+
+type newProduct struct {
+	Name     string `json:"name" validate:"required"`
+	Quantity int    `json:"quantity" validate:"gte=1"`
+}
+
+func Test_Localize(t *testing.T) {
+	validationErr := func() *errs.Error {
+		err := errs.Check(newProduct{Quantity: 0})
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	tests := []struct {
+		name   string
+		err    *errs.Error
+		locale string
+		exp    errs.FieldErrors
+		expMsg string
+	}{
+		{
+			name:   "spanish",
+			err:    validationErr(),
+			locale: "es",
+			exp: errs.FieldErrors{
+				{Field: "name", Err: "name es un campo obligatorio", Tag: "required"},
+				{Field: "quantity", Err: "quantity debe ser 1 o mayor", Tag: "gte", Param: "1"},
+			},
+			expMsg: `[{"field":"name","error":"name es un campo obligatorio"},{"field":"quantity","error":"quantity debe ser 1 o mayor"}]`,
+		},
+		{
+			name:   "german",
+			err:    validationErr(),
+			locale: "de",
+			exp: errs.FieldErrors{
+				{Field: "name", Err: "name ist ein Pflichtfeld", Tag: "required"},
+				{Field: "quantity", Err: "quantity muss 1 oder größer sein", Tag: "gte", Param: "1"},
+			},
+			expMsg: `[{"field":"name","error":"name ist ein Pflichtfeld"},{"field":"quantity","error":"quantity muss 1 oder größer sein"}]`,
+		},
+		{
+			name:   "english",
+			err:    validationErr(),
+			locale: "en",
+			exp: errs.FieldErrors{
+				{Field: "name", Err: "name is a required field", Tag: "required"},
+				{Field: "quantity", Err: "quantity must be 1 or greater", Tag: "gte", Param: "1"},
+			},
+			expMsg: `validate: [{"field":"name","error":"name is a required field"},{"field":"quantity","error":"quantity must be 1 or greater"}]`,
+		},
+		{
+			name:   "no-catalog",
+			err:    validationErr(),
+			locale: "it",
+			exp: errs.FieldErrors{
+				{Field: "name", Err: "name is a required field", Tag: "required"},
+				{Field: "quantity", Err: "quantity must be 1 or greater", Tag: "gte", Param: "1"},
+			},
+			expMsg: `validate: [{"field":"name","error":"name is a required field"},{"field":"quantity","error":"quantity must be 1 or greater"}]`,
+		},
+		{
+			name:   "not-validation",
+			err:    errs.NewFieldErrors("name", errors.New("invalid name \"$#!\"")),
+			locale: "es",
+			exp: errs.FieldErrors{
+				{Field: "name", Err: "invalid name \"$#!\""},
+			},
+			expMsg: `[{"field":"name","error":"invalid name \"$#!\""}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgMsg := tt.err.Message
+
+			got := tt.err.Localize(tt.locale)
+
+			if diff := cmp.Diff(got.Fields, tt.exp); diff != "" {
+				t.Fatalf("Should get the expected fields:\n%s", diff)
+			}
+
+			if got.Message != tt.expMsg {
+				t.Fatalf("Should get the expected message:\ngot %s\nexp %s", got.Message, tt.expMsg)
+			}
+
+			if tt.err.Message != orgMsg {
+				t.Fatalf("Should not change the error: got %s, exp %s", tt.err.Message, orgMsg)
+			}
+		})
+	}
+}
+
+func Test_ErrorFields(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *errs.Error
+		exp  string
+	}{
+		{
+			name: "fields",
+			err:  errs.NewFieldErrors("name", errors.New("invalid name")),
+			exp:  `{"code":"invalid_argument","message":"[{\"field\":\"name\",\"error\":\"invalid name\"}]","fields":[{"field":"name","error":"invalid name"}]}`,
+		},
+		{
+			name: "localized",
+			err:  errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", errs.Check(newProduct{Name: "Guitar"}))).Localize("fr"),
+			exp:  `{"code":"invalid_argument","message":"[{\"field\":\"quantity\",\"error\":\"quantity doit être supérieur ou égal à 1\"}]","fields":[{"field":"quantity","error":"quantity doit être supérieur ou égal à 1"}]}`,
+		},
+		{
+			name: "no-fields",
+			err:  errs.Newf(errs.NotFound, "product not found"),
+			exp:  `{"code":"not_found","message":"product not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _, err := tt.err.Encode()
+			if err != nil {
+				t.Fatalf("Should be able to encode the error: %s", err)
+			}
+
+			if string(data) != tt.exp {
+				t.Fatalf("Should get the expected body:\ngot %s\nexp %s", data, tt.exp)
+			}
+		})
+	}
+}
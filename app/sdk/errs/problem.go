@@ -0,0 +1,84 @@
+package errs
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemMediaType is the media type of RFC 7807 problem details. Clients
+// opt into problem details by listing it in their Accept header.
+const ProblemMediaType = "application/problem+json"
+
+// problemTypePrefix prefixes the error code to form the problem type. The
+// codes aren't documented at a URL so a URN identifies them instead.
+const problemTypePrefix = "urn:problem-type:"
+
+// Problem represents an error as RFC 7807 problem details. Field violations
+// are listed under the errors extension member.
+type Problem struct {
+	Type   string      `json:"type"`
+	Title  string      `json:"title"`
+	Status int         `json:"status"`
+	Detail string      `json:"detail"`
+	Code   ErrCode     `json:"code"`
+	Errors FieldErrors `json:"errors,omitempty"`
+
+	RequestID string `json:"requestID,omitempty"`
+
+	err *Error
+}
+
+// Problem converts the error to problem details with the same status.
+func (e *Error) Problem() *Problem {
+	status := e.HTTPStatus()
+
+	// The message of a field error is the encoded list of fields, which the
+	// problem already carries in a structured form.
+	detail := e.Message
+	if len(e.Fields) > 0 {
+		detail = "the request has invalid fields"
+	}
+
+	return &Problem{
+		Type:      problemTypePrefix + e.Code.String(),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      e.Code,
+		Errors:    e.Fields,
+		RequestID: e.RequestID,
+		err:       e,
+	}
+}
+
+// Error implements the error interface.
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+// Unwrap returns the error the problem was built from so middleware can
+// still find it with errors.As.
+func (p *Problem) Unwrap() error {
+	if p.err == nil {
+		return nil
+	}
+
+	return p.err
+}
+
+// Encode implements the encoder interface.
+func (p *Problem) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(p)
+	return data, ProblemMediaType, err
+}
+
+// HTTPStatus implements the web package httpStatus interface so the
+// web framework can use the correct http status.
+func (p *Problem) HTTPStatus() int {
+	return p.Status
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p *Problem) Equal(p2 *Problem) bool {
+	return p.Type == p2.Type && p.Status == p2.Status && p.Detail == p2.Detail
+}
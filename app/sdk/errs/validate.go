@@ -1,7 +1,6 @@
 package errs
 
 import (
-	"errors"
 	"reflect"
 	"strings"
 
@@ -41,18 +40,31 @@ func init() {
 
 // Check validates the provided model against it's declared tags.
 func Check(val any) error {
-	if err := validate.Struct(val); err != nil {
+	return toFieldErrors(validate.Struct(val))
+}
+
+// CheckExcept validates the provided model against it's declared tags,
+// skipping the named struct fields.
+func CheckExcept(val any, fields ...string) error {
+	return toFieldErrors(validate.StructExcept(val, fields...))
+}
+
+// toFieldErrors converts the validation errors into field errors.
+func toFieldErrors(err error) error {
+	if err != nil {
 		verrors, ok := err.(validator.ValidationErrors)
 		if !ok {
 			return err
 		}
 
-		var fields FieldErrors
-		for _, verror := range verrors {
-			fields.Add(
-				verror.Field(),
-				errors.New(verror.Translate(translator)),
-			)
+		fields := make(FieldErrors, len(verrors))
+		for i, verror := range verrors {
+			fields[i] = FieldError{
+				Field: verror.Field(),
+				Err:   verror.Translate(translator),
+				Tag:   verror.Tag(),
+				Param: verror.Param(),
+			}
 		}
 
 		return fields
@@ -0,0 +1,58 @@
+// Package loader provides support for expanding the relations of a set of
+// records with one lookup, instead of one lookup per record.
+package loader
+
+import (
+	"context"
+)
+
+// FetchFunc looks up the values of the keys at once. A key without a value is
+// left out of the map.
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys of the values related to a set of records, like
+// the categories of a page of products, and looks them up with a single
+// fetch.
+type Loader[K comparable, V any] struct {
+	fetch FetchFunc[K, V]
+	keys  []K
+	seen  map[K]struct{}
+}
+
+// New constructs a loader looking up values with the fetch function.
+func New[K comparable, V any](fetch FetchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		seen:  make(map[K]struct{}),
+	}
+}
+
+// Add queues the key to be looked up. A key that's already queued is only
+// looked up once.
+func (l *Loader[K, V]) Add(key K) {
+	if _, exists := l.seen[key]; exists {
+		return
+	}
+
+	l.seen[key] = struct{}{}
+	l.keys = append(l.keys, key)
+}
+
+// Load looks up the values of the queued keys, in the order they were
+// queued, and empties the queue. Nothing is fetched when no key is queued.
+func (l *Loader[K, V]) Load(ctx context.Context) (map[K]V, error) {
+	if len(l.keys) == 0 {
+		return map[K]V{}, nil
+	}
+
+	keys := l.keys
+	l.keys = nil
+	clear(l.seen)
+
+	values, err := l.fetch(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,101 @@
+package loader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/loader"
+)
+
+func Test_Load(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  int
+		kinds int
+	}{
+		{name: "one-row", rows: 1, kinds: 1},
+		{name: "small-page", rows: 10, kinds: 3},
+		{name: "full-page", rows: 100, kinds: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetches int
+			var fetched []int
+
+			fetch := func(ctx context.Context, keys []int) (map[int]string, error) {
+				fetches++
+				fetched = keys
+
+				values := make(map[int]string, len(keys))
+				for _, k := range keys {
+					values[k] = fmt.Sprintf("category-%d", k)
+				}
+				return values, nil
+			}
+
+			ld := loader.New(fetch)
+			for i := range tt.rows {
+				ld.Add(i % tt.kinds)
+			}
+
+			values, err := ld.Load(context.Background())
+			if err != nil {
+				t.Fatalf("Should be able to load the values: %s", err)
+			}
+
+			if fetches != 1 {
+				t.Fatalf("Should fetch once, got %d fetches", fetches)
+			}
+
+			exp := make([]int, tt.kinds)
+			for i := range exp {
+				exp[i] = i
+			}
+
+			if !slices.Equal(fetched, exp) {
+				t.Fatalf("Should fetch every key once in order, got %v, exp %v", fetched, exp)
+			}
+
+			for i := range tt.rows {
+				if exp := fmt.Sprintf("category-%d", i%tt.kinds); values[i%tt.kinds] != exp {
+					t.Errorf("Should get back %s, got %s", exp, values[i%tt.kinds])
+				}
+			}
+		})
+	}
+}
+
+func Test_LoadNothing(t *testing.T) {
+	fetch := func(ctx context.Context, keys []int) (map[int]string, error) {
+		t.Fatal("Should not fetch without keys")
+		return nil, nil
+	}
+
+	values, err := loader.New(fetch).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to load the values: %s", err)
+	}
+
+	if len(values) != 0 {
+		t.Fatalf("Should get back no values, got %v", values)
+	}
+}
+
+func Test_LoadError(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+
+	fetch := func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, errFetch
+	}
+
+	ld := loader.New(fetch)
+	ld.Add(1)
+
+	if _, err := ld.Load(context.Background()); !errors.Is(err, errFetch) {
+		t.Fatalf("Should get back %v, got %v", errFetch, err)
+	}
+}
@@ -26,7 +26,15 @@ func Authenticate(client *authclient.Client) web.MidFunc {
 			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 
+			start := time.Now()
 			resp, err := client.Authenticate(ctx, r.Header.Get("authorization"))
+			web.TimeSince(ctx, web.TimingAuth, start)
+
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			ctx, err = setTenant(ctx, resp.Claims)
 			if err != nil {
 				return errs.New(errs.Unauthenticated, err)
 			}
@@ -47,7 +55,10 @@ func Authenticate(client *authclient.Client) web.MidFunc {
 func Bearer(ath *auth.Auth) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			start := time.Now()
 			claims, err := ath.Authenticate(ctx, r.Header.Get("authorization"))
+			web.TimeSince(ctx, web.TimingAuth, start)
+
 			if err != nil {
 				return errs.New(errs.Unauthenticated, err)
 			}
@@ -61,6 +72,11 @@ func Bearer(ath *auth.Auth) web.MidFunc {
 				return errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
 			}
 
+			ctx, err = setTenant(ctx, claims)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
 			ctx = setUserID(ctx, subjectID)
 			ctx = setClaims(ctx, claims)
 
@@ -87,7 +103,10 @@ func Basic(ath *auth.Auth, userBus userbus.ExtBusiness) web.MidFunc {
 				return errs.New(errs.Unauthenticated, err)
 			}
 
+			start := time.Now()
 			usr, err := userBus.Authenticate(ctx, *addr, pass)
+			web.TimeSince(ctx, web.TimingAuth, start)
+
 			if err != nil {
 				return errs.New(errs.Unauthenticated, err)
 			}
@@ -107,6 +126,11 @@ func Basic(ath *auth.Auth, userBus userbus.ExtBusiness) web.MidFunc {
 				return errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
 			}
 
+			ctx, err = setTenant(ctx, claims)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
 			ctx = setUserID(ctx, subjectID)
 			ctx = setClaims(ctx, claims)
 
@@ -12,6 +12,7 @@ import (
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/types/sku"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/google/uuid"
 )
@@ -37,7 +38,7 @@ func Authorize(client *authclient.Client, rule string) web.MidFunc {
 			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 
-			if err := client.Authorize(ctx, auth); err != nil {
+			if err := authorizeRule(ctx, client, auth); err != nil {
 				return errs.New(errs.Unauthenticated, err)
 			}
 
@@ -90,7 +91,7 @@ func AuthorizeUser(client *authclient.Client, userBus userbus.ExtBusiness, rule
 				Rule:   rule,
 			}
 
-			if err := client.Authorize(ctx, auth); err != nil {
+			if err := authorizeRule(ctx, client, auth); err != nil {
 				return errs.New(errs.Unauthenticated, err)
 			}
 
@@ -108,6 +109,60 @@ func AuthorizeUser(client *authclient.Client, userBus userbus.ExtBusiness, rule
 // the rule specified, the userid from the claims may be compared with the
 // specified user id from the product.
 func AuthorizeProduct(client *authclient.Client, productBus *productbus.Business) web.MidFunc {
+	return authorizeProduct(client, productBus.QueryByID)
+}
+
+// AuthorizeProductWithDeleted works like AuthorizeProduct but will also
+// extract a product that has been soft deleted.
+func AuthorizeProductWithDeleted(client *authclient.Client, productBus *productbus.Business) web.MidFunc {
+	return authorizeProduct(client, productBus.QueryByIDWithDeleted)
+}
+
+// AuthorizeProductBySKU works like AuthorizeProduct but extracts the product
+// identified by the sku query parameter.
+func AuthorizeProductBySKU(client *authclient.Client, productBus *productbus.Business) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			sku, err := sku.Parse(r.URL.Query().Get("sku"))
+			if err != nil {
+				return errs.NewFieldErrors("sku", err)
+			}
+
+			prd, err := productBus.QueryBySKU(ctx, sku)
+			if err != nil {
+				switch {
+				case errors.Is(err, productbus.ErrNotFound):
+					return errs.New(errs.NotFound, err)
+				default:
+					return errs.Newf(errs.Internal, "querybysku: sku[%s]: %s", sku, err)
+				}
+			}
+
+			ctx = setProduct(ctx, prd)
+
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			auth := authclient.Authorize{
+				UserID: prd.UserID,
+				Claims: GetClaims(ctx),
+				Rule:   auth.RuleAdminOrSubject,
+			}
+
+			if err := authorizeRule(ctx, client, auth); err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func authorizeProduct(client *authclient.Client, queryByID func(ctx context.Context, productID uuid.UUID) (productbus.Product, error)) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
 			id := web.Param(r, "product_id")
@@ -121,11 +176,13 @@ func AuthorizeProduct(client *authclient.Client, productBus *productbus.Business
 					return errs.New(errs.Unauthenticated, ErrInvalidID)
 				}
 
-				prd, err := productBus.QueryByID(ctx, productID)
+				prd, err := queryByID(ctx, productID)
 				if err != nil {
 					switch {
 					case errors.Is(err, productbus.ErrNotFound):
-						return errs.New(errs.Unauthenticated, err)
+						// Products of other tenants are reported as not found
+						// so their existence isn't leaked.
+						return errs.New(errs.NotFound, err)
 					default:
 						return errs.Newf(errs.Internal, "querybyid: productID[%s]: %s", productID, err)
 					}
@@ -144,7 +201,7 @@ func AuthorizeProduct(client *authclient.Client, productBus *productbus.Business
 				Rule:   auth.RuleAdminOrSubject,
 			}
 
-			if err := client.Authorize(ctx, auth); err != nil {
+			if err := authorizeRule(ctx, client, auth); err != nil {
 				return errs.New(errs.Unauthenticated, err)
 			}
 
@@ -198,7 +255,7 @@ func AuthorizeHome(client *authclient.Client, homeBus *homebus.Business) web.Mid
 				Rule:   auth.RuleAdminOrSubject,
 			}
 
-			if err := client.Authorize(ctx, auth); err != nil {
+			if err := authorizeRule(ctx, client, auth); err != nil {
 				return errs.New(errs.Unauthenticated, err)
 			}
 
@@ -210,3 +267,11 @@ func AuthorizeHome(client *authclient.Client, homeBus *homebus.Business) web.Mid
 
 	return m
 }
+
+// authorizeRule asks the auth service to authorize the request, recording
+// the time spent waiting on it.
+func authorizeRule(ctx context.Context, client *authclient.Client, auth authclient.Authorize) error {
+	defer web.TimeSince(ctx, web.TimingAuth, time.Now())
+
+	return client.Authorize(ctx, auth)
+}
@@ -0,0 +1,67 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// MaxBodySize rejects requests with a body larger than limit bytes. Requests
+// declaring a larger Content-Length are rejected before the body is read and
+// the others fail to read past the limit, so a handler never buffers more
+// than limit bytes while decoding.
+func MaxBodySize(limit int64) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if r.ContentLength > limit {
+				return payloadTooLarge(limit)
+			}
+
+			body := limitedBody{
+				ReadCloser: http.MaxBytesReader(web.GetWriter(ctx), r.Body, limit),
+			}
+			r.Body = &body
+
+			resp := next(ctx, r)
+
+			// The handler turns the failed read into an error of its own,
+			// it's replaced so the client learns why the request failed.
+			// Handlers streaming their response have already written it.
+			if body.exceeded.Load() && isError(resp) != nil {
+				return payloadTooLarge(limit)
+			}
+
+			return resp
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func payloadTooLarge(limit int64) *errs.Error {
+	return errs.Newf(errs.PayloadTooLarge, "request body must be at most %d bytes", limit)
+}
+
+// limitedBody records whether a read failed because of the body limit.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded atomic.Bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded.Store(true)
+	}
+
+	return n, err
+}
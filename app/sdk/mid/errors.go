@@ -40,6 +40,17 @@ func Errors(log *logger.Logger) web.MidFunc {
 				appErr = errs.Newf(errs.Internal, "Internal Server Error")
 			}
 
+			// Validation messages are rendered in the locale the client
+			// prefers when a catalog exists for it.
+			appErr = appErr.Localize(errs.ParseLocale(r.Header.Get("Accept-Language")))
+			appErr.RequestID = web.GetRequestID(ctx)
+
+			// Clients asking for problem details get them, everyone else
+			// keeps getting the original error body.
+			if web.Accepts(r, errs.ProblemMediaType) {
+				return appErr.Problem()
+			}
+
 			// Send the error to the transport package so the error can be
 			// used as the response.
 
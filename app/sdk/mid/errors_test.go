@@ -0,0 +1,74 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type newProduct struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func Test_ErrorsLocalize(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	handler := func(ctx context.Context, r *http.Request) web.Encoder {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", errs.Check(newProduct{})))
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		exp            string
+	}{
+		{
+			name: "default",
+			exp:  `{"code":"invalid_argument","message":"validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]","fields":[{"field":"name","error":"name is a required field"}]}`,
+		},
+		{
+			name:           "region",
+			acceptLanguage: "es-MX,es;q=0.9,en;q=0.8",
+			exp:            `{"code":"invalid_argument","message":"[{\"field\":\"name\",\"error\":\"name es un campo obligatorio\"}]","fields":[{"field":"name","error":"name es un campo obligatorio"}]}`,
+		},
+		{
+			name:           "unsupported",
+			acceptLanguage: "ja",
+			exp:            `{"code":"invalid_argument","message":"validate: [{\"field\":\"name\",\"error\":\"name is a required field\"}]","fields":[{"field":"name","error":"name is a required field"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/products", nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			resp := mid.Errors(log)(handler)(context.Background(), r)
+
+			var appErr *errs.Error
+			if !errors.As(resp.(error), &appErr) {
+				t.Fatalf("Should get an app error: %T", resp)
+			}
+
+			data, _, err := appErr.Encode()
+			if err != nil {
+				t.Fatalf("Should be able to encode the error: %s", err)
+			}
+
+			if string(data) != tt.exp {
+				t.Fatalf("Should get the expected body:\ngot %s\nexp %s", data, tt.exp)
+			}
+		})
+	}
+}
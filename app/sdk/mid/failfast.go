@@ -0,0 +1,51 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/breaker"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// CircuitBreaker reports the state of the circuit breaker in front of the
+// database.
+type CircuitBreaker interface {
+	State() breaker.State
+}
+
+// FailFast rejects the requests of a route with an Unavailable error while the
+// breaker is open, without running the handler. A request failing with an
+// internal error while the breaker isn't closed, because a call it made was
+// rejected, gets the Unavailable error instead. It should run before the
+// middleware querying the database so their failures are turned into an
+// Unavailable error as well. Requests are never rejected when the breaker is
+// nil.
+func FailFast(cb CircuitBreaker) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		if cb == nil {
+			return next
+		}
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if cb.State() == breaker.Open {
+				return errs.New(errs.Unavailable, breaker.ErrOpen)
+			}
+
+			resp := next(ctx, r)
+
+			var appErr *errs.Error
+			if err := isError(resp); err != nil && errors.As(err, &appErr) && appErr.Code == errs.Internal && cb.State() != breaker.Closed {
+				return errs.New(errs.Unavailable, breaker.ErrOpen)
+			}
+
+			return resp
+		}
+
+		return h
+	}
+
+	return m
+}
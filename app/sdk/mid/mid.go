@@ -10,6 +10,7 @@ import (
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/tenant"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/google/uuid"
 )
@@ -49,6 +50,17 @@ func GetClaims(ctx context.Context) auth.Claims {
 	return v
 }
 
+// setTenant stores the tenant of the claims in the context so the business
+// layer scopes the data it touches to that tenant.
+func setTenant(ctx context.Context, claims auth.Claims) (context.Context, error) {
+	tenantID, err := claims.Tenant()
+	if err != nil {
+		return ctx, err
+	}
+
+	return tenant.Set(ctx, tenantID), nil
+}
+
 // GetSubjectID returns the subject id from the claims.
 func GetSubjectID(ctx context.Context) uuid.UUID {
 	v := GetClaims(ctx)
@@ -0,0 +1,47 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// RateLimiter decides if the caller identified by the key can make another
+// request. When it can't, the time until it can is returned.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (bool, time.Duration, error)
+}
+
+// RateLimit throttles requests per authenticated user. It must run after
+// Authenticate since the limit is keyed on the subject of the claims.
+func RateLimit(limiter RateLimiter) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			subject := GetClaims(ctx).Subject
+
+			ok, wait, err := limiter.Allow(ctx, subject)
+			if err != nil {
+				return errs.Newf(errs.Internal, "ratelimit: %s", err)
+			}
+
+			if !ok {
+				retry := max(int(math.Ceil(wait.Seconds())), 1)
+				web.SetHeader(ctx, "Retry-After", strconv.Itoa(retry))
+
+				return errs.New(errs.TooManyRequests, fmt.Errorf("rate limit exceeded, retry in %ds", retry))
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,44 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ReadOnlyMode reports whether the service is in maintenance and must reject
+// writes.
+type ReadOnlyMode interface {
+	ReadOnly() bool
+}
+
+// CheckReadOnly returns an Unavailable error when the mode is read-only. A nil
+// mode never is.
+func CheckReadOnly(mode ReadOnlyMode) error {
+	if mode != nil && mode.ReadOnly() {
+		return errs.Newf(errs.Unavailable, "the service is read-only for maintenance, writes are rejected until it's over")
+	}
+
+	return nil
+}
+
+// ReadOnly rejects the requests of a mutating route while the mode is
+// read-only. It should run before BeginCommitRollback so no transaction is
+// started for a rejected request.
+func ReadOnly(mode ReadOnlyMode) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if err := CheckReadOnly(mode); err != nil {
+				return err.(*errs.Error)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,55 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+// requestIDHeader carries the id correlating the logs of a request across
+// services.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the id a client can provide since it's written
+// to every log of the request.
+const maxRequestIDLength = 128
+
+// RequestID stores the request id of the caller in the context, or a new one
+// when the caller didn't provide a usable id, and echoes it on the response.
+func RequestID() web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			id := r.Header.Get(requestIDHeader)
+			if !validRequestID(id) {
+				id = uuid.NewString()
+			}
+
+			ctx = web.SetRequestID(ctx, id)
+			web.SetHeader(ctx, requestIDHeader, id)
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// validRequestID reports whether the id can be logged and echoed as is. Only
+// printable ASCII is accepted so an id can't forge log lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,60 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/jsonschema"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ValidateSchema rejects the requests whose body doesn't comply with the
+// schema, reporting every violation as a field error, before the handler
+// decodes the body. Bodies that aren't valid JSON are handed to the handler
+// so it reports the error as it always does. It should run after
+// web.FieldNaming so the body is validated with the fields in camelCase.
+// Bodies aren't validated when the schema is nil.
+func ValidateSchema(schema *jsonschema.Schema) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		if schema == nil {
+			return next
+		}
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				return errs.Newf(errs.InvalidArgument, "request: unable to read payload: %s", err)
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(data))
+
+			violations, err := schema.Validate(data)
+			if err != nil {
+				return next(ctx, r)
+			}
+
+			if len(violations) > 0 {
+				var fieldErrors errs.FieldErrors
+				for _, v := range violations {
+					field := v.Path
+					if field == "" {
+						field = "body"
+					}
+					fieldErrors.Add(field, errors.New(v.Message))
+				}
+
+				return fieldErrors.ToError()
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,172 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/webhook"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+// Set of headers carrying the signature of a signed request.
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+)
+
+// defaultSignatureSkew is how far the timestamp of a signed request can be
+// from the clock of the service when the configuration doesn't say.
+const defaultSignatureSkew = 5 * time.Minute
+
+// maxNonceLength bounds the nonces remembered for replay protection.
+const maxNonceLength = 128
+
+// NonceStore remembers the nonces of the signed requests. Use reports false
+// when the nonce was already used within the ttl.
+type NonceStore interface {
+	Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// SignatureConfig configures the verification of signed requests.
+type SignatureConfig struct {
+	// Secret is shared with the callers to sign their requests.
+	Secret []byte
+
+	// Claims are the claims the signed requests act with, like a bearer
+	// token would carry. The subject must be the id of the user the
+	// caller acts as.
+	Claims auth.Claims
+
+	// Skew is how far the timestamp of a request can be from the clock of
+	// the service. It defaults to 5 minutes when zero.
+	Skew time.Duration
+
+	// Nonces remembers the nonces of the verified requests so a request
+	// can't be replayed within the skew window.
+	Nonces NonceStore
+}
+
+// Validate checks the configuration can verify signatures.
+func (cfg SignatureConfig) Validate() error {
+	if len(cfg.Secret) == 0 {
+		return errors.New("a secret is required")
+	}
+
+	if cfg.Nonces == nil {
+		return errors.New("a nonce store is required")
+	}
+
+	if _, err := uuid.Parse(cfg.Claims.Subject); err != nil {
+		return fmt.Errorf("subject: %w", err)
+	}
+
+	if _, err := cfg.Claims.Tenant(); err != nil {
+		return fmt.Errorf("tenant: %w", err)
+	}
+
+	return nil
+}
+
+// Sign returns the signature of a request for the specified secret. The
+// signature covers the method, the path with its query, the timestamp in
+// unix seconds, the nonce and the body of the request.
+func Sign(secret []byte, method string, path string, timestamp string, nonce string, body []byte) string {
+	var buf bytes.Buffer
+	buf.WriteString(strings.ToUpper(method) + "\n")
+	buf.WriteString(path + "\n")
+	buf.WriteString(timestamp + "\n")
+	buf.WriteString(nonce + "\n")
+	buf.Write(body)
+
+	return webhook.Sign(secret, buf.Bytes())
+}
+
+// VerifySignature authenticates server to server callers with a signature
+// of the request instead of a bearer token, and attaches the configured
+// claims to the request like Authenticate does. Requests whose timestamp is
+// outside of the skew window, whose signature doesn't match or whose nonce
+// was already used are rejected with a 401. The body is read whole to check
+// the signature, so a MaxBodySize should run before it.
+func VerifySignature(cfg SignatureConfig) web.MidFunc {
+	skew := cfg.Skew
+	if skew <= 0 {
+		skew = defaultSignatureSkew
+	}
+
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			timestamp := r.Header.Get(SignatureTimestampHeader)
+
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return errs.Newf(errs.Unauthenticated, "invalid signature timestamp %q", timestamp)
+			}
+
+			if d := time.Since(time.Unix(unix, 0)); d > skew || d < -skew {
+				return errs.Newf(errs.Unauthenticated, "signature timestamp is outside of the %s window", skew)
+			}
+
+			nonce := r.Header.Get(SignatureNonceHeader)
+			if nonce == "" || len(nonce) > maxNonceLength {
+				return errs.Newf(errs.Unauthenticated, "signature nonce must have between 1 and %d characters", maxNonceLength)
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					return errs.New(errs.PayloadTooLarge, err)
+				}
+				return errs.Newf(errs.InvalidArgument, "reading body: %s", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			exp := Sign(cfg.Secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+			if !hmac.Equal([]byte(exp), []byte(r.Header.Get(SignatureHeader))) {
+				return errs.Newf(errs.Unauthenticated, "signature mismatch")
+			}
+
+			// The nonce is only recorded once the signature is verified so
+			// unsigned requests can't use up the nonces of the callers.
+			fresh, err := cfg.Nonces.Use(ctx, nonce, 2*skew)
+			if err != nil {
+				return errs.Newf(errs.Internal, "nonce: %s", err)
+			}
+
+			if !fresh {
+				return errs.Newf(errs.Unauthenticated, "signature nonce already used")
+			}
+
+			userID, err := uuid.Parse(cfg.Claims.Subject)
+			if err != nil {
+				return errs.Newf(errs.Internal, "parsing subject: %s", err)
+			}
+
+			ctx, err = setTenant(ctx, cfg.Claims)
+			if err != nil {
+				return errs.Newf(errs.Internal, "tenant: %s", err)
+			}
+
+			ctx = setUserID(ctx, userID)
+			ctx = setClaims(ctx, cfg.Claims)
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
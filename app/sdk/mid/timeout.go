@@ -0,0 +1,50 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// timeoutWriteGrace is the time left to write the response once the
+// timeout of a request is up.
+const timeoutWriteGrace = 5 * time.Second
+
+// Timeout cancels the context of the request once timeout is up, which
+// cancels the queries run with it. A request failing after its time is up
+// gets a DeadlineExceeded error instead of the error of the canceled query.
+// The write deadline of the server is moved to the timeout, plus some time
+// to respond, so a route can be given more time than the server's default.
+// Requests aren't given a timeout when it's zero.
+func Timeout(timeout time.Duration) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		if timeout <= 0 {
+			return next
+		}
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			// Not every writer supports deadlines, those use the server's.
+			rc := http.NewResponseController(web.GetWriter(ctx))
+			_ = rc.SetWriteDeadline(time.Now().Add(timeout + timeoutWriteGrace))
+
+			resp := next(ctx, r)
+
+			if isError(resp) != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return errs.Newf(errs.DeadlineExceeded, "the request didn't complete within %s", timeout)
+			}
+
+			return resp
+		}
+
+		return h
+	}
+
+	return m
+}
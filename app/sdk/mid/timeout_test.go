@@ -0,0 +1,104 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type okResponse struct{}
+
+func (okResponse) Encode() ([]byte, string, error) {
+	return []byte("{}"), "application/json", nil
+}
+
+// blockingHandler works for the duration unless its context is done first,
+// like a query canceled with the context of the request.
+func blockingHandler(work time.Duration) web.HandlerFunc {
+	h := func(ctx context.Context, r *http.Request) web.Encoder {
+		select {
+		case <-ctx.Done():
+			return errs.New(errs.Internal, ctx.Err())
+		case <-time.After(work):
+			return okResponse{}
+		}
+	}
+
+	return h
+}
+
+func Test_Timeout(t *testing.T) {
+	const (
+		queryTimeout = 20 * time.Millisecond
+		bulkTimeout  = time.Second
+	)
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		handler web.HandlerFunc
+		status  int
+	}{
+		{name: "timed-out", timeout: queryTimeout, handler: blockingHandler(time.Hour), status: http.StatusGatewayTimeout},
+		{name: "in-time", timeout: queryTimeout, handler: blockingHandler(0), status: http.StatusOK},
+		{name: "bulk-override", timeout: bulkTimeout, handler: blockingHandler(5 * queryTimeout), status: http.StatusOK},
+		{name: "bulk-timed-out", timeout: 5 * queryTimeout, handler: blockingHandler(time.Hour), status: http.StatusGatewayTimeout},
+		{name: "no-timeout", timeout: 0, handler: blockingHandler(5 * queryTimeout), status: http.StatusOK},
+		{
+			name:    "other-error",
+			timeout: queryTimeout,
+			handler: func(ctx context.Context, r *http.Request) web.Encoder {
+				return errs.Newf(errs.NotFound, "product not found")
+			},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+
+			resp := mid.Timeout(tt.timeout)(tt.handler)(context.Background(), r)
+
+			status := http.StatusOK
+			var appErr *errs.Error
+			if err, ok := resp.(error); ok && errors.As(err, &appErr) {
+				status = appErr.HTTPStatus()
+			}
+
+			if status != tt.status {
+				t.Fatalf("Should get the expected status: got %d, exp %d: %v", status, tt.status, resp)
+			}
+		})
+	}
+}
+
+func Test_TimeoutDeadline(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+
+	handler := func(ctx context.Context, r *http.Request) web.Encoder {
+		deadline, hasDeadline = ctx.Deadline()
+		return okResponse{}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+
+	mid.Timeout(0)(handler)(context.Background(), r)
+	if hasDeadline {
+		t.Fatalf("Should not give the request a deadline without a timeout: %s", deadline)
+	}
+
+	start := time.Now()
+	mid.Timeout(time.Minute)(handler)(context.Background(), r)
+	if !hasDeadline || deadline.Before(start.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Fatalf("Should give the request a deadline a minute away: %s", deadline)
+	}
+}
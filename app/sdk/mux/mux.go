@@ -5,16 +5,22 @@ package mux
 import (
 	"embed"
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/categorybus"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/eventstream"
+	"github.com/ardanlabs/service/foundation/breaker"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/maintenance"
+	"github.com/ardanlabs/service/foundation/objstore"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/trace"
@@ -55,7 +61,80 @@ func WithFileServer(react bool, static embed.FS, dir string, path string) func(o
 
 // SalesConfig contains sales service specific config.
 type SalesConfig struct {
-	AuthClient *authclient.Client
+	AuthClient    *authclient.Client
+	CreateLimiter mid.RateLimiter
+
+	// ProductCacheMaxAge is how long clients and intermediate caches may
+	// reuse a product read before revalidating it.
+	ProductCacheMaxAge time.Duration
+
+	// ProductMaxRowsPerPage is the largest number of products returned in a
+	// single page.
+	ProductMaxRowsPerPage int
+
+	// ProductDefaultQuantity is the quantity of products created without
+	// one. Nil keeps the quantity required.
+	ProductDefaultQuantity *int
+
+	// ProductGenerateSKU gives products created without a SKU a generated
+	// one.
+	ProductGenerateSKU bool
+
+	// ProductImageSigner pre-signs the uploads of product images. Product
+	// images can't be uploaded when it's nil.
+	ProductImageSigner *objstore.Signer
+
+	// ProductImageUploadTTL is how long a product image upload url stays
+	// valid.
+	ProductImageUploadTTL time.Duration
+
+	// ProductRequireDeleteReason rejects product deletes made without a
+	// reason.
+	ProductRequireDeleteReason bool
+
+	// ProductStrictDelete returns a 404 instead of a 204 when deleting a
+	// product that's already deleted.
+	ProductStrictDelete bool
+
+	// ProductEvents streams the changes made to products to the clients of
+	// the product stream. The stream isn't served when it's nil.
+	ProductEvents *eventstream.Hub
+
+	// ProductMaxBodySize and ProductImportMaxBodySize are the largest
+	// request bodies accepted by the product routes and the product import,
+	// in bytes.
+	ProductMaxBodySize       int64
+	ProductImportMaxBodySize int64
+
+	// ProductQueryTimeout is how long a product request has to complete,
+	// ProductBulkQueryTimeout the same for the export and bulk routes.
+	ProductQueryTimeout     time.Duration
+	ProductBulkQueryTimeout time.Duration
+
+	// ProductTolerateCountErrors returns the page of a product query with a
+	// total of -1 when the total can't be counted.
+	ProductTolerateCountErrors bool
+
+	// ProductFieldNaming is the naming of the product fields for clients
+	// that don't ask for one.
+	ProductFieldNaming web.Naming
+
+	// ProductImportSignature authenticates product imports with a request
+	// signature instead of a bearer token when it's set.
+	ProductImportSignature *mid.SignatureConfig
+
+	// Maintenance puts the service in read-only mode at runtime. Writes are
+	// always accepted when it's nil.
+	Maintenance *maintenance.Mode
+
+	// ProductBreaker is the circuit breaker in front of the product store.
+	// Product requests fail fast with a 503 while it's open, and the service
+	// isn't ready. Requests are never failed fast when it's nil.
+	ProductBreaker *breaker.Breaker
+
+	// ProductValidateSchema checks the bodies of the requests creating and
+	// updating a product against their JSON Schema.
+	ProductValidateSchema bool
 }
 
 // AuthConfig contains auth service specific config.
@@ -65,6 +144,7 @@ type AuthConfig struct {
 
 type BusConfig struct {
 	AuditBus    *auditbus.Business
+	CategoryBus *categorybus.Business
 	UserBus     userbus.ExtBusiness
 	ProductBus  *productbus.Business
 	HomeBus     *homebus.Business
@@ -93,6 +173,7 @@ func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) h
 	app := web.NewApp(
 		cfg.Log.Info,
 		cfg.Tracer,
+		mid.RequestID(),
 		mid.Otel(cfg.Tracer),
 		mid.Logger(cfg.Log),
 		mid.Errors(cfg.Log),
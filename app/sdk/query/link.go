@@ -0,0 +1,72 @@
+package query
+
+import (
+	"maps"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/service/business/sdk/page"
+)
+
+// Links returns the value of a Link header (RFC 8288) pointing at the first,
+// previous, next and last pages of a result. Every URL is the path with the
+// request parameters in values, only replacing the paging parameters, so the
+// filter and order round trip. Offset paging is kept when the request used
+// limit or offset. The previous page is left out on the first page and the
+// next page on the last. With UnknownTotal the last page can't be told so
+// it's left out, and the next page is always given.
+func Links(path string, values url.Values, pg page.Page, total int) string {
+	offsetPaging := values.Has("limit") || values.Has("offset")
+	rows := pg.RowsPerPage()
+
+	link := func(offset int) string {
+		v := maps.Clone(values)
+		if offsetPaging {
+			v.Set("limit", strconv.Itoa(rows))
+			v.Set("offset", strconv.Itoa(offset))
+		} else {
+			v.Set("page", strconv.Itoa(offset/rows+1))
+			v.Set("rows", strconv.Itoa(rows))
+		}
+
+		u := url.URL{Path: path, RawQuery: v.Encode()}
+
+		return u.String()
+	}
+
+	// An empty result still has a first page, which is also the last.
+	last := 0
+	if total > 0 {
+		last = (total - 1) / rows * rows
+	}
+
+	links := []string{`<` + link(0) + `>; rel="first"`}
+
+	if pg.Offset() > 0 {
+		links = append(links, `<`+link(max(pg.Offset()-rows, 0))+`>; rel="prev"`)
+	}
+
+	if pg.Offset()+rows < total || total == UnknownTotal {
+		links = append(links, `<`+link(pg.Offset()+rows)+`>; rel="next"`)
+	}
+
+	if total != UnknownTotal {
+		links = append(links, `<`+link(last)+`>; rel="last"`)
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// CursorLinks returns the value of a Link header (RFC 8288) pointing at the
+// window that follows a cursor window. The URL is the path with the request
+// parameters in values, only replacing the cursor with next. A cursor can't
+// be walked back or jumped to the end, so there is only a next link.
+func CursorLinks(path string, values url.Values, next string) string {
+	v := maps.Clone(values)
+	v.Set("cursor", next)
+
+	u := url.URL{Path: path, RawQuery: v.Encode()}
+
+	return `<` + u.String() + `>; rel="next"`
+}
@@ -0,0 +1,86 @@
+package query_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/sdk/page"
+)
+
+func Test_Links(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		page   page.Page
+		total  int
+		exp    string
+	}{
+		{
+			name:   "first-page",
+			values: url.Values{"page": {"1"}, "rows": {"2"}, "name": {"Guitar"}},
+			page:   page.MustParse("1", "2"),
+			total:  5,
+			exp:    `</v1/products?name=Guitar&page=1&rows=2>; rel="first", </v1/products?name=Guitar&page=2&rows=2>; rel="next", </v1/products?name=Guitar&page=3&rows=2>; rel="last"`,
+		},
+		{
+			name:   "middle-page",
+			values: url.Values{"page": {"2"}, "rows": {"2"}},
+			page:   page.MustParse("2", "2"),
+			total:  5,
+			exp:    `</v1/products?page=1&rows=2>; rel="first", </v1/products?page=1&rows=2>; rel="prev", </v1/products?page=3&rows=2>; rel="next", </v1/products?page=3&rows=2>; rel="last"`,
+		},
+		{
+			name:   "last-page",
+			values: url.Values{"page": {"3"}, "rows": {"2"}},
+			page:   page.MustParse("3", "2"),
+			total:  5,
+			exp:    `</v1/products?page=1&rows=2>; rel="first", </v1/products?page=2&rows=2>; rel="prev", </v1/products?page=3&rows=2>; rel="last"`,
+		},
+		{
+			name:   "empty",
+			values: url.Values{},
+			page:   page.MustParse("1", "10"),
+			total:  0,
+			exp:    `</v1/products?page=1&rows=10>; rel="first", </v1/products?page=1&rows=10>; rel="last"`,
+		},
+		{
+			name:   "unknown-total",
+			values: url.Values{"page": {"2"}, "rows": {"2"}},
+			page:   page.MustParse("2", "2"),
+			total:  query.UnknownTotal,
+			exp:    `</v1/products?page=1&rows=2>; rel="first", </v1/products?page=1&rows=2>; rel="prev", </v1/products?page=3&rows=2>; rel="next"`,
+		},
+		{
+			name:   "offset",
+			values: url.Values{"limit": {"2"}, "offset": {"1"}},
+			page: func() page.Page {
+				pg, _ := page.ParseOffsetClamped("2", "1", 10)
+				return pg
+			}(),
+			total: 5,
+			exp:   `</v1/products?limit=2&offset=0>; rel="first", </v1/products?limit=2&offset=0>; rel="prev", </v1/products?limit=2&offset=3>; rel="next", </v1/products?limit=2&offset=4>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := query.Links("/v1/products", tt.values, tt.page, tt.total); got != tt.exp {
+				t.Errorf("Should get\n%s\ngot\n%s", tt.exp, got)
+			}
+		})
+	}
+}
+
+func Test_CursorLinks(t *testing.T) {
+	values := url.Values{"cursor": {"abc"}, "rows": {"2"}, "name": {"Guitar"}}
+
+	exp := `</v1/products?cursor=def&name=Guitar&rows=2>; rel="next"`
+	if got := query.CursorLinks("/v1/products", values, "def"); got != exp {
+		t.Errorf("Should get\n%s\ngot\n%s", exp, got)
+	}
+
+	if values.Get("cursor") != "abc" {
+		t.Errorf("Should leave the request values alone, got cursor %q", values.Get("cursor"))
+	}
+}
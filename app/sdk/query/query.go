@@ -7,21 +7,44 @@ import (
 	"github.com/ardanlabs/service/business/sdk/page"
 )
 
+// UnknownTotal is the total of a result whose total couldn't be counted.
+const UnknownTotal = -1
+
 // Result is the data model used when returning a query result.
 type Result[T any] struct {
-	Items       []T `json:"items"`
-	Total       int `json:"total"`
-	Page        int `json:"page"`
-	RowsPerPage int `json:"rowsPerPage"`
+	Items       []T    `json:"items"`
+	Total       int    `json:"total"`
+	Page        int    `json:"page"`
+	RowsPerPage int    `json:"rowsPerPage"`
+	Pages       int    `json:"pages"`
+	HasNext     bool   `json:"hasNext"`
+	HasPrev     bool   `json:"hasPrev"`
+	NextCursor  string `json:"nextCursor,omitempty"`
+	Snapshot    string `json:"snapshot,omitempty"`
 }
 
-// NewResult constructs a result value to return query results.
+// NewResult constructs a result value to return query results. When the
+// total is UnknownTotal the number of pages is left at zero and a full page
+// is taken to have another page after it.
 func NewResult[T any](items []T, total int, page page.Page) Result[T] {
+	var pages int
+	if total > 0 {
+		pages = (total + page.RowsPerPage() - 1) / page.RowsPerPage()
+	}
+
+	hasNext := page.Offset()+page.RowsPerPage() < total
+	if total == UnknownTotal {
+		hasNext = len(items) == page.RowsPerPage()
+	}
+
 	return Result[T]{
 		Items:       items,
 		Total:       total,
 		Page:        page.Number(),
 		RowsPerPage: page.RowsPerPage(),
+		Pages:       pages,
+		HasNext:     hasNext,
+		HasPrev:     page.Offset() > 0,
 	}
 }
 
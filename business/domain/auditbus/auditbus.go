@@ -9,6 +9,7 @@ import (
 
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
 	"github.com/google/uuid"
@@ -17,6 +18,7 @@ import (
 // Storer interface declares the behavior this package needs to persist and
 // retrieve data.
 type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, audit Audit) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Audit, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
@@ -36,6 +38,22 @@ func NewBusiness(log *logger.Logger, storer Storer) *Business {
 	}
 }
 
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
 // Create adds a new audit record to the system.
 func (b *Business) Create(ctx context.Context, na NewAudit) (Audit, error) {
 	ctx, span := otel.AddSpan(ctx, "business.auditbus.create")
@@ -11,6 +11,7 @@ import (
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
 type QueryFilter struct {
+	ID        *uuid.UUID
 	ObjID     *uuid.UUID
 	ObjDomain *domain.Domain
 	ObjName   *name.Name
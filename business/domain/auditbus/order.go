@@ -12,4 +12,5 @@ const (
 	OrderByObjName   = "c"
 	OrderByActorID   = "d"
 	OrderByAction    = "e"
+	OrderByTimestamp = "f"
 )
@@ -28,6 +28,22 @@ func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
 	}
 }
 
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (auditbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
 // Create inserts a new audit record into the database.
 func (s *Store) Create(ctx context.Context, a auditbus.Audit) error {
 	const q = `
@@ -50,7 +66,7 @@ func (s *Store) Create(ctx context.Context, a auditbus.Audit) error {
 
 func (s *Store) Query(ctx context.Context, filter auditbus.QueryFilter, orderBy order.By, page page.Page) ([]auditbus.Audit, error) {
 	data := map[string]any{
-		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"offset":        page.Offset(),
 		"rows_per_page": page.RowsPerPage(),
 	}
 
@@ -11,6 +11,11 @@ import (
 func applyFilter(filter auditbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
 	var wc []string
 
+	if filter.ID != nil {
+		data["id"] = filter.ID
+		wc = append(wc, "id = :id")
+	}
+
 	if filter.ObjID != nil {
 		data["obj_id"] = filter.ObjID
 		wc = append(wc, "obj_id = :obj_id")
@@ -13,6 +13,7 @@ var orderByFields = map[string]string{
 	auditbus.OrderByObjName:   "obj_name",
 	auditbus.OrderByActorID:   "actor_id",
 	auditbus.OrderByAction:    "action",
+	auditbus.OrderByTimestamp: "timestamp",
 }
 
 func orderByClause(orderBy order.By) (string, error) {
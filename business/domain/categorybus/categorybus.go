@@ -0,0 +1,132 @@
+// Package categorybus provides business access to category domain.
+package categorybus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("category not found")
+	ErrUniqueName = errors.New("category name already exists")
+	ErrInUse      = errors.New("category still has products")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, cat Category) error
+	Delete(ctx context.Context, cat Category) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Category, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, categoryID uuid.UUID) (Category, error)
+}
+
+// Business manages the set of APIs for category access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a category business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new category to the system.
+func (b *Business) Create(ctx context.Context, nc NewCategory) (Category, error) {
+	ctx, span := otel.AddSpan(ctx, "business.categorybus.create")
+	defer span.End()
+
+	now := time.Now()
+
+	cat := Category{
+		ID:          uuid.New(),
+		Name:        nc.Name,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := b.storer.Create(ctx, cat); err != nil {
+		return Category{}, fmt.Errorf("create: %w", err)
+	}
+
+	return cat, nil
+}
+
+// Delete removes the specified category. A category that is still assigned
+// to products can't be deleted and ErrInUse is returned.
+func (b *Business) Delete(ctx context.Context, cat Category) error {
+	ctx, span := otel.AddSpan(ctx, "business.categorybus.delete")
+	defer span.End()
+
+	if err := b.storer.Delete(ctx, cat); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing categories.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Category, error) {
+	ctx, span := otel.AddSpan(ctx, "business.categorybus.query")
+	defer span.End()
+
+	cats, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return cats, nil
+}
+
+// Count returns the total number of categories.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.categorybus.count")
+	defer span.End()
+
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the category by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, categoryID uuid.UUID) (Category, error) {
+	ctx, span := otel.AddSpan(ctx, "business.categorybus.querybyid")
+	defer span.End()
+
+	cat, err := b.storer.QueryByID(ctx, categoryID)
+	if err != nil {
+		return Category{}, fmt.Errorf("query: categoryID[%s]: %w", categoryID, err)
+	}
+
+	return cat, nil
+}
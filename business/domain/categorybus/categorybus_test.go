@@ -0,0 +1,247 @@
+package categorybus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/unitest"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Category(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Category")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unitest.Run(t, query(db.BusDomain, sd), "query")
+	unitest.Run(t, create(db.BusDomain, sd), "create")
+	unitest.Run(t, delete(db.BusDomain, sd), "delete")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unitest.SeedData, error) {
+	ctx := context.Background()
+
+	cats, err := categorybus.TestGenerateSeedCategories(ctx, 3, busDomain.Category)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding categories : %w", err)
+	}
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	np := productbus.NewProduct{
+		UserID:     usrs[0].ID,
+		SKU:        sku.MustParse("GTR-001"),
+		Name:       name.MustParse("Guitar"),
+		Cost:       money.MustParse(10.34),
+		Quantity:   quantity.MustParse(10),
+		CategoryID: &cats[0].ID,
+	}
+
+	prd, err := busDomain.Product.Create(ctx, np)
+	if err != nil {
+		return unitest.SeedData{}, fmt.Errorf("seeding products : %w", err)
+	}
+
+	tu1 := unitest.User{
+		User:     usrs[0],
+		Products: []productbus.Product{prd},
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := unitest.SeedData{
+		Users:      []unitest.User{tu1},
+		Categories: cats,
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	cats := make([]categorybus.Category, len(sd.Categories))
+	copy(cats, sd.Categories)
+
+	sort.Slice(cats, func(i, j int) bool {
+		return cats[i].Name.String() <= cats[j].Name.String()
+	})
+
+	table := []unitest.Table{
+		{
+			Name:    "all",
+			ExpResp: cats,
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Category.Query(ctx, categorybus.QueryFilter{}, categorybus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.([]categorybus.Category)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.([]categorybus.Category)
+
+				for i := range gotResp {
+					expResp[i].DateCreated = gotResp[i].DateCreated
+					expResp[i].DateUpdated = gotResp[i].DateUpdated
+				}
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "byid",
+			ExpResp: sd.Categories[0],
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Category.QueryByID(ctx, sd.Categories[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(categorybus.Category)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(categorybus.Category)
+
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: categorybus.Category{
+				Name: name.MustParse("Instruments"),
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nc := categorybus.NewCategory{
+					Name: name.MustParse("Instruments"),
+				}
+
+				resp, err := busDomain.Category.Create(ctx, nc)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(categorybus.Category)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(categorybus.Category)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name:    "duplicate",
+			ExpResp: categorybus.ErrUniqueName,
+			ExcFunc: func(ctx context.Context) any {
+				nc := categorybus.NewCategory{
+					Name: sd.Categories[0].Name,
+				}
+
+				_, err := busDomain.Category.Create(ctx, nc)
+
+				return err
+			},
+			CmpFunc: func(got any, exp any) string {
+				err, exists := got.(error)
+				if !exists || !errors.Is(err, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name:    "basic",
+			ExpResp: nil,
+			ExcFunc: func(ctx context.Context) any {
+				if err := busDomain.Category.Delete(ctx, sd.Categories[1]); err != nil {
+					return err
+				}
+
+				return nil
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:    "inuse",
+			ExpResp: categorybus.ErrInUse,
+			ExcFunc: func(ctx context.Context) any {
+				return busDomain.Category.Delete(ctx, sd.Categories[0])
+			},
+			CmpFunc: func(got any, exp any) string {
+				err, exists := got.(error)
+				if !exists || !errors.Is(err, exp.(error)) {
+					return fmt.Sprintf("got %v, exp %v", got, exp)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,21 @@
+package categorybus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/uuid"
+)
+
+// Category represents a group products can belong to.
+type Category struct {
+	ID          uuid.UUID
+	Name        name.Name
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewCategory is what we require from clients when adding a Category.
+type NewCategory struct {
+	Name name.Name
+}
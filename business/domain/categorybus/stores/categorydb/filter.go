@@ -0,0 +1,37 @@
+package categorydb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/categorybus"
+)
+
+func applyFilter(filter categorybus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["category_id"] = filter.ID
+		wc = append(wc, "category_id = :category_id")
+	}
+
+	if len(filter.IDs) > 0 {
+		ids := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			ids[i] = id.String()
+		}
+
+		data["category_ids"] = "{" + strings.Join(ids, ",") + "}"
+		wc = append(wc, "category_id = ANY(CAST(:category_ids AS uuid[]))")
+	}
+
+	if filter.Name != nil {
+		data["name"] = "%" + filter.Name.String() + "%"
+		wc = append(wc, "name LIKE :name")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,58 @@
+package categorydb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/uuid"
+)
+
+type category struct {
+	ID          uuid.UUID `db:"category_id"`
+	Name        string    `db:"name"`
+	DateCreated time.Time `db:"date_created"`
+	DateUpdated time.Time `db:"date_updated"`
+}
+
+func toDBCategory(bus categorybus.Category) category {
+	db := category{
+		ID:          bus.ID,
+		Name:        bus.Name.String(),
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+
+	return db
+}
+
+func toBusCategory(db category) (categorybus.Category, error) {
+	name, err := name.Parse(db.Name)
+	if err != nil {
+		return categorybus.Category{}, fmt.Errorf("parse name: %w", err)
+	}
+
+	bus := categorybus.Category{
+		ID:          db.ID,
+		Name:        name,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusCategories(dbs []category) ([]categorybus.Category, error) {
+	bus := make([]categorybus.Category, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusCategory(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,22 @@
+package categorydb
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	categorybus.OrderByID:   "category_id",
+	categorybus.OrderByName: "name",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
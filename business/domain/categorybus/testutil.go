@@ -0,0 +1,44 @@
+package categorybus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/ardanlabs/service/business/types/name"
+)
+
+// TestGenerateNewCategories is a helper method for testing.
+func TestGenerateNewCategories(n int) []NewCategory {
+	newCats := make([]NewCategory, n)
+
+	idx := rand.Intn(10000)
+	for i := range n {
+		idx++
+
+		nc := NewCategory{
+			Name: name.MustParse(fmt.Sprintf("Category%d", idx)),
+		}
+
+		newCats[i] = nc
+	}
+
+	return newCats
+}
+
+// TestGenerateSeedCategories is a helper method for testing.
+func TestGenerateSeedCategories(ctx context.Context, n int, api *Business) ([]Category, error) {
+	newCats := TestGenerateNewCategories(n)
+
+	cats := make([]Category, len(newCats))
+	for i, nc := range newCats {
+		cat, err := api.Create(ctx, nc)
+		if err != nil {
+			return nil, fmt.Errorf("seeding category: idx: %d : %w", i, err)
+		}
+
+		cats[i] = cat
+	}
+
+	return cats, nil
+}
@@ -109,7 +109,7 @@ func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
 // Query retrieves a list of existing homes from the database.
 func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy order.By, page page.Page) ([]homebus.Home, error) {
 	data := map[string]any{
-		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"offset":        page.Offset(),
 		"rows_per_page": page.RowsPerPage(),
 	}
 
@@ -0,0 +1,115 @@
+package productbus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxCachedCounts bounds the number of filters a count is cached for.
+const maxCachedCounts = 1000
+
+// WithCountCache caches the totals returned by CountCached for the ttl, so
+// paging through a large result only counts the rows for the first page.
+// Totals are only cached when the ttl is positive.
+func WithCountCache(ttl time.Duration) func(b *Business) {
+	return func(b *Business) {
+		if ttl > 0 {
+			b.counts = newCountCache(ttl)
+		}
+	}
+}
+
+type countEntry struct {
+	count   int
+	expires time.Time
+}
+
+// countCache holds the totals of recently counted filters. A total can be
+// off by the writes made within the ttl, which is the price of not counting
+// every page.
+type countCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]countEntry
+}
+
+func newCountCache(ttl time.Duration) *countCache {
+	return &countCache{
+		ttl:     ttl,
+		entries: make(map[string]countEntry),
+	}
+}
+
+func (cc *countCache) get(key string, now time.Time) (int, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	entry, exists := cc.entries[key]
+	if !exists || !now.Before(entry.expires) {
+		return 0, false
+	}
+
+	return entry.count, true
+}
+
+func (cc *countCache) set(key string, count int, now time.Time) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if len(cc.entries) >= maxCachedCounts {
+		for k, entry := range cc.entries {
+			if !now.Before(entry.expires) {
+				delete(cc.entries, k)
+			}
+		}
+
+		// All the entries are still valid so start over rather than
+		// tracking which one is the oldest.
+		if len(cc.entries) >= maxCachedCounts {
+			clear(cc.entries)
+		}
+	}
+
+	cc.entries[key] = countEntry{
+		count:   count,
+		expires: now.Add(cc.ttl),
+	}
+}
+
+// =============================================================================
+
+// CountCached returns the total number of products matching the filter like
+// Count, reusing a total counted within the ttl of the count cache. Without
+// a count cache, or inside a transaction, it always counts.
+func (b *Business) CountCached(ctx context.Context, filter QueryFilter) (_ int, err error) {
+	if b.counts == nil {
+		return b.Count(ctx, filter)
+	}
+
+	key := scopeFilter(ctx, filter).String()
+
+	if count, exists := b.counts.get(key, time.Now()); exists {
+		_, span := otel.AddSpan(ctx, "business.productbus.countcached",
+			attribute.Int("product.count", count),
+		)
+		span.End()
+
+		return count, nil
+	}
+
+	return b.Count(ctx, filter)
+}
+
+// cacheCount stores the total counted for the filter, which is expected to be
+// scoped to the tenant already.
+func (b *Business) cacheCount(filter QueryFilter, count int) {
+	if b.counts == nil {
+		return
+	}
+
+	b.counts.set(filter.String(), count, time.Now())
+}
@@ -0,0 +1,93 @@
+package productbus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/google/uuid"
+)
+
+type countStore struct {
+	productbus.Storer
+	counts int
+}
+
+func (s *countStore) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	s.counts++
+	return 10 + s.counts, nil
+}
+
+func Test_CountCached(t *testing.T) {
+	store := countStore{}
+	bus := productbus.NewBusiness(nil, nil, nil, &store, productbus.WithCountCache(time.Hour))
+
+	ctx := tenant.Set(context.Background(), uuid.New())
+
+	quantity := 5
+	filter := productbus.QueryFilter{Quantity: &quantity}
+
+	first, err := bus.CountCached(ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	second, err := bus.CountCached(ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	if first != second || store.counts != 1 {
+		t.Fatalf("Should reuse the first total: got %d and %d after %d counts", first, second, store.counts)
+	}
+
+	other := 6
+	if _, err := bus.CountCached(ctx, productbus.QueryFilter{Quantity: &other}); err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	if store.counts != 2 {
+		t.Fatalf("Should count a different filter: got %d counts", store.counts)
+	}
+
+	if _, err := bus.CountCached(tenant.Set(context.Background(), uuid.New()), filter); err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	if store.counts != 3 {
+		t.Fatalf("Should count the filter of another tenant: got %d counts", store.counts)
+	}
+
+	fresh, err := bus.Count(ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	cached, err := bus.CountCached(ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	if fresh == first || cached != fresh {
+		t.Fatalf("Should refresh the total with a fresh count: got fresh %d cached %d first %d", fresh, cached, first)
+	}
+}
+
+func Test_CountCachedDisabled(t *testing.T) {
+	store := countStore{}
+	bus := productbus.NewBusiness(nil, nil, nil, &store)
+
+	ctx := tenant.Set(context.Background(), uuid.New())
+
+	for range 2 {
+		if _, err := bus.CountCached(ctx, productbus.QueryFilter{}); err != nil {
+			t.Fatalf("Should be able to count: %s", err)
+		}
+	}
+
+	if store.counts != 2 {
+		t.Fatalf("Should count every time without a count cache: got %d counts", store.counts)
+	}
+}
@@ -4,11 +4,65 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/google/uuid"
 )
 
+// DomainName represents the name of this domain.
+const DomainName = "product"
+
+// Set of delegate actions.
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// ActionParms represents the parameters for the product lifecycle actions.
+type ActionParms struct {
+	ProductID  uuid.UUID  `json:"productID"`
+	TenantID   uuid.UUID  `json:"tenantID"`
+	CategoryID *uuid.UUID `json:"categoryID,omitempty"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// String returns a string representation of the action parameters.
+func (act *ActionParms) String() string {
+	return fmt.Sprintf("&EventParams{ProductID:%v, TenantID:%v, CategoryID:%v, Timestamp:%v}", act.ProductID, act.TenantID, act.CategoryID, act.Timestamp)
+}
+
+// Marshal returns the event parameters encoded as JSON.
+func (act *ActionParms) Marshal() ([]byte, error) {
+	return json.Marshal(act)
+}
+
+// ActionData constructs the data for one of the lifecycle actions of the
+// product.
+func ActionData(action string, prd Product) delegate.Data {
+	params := ActionParms{
+		ProductID:  prd.ID,
+		TenantID:   prd.TenantID,
+		CategoryID: prd.CategoryID,
+		Timestamp:  prd.DateUpdated,
+	}
+
+	rawParams, err := params.Marshal()
+	if err != nil {
+		panic(err)
+	}
+
+	return delegate.Data{
+		Domain:    DomainName,
+		Action:    action,
+		RawParams: rawParams,
+	}
+}
+
+// =============================================================================
+
 // registerDelegateFunctions will register action functions with the delegate
 // system. If the business was constructed for query only, there won't be a
 // delegate provided.
@@ -32,3 +86,31 @@ func (b *Business) actionUserDeleted(ctx context.Context, data delegate.Data) er
 
 	return nil
 }
+
+// callDelegate lets other domains know about a product lifecycle action. If
+// the business was constructed for query only, there won't be a delegate.
+// Inside a transaction the action is queued and only sent once the
+// transaction commits, so a change that is rolled back is never announced.
+func (b *Business) callDelegate(ctx context.Context, action string, prd Product) error {
+	if b.delegate == nil {
+		return nil
+	}
+
+	data := ActionData(action, prd)
+
+	if b.afterCommit != nil {
+		ctx := context.WithoutCancel(ctx)
+		b.afterCommit.AfterCommit(func() {
+			if err := b.delegate.Call(ctx, data); err != nil {
+				b.log.Error(ctx, "product: delegate after commit", "action", action, "err", err)
+			}
+		})
+		return nil
+	}
+
+	if err := b.delegate.Call(ctx, data); err != nil {
+		return fmt.Errorf("failed to execute `%s` action: %w", action, err)
+	}
+
+	return nil
+}
@@ -1,15 +1,154 @@
 package productbus
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/business/types/tag"
 	"github.com/google/uuid"
 )
 
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
 type QueryFilter struct {
-	ID       *uuid.UUID
+	// TenantID is always replaced with the tenant of the context by the
+	// business layer so a query can't reach the products of another tenant.
+	TenantID *uuid.UUID
+
+	ID  *uuid.UUID
+	SKU *sku.SKU
+
+	// IDs limits the products to the ones with one of the ids. It applies
+	// along with ID when both are set.
+	IDs []uuid.UUID
+
 	Name     *name.Name
-	Cost     *float64
+	NameLike *string
+	Cost     *money.Money
 	Quantity *int
+	MinCost  *money.Money
+	MaxCost  *money.Money
+
+	// The quantity range is inclusive of its bounds.
+	MinQuantity *int
+	MaxQuantity *int
+
+	// CategoryID limits the products to the ones assigned to the category.
+	CategoryID *uuid.UUID
+
+	// Tags limits the products to the ones with every tag, or with any of
+	// them when TagMatch is TagMatchAny.
+	Tags     []tag.Tag
+	TagMatch TagMatch
+
+	// The date ranges are inclusive of their bounds.
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+
+	// IncludeDeleted returns soft deleted products along with the rest.
+	IncludeDeleted *bool
+
+	// Active limits the products to the ones that aren't deleted and are in
+	// stock, which a partial index holds. It overrides IncludeDeleted, and
+	// false doesn't limit the products.
+	Active *bool
+}
+
+// String lists every field set on the filter with its value, so two filters
+// only have the same string when they match the same products.
+func (filter QueryFilter) String() string {
+	var b strings.Builder
+
+	add := func(name string, value string) {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(value))
+		b.WriteByte(';')
+	}
+
+	addTime := func(name string, t *time.Time) {
+		if t != nil {
+			add(name, t.UTC().Format(time.RFC3339Nano))
+		}
+	}
+
+	if filter.TenantID != nil {
+		add("tenant_id", filter.TenantID.String())
+	}
+	if filter.ID != nil {
+		add("product_id", filter.ID.String())
+	}
+	if len(filter.IDs) > 0 {
+		ids := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			ids[i] = id.String()
+		}
+		add("product_ids", strings.Join(ids, ","))
+	}
+	if filter.SKU != nil {
+		add("sku", filter.SKU.String())
+	}
+	if filter.Name != nil {
+		add("name", filter.Name.String())
+	}
+	if filter.NameLike != nil {
+		add("name_like", *filter.NameLike)
+	}
+	if filter.Cost != nil {
+		add("cost", filter.Cost.String())
+	}
+	if filter.Quantity != nil {
+		add("quantity", strconv.Itoa(*filter.Quantity))
+	}
+	if filter.MinCost != nil {
+		add("min_cost", filter.MinCost.String())
+	}
+	if filter.MaxCost != nil {
+		add("max_cost", filter.MaxCost.String())
+	}
+	if filter.MinQuantity != nil {
+		add("min_quantity", strconv.Itoa(*filter.MinQuantity))
+	}
+	if filter.MaxQuantity != nil {
+		add("max_quantity", strconv.Itoa(*filter.MaxQuantity))
+	}
+	if filter.CategoryID != nil {
+		add("category_id", filter.CategoryID.String())
+	}
+	if len(filter.Tags) > 0 {
+		norm := normalizeTags(filter.Tags)
+		tags := make([]string, len(norm))
+		for i, t := range norm {
+			tags[i] = t.String()
+		}
+		add("tags", strings.Join(tags, ","))
+
+		if filter.TagMatch == TagMatchAny {
+			add("tag_match", string(TagMatchAny))
+		}
+	}
+	addTime("created_after", filter.CreatedAfter)
+	addTime("created_before", filter.CreatedBefore)
+	addTime("updated_after", filter.UpdatedAfter)
+	addTime("updated_before", filter.UpdatedBefore)
+	if filter.IncludeDeleted != nil {
+		add("include_deleted", strconv.FormatBool(*filter.IncludeDeleted))
+	}
+	if filter.Active != nil {
+		add("active", strconv.FormatBool(*filter.Active))
+	}
+
+	return b.String()
+}
+
+// IsEmpty reports whether no field is set on the filter, so it matches every
+// product.
+func (filter QueryFilter) IsEmpty() bool {
+	return filter.String() == ""
 }
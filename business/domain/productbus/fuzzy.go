@@ -0,0 +1,50 @@
+package productbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/ardanlabs/service/foundation/otel"
+)
+
+// DefaultSimilarityThreshold is the smallest trigram similarity, between 0
+// and 1, of a product name matching a fuzzy search unless configured
+// otherwise. It's the default of the pg_trgm extension.
+const DefaultSimilarityThreshold = 0.3
+
+// WithSimilarityThreshold sets the smallest trigram similarity, between 0
+// and 1, of a product name matching a fuzzy search. A lower threshold
+// tolerates more typos at the cost of more unrelated matches. Thresholds
+// outside of the range keep the default.
+func WithSimilarityThreshold(threshold float64) func(b *Business) {
+	return func(b *Business) {
+		if threshold > 0 && threshold <= 1 {
+			b.similarity = threshold
+		}
+	}
+}
+
+// FuzzySearch retrieves the products whose name is similar to the query,
+// most similar first. Unlike Search it matches names with typos in them.
+func (b *Business) FuzzySearch(ctx context.Context, query string, page page.Page) ([]SearchResult, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.fuzzysearch")
+	defer span.End()
+
+	results, err := b.storer.FuzzySearch(ctx, tenant.Get(ctx), query, b.similarity, page)
+	if err != nil {
+		return nil, fmt.Errorf("fuzzysearch: %w", err)
+	}
+
+	return results, nil
+}
+
+// FuzzySearchCount returns the total number of products whose name is
+// similar to the query.
+func (b *Business) FuzzySearchCount(ctx context.Context, query string) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.fuzzysearchcount")
+	defer span.End()
+
+	return b.storer.FuzzySearchCount(ctx, tenant.Get(ctx), query, b.similarity)
+}
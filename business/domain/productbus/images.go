@@ -0,0 +1,99 @@
+package productbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Set of error variables for product images.
+var (
+	ErrImageType      = errors.New("image content type not supported")
+	ErrImageSize      = errors.New("image size not valid")
+	ErrNoPendingImage = errors.New("no pending image upload with the key")
+)
+
+// MaxImageSize is the largest image in bytes that can be uploaded for a
+// product.
+const MaxImageSize = 5 << 20
+
+// imageTypes maps the content types accepted for product images to the
+// extension of the stored object.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// ImageUpload is an image upload started for a product. The object is
+// expected to be stored under the key with the content type and size.
+type ImageUpload struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// StartImageUpload validates the image about to be uploaded for the product
+// and records it as the pending image of the product. A pending upload that
+// wasn't confirmed is replaced.
+func (b *Business) StartImageUpload(ctx context.Context, prd Product, contentType string, size int64) (_ ImageUpload, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.startimageupload",
+		attribute.String("product.id", prd.ID.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := checkTenant(ctx, prd); err != nil {
+		return ImageUpload{}, err
+	}
+
+	ext, exists := imageTypes[contentType]
+	if !exists {
+		return ImageUpload{}, fmt.Errorf("%q: %w", contentType, ErrImageType)
+	}
+
+	if size <= 0 || size > MaxImageSize {
+		return ImageUpload{}, fmt.Errorf("size[%d] max[%d]: %w", size, MaxImageSize, ErrImageSize)
+	}
+
+	upload := ImageUpload{
+		Key:         fmt.Sprintf("products/%s/%s/%s%s", prd.TenantID, prd.ID, uuid.New(), ext),
+		ContentType: contentType,
+		Size:        size,
+	}
+
+	if err := b.storer.SetPendingImage(ctx, prd, upload.Key); err != nil {
+		return ImageUpload{}, fmt.Errorf("setpendingimage: %w", err)
+	}
+
+	return upload, nil
+}
+
+// ConfirmImage makes the uploaded image identified by the key the image of
+// the product, served from the url. The key has to be the one of the pending
+// upload of the product, otherwise ErrNoPendingImage is returned.
+func (b *Business) ConfirmImage(ctx context.Context, prd Product, key string, url string) (_ Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.confirmimage",
+		attribute.String("product.id", prd.ID.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := checkTenant(ctx, prd); err != nil {
+		return Product{}, err
+	}
+
+	prd, err = b.storer.ConfirmImage(ctx, prd, key, url, time.Now())
+	if err != nil {
+		return Product{}, fmt.Errorf("confirmimage: %w", err)
+	}
+
+	if err := b.callDelegate(ctx, ActionUpdated, prd); err != nil {
+		return Product{}, err
+	}
+
+	return prd, nil
+}
@@ -1,31 +1,47 @@
 package productbus
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/business/types/tag"
 	"github.com/google/uuid"
 )
 
 // Product represents an individual product.
 type Product struct {
 	ID          uuid.UUID
+	TenantID    uuid.UUID
 	UserID      uuid.UUID
+	SKU         sku.SKU
 	Name        name.Name
+	Description string
 	Cost        money.Money
 	Quantity    quantity.Quantity
+	CategoryID  *uuid.UUID
+	Tags        []tag.Tag
+	ImageURL    string
 	DateCreated time.Time
 	DateUpdated time.Time
+	DateDeleted *time.Time
 }
 
 // NewProduct is what we require from clients when adding a Product.
 type NewProduct struct {
-	UserID   uuid.UUID
-	Name     name.Name
-	Cost     money.Money
-	Quantity quantity.Quantity
+	UserID      uuid.UUID
+	SKU         sku.SKU
+	Name        name.Name
+	Description string
+	Cost        money.Money
+	Quantity    quantity.Quantity
+	CategoryID  *uuid.UUID
+	Tags        []tag.Tag
 }
 
 // UpdateProduct defines what information may be provided to modify an
@@ -35,7 +51,150 @@ type NewProduct struct {
 // explicitly blank. Normally we do not want to use pointers to basic types but
 // we make exceptions around marshalling/unmarshalling.
 type UpdateProduct struct {
-	Name     *name.Name
-	Cost     *money.Money
-	Quantity *quantity.Quantity
+	SKU         *sku.SKU
+	Name        *name.Name
+	Description *string
+	Cost        *money.Money
+	Quantity    *quantity.Quantity
+	CategoryID  *uuid.UUID
+
+	// Tags replaces every tag of the product when it's set, an empty slice
+	// removes them all.
+	Tags *[]tag.Tag
+}
+
+// PriceChange records a change of the cost of a product.
+type PriceChange struct {
+	ID          uuid.UUID
+	ProductID   uuid.UUID
+	OldCost     money.Money
+	NewCost     money.Money
+	DateChanged time.Time
+}
+
+// PriceAdjusted is a product whose cost was changed by a price adjustment.
+// Before is the product as it was prior to the adjustment and Change is the
+// entry recorded in its price history.
+type PriceAdjusted struct {
+	Product Product
+	Before  Product
+	Change  PriceChange
+}
+
+// PriceAdjustment describes a change applied to the cost of many products.
+// The cost is scaled by Percent, so -10 takes 10% off, and AmountCents is
+// added to the scaled cost. The result is rounded to whole cents.
+type PriceAdjustment struct {
+	Percent     float64
+	AmountCents int64
+}
+
+// ProductUpdate pairs a product with the change to apply to it in a bulk
+// update.
+type ProductUpdate struct {
+	Product Product
+	Update  UpdateProduct
+}
+
+// BulkOutcome is the result of storing one product of a partial bulk create
+// or update. Err is set when the product wasn't stored.
+type BulkOutcome struct {
+	Product Product
+	Err     error
+}
+
+// ConflictTarget names what an upsert matches existing products by.
+type ConflictTarget string
+
+// Set of targets an upsert can match existing products by.
+const (
+	ConflictSKU ConflictTarget = "sku"
+	ConflictID  ConflictTarget = "id"
+)
+
+// UpsertProduct is a product to create, or to apply to the existing product
+// it matches. ID identifies the product when upserting by ID and is ignored
+// when upserting by SKU.
+type UpsertProduct struct {
+	ID uuid.UUID
+	NewProduct
+}
+
+// Upserted is the result of an upsert. Before is the product as it was prior
+// to an update and is nil when the product was created.
+type Upserted struct {
+	Product Product
+	Created bool
+	Before  *Product
+}
+
+// UpsertOutcome is the result of one product of a bulk upsert. Err is set
+// when the product wasn't stored.
+type UpsertOutcome struct {
+	Upserted
+	Err error
+}
+
+// SearchResult is a product matching a full text search along with the rank
+// of the match. A higher rank is a better match.
+type SearchResult struct {
+	Product Product
+	Rank    float64
+}
+
+// Popular represents a product along with the number of users who viewed it.
+type Popular struct {
+	Product Product
+	Views   int
+}
+
+// Summary represents the totals of the products matching a filter. Amounts
+// are in cents since the value of an inventory can exceed the largest Money.
+type Summary struct {
+	Count               int
+	InventoryValueCents int64
+	AverageCostCents    int64
+	OutOfStock          int
+}
+
+// Cursor marks the position of the last product seen by a keyset query. The
+// value holds the sort key of that product in its string form and the ID is
+// used to break ties between products sharing the same sort key.
+type Cursor struct {
+	OrderBy order.By
+	Value   string
+	ID      uuid.UUID
+}
+
+// NewCursor constructs the cursor that continues a keyset query after the
+// specified product using the specified ordering.
+func NewCursor(prd Product, orderBy order.By) (Cursor, error) {
+	var value string
+
+	switch orderBy.Field {
+	case OrderByProductID:
+		value = prd.ID.String()
+	case OrderByUserID:
+		value = prd.UserID.String()
+	case OrderByName:
+		value = prd.Name.String()
+	case OrderByCost:
+		value = prd.Cost.String()
+	case OrderByQuantity:
+		value = strconv.Itoa(prd.Quantity.Value())
+	case OrderByDateCreated:
+		value = prd.DateCreated.UTC().Format(time.RFC3339Nano)
+	case OrderByDateUpdated:
+		value = prd.DateUpdated.UTC().Format(time.RFC3339Nano)
+	default:
+		return Cursor{}, fmt.Errorf("unknown order field: %s", orderBy.Field)
+	}
+
+	c := Cursor{
+		OrderBy: orderBy,
+		Value:   value,
+		ID:      prd.ID,
+	}
+
+	return c, nil
 }
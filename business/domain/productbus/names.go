@@ -0,0 +1,36 @@
+package productbus
+
+import (
+	"strings"
+
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/uuid"
+)
+
+// NormalizeName trims the name of a product and collapses the runs of
+// whitespace inside it into a single space.
+func NormalizeName(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// ParseName parses the name of a product once it's normalized, so names
+// differing only by whitespace are the same name. The normalized name is
+// the one stored and checked for uniqueness.
+func ParseName(value string) (name.Name, error) {
+	return name.Parse(NormalizeName(value))
+}
+
+// WithUniqueNames requires the products of the tenants the policy reports
+// to have names that are unique, ignoring case. A nil policy lets every
+// tenant reuse names.
+func WithUniqueNames(policy func(tenantID uuid.UUID) bool) func(b *Business) {
+	return func(b *Business) {
+		b.uniqueNames = policy
+	}
+}
+
+// requiresUniqueName reports whether the products of the tenant must have
+// unique names.
+func (b *Business) requiresUniqueName(tenantID uuid.UUID) bool {
+	return b.uniqueNames != nil && b.uniqueNames(tenantID)
+}
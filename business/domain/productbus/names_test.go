@@ -0,0 +1,121 @@
+package productbus_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/google/uuid"
+)
+
+type enabledUsers struct {
+	userbus.ExtBusiness
+}
+
+func (enabledUsers) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	return userbus.User{ID: userID, Enabled: true}, nil
+}
+
+// nameStore keeps the names of the created products, ignoring case, to
+// reject a name already in use when asked to.
+type nameStore struct {
+	productbus.Storer
+	names  map[string]bool
+	unique int
+}
+
+func (s *nameStore) Create(ctx context.Context, prd productbus.Product) error {
+	s.names[strings.ToLower(prd.Name.String())] = true
+	return nil
+}
+
+func (s *nameStore) CreateUniqueName(ctx context.Context, prd productbus.Product) error {
+	s.unique++
+	if s.names[strings.ToLower(prd.Name.String())] {
+		return productbus.ErrDuplicateName
+	}
+
+	return s.Create(ctx, prd)
+}
+
+func Test_UniqueNames(t *testing.T) {
+	strict := uuid.New()
+	lenient := uuid.New()
+
+	policy := func(tenantID uuid.UUID) bool {
+		return tenantID == strict
+	}
+
+	tests := []struct {
+		name     string
+		policy   func(tenantID uuid.UUID) bool
+		tenantID uuid.UUID
+		expErr   error
+		expCheck int
+	}{
+		{name: "disabled", policy: nil, tenantID: strict, expErr: nil, expCheck: 0},
+		{name: "tenant-not-listed", policy: policy, tenantID: lenient, expErr: nil, expCheck: 0},
+		{name: "tenant-listed", policy: policy, tenantID: strict, expErr: productbus.ErrDuplicateName, expCheck: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := nameStore{names: make(map[string]bool)}
+			bus := productbus.NewBusiness(nil, enabledUsers{}, nil, &store, productbus.WithUniqueNames(tt.policy))
+
+			ctx := tenant.Set(context.Background(), tt.tenantID)
+
+			if _, err := bus.Create(ctx, productbus.NewProduct{SKU: sku.MustParse("GTR-1"), Name: name.MustParse("Guitar")}); err != nil {
+				t.Fatalf("Should be able to create the first product: %s", err)
+			}
+
+			_, err := bus.Create(ctx, productbus.NewProduct{SKU: sku.MustParse("GTR-2"), Name: name.MustParse("GUITAR")})
+			if !errors.Is(err, tt.expErr) {
+				t.Fatalf("Should get back %v, got %v", tt.expErr, err)
+			}
+
+			if store.unique != tt.expCheck {
+				t.Fatalf("Should check the name %d times, got %d", tt.expCheck, store.unique)
+			}
+		})
+	}
+}
+
+func Test_ParseName(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		exp    string
+		expErr bool
+	}{
+		{name: "clean", value: "Guitar Case", exp: "Guitar Case"},
+		{name: "trimmed", value: "  Guitar Case\t", exp: "Guitar Case"},
+		{name: "collapsed", value: "Guitar \t  Case", exp: "Guitar Case"},
+		{name: "too-short", value: "  G  ", expErr: true},
+		{name: "invalid", value: "Guitar $ Case", expErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := productbus.ParseName(tt.value)
+			if (err != nil) != tt.expErr {
+				t.Fatalf("Should get the expected error for %q: %v", tt.value, err)
+			}
+
+			if got.String() != tt.exp {
+				t.Fatalf("Should get the expected name: got %q, exp %q", got, tt.exp)
+			}
+		})
+	}
+
+	// The names of the other domains aren't normalized.
+	if nme, err := name.Parse("Bill  Kennedy"); err != nil || nme.String() != "Bill  Kennedy" {
+		t.Fatalf("Should keep the whitespace of other names: got %q: %v", nme, err)
+	}
+}
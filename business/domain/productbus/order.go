@@ -7,9 +7,25 @@ var DefaultOrderBy = order.NewBy(OrderByProductID, order.ASC)
 
 // Set of fields that the results can be ordered by.
 const (
-	OrderByProductID = "a"
-	OrderByUserID    = "b"
-	OrderByName      = "c"
-	OrderByCost      = "d"
-	OrderByQuantity  = "e"
+	OrderByProductID   = "a"
+	OrderByUserID      = "b"
+	OrderByName        = "c"
+	OrderByCost        = "d"
+	OrderByQuantity    = "e"
+	OrderByDateCreated = "f"
+	OrderByDateUpdated = "g"
 )
+
+// OrderByFields is the allow list of the fields clients can order products
+// by, keyed by the names used in requests. It's the one place the sortable
+// fields are declared, so a field is only added once the store can sort by
+// it without scanning the table.
+var OrderByFields = order.AllowList{
+	"product_id":   OrderByProductID,
+	"name":         OrderByName,
+	"cost":         OrderByCost,
+	"quantity":     OrderByQuantity,
+	"user_id":      OrderByUserID,
+	"date_created": OrderByDateCreated,
+	"date_updated": OrderByDateUpdated,
+}
@@ -0,0 +1,118 @@
+package productbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/ardanlabs/service/foundation/otel"
+)
+
+// DefaultPopularMaxAge is how old the popular products can get before they're
+// counted live unless configured otherwise.
+const DefaultPopularMaxAge = 30 * time.Minute
+
+// WithPopularMaxAge sets how long after their last refresh the popular
+// products are read from the materialized view. Past it, or when the view
+// was never refreshed, the views are counted live. Ages that aren't
+// positive keep the default.
+func WithPopularMaxAge(maxAge time.Duration) func(b *Business) {
+	return func(b *Business) {
+		if maxAge > 0 {
+			b.popularMaxAge = maxAge
+		}
+	}
+}
+
+// QueryPopular retrieves the products that were viewed by the most users,
+// most viewed first.
+func (b *Business) QueryPopular(ctx context.Context, page page.Page) ([]Popular, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querypopular")
+	defer span.End()
+
+	live, err := b.popularStale(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prds, err := b.storer.QueryPopular(ctx, tenant.Get(ctx), live, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: live[%t]: %w", live, err)
+	}
+
+	return prds, nil
+}
+
+// CountPopular returns the number of products QueryPopular ranks.
+func (b *Business) CountPopular(ctx context.Context) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.countpopular")
+	defer span.End()
+
+	live, err := b.popularStale(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return b.storer.CountPopular(ctx, tenant.Get(ctx), live)
+}
+
+// RefreshPopular recounts the views of the popular products of every tenant.
+func (b *Business) RefreshPopular(ctx context.Context) error {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.refreshpopular")
+	defer span.End()
+
+	if err := b.storer.RefreshPopular(ctx, time.Now()); err != nil {
+		return fmt.Errorf("refresh: %w", err)
+	}
+
+	return nil
+}
+
+// StartPopularRefresh refreshes the popular products every interval in the
+// background. A refresh that fails is logged and tried again on the next
+// one. The returned function stops the refreshes, waiting for the one in
+// progress.
+func (b *Business) StartPopularRefresh(ctx context.Context, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := b.RefreshPopular(ctx); err != nil && ctx.Err() == nil {
+				b.log.Error(ctx, "refreshpopular", "err", err)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// popularStale reports whether the materialized view of the popular products
+// is too old to be read, so the views have to be counted live.
+func (b *Business) popularStale(ctx context.Context) (bool, error) {
+	refreshed, err := b.storer.PopularRefreshed(ctx)
+	if err != nil {
+		return false, fmt.Errorf("popularrefreshed: %w", err)
+	}
+
+	return refreshed.IsZero() || time.Since(refreshed) > b.popularMaxAge, nil
+}
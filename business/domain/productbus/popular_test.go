@@ -0,0 +1,105 @@
+package productbus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/google/uuid"
+)
+
+type popularStore struct {
+	productbus.Storer
+	refreshed time.Time
+	live      []bool
+	refreshes chan struct{}
+}
+
+func (s *popularStore) PopularRefreshed(ctx context.Context) (time.Time, error) {
+	return s.refreshed, nil
+}
+
+func (s *popularStore) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]productbus.Popular, error) {
+	s.live = append(s.live, live)
+	return nil, nil
+}
+
+func (s *popularStore) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error) {
+	s.live = append(s.live, live)
+	return 0, nil
+}
+
+func (s *popularStore) RefreshPopular(ctx context.Context, now time.Time) error {
+	select {
+	case s.refreshes <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func Test_QueryPopular(t *testing.T) {
+	tests := []struct {
+		name      string
+		refreshed time.Time
+		live      bool
+	}{
+		{name: "fresh", refreshed: time.Now().Add(-time.Minute), live: false},
+		{name: "stale", refreshed: time.Now().Add(-time.Hour), live: true},
+		{name: "never-refreshed", live: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := popularStore{refreshed: tt.refreshed}
+			bus := productbus.NewBusiness(nil, nil, nil, &store, productbus.WithPopularMaxAge(10*time.Minute))
+
+			ctx := tenant.Set(context.Background(), uuid.New())
+
+			if _, err := bus.QueryPopular(ctx, page.MustParse("1", "10")); err != nil {
+				t.Fatalf("Should be able to query: %s", err)
+			}
+
+			if _, err := bus.CountPopular(ctx); err != nil {
+				t.Fatalf("Should be able to count: %s", err)
+			}
+
+			for _, live := range store.live {
+				if live != tt.live {
+					t.Fatalf("Should read the ranking with live %t: got %v", tt.live, store.live)
+				}
+			}
+		})
+	}
+}
+
+func Test_StartPopularRefresh(t *testing.T) {
+	store := popularStore{refreshes: make(chan struct{}, 10)}
+	bus := productbus.NewBusiness(nil, nil, nil, &store)
+
+	stop := bus.StartPopularRefresh(context.Background(), time.Millisecond)
+
+	for range 2 {
+		select {
+		case <-store.refreshes:
+		case <-time.After(time.Second):
+			t.Fatal("Should refresh on every interval")
+		}
+	}
+
+	stop()
+
+	// Drain the refreshes made before the stop.
+	for len(store.refreshes) > 0 {
+		<-store.refreshes
+	}
+
+	select {
+	case <-store.refreshes:
+		t.Fatal("Should not refresh once stopped")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"time"
 
 	"github.com/ardanlabs/service/business/domain/userbus"
@@ -12,29 +13,77 @@ import (
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Set of error variables for CRUD operations.
 var (
-	ErrNotFound     = errors.New("product not found")
-	ErrUserDisabled = errors.New("user disabled")
-	ErrInvalidCost  = errors.New("cost not valid")
+	ErrNotFound            = errors.New("product not found")
+	ErrUserDisabled        = errors.New("user disabled")
+	ErrInvalidCost         = errors.New("cost not valid")
+	ErrVersionConflict     = errors.New("product version conflict")
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrIdempotencyKeyInUse = errors.New("idempotency key in use")
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrDuplicateSKU        = errors.New("sku is already in use")
+	ErrDuplicateName       = errors.New("name is already in use")
+	ErrSavepoint           = errors.New("savepoint failed")
 )
 
+// IdempotencyTTL is how long an idempotency key provided on create is
+// remembered before it can be used again.
+const IdempotencyTTL = 24 * time.Hour
+
 // Storer interface declares the behavior this package needs to persist and
 // retrieve data.
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, prd Product) error
-	Update(ctx context.Context, prd Product) error
+	CreateUniqueName(ctx context.Context, prd Product) error
+	Update(ctx context.Context, prd Product, version time.Time) error
+	Upsert(ctx context.Context, prd Product, target ConflictTarget) (Upserted, error)
 	Delete(ctx context.Context, prd Product) error
-	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error)
+	DeleteByFilter(ctx context.Context, filter QueryFilter, now time.Time) ([]Product, error)
+	AdjustPriceByFilter(ctx context.Context, filter QueryFilter, adj PriceAdjustment, now time.Time) ([]PriceAdjusted, error)
+	AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (Product, error)
+	Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (Product, error)
+	Query(ctx context.Context, filter QueryFilter, orderBy []order.By, page page.Page) ([]Product, error)
+	QueryByCursor(ctx context.Context, filter QueryFilter, cursor Cursor, rows int) ([]Product, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
-	QueryByID(ctx context.Context, productID uuid.UUID) (Product, error)
-	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error)
+	Summarize(ctx context.Context, filter QueryFilter) (Summary, error)
+	Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) ([]SearchResult, error)
+	SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (int, error)
+	FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) ([]SearchResult, error)
+	FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (int, error)
+	QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (Product, error)
+	QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (Product, error)
+	NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (bool, error)
+	SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error)
+	NextSKUSequence(ctx context.Context) (int64, error)
+	QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]Product, error)
+	QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]Product, error)
+	CreatePriceChange(ctx context.Context, pc PriceChange) error
+	QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]PriceChange, error)
+	CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error)
+	QuerySimilar(ctx context.Context, prd Product, band float64, page page.Page) ([]Product, error)
+	CountSimilar(ctx context.Context, prd Product, band float64) (int, error)
+	QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (uuid.UUID, error)
+	CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) error
+	RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) error
+	QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]Product, error)
+	QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]Popular, error)
+	CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error)
+	RefreshPopular(ctx context.Context, now time.Time) error
+	PopularRefreshed(ctx context.Context) (time.Time, error)
+	SetPendingImage(ctx context.Context, prd Product, key string) error
+	ConfirmImage(ctx context.Context, prd Product, key string, url string, now time.Time) (Product, error)
+	WithSavepoint(ctx context.Context, fn func() error) error
 }
 
 // Business manages the set of APIs for product access.
@@ -43,15 +92,43 @@ type Business struct {
 	userBus  userbus.ExtBusiness
 	delegate *delegate.Delegate
 	storer   Storer
+	views    *viewRecorder
+	counts   *countCache
+
+	// uniqueNames reports the tenants whose product names must be unique.
+	uniqueNames func(tenantID uuid.UUID) bool
+
+	// similarity is the smallest similarity of a fuzzy search match.
+	similarity float64
+
+	// skuPattern generates the skus of the products created without one.
+	skuPattern SKUPattern
+
+	// popularMaxAge is how old the popular products can get before they're
+	// counted live.
+	popularMaxAge time.Duration
+
+	// afterCommit holds back the lifecycle actions of a business constructed
+	// with NewWithTx until its transaction commits.
+	afterCommit sqldb.AfterCommitter
 }
 
 // NewBusiness constructs a product business API for use.
-func NewBusiness(log *logger.Logger, userBus userbus.ExtBusiness, delegate *delegate.Delegate, storer Storer) *Business {
+func NewBusiness(log *logger.Logger, userBus userbus.ExtBusiness, delegate *delegate.Delegate, storer Storer, options ...func(b *Business)) *Business {
 	b := Business{
 		log:      log,
 		userBus:  userBus,
 		delegate: delegate,
 		storer:   storer,
+		views:    newViewRecorder(log, storer),
+
+		similarity:    DefaultSimilarityThreshold,
+		skuPattern:    MustParseSKUPattern(DefaultSKUPattern),
+		popularMaxAge: DefaultPopularMaxAge,
+	}
+
+	for _, option := range options {
+		option(&b)
 	}
 
 	b.registerDelegateFunctions()
@@ -60,7 +137,9 @@ func NewBusiness(log *logger.Logger, userBus userbus.ExtBusiness, delegate *dele
 }
 
 // NewWithTx constructs a new business value that will use the
-// specified transaction in any store related calls.
+// specified transaction in any store related calls. Totals aren't cached
+// inside the transaction since they could include writes that are rolled
+// back.
 func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 	storer, err := b.storer.NewWithTx(tx)
 	if err != nil {
@@ -77,15 +156,31 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 		userBus:  userBus,
 		delegate: b.delegate,
 		storer:   storer,
+		views:    b.views,
+
+		uniqueNames:   b.uniqueNames,
+		similarity:    b.similarity,
+		skuPattern:    b.skuPattern,
+		popularMaxAge: b.popularMaxAge,
+	}
+
+	if ac, ok := tx.(sqldb.AfterCommitter); ok {
+		bus.afterCommit = ac
 	}
 
 	return &bus, nil
 }
 
-// Create adds a new product to the system.
-func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
-	ctx, span := otel.AddSpan(ctx, "business.productbus.create")
-	defer span.End()
+// Create adds a new product to the system on behalf of the tenant of the
+// context. A product without a SKU is given one generated from the sku
+// pattern. ErrDuplicateSKU is returned when another product of the tenant
+// already uses the SKU, and ErrDuplicateName when the tenant requires
+// unique names and another product already uses the name.
+func (b *Business) Create(ctx context.Context, np NewProduct) (_ Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.create",
+		attribute.String("product.user_id", np.UserID.String()),
+	)
+	defer func() { endSpan(span, err) }()
 
 	usr, err := b.userBus.QueryByID(ctx, np.UserID)
 	if err != nil {
@@ -97,33 +192,427 @@ func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
 	}
 
 	now := time.Now()
+	tenantID := tenant.Get(ctx)
+
+	prdSKU, err := b.skuFor(ctx, tenantID, np)
+	if err != nil {
+		return Product{}, err
+	}
 
 	prd := Product{
 		ID:          uuid.New(),
+		TenantID:    tenantID,
+		SKU:         prdSKU,
 		Name:        np.Name,
+		Description: np.Description,
 		Cost:        np.Cost,
 		Quantity:    np.Quantity,
 		UserID:      np.UserID,
+		CategoryID:  np.CategoryID,
+		Tags:        normalizeTags(np.Tags),
 		DateCreated: now,
 		DateUpdated: now,
 	}
 
-	if err := b.storer.Create(ctx, prd); err != nil {
+	span.SetAttributes(attribute.String("product.id", prd.ID.String()))
+
+	create := b.storer.Create
+	if b.requiresUniqueName(prd.TenantID) {
+		create = b.storer.CreateUniqueName
+	}
+
+	if err := create(ctx, prd); err != nil {
 		return Product{}, fmt.Errorf("create: %w", err)
 	}
 
+	if err := b.callDelegate(ctx, ActionCreated, prd); err != nil {
+		return Product{}, err
+	}
+
+	return prd, nil
+}
+
+// CreateIdempotent adds a new product to the system unless the user already
+// created a product with the same key within the IdempotencyTTL. In that case
+// the original product is returned and replayed is true. The caller is
+// expected to provide a transaction via NewWithTx so the product and the key
+// are stored together.
+func (b *Business) CreateIdempotent(ctx context.Context, key string, np NewProduct) (_ Product, replayed bool, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.createidempotent")
+	defer span.End()
+
+	now := time.Now()
+	since := now.Add(-IdempotencyTTL)
+
+	productID, err := b.storer.QueryIdempotencyKey(ctx, np.UserID, key, since)
+	switch {
+	case err == nil:
+		prd, err := b.QueryByIDWithDeleted(ctx, productID)
+		if err != nil {
+			return Product{}, false, fmt.Errorf("querybyid: %w", err)
+		}
+		return prd, true, nil
+
+	case !errors.Is(err, ErrNotFound):
+		return Product{}, false, fmt.Errorf("queryidempotencykey: %w", err)
+	}
+
+	prd, err := b.Create(ctx, np)
+	if err != nil {
+		return Product{}, false, err
+	}
+
+	if err := b.storer.CreateIdempotencyKey(ctx, np.UserID, key, prd.ID, now, since); err != nil {
+		return Product{}, false, fmt.Errorf("createidempotencykey: %w", err)
+	}
+
+	return prd, false, nil
+}
+
+// BulkCreate adds a set of new products to the system. The products are
+// inserted one at a time so the caller is expected to provide a transaction
+// via NewWithTx if the batch must be stored as a single unit of work.
+func (b *Business) BulkCreate(ctx context.Context, nps []NewProduct) (_ []Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.bulkcreate",
+		attribute.Int("product.rows", len(nps)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	checked := make(map[uuid.UUID]struct{})
+	for _, np := range nps {
+		if _, exists := checked[np.UserID]; exists {
+			continue
+		}
+
+		usr, err := b.userBus.QueryByID(ctx, np.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("user.querybyid: %s: %w", np.UserID, err)
+		}
+
+		if !usr.Enabled {
+			return nil, ErrUserDisabled
+		}
+
+		checked[np.UserID] = struct{}{}
+	}
+
+	now := time.Now()
+	tenantID := tenant.Get(ctx)
+
+	create := b.storer.Create
+	if b.requiresUniqueName(tenantID) {
+		create = b.storer.CreateUniqueName
+	}
+
+	prds := make([]Product, len(nps))
+	for i, np := range nps {
+		prdSKU, err := b.skuFor(ctx, tenantID, np)
+		if err != nil {
+			return nil, fmt.Errorf("index[%d]: %w", i, err)
+		}
+
+		prd := Product{
+			ID:          uuid.New(),
+			TenantID:    tenantID,
+			SKU:         prdSKU,
+			Name:        np.Name,
+			Description: np.Description,
+			Cost:        np.Cost,
+			Quantity:    np.Quantity,
+			UserID:      np.UserID,
+			CategoryID:  np.CategoryID,
+			Tags:        normalizeTags(np.Tags),
+			DateCreated: now,
+			DateUpdated: now,
+		}
+
+		if err := create(ctx, prd); err != nil {
+			return nil, fmt.Errorf("create: index[%d]: %w", i, err)
+		}
+
+		prds[i] = prd
+	}
+
+	for _, prd := range prds {
+		if err := b.callDelegate(ctx, ActionCreated, prd); err != nil {
+			return nil, err
+		}
+	}
+
+	return prds, nil
+}
+
+// BulkCreatePartial adds a set of new products to the system, each one in a
+// savepoint of its own so a product that can't be stored doesn't undo the
+// ones that were. The caller must provide a transaction via NewWithTx and
+// commits it with the products that were stored. An error is only returned
+// when the savepoints themselves fail, which leaves the transaction unusable.
+func (b *Business) BulkCreatePartial(ctx context.Context, nps []NewProduct) (_ []BulkOutcome, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.bulkcreatepartial",
+		attribute.Int("product.rows", len(nps)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	outcomes := make([]BulkOutcome, len(nps))
+	for i, np := range nps {
+		err := b.storer.WithSavepoint(ctx, func() error {
+			prd, err := b.Create(ctx, np)
+			if err != nil {
+				return err
+			}
+
+			outcomes[i].Product = prd
+			return nil
+		})
+
+		if err != nil {
+			if errors.Is(err, ErrSavepoint) {
+				return nil, fmt.Errorf("create: index[%d]: %w", i, err)
+			}
+
+			outcomes[i].Err = err
+		}
+	}
+
+	return outcomes, nil
+}
+
+// BulkUpdate applies every update in order and stops at the first one that
+// fails. The caller is expected to provide a transaction via NewWithTx so a
+// failure leaves none of the products changed.
+func (b *Business) BulkUpdate(ctx context.Context, pus []ProductUpdate) (_ []Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.bulkupdate",
+		attribute.Int("product.rows", len(pus)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	prds := make([]Product, len(pus))
+	for i, pu := range pus {
+		prd, err := b.Update(ctx, pu.Product, pu.Update)
+		if err != nil {
+			return nil, fmt.Errorf("update: index[%d]: %w", i, err)
+		}
+
+		prds[i] = prd
+	}
+
+	return prds, nil
+}
+
+// BulkUpdatePartial applies every update inside its own savepoint of the
+// transaction provided via NewWithTx, so a failed update is rolled back
+// without undoing the others. The outcome of every update is returned in
+// order. An error is only returned when a savepoint can't be managed.
+func (b *Business) BulkUpdatePartial(ctx context.Context, pus []ProductUpdate) (_ []BulkOutcome, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.bulkupdatepartial",
+		attribute.Int("product.rows", len(pus)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	outcomes := make([]BulkOutcome, len(pus))
+	for i, pu := range pus {
+		err := b.storer.WithSavepoint(ctx, func() error {
+			prd, err := b.Update(ctx, pu.Product, pu.Update)
+			if err != nil {
+				return err
+			}
+
+			outcomes[i].Product = prd
+			return nil
+		})
+
+		if err != nil {
+			if errors.Is(err, ErrSavepoint) {
+				return nil, fmt.Errorf("update: index[%d]: %w", i, err)
+			}
+
+			outcomes[i].Err = err
+		}
+	}
+
+	return outcomes, nil
+}
+
+// Upsert adds the product, or applies it to the existing product it matches
+// by the target. An existing product keeps its owner and creation date, and
+// a product that was deleted isn't brought back: ErrNotFound is returned when
+// matching by ID and ErrDuplicateSKU when matching by SKU. A change of cost is
+// recorded in the price history, so the caller is expected to provide a
+// transaction via NewWithTx to store both together.
+func (b *Business) Upsert(ctx context.Context, target ConflictTarget, up UpsertProduct) (_ Upserted, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.upsert",
+		attribute.String("product.user_id", up.UserID.String()),
+		attribute.String("product.conflict", string(target)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	usr, err := b.userBus.QueryByID(ctx, up.UserID)
+	if err != nil {
+		return Upserted{}, fmt.Errorf("user.querybyid: %s: %w", up.UserID, err)
+	}
+
+	if !usr.Enabled {
+		return Upserted{}, ErrUserDisabled
+	}
+
+	id := up.ID
+	if target == ConflictSKU || id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	now := time.Now()
+
+	prd := Product{
+		ID:          id,
+		TenantID:    tenant.Get(ctx),
+		SKU:         up.SKU,
+		Name:        up.Name,
+		Description: up.Description,
+		Cost:        up.Cost,
+		Quantity:    up.Quantity,
+		UserID:      up.UserID,
+		CategoryID:  up.CategoryID,
+		Tags:        normalizeTags(up.Tags),
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	res, err := b.storer.Upsert(ctx, prd, target)
+	if err != nil {
+		return Upserted{}, fmt.Errorf("upsert: %w", err)
+	}
+
+	span.SetAttributes(attribute.String("product.id", res.Product.ID.String()))
+
+	action := ActionCreated
+	if !res.Created {
+		action = ActionUpdated
+	}
+
+	if res.Before != nil && !res.Product.Cost.Equal(res.Before.Cost) {
+		pc := PriceChange{
+			ID:          uuid.New(),
+			ProductID:   res.Product.ID,
+			OldCost:     res.Before.Cost,
+			NewCost:     res.Product.Cost,
+			DateChanged: res.Product.DateUpdated,
+		}
+
+		if err := b.storer.CreatePriceChange(ctx, pc); err != nil {
+			return Upserted{}, fmt.Errorf("createpricechange: %w", err)
+		}
+	}
+
+	if err := b.callDelegate(ctx, action, res.Product); err != nil {
+		return Upserted{}, err
+	}
+
+	return res, nil
+}
+
+// BulkUpsert upserts every product inside its own savepoint of the
+// transaction provided via NewWithTx, so a product that can't be stored
+// doesn't undo the others. The outcome of every product is returned in order.
+// An error is only returned when a savepoint can't be managed.
+func (b *Business) BulkUpsert(ctx context.Context, target ConflictTarget, ups []UpsertProduct) (_ []UpsertOutcome, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.bulkupsert",
+		attribute.Int("product.rows", len(ups)),
+		attribute.String("product.conflict", string(target)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	outcomes := make([]UpsertOutcome, len(ups))
+	for i, up := range ups {
+		err := b.storer.WithSavepoint(ctx, func() error {
+			res, err := b.Upsert(ctx, target, up)
+			if err != nil {
+				return err
+			}
+
+			outcomes[i].Upserted = res
+			return nil
+		})
+
+		if err != nil {
+			if errors.Is(err, ErrSavepoint) {
+				return nil, fmt.Errorf("upsert: index[%d]: %w", i, err)
+			}
+
+			outcomes[i].Err = err
+		}
+	}
+
+	return outcomes, nil
+}
+
+// Update modifies information about a product. The DateUpdated of the
+// specified product is the version the change is based on. If the stored
+// product no longer carries that version ErrVersionConflict is returned.
+// A change of cost is recorded in the price history, so the caller is
+// expected to provide a transaction via NewWithTx to store both together.
+func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (_ Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.update",
+		attribute.String("product.id", prd.ID.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := checkTenant(ctx, prd); err != nil {
+		return Product{}, err
+	}
+
+	version := prd.DateUpdated
+	oldCost := prd.Cost
+
+	prd = applyUpdate(prd, up, time.Now())
+
+	if err := b.storer.Update(ctx, prd, version); err != nil {
+		return Product{}, fmt.Errorf("update: %w", err)
+	}
+
+	if !prd.Cost.Equal(oldCost) {
+		pc := PriceChange{
+			ID:          uuid.New(),
+			ProductID:   prd.ID,
+			OldCost:     oldCost,
+			NewCost:     prd.Cost,
+			DateChanged: prd.DateUpdated,
+		}
+
+		if err := b.storer.CreatePriceChange(ctx, pc); err != nil {
+			return Product{}, fmt.Errorf("createpricechange: %w", err)
+		}
+	}
+
+	if err := b.callDelegate(ctx, ActionUpdated, prd); err != nil {
+		return Product{}, err
+	}
+
 	return prd, nil
 }
 
-// Update modifies information about a product.
-func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (Product, error) {
-	ctx, span := otel.AddSpan(ctx, "business.productbus.update")
+// PreviewUpdate returns the product as it would be stored by Update without
+// storing anything.
+func (b *Business) PreviewUpdate(ctx context.Context, prd Product, up UpdateProduct) Product {
+	_, span := otel.AddSpan(ctx, "business.productbus.previewupdate")
 	defer span.End()
 
+	return applyUpdate(prd, up, time.Now())
+}
+
+// applyUpdate merges the changes into the product.
+func applyUpdate(prd Product, up UpdateProduct, now time.Time) Product {
+	if up.SKU != nil {
+		prd.SKU = *up.SKU
+	}
+
 	if up.Name != nil {
 		prd.Name = *up.Name
 	}
 
+	if up.Description != nil {
+		prd.Description = *up.Description
+	}
+
 	if up.Cost != nil {
 		prd.Cost = *up.Cost
 	}
@@ -132,46 +621,361 @@ func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (P
 		prd.Quantity = *up.Quantity
 	}
 
-	prd.DateUpdated = time.Now()
+	if up.CategoryID != nil {
+		prd.CategoryID = up.CategoryID
+	}
 
-	if err := b.storer.Update(ctx, prd); err != nil {
-		return Product{}, fmt.Errorf("update: %w", err)
+	if up.Tags != nil {
+		prd.Tags = normalizeTags(*up.Tags)
 	}
 
-	return prd, nil
+	prd.DateUpdated = now
+
+	return prd
 }
 
-// Delete removes the specified product.
-func (b *Business) Delete(ctx context.Context, prd Product) error {
-	ctx, span := otel.AddSpan(ctx, "business.productbus.delete")
-	defer span.End()
+// Delete marks the specified product as deleted. The product is retained so
+// it can be restored. Deleting a product that is already deleted is a no-op.
+func (b *Business) Delete(ctx context.Context, prd Product) (err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.delete",
+		attribute.String("product.id", prd.ID.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := checkTenant(ctx, prd); err != nil {
+		return err
+	}
+
+	if prd.DateDeleted != nil {
+		return nil
+	}
+
+	now := time.Now()
+	prd.DateUpdated = now
+	prd.DateDeleted = &now
 
 	if err := b.storer.Delete(ctx, prd); err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	if err := b.callDelegate(ctx, ActionDeleted, prd); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// Query retrieves a list of existing products.
-func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error) {
-	ctx, span := otel.AddSpan(ctx, "business.productbus.query")
+// DeleteByFilter marks every product matching the filter as deleted and
+// returns the products as they were before they were deleted. Products that
+// are already deleted aren't touched.
+func (b *Business) DeleteByFilter(ctx context.Context, filter QueryFilter) (_ []Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.deletebyfilter",
+		filterAttribute(filter),
+	)
+	defer func() { endSpan(span, err) }()
+
+	includeDeleted := false
+	filter.IncludeDeleted = &includeDeleted
+	filter = scopeFilter(ctx, filter)
+
+	now := time.Now()
+
+	prds, err := b.storer.DeleteByFilter(ctx, filter, now)
+	if err != nil {
+		return nil, fmt.Errorf("deletebyfilter: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("product.rows", len(prds)))
+
+	for _, prd := range prds {
+		prd.DateUpdated = now
+		if err := b.callDelegate(ctx, ActionDeleted, prd); err != nil {
+			return nil, err
+		}
+	}
+
+	return prds, nil
+}
+
+// AdjustPriceByFilter applies the adjustment to the cost of every product
+// matching the filter in a single statement and records each change in the
+// price history. Products whose cost doesn't change aren't touched. If the
+// adjustment would make any cost negative nothing is changed and
+// ErrInvalidCost is returned.
+func (b *Business) AdjustPriceByFilter(ctx context.Context, filter QueryFilter, adj PriceAdjustment) (_ []PriceAdjusted, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.adjustpricebyfilter",
+		filterAttribute(filter),
+		attribute.Float64("product.percent", adj.Percent),
+		attribute.Int64("product.amount_cents", adj.AmountCents),
+	)
+	defer func() { endSpan(span, err) }()
+
+	includeDeleted := false
+	filter.IncludeDeleted = &includeDeleted
+	filter = scopeFilter(ctx, filter)
+
+	adjusted, err := b.storer.AdjustPriceByFilter(ctx, filter, adj, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("adjustpricebyfilter: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("product.rows", len(adjusted)))
+
+	for _, pa := range adjusted {
+		if err := b.callDelegate(ctx, ActionUpdated, pa.Product); err != nil {
+			return nil, err
+		}
+	}
+
+	return adjusted, nil
+}
+
+// AdjustStock atomically changes the quantity of the specified product by
+// delta. If the change would take the quantity below zero nothing is changed
+// and ErrInsufficientStock is returned.
+func (b *Business) AdjustStock(ctx context.Context, prd Product, delta int) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.adjuststock")
+	defer span.End()
+
+	if err := checkTenant(ctx, prd); err != nil {
+		return Product{}, err
+	}
+
+	adjPrd, err := b.storer.AdjustStock(ctx, tenant.Get(ctx), prd.ID, delta, time.Now())
+	if err != nil {
+		return Product{}, fmt.Errorf("adjuststock: productID[%s] delta[%d]: %w", prd.ID, delta, err)
+	}
+
+	if err := b.callDelegate(ctx, ActionUpdated, adjPrd); err != nil {
+		return Product{}, err
+	}
+
+	return adjPrd, nil
+}
+
+// Touch sets the update date of the specified product to now without
+// changing anything else, so caches keyed on it are refreshed. It's reported
+// like any other update.
+func (b *Business) Touch(ctx context.Context, prd Product) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.touch")
 	defer span.End()
 
-	prds, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err := checkTenant(ctx, prd); err != nil {
+		return Product{}, err
+	}
+
+	touched, err := b.storer.Touch(ctx, tenant.Get(ctx), prd.ID, time.Now())
+	if err != nil {
+		return Product{}, fmt.Errorf("touch: productID[%s]: %w", prd.ID, err)
+	}
+
+	if err := b.callDelegate(ctx, ActionUpdated, touched); err != nil {
+		return Product{}, err
+	}
+
+	return touched, nil
+}
+
+// Restore clears the deleted state of the specified product. The tags the
+// product lost when it was deleted aren't given back. Restoring a product
+// that isn't deleted returns the product unchanged.
+func (b *Business) Restore(ctx context.Context, prd Product) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.restore")
+	defer span.End()
+
+	if err := checkTenant(ctx, prd); err != nil {
+		return Product{}, err
+	}
+
+	if prd.DateDeleted == nil {
+		return prd, nil
+	}
+
+	version := prd.DateUpdated
+
+	prd.DateDeleted = nil
+	prd.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, prd, version); err != nil {
+		return Product{}, fmt.Errorf("update: %w", err)
+	}
+
+	if err := b.callDelegate(ctx, ActionUpdated, prd); err != nil {
+		return Product{}, err
+	}
+
+	return prd, nil
+}
+
+// Query retrieves a list of existing products.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy []order.By, page page.Page) (_ []Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.query",
+		filterAttribute(filter),
+		attribute.Int("product.page", page.Number()),
+		attribute.Int("product.rows_per_page", page.RowsPerPage()),
+		attribute.Int("product.offset", page.Offset()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	prds, err := b.storer.Query(ctx, scopeFilter(ctx, filter), orderBy, page)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
 
+	span.SetAttributes(attribute.Int("product.rows", len(prds)))
+
 	return prds, nil
 }
 
-// Count returns the total number of products.
-func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
-	ctx, span := otel.AddSpan(ctx, "business.productbus.count")
+// QueryByCursor retrieves the next window of products that follow the
+// specified cursor. A cursor with a zero ID starts from the beginning.
+func (b *Business) QueryByCursor(ctx context.Context, filter QueryFilter, cursor Cursor, rows int) (_ []Product, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybycursor",
+		filterAttribute(filter),
+		attribute.Int("product.rows_per_page", rows),
+	)
+	defer func() { endSpan(span, err) }()
+
+	prds, err := b.storer.QueryByCursor(ctx, scopeFilter(ctx, filter), cursor, rows)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("product.rows", len(prds)))
+
+	return prds, nil
+}
+
+// Count returns the total number of products. It always counts, refreshing
+// the total CountCached returns for the filter.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (_ int, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.count",
+		filterAttribute(filter),
+	)
+	defer func() { endSpan(span, err) }()
+
+	filter = scopeFilter(ctx, filter)
+
+	count, err := b.storer.Count(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	b.cacheCount(filter, count)
+
+	span.SetAttributes(attribute.Int("product.count", count))
+
+	return count, nil
+}
+
+// Summarize returns the totals of the products matching the filter. Every
+// total is zero when no product matches.
+func (b *Business) Summarize(ctx context.Context, filter QueryFilter) (_ Summary, err error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.summarize",
+		filterAttribute(filter),
+	)
+	defer func() { endSpan(span, err) }()
+
+	sum, err := b.storer.Summarize(ctx, scopeFilter(ctx, filter))
+	if err != nil {
+		return Summary{}, fmt.Errorf("summarize: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("product.count", sum.Count))
+
+	return sum, nil
+}
+
+// Search retrieves the products whose name or description match the full text
+// query, best matches first.
+func (b *Business) Search(ctx context.Context, query string, page page.Page) ([]SearchResult, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.search")
+	defer span.End()
+
+	results, err := b.storer.Search(ctx, tenant.Get(ctx), query, page)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	return results, nil
+}
+
+// QueryPriceHistory retrieves the cost changes of the specified product in
+// the order they happened.
+func (b *Business) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]PriceChange, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querypricehistory")
 	defer span.End()
 
-	return b.storer.Count(ctx, filter)
+	history, err := b.storer.QueryPriceHistory(ctx, productID, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	return history, nil
+}
+
+// CountPriceHistory returns the number of cost changes of the specified
+// product.
+func (b *Business) CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.countpricehistory")
+	defer span.End()
+
+	return b.storer.CountPriceHistory(ctx, productID)
+}
+
+// Export returns every product matching the filter. The products are read
+// from the store in windows of batchSize using keyset pagination on the
+// product id so memory use doesn't grow with the size of the catalog. The
+// sequence stops at the first error, including the context being canceled.
+func (b *Business) Export(ctx context.Context, filter QueryFilter, batchSize int) iter.Seq2[Product, error] {
+	return func(yield func(Product, error) bool) {
+		ctx, span := otel.AddSpan(ctx, "business.productbus.export")
+		defer span.End()
+
+		filter := scopeFilter(ctx, filter)
+
+		cursor := Cursor{
+			OrderBy: order.NewBy(OrderByProductID, order.ASC),
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(Product{}, err)
+				return
+			}
+
+			prds, err := b.storer.QueryByCursor(ctx, filter, cursor, batchSize)
+			if err != nil {
+				yield(Product{}, fmt.Errorf("query: %w", err))
+				return
+			}
+
+			for _, prd := range prds {
+				if !yield(prd, nil) {
+					return
+				}
+			}
+
+			if len(prds) < batchSize {
+				return
+			}
+
+			cursor, err = NewCursor(prds[len(prds)-1], cursor.OrderBy)
+			if err != nil {
+				yield(Product{}, fmt.Errorf("cursor: %w", err))
+				return
+			}
+		}
+	}
+}
+
+// SearchCount returns the total number of products matching the full text
+// query.
+func (b *Business) SearchCount(ctx context.Context, query string) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.searchcount")
+	defer span.End()
+
+	return b.storer.SearchCount(ctx, tenant.Get(ctx), query)
 }
 
 // QueryByID finds the product by the specified ID.
@@ -179,7 +983,7 @@ func (b *Business) QueryByID(ctx context.Context, productID uuid.UUID) (Product,
 	ctx, span := otel.AddSpan(ctx, "business.productbus.querybyid")
 	defer span.End()
 
-	prd, err := b.storer.QueryByID(ctx, productID)
+	prd, err := b.storer.QueryByID(ctx, tenant.Get(ctx), productID)
 	if err != nil {
 		return Product{}, fmt.Errorf("query: productID[%s]: %w", productID, err)
 	}
@@ -187,15 +991,105 @@ func (b *Business) QueryByID(ctx context.Context, productID uuid.UUID) (Product,
 	return prd, nil
 }
 
+// QueryBySKU finds the product by the specified SKU.
+func (b *Business) QueryBySKU(ctx context.Context, sku sku.SKU) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybysku")
+	defer span.End()
+
+	prd, err := b.storer.QueryBySKU(ctx, tenant.Get(ctx), sku)
+	if err != nil {
+		return Product{}, fmt.Errorf("query: sku[%s]: %w", sku, err)
+	}
+
+	return prd, nil
+}
+
+// NameExists reports whether a product with the name, ignoring case, exists.
+// It doesn't load the product so it's cheap enough to call while a form is
+// being filled in.
+func (b *Business) NameExists(ctx context.Context, name name.Name) (bool, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.nameexists")
+	defer span.End()
+
+	exists, err := b.storer.NameExists(ctx, tenant.Get(ctx), name)
+	if err != nil {
+		return false, fmt.Errorf("nameexists: name[%s]: %w", name, err)
+	}
+
+	return exists, nil
+}
+
+// QueryByIDs finds the products by the specified IDs in a single call. IDs
+// that don't match a product are not reported as an error.
+func (b *Business) QueryByIDs(ctx context.Context, productIDs []uuid.UUID) ([]Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybyids")
+	defer span.End()
+
+	if len(productIDs) == 0 {
+		return []Product{}, nil
+	}
+
+	prds, err := b.storer.QueryByIDs(ctx, tenant.Get(ctx), productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return prds, nil
+}
+
+// QueryByIDWithDeleted finds the product by the specified ID even when the
+// product has been soft deleted.
+func (b *Business) QueryByIDWithDeleted(ctx context.Context, productID uuid.UUID) (Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querybyidwithdeleted")
+	defer span.End()
+
+	includeDeleted := true
+	filter := QueryFilter{
+		ID:             &productID,
+		IncludeDeleted: &includeDeleted,
+	}
+
+	prds, err := b.storer.Query(ctx, scopeFilter(ctx, filter), []order.By{DefaultOrderBy}, page.MustParse("1", "1"))
+	if err != nil {
+		return Product{}, fmt.Errorf("query: productID[%s]: %w", productID, err)
+	}
+
+	if len(prds) == 0 {
+		return Product{}, fmt.Errorf("query: productID[%s]: %w", productID, ErrNotFound)
+	}
+
+	return prds[0], nil
+}
+
 // QueryByUserID finds the products by a specified User ID.
 func (b *Business) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error) {
 	ctx, span := otel.AddSpan(ctx, "business.productbus.querybyuserid")
 	defer span.End()
 
-	prds, err := b.storer.QueryByUserID(ctx, userID)
+	prds, err := b.storer.QueryByUserID(ctx, tenant.Get(ctx), userID)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
 
 	return prds, nil
 }
+
+// =============================================================================
+
+// scopeFilter limits the filter to the products of the tenant of the context.
+func scopeFilter(ctx context.Context, filter QueryFilter) QueryFilter {
+	tenantID := tenant.Get(ctx)
+	filter.TenantID = &tenantID
+
+	return filter
+}
+
+// checkTenant returns ErrNotFound when the product belongs to a tenant other
+// than the one of the context, so its existence isn't leaked.
+func checkTenant(ctx context.Context, prd Product) error {
+	if prd.TenantID != tenant.Get(ctx) {
+		return fmt.Errorf("tenant: productID[%s]: %w", prd.ID, ErrNotFound)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package productbus_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
@@ -10,13 +11,18 @@ import (
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/tenant"
 	"github.com/ardanlabs/service/business/sdk/unitest"
 	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/quantity"
 	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/business/types/tag"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 func Test_Product(t *testing.T) {
@@ -33,7 +39,9 @@ func Test_Product(t *testing.T) {
 
 	unitest.Run(t, query(db.BusDomain, sd), "query")
 	unitest.Run(t, create(db.BusDomain, sd), "create")
+	unitest.Run(t, bulkCreate(db.BusDomain, sd), "bulkCreate")
 	unitest.Run(t, update(db.BusDomain, sd), "update")
+	unitest.Run(t, stock(db.BusDomain, sd), "stock")
 	unitest.Run(t, delete(db.BusDomain, sd), "delete")
 }
 
@@ -104,7 +112,7 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 					Name: dbtest.NamePointer("Name"),
 				}
 
-				resp, err := busDomain.Product.Query(ctx, filter, productbus.DefaultOrderBy, page.MustParse("1", "10"))
+				resp, err := busDomain.Product.Query(ctx, filter, []order.By{productbus.DefaultOrderBy}, page.MustParse("1", "10"))
 				if err != nil {
 					return err
 				}
@@ -173,6 +181,7 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 			Name: "basic",
 			ExpResp: productbus.Product{
 				UserID:   sd.Users[0].ID,
+				SKU:      sku.MustParse("GTR-001"),
 				Name:     name.MustParse("Guitar"),
 				Cost:     money.MustParse(10.34),
 				Quantity: quantity.MustParse(10),
@@ -180,6 +189,7 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 			ExcFunc: func(ctx context.Context) any {
 				np := productbus.NewProduct{
 					UserID:   sd.Users[0].ID,
+					SKU:      sku.MustParse("GTR-001"),
 					Name:     name.MustParse("Guitar"),
 					Cost:     money.MustParse(10.34),
 					Quantity: quantity.MustParse(10),
@@ -204,6 +214,145 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				expResp.DateCreated = gotResp.DateCreated
 				expResp.DateUpdated = gotResp.DateUpdated
 
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+		{
+			Name: "tags",
+			ExpResp: productbus.Product{
+				UserID:   sd.Users[0].ID,
+				SKU:      sku.MustParse("UKE-001"),
+				Name:     name.MustParse("Ukulele"),
+				Cost:     money.MustParse(40),
+				Quantity: quantity.MustParse(3),
+				Tags:     []tag.Tag{tag.MustParse("sale"), tag.MustParse("strings")},
+			},
+			ExcFunc: func(ctx context.Context) any {
+				np := productbus.NewProduct{
+					UserID:   sd.Users[0].ID,
+					SKU:      sku.MustParse("UKE-001"),
+					Name:     name.MustParse("Ukulele"),
+					Cost:     money.MustParse(40),
+					Quantity: quantity.MustParse(3),
+					Tags:     []tag.Tag{tag.MustParse("strings"), tag.MustParse("Sale"), tag.MustParse("sale")},
+				}
+
+				if _, err := busDomain.Product.Create(ctx, np); err != nil {
+					return err
+				}
+
+				filter := productbus.QueryFilter{
+					Tags: []tag.Tag{tag.MustParse("sale"), tag.MustParse("strings")},
+				}
+
+				prds, err := busDomain.Product.Query(ctx, filter, []order.By{productbus.DefaultOrderBy}, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				if len(prds) != 1 {
+					return fmt.Errorf("expected the tagged product only, got %d products", len(prds))
+				}
+
+				filter.Tags = append(filter.Tags, tag.MustParse("drums"))
+
+				for match, exp := range map[productbus.TagMatch]int{productbus.TagMatchAll: 0, productbus.TagMatchAny: 1} {
+					filter.TagMatch = match
+
+					n, err := busDomain.Product.Count(ctx, filter)
+					if err != nil {
+						return err
+					}
+
+					if n != exp {
+						return fmt.Errorf("expected %d products matching %s of the tags, got %d", exp, match, n)
+					}
+				}
+
+				return prds[0]
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(productbus.Product)
+				if !exists {
+					return fmt.Sprintf("error occurred: %v", got)
+				}
+
+				expResp := exp.(productbus.Product)
+
+				expResp.ID = gotResp.ID
+				expResp.DateCreated = gotResp.DateCreated
+				expResp.DateUpdated = gotResp.DateUpdated
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func bulkCreate(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	table := []unitest.Table{
+		{
+			Name: "basic",
+			ExpResp: []productbus.Product{
+				{
+					UserID:   sd.Users[0].ID,
+					SKU:      sku.MustParse("DRM-001"),
+					Name:     name.MustParse("Drums"),
+					Cost:     money.MustParse(200.50),
+					Quantity: quantity.MustParse(2),
+				},
+				{
+					UserID:   sd.Users[0].ID,
+					SKU:      sku.MustParse("PNO-001"),
+					Name:     name.MustParse("Piano"),
+					Cost:     money.MustParse(1500),
+					Quantity: quantity.MustParse(1),
+				},
+			},
+			ExcFunc: func(ctx context.Context) any {
+				nps := []productbus.NewProduct{
+					{
+						UserID:   sd.Users[0].ID,
+						SKU:      sku.MustParse("DRM-001"),
+						Name:     name.MustParse("Drums"),
+						Cost:     money.MustParse(200.50),
+						Quantity: quantity.MustParse(2),
+					},
+					{
+						UserID:   sd.Users[0].ID,
+						SKU:      sku.MustParse("PNO-001"),
+						Name:     name.MustParse("Piano"),
+						Cost:     money.MustParse(1500),
+						Quantity: quantity.MustParse(1),
+					},
+				}
+
+				resp, err := busDomain.Product.BulkCreate(ctx, nps)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.([]productbus.Product)
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.([]productbus.Product)
+				if len(gotResp) != len(expResp) {
+					return fmt.Sprintf("got %d products, exp %d", len(gotResp), len(expResp))
+				}
+
+				for i := range expResp {
+					expResp[i].ID = gotResp[i].ID
+					expResp[i].DateCreated = gotResp[i].DateCreated
+					expResp[i].DateUpdated = gotResp[i].DateUpdated
+				}
+
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
@@ -219,6 +368,7 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 			ExpResp: productbus.Product{
 				ID:          sd.Users[0].Products[0].ID,
 				UserID:      sd.Users[0].ID,
+				SKU:         sd.Users[0].Products[0].SKU,
 				Name:        name.MustParse("Guitar"),
 				Cost:        money.MustParse(10.34),
 				Quantity:    quantity.MustParse(10),
@@ -252,6 +402,124 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "version-conflict",
+			ExpResp: productbus.ErrVersionConflict,
+			ExcFunc: func(ctx context.Context) any {
+				up := productbus.UpdateProduct{
+					Name: dbtest.NamePointer("Banjo"),
+				}
+
+				// The basic case already moved the stored version forward so
+				// the seed value is now stale.
+				resp, err := busDomain.Product.Update(ctx, sd.Users[0].Products[0], up)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotErr, exists := got.(error)
+				if !exists {
+					return "expected an error"
+				}
+
+				if !errors.Is(gotErr, exp.(error)) {
+					return fmt.Sprintf("got %q, exp %q", gotErr, exp)
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func stock(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
+	cmpErr := func(got any, exp any) string {
+		gotErr, exists := got.(error)
+		if !exists {
+			return "expected an error"
+		}
+
+		if !errors.Is(gotErr, exp.(error)) {
+			return fmt.Sprintf("got %q, exp %q", gotErr, exp)
+		}
+
+		return ""
+	}
+
+	table := []unitest.Table{
+		{
+			Name:    "insufficient",
+			ExpResp: productbus.ErrInsufficientStock,
+			ExcFunc: func(ctx context.Context) any {
+				prd := sd.Users[0].Products[0]
+
+				resp, err := busDomain.Product.AdjustStock(ctx, prd, -(prd.Quantity.Value() + 1000))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "missing",
+			ExpResp: productbus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				prd := productbus.Product{ID: uuid.New(), TenantID: tenant.Get(ctx)}
+
+				resp, err := busDomain.Product.AdjustStock(ctx, prd, 1)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "other-tenant",
+			ExpResp: productbus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				// The product claims the tenant of the context, the stored
+				// product still belongs to another one.
+				ctx = tenant.Set(ctx, uuid.New())
+
+				prd := sd.Users[0].Products[0]
+				prd.TenantID = tenant.Get(ctx)
+
+				resp, err := busDomain.Product.AdjustStock(ctx, prd, 1)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpErr,
+		},
+		{
+			Name:    "touch-other-tenant",
+			ExpResp: productbus.ErrNotFound,
+			ExcFunc: func(ctx context.Context) any {
+				ctx = tenant.Set(ctx, uuid.New())
+
+				prd := sd.Users[0].Products[0]
+				prd.TenantID = tenant.Get(ctx)
+
+				resp, err := busDomain.Product.Touch(ctx, prd)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpErr,
+		},
 	}
 
 	return table
@@ -287,6 +555,39 @@ func delete(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(got, exp)
 			},
 		},
+		{
+			Name:    "restore",
+			ExpResp: sd.Users[0].Products[1].ID,
+			ExcFunc: func(ctx context.Context) any {
+				if _, err := busDomain.Product.QueryByID(ctx, sd.Users[0].Products[1].ID); !errors.Is(err, productbus.ErrNotFound) {
+					return fmt.Errorf("expected deleted product to be hidden: %w", err)
+				}
+
+				prd, err := busDomain.Product.QueryByIDWithDeleted(ctx, sd.Users[0].Products[1].ID)
+				if err != nil {
+					return err
+				}
+
+				if prd.DateDeleted == nil {
+					return errors.New("expected product to be marked deleted")
+				}
+
+				prd, err = busDomain.Product.Restore(ctx, prd)
+				if err != nil {
+					return err
+				}
+
+				prd, err = busDomain.Product.QueryByID(ctx, prd.ID)
+				if err != nil {
+					return err
+				}
+
+				return prd.ID
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
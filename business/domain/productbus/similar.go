@@ -0,0 +1,43 @@
+package productbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/otel"
+)
+
+// QuerySimilar retrieves the products to recommend alongside the product:
+// the other products of its category whose cost is within the band of its
+// cost, closest in cost first. The band is a fraction of the cost, so 0.2
+// matches the products costing 20% less up to 20% more. A product without a
+// category has no similar products.
+func (b *Business) QuerySimilar(ctx context.Context, prd Product, band float64, page page.Page) ([]Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.querysimilar")
+	defer span.End()
+
+	if prd.CategoryID == nil {
+		return []Product{}, nil
+	}
+
+	prds, err := b.storer.QuerySimilar(ctx, prd, band, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: productID[%s]: %w", prd.ID, err)
+	}
+
+	return prds, nil
+}
+
+// CountSimilar returns the number of products QuerySimilar recommends
+// alongside the product.
+func (b *Business) CountSimilar(ctx context.Context, prd Product, band float64) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.countsimilar")
+	defer span.End()
+
+	if prd.CategoryID == nil {
+		return 0, nil
+	}
+
+	return b.storer.CountSimilar(ctx, prd, band)
+}
@@ -0,0 +1,255 @@
+package productbus
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/google/uuid"
+)
+
+// DefaultSKUPattern generates the skus of products created without one
+// unless configured otherwise.
+const DefaultSKUPattern = "PRD-{rand:16}"
+
+// maxSKUAttempts is the number of skus generated for a product before giving
+// up when every one of them is already in use.
+const maxSKUAttempts = 5
+
+// ErrSKUExhausted is returned when no free sku could be generated.
+var ErrSKUExhausted = errors.New("no free sku could be generated")
+
+// Set of placeholders a sku pattern can hold.
+const (
+	skuSeq  = "seq"
+	skuName = "name"
+	skuRand = "rand"
+)
+
+// Widths of the placeholders given without one. The sequence isn't padded
+// by default.
+const (
+	defaultSKUNameWidth = 8
+	defaultSKURandWidth = 8
+)
+
+var skuPlaceholder = regexp.MustCompile(`\{([a-z]+)(?::([0-9]+))?\}`)
+
+var skuLiteral = regexp.MustCompile(`^[A-Z0-9-]*$`)
+
+type skuPart struct {
+	kind  string
+	text  string
+	width int
+}
+
+// SKUPattern describes how the skus of the products created without one are
+// generated. A pattern is text holding placeholders:
+//
+//	{seq:N}  the next value of a sequence, zero-padded to N digits
+//	{name:N} the first N letters and digits of the product name
+//	{rand:N} N random letters and digits
+//
+// For example "PRD-{seq:6}" generates PRD-000001, PRD-000002 and so on, and
+// "{name}-{rand:4}" generates GUITAR-7KQ2. The sequence is shared by every
+// tenant so its skus are unique, the random ones are checked before use.
+type SKUPattern struct {
+	parts []skuPart
+}
+
+// ParseSKUPattern parses the pattern. The text outside the placeholders can
+// only hold upper case letters, digits and dashes, and the pattern must hold
+// a sequence or random placeholder so its skus can differ.
+func ParseSKUPattern(pattern string) (SKUPattern, error) {
+	var p SKUPattern
+
+	addText := func(text string) error {
+		if !skuLiteral.MatchString(text) {
+			return fmt.Errorf("invalid sku pattern %q: text %q can only hold upper case letters, digits and dashes", pattern, text)
+		}
+		if text != "" {
+			p.parts = append(p.parts, skuPart{text: text})
+		}
+		return nil
+	}
+
+	var unique bool
+	var last int
+
+	for _, m := range skuPlaceholder.FindAllStringSubmatchIndex(pattern, -1) {
+		if err := addText(pattern[last:m[0]]); err != nil {
+			return SKUPattern{}, err
+		}
+		last = m[1]
+
+		part := skuPart{kind: pattern[m[2]:m[3]]}
+
+		if m[4] != -1 {
+			width, err := strconv.Atoi(pattern[m[4]:m[5]])
+			if err != nil || width < 1 {
+				return SKUPattern{}, fmt.Errorf("invalid sku pattern %q: invalid width in %s", pattern, pattern[m[0]:m[1]])
+			}
+			part.width = width
+		}
+
+		switch part.kind {
+		case skuSeq:
+			unique = true
+
+		case skuName:
+			if part.width == 0 {
+				part.width = defaultSKUNameWidth
+			}
+
+		case skuRand:
+			if part.width == 0 {
+				part.width = defaultSKURandWidth
+			}
+			if part.width > 26 {
+				return SKUPattern{}, fmt.Errorf("invalid sku pattern %q: at most 26 random characters can be generated", pattern)
+			}
+			unique = true
+
+		default:
+			return SKUPattern{}, fmt.Errorf("invalid sku pattern %q: unknown placeholder %s", pattern, pattern[m[0]:m[1]])
+		}
+
+		p.parts = append(p.parts, part)
+	}
+
+	if err := addText(pattern[last:]); err != nil {
+		return SKUPattern{}, err
+	}
+
+	if !unique {
+		return SKUPattern{}, fmt.Errorf("invalid sku pattern %q: a {seq} or {rand} placeholder is required", pattern)
+	}
+
+	return p, nil
+}
+
+// MustParseSKUPattern parses the pattern and panics when it's invalid.
+func MustParseSKUPattern(pattern string) SKUPattern {
+	p, err := ParseSKUPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// WithSKUPattern sets the pattern used to generate the skus of the products
+// created without one. DefaultSKUPattern is used otherwise.
+func WithSKUPattern(pattern SKUPattern) func(b *Business) {
+	return func(b *Business) {
+		b.skuPattern = pattern
+	}
+}
+
+// generate returns a sku for the product name from the pattern. The next
+// value of the sequence is only read when the pattern uses it.
+func (p SKUPattern) generate(nme name.Name, next func() (int64, error)) (sku.SKU, error) {
+	var b strings.Builder
+
+	for _, part := range p.parts {
+		switch part.kind {
+		case "":
+			b.WriteString(part.text)
+
+		case skuSeq:
+			seq, err := next()
+			if err != nil {
+				return sku.SKU{}, fmt.Errorf("sequence: %w", err)
+			}
+			fmt.Fprintf(&b, "%0*d", part.width, seq)
+
+		case skuName:
+			b.WriteString(skuSlug(nme.String(), part.width))
+
+		case skuRand:
+			b.WriteString(rand.Text()[:part.width])
+		}
+	}
+
+	s, err := sku.Parse(b.String())
+	if err != nil {
+		return sku.SKU{}, fmt.Errorf("parse: %w", err)
+	}
+
+	return s, nil
+}
+
+// skuSlug returns up to width of the letters and digits of the name in upper
+// case. Runs of other characters become a single dash.
+func skuSlug(value string, width int) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToUpper(value) {
+		if b.Len() == width {
+			break
+		}
+
+		switch {
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			if dash && b.Len() > 0 && b.Len() < width-1 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+
+		default:
+			dash = true
+		}
+	}
+
+	return b.String()
+}
+
+// skuFor returns the sku of the new product, generating one when it has
+// none.
+func (b *Business) skuFor(ctx context.Context, tenantID uuid.UUID, np NewProduct) (sku.SKU, error) {
+	if np.SKU != (sku.SKU{}) {
+		return np.SKU, nil
+	}
+
+	s, err := b.generateSKU(ctx, tenantID, np.Name)
+	if err != nil {
+		return sku.SKU{}, fmt.Errorf("generatesku: %w", err)
+	}
+
+	return s, nil
+}
+
+// generateSKU returns a sku for the product that no product of the tenant
+// uses yet, deleted products included. A sku found in use is generated
+// again, up to maxSKUAttempts times.
+func (b *Business) generateSKU(ctx context.Context, tenantID uuid.UUID, nme name.Name) (sku.SKU, error) {
+	next := func() (int64, error) {
+		return b.storer.NextSKUSequence(ctx)
+	}
+
+	for range maxSKUAttempts {
+		s, err := b.skuPattern.generate(nme, next)
+		if err != nil {
+			return sku.SKU{}, fmt.Errorf("generate: %w", err)
+		}
+
+		exists, err := b.storer.SKUExists(ctx, tenantID, s)
+		if err != nil {
+			return sku.SKU{}, fmt.Errorf("skuexists: %w", err)
+		}
+
+		if !exists {
+			return s, nil
+		}
+	}
+
+	return sku.SKU{}, ErrSKUExhausted
+}
@@ -0,0 +1,153 @@
+package productbus_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/google/uuid"
+)
+
+// skuStore hands out a sequence and reports the skus of the created
+// products, and the ones listed in taken, as in use.
+type skuStore struct {
+	productbus.Storer
+	seq     int64
+	taken   map[string]bool
+	created []sku.SKU
+}
+
+func (s *skuStore) Create(ctx context.Context, prd productbus.Product) error {
+	s.taken[prd.SKU.String()] = true
+	s.created = append(s.created, prd.SKU)
+	return nil
+}
+
+func (s *skuStore) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error) {
+	return s.taken[sku.String()], nil
+}
+
+func (s *skuStore) NextSKUSequence(ctx context.Context) (int64, error) {
+	s.seq++
+	return s.seq, nil
+}
+
+func Test_ParseSKUPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   bool
+	}{
+		{pattern: productbus.DefaultSKUPattern, valid: true},
+		{pattern: "PRD-{seq:6}", valid: true},
+		{pattern: "{name}-{rand:4}", valid: true},
+		{pattern: "{seq}", valid: true},
+		{pattern: "PRD-{name}", valid: false},
+		{pattern: "PRD-1", valid: false},
+		{pattern: "prd-{seq}", valid: false},
+		{pattern: "PRD_{seq}", valid: false},
+		{pattern: "PRD-{uuid}", valid: false},
+		{pattern: "PRD-{seq:0}", valid: false},
+		{pattern: "PRD-{rand:27}", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := productbus.ParseSKUPattern(tt.pattern)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Should get back valid %t, got err %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func Test_GenerateSKU(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		taken   []string
+		expSKUs []*regexp.Regexp
+	}{
+		{
+			name:    "sequence",
+			pattern: "PRD-{seq:4}",
+			expSKUs: []*regexp.Regexp{regexp.MustCompile(`^PRD-0001$`), regexp.MustCompile(`^PRD-0002$`)},
+		},
+		{
+			name:    "sequence-taken",
+			pattern: "PRD-{seq:4}",
+			taken:   []string{"PRD-0001", "PRD-0002"},
+			expSKUs: []*regexp.Regexp{regexp.MustCompile(`^PRD-0003$`), regexp.MustCompile(`^PRD-0004$`)},
+		},
+		{
+			name:    "name-random",
+			pattern: "{name:10}-{rand:4}",
+			expSKUs: []*regexp.Regexp{regexp.MustCompile(`^ELEC-GUITA-[A-Z2-7]{4}$`), regexp.MustCompile(`^ELEC-GUITA-[A-Z2-7]{4}$`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := skuStore{taken: make(map[string]bool)}
+			for _, s := range tt.taken {
+				store.taken[s] = true
+			}
+
+			bus := productbus.NewBusiness(nil, enabledUsers{}, nil, &store, productbus.WithSKUPattern(productbus.MustParseSKUPattern(tt.pattern)))
+
+			ctx := tenant.Set(context.Background(), uuid.New())
+
+			for range tt.expSKUs {
+				if _, err := bus.Create(ctx, productbus.NewProduct{Name: name.MustParse("Elec Guitar")}); err != nil {
+					t.Fatalf("Should be able to create the product: %s", err)
+				}
+			}
+
+			for i, exp := range tt.expSKUs {
+				if !exp.MatchString(store.created[i].String()) {
+					t.Errorf("Should generate a sku matching %s, got %s", exp, store.created[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_GenerateSKUKeepsGiven(t *testing.T) {
+	store := skuStore{taken: make(map[string]bool)}
+	bus := productbus.NewBusiness(nil, enabledUsers{}, nil, &store)
+
+	ctx := tenant.Set(context.Background(), uuid.New())
+
+	prd, err := bus.Create(ctx, productbus.NewProduct{SKU: sku.MustParse("GTR-1"), Name: name.MustParse("Guitar")})
+	if err != nil {
+		t.Fatalf("Should be able to create the product: %s", err)
+	}
+
+	if prd.SKU.String() != "GTR-1" {
+		t.Fatalf("Should keep the given sku, got %s", prd.SKU)
+	}
+
+	if store.seq != 0 {
+		t.Fatalf("Should not use the sequence, got %d", store.seq)
+	}
+}
+
+func Test_GenerateSKUExhausted(t *testing.T) {
+	store := skuStore{taken: make(map[string]bool)}
+	bus := productbus.NewBusiness(nil, enabledUsers{}, nil, &store, productbus.WithSKUPattern(productbus.MustParseSKUPattern("SKU{rand:1}")))
+
+	for _, c := range "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567" {
+		store.taken["SKU"+string(c)] = true
+	}
+
+	ctx := tenant.Set(context.Background(), uuid.New())
+
+	_, err := bus.Create(ctx, productbus.NewProduct{Name: name.MustParse("Guitar")})
+	if !errors.Is(err, productbus.ErrSKUExhausted) {
+		t.Fatalf("Should get back %v, got %v", productbus.ErrSKUExhausted, err)
+	}
+}
@@ -0,0 +1,392 @@
+// Package productbreaker contains product related CRUD functionality that
+// fails fast while the database is failing.
+//
+// Every call to the store goes through a circuit breaker. Once the error rate
+// of the calls opens the breaker, calls fail with breaker.ErrOpen without
+// reaching the database until a probe finds it recovered. Errors reporting
+// the outcome of a call, like a product that isn't found, and canceled calls
+// aren't failures.
+package productbreaker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/foundation/breaker"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for product data access through a circuit
+// breaker.
+type Store struct {
+	storer  productbus.Storer
+	breaker *breaker.Breaker
+}
+
+// NewStore constructs the api for data access through the breaker.
+func NewStore(storer productbus.Storer, breaker *breaker.Breaker) *Store {
+	return &Store{
+		storer:  storer,
+		breaker: breaker,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the wrapped store with
+// its transactional version. The calls made in the transaction go through
+// the same breaker.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:  storer,
+		breaker: s.breaker,
+	}
+
+	return &store, nil
+}
+
+// Create adds a Product.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	return s.do(func() error {
+		return s.storer.Create(ctx, prd)
+	})
+}
+
+// CreateUniqueName adds a Product unless its name is already in use.
+func (s *Store) CreateUniqueName(ctx context.Context, prd productbus.Product) error {
+	return s.do(func() error {
+		return s.storer.CreateUniqueName(ctx, prd)
+	})
+}
+
+// Update modifies data about a product.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	return s.do(func() error {
+		return s.storer.Update(ctx, prd, version)
+	})
+}
+
+// Upsert adds the product or applies it to the product it conflicts with.
+func (s *Store) Upsert(ctx context.Context, prd productbus.Product, target productbus.ConflictTarget) (productbus.Upserted, error) {
+	return call(s, func() (productbus.Upserted, error) {
+		return s.storer.Upsert(ctx, prd, target)
+	})
+}
+
+// Delete marks the product as deleted.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	return s.do(func() error {
+		return s.storer.Delete(ctx, prd)
+	})
+}
+
+// DeleteByFilter marks every product matching the filter as deleted.
+func (s *Store) DeleteByFilter(ctx context.Context, filter productbus.QueryFilter, now time.Time) ([]productbus.Product, error) {
+	return call(s, func() ([]productbus.Product, error) {
+		return s.storer.DeleteByFilter(ctx, filter, now)
+	})
+}
+
+// AdjustPriceByFilter adjusts the cost of every product matching the filter.
+func (s *Store) AdjustPriceByFilter(ctx context.Context, filter productbus.QueryFilter, adj productbus.PriceAdjustment, now time.Time) ([]productbus.PriceAdjusted, error) {
+	return call(s, func() ([]productbus.PriceAdjusted, error) {
+		return s.storer.AdjustPriceByFilter(ctx, filter, adj, now)
+	})
+}
+
+// AdjustStock changes the quantity of the product by delta.
+func (s *Store) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (productbus.Product, error) {
+	return call(s, func() (productbus.Product, error) {
+		return s.storer.AdjustStock(ctx, tenantID, productID, delta, now)
+	})
+}
+
+// Touch sets the update date of the product to now.
+func (s *Store) Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (productbus.Product, error) {
+	return call(s, func() (productbus.Product, error) {
+		return s.storer.Touch(ctx, tenantID, productID, now)
+	})
+}
+
+// Query retrieves a list of existing products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) ([]productbus.Product, error) {
+	return call(s, func() ([]productbus.Product, error) {
+		return s.storer.Query(ctx, filter, orderBy, page)
+	})
+}
+
+// QueryByCursor retrieves the window of products that follow the cursor.
+func (s *Store) QueryByCursor(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, rows int) ([]productbus.Product, error) {
+	return call(s, func() ([]productbus.Product, error) {
+		return s.storer.QueryByCursor(ctx, filter, cursor, rows)
+	})
+}
+
+// Count returns the number of products matching the filter.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	return call(s, func() (int, error) {
+		return s.storer.Count(ctx, filter)
+	})
+}
+
+// Summarize returns the totals of the products matching the filter.
+func (s *Store) Summarize(ctx context.Context, filter productbus.QueryFilter) (productbus.Summary, error) {
+	return call(s, func() (productbus.Summary, error) {
+		return s.storer.Summarize(ctx, filter)
+	})
+}
+
+// Search retrieves the products matching the full text query.
+func (s *Store) Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) ([]productbus.SearchResult, error) {
+	return call(s, func() ([]productbus.SearchResult, error) {
+		return s.storer.Search(ctx, tenantID, query, page)
+	})
+}
+
+// SearchCount returns the number of products matching the full text query.
+func (s *Store) SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (int, error) {
+	return call(s, func() (int, error) {
+		return s.storer.SearchCount(ctx, tenantID, query)
+	})
+}
+
+// FuzzySearch retrieves the products whose name is similar to the query.
+func (s *Store) FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) ([]productbus.SearchResult, error) {
+	return call(s, func() ([]productbus.SearchResult, error) {
+		return s.storer.FuzzySearch(ctx, tenantID, query, threshold, page)
+	})
+}
+
+// FuzzySearchCount returns the number of products whose name is similar to
+// the query.
+func (s *Store) FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (int, error) {
+	return call(s, func() (int, error) {
+		return s.storer.FuzzySearchCount(ctx, tenantID, query, threshold)
+	})
+}
+
+// QueryByID finds the product identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	return call(s, func() (productbus.Product, error) {
+		return s.storer.QueryByID(ctx, tenantID, productID)
+	})
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (productbus.Product, error) {
+	return call(s, func() (productbus.Product, error) {
+		return s.storer.QueryBySKU(ctx, tenantID, sku)
+	})
+}
+
+// NameExists reports whether a product with the name exists.
+func (s *Store) NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (bool, error) {
+	return call(s, func() (bool, error) {
+		return s.storer.NameExists(ctx, tenantID, name)
+	})
+}
+
+// SKUExists reports whether a product uses the sku.
+func (s *Store) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error) {
+	return call(s, func() (bool, error) {
+		return s.storer.SKUExists(ctx, tenantID, sku)
+	})
+}
+
+// NextSKUSequence returns the next value of the sku sequence.
+func (s *Store) NextSKUSequence(ctx context.Context) (int64, error) {
+	return call(s, func() (int64, error) {
+		return s.storer.NextSKUSequence(ctx)
+	})
+}
+
+// QueryByIDs finds the products identified by the given IDs.
+func (s *Store) QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]productbus.Product, error) {
+	return call(s, func() ([]productbus.Product, error) {
+		return s.storer.QueryByIDs(ctx, tenantID, productIDs)
+	})
+}
+
+// QueryByUserID finds the products of a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]productbus.Product, error) {
+	return call(s, func() ([]productbus.Product, error) {
+		return s.storer.QueryByUserID(ctx, tenantID, userID)
+	})
+}
+
+// CreatePriceChange records a change of cost.
+func (s *Store) CreatePriceChange(ctx context.Context, pc productbus.PriceChange) error {
+	return s.do(func() error {
+		return s.storer.CreatePriceChange(ctx, pc)
+	})
+}
+
+// QueryPriceHistory retrieves the cost changes of a product.
+func (s *Store) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]productbus.PriceChange, error) {
+	return call(s, func() ([]productbus.PriceChange, error) {
+		return s.storer.QueryPriceHistory(ctx, productID, page)
+	})
+}
+
+// CountPriceHistory returns the number of cost changes of a product.
+func (s *Store) CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error) {
+	return call(s, func() (int, error) {
+		return s.storer.CountPriceHistory(ctx, productID)
+	})
+}
+
+// QuerySimilar retrieves the products of the same category as the product
+// whose cost is within the band of its cost.
+func (s *Store) QuerySimilar(ctx context.Context, prd productbus.Product, band float64, page page.Page) ([]productbus.Product, error) {
+	return call(s, func() ([]productbus.Product, error) {
+		return s.storer.QuerySimilar(ctx, prd, band, page)
+	})
+}
+
+// CountSimilar returns the number of products of the same category as the
+// product whose cost is within the band of its cost.
+func (s *Store) CountSimilar(ctx context.Context, prd productbus.Product, band float64) (int, error) {
+	return call(s, func() (int, error) {
+		return s.storer.CountSimilar(ctx, prd, band)
+	})
+}
+
+// QueryIdempotencyKey finds the product created with the key.
+func (s *Store) QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (uuid.UUID, error) {
+	return call(s, func() (uuid.UUID, error) {
+		return s.storer.QueryIdempotencyKey(ctx, userID, key, since)
+	})
+}
+
+// CreateIdempotencyKey records the key of a create.
+func (s *Store) CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) error {
+	return s.do(func() error {
+		return s.storer.CreateIdempotencyKey(ctx, userID, key, productID, now, since)
+	})
+}
+
+// RecordView records that the user viewed the product.
+func (s *Store) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) error {
+	return s.do(func() error {
+		return s.storer.RecordView(ctx, userID, productID, now, keep)
+	})
+}
+
+// QueryRecentlyViewed retrieves the products the user viewed most recently.
+func (s *Store) QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]productbus.Product, error) {
+	return call(s, func() ([]productbus.Product, error) {
+		return s.storer.QueryRecentlyViewed(ctx, tenantID, userID, limit)
+	})
+}
+
+// QueryPopular retrieves the products of the tenant that were viewed, the
+// most viewed first.
+func (s *Store) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]productbus.Popular, error) {
+	return call(s, func() ([]productbus.Popular, error) {
+		return s.storer.QueryPopular(ctx, tenantID, live, page)
+	})
+}
+
+// CountPopular returns the number of products QueryPopular ranks.
+func (s *Store) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error) {
+	return call(s, func() (int, error) {
+		return s.storer.CountPopular(ctx, tenantID, live)
+	})
+}
+
+// RefreshPopular recounts the views of the popular products.
+func (s *Store) RefreshPopular(ctx context.Context, now time.Time) error {
+	return s.do(func() error {
+		return s.storer.RefreshPopular(ctx, now)
+	})
+}
+
+// PopularRefreshed returns when the popular products were last refreshed.
+func (s *Store) PopularRefreshed(ctx context.Context) (time.Time, error) {
+	return call(s, func() (time.Time, error) {
+		return s.storer.PopularRefreshed(ctx)
+	})
+}
+
+// SetPendingImage records the image being uploaded for the product.
+func (s *Store) SetPendingImage(ctx context.Context, prd productbus.Product, key string) error {
+	return s.do(func() error {
+		return s.storer.SetPendingImage(ctx, prd, key)
+	})
+}
+
+// ConfirmImage makes the pending image the image of the product.
+func (s *Store) ConfirmImage(ctx context.Context, prd productbus.Product, key string, url string, now time.Time) (productbus.Product, error) {
+	return call(s, func() (productbus.Product, error) {
+		return s.storer.ConfirmImage(ctx, prd, key, url, now)
+	})
+}
+
+// WithSavepoint runs fn inside a savepoint. The operations fn runs go through
+// the breaker on their own.
+func (s *Store) WithSavepoint(ctx context.Context, fn func() error) error {
+	return s.storer.WithSavepoint(ctx, fn)
+}
+
+// =============================================================================
+
+// outcomes are the errors reporting the outcome of a call rather than a
+// failing database.
+var outcomes = []error{
+	productbus.ErrNotFound,
+	productbus.ErrInvalidCost,
+	productbus.ErrVersionConflict,
+	productbus.ErrInsufficientStock,
+	productbus.ErrIdempotencyKeyInUse,
+	productbus.ErrCategoryNotFound,
+	productbus.ErrDuplicateSKU,
+	productbus.ErrDuplicateName,
+	context.Canceled,
+}
+
+// isFailure reports whether the error counts against the breaker.
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, target := range outcomes {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// do makes the call when the breaker allows it and records its outcome.
+func (s *Store) do(fn func() error) error {
+	_, err := call(s, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+
+	return err
+}
+
+// call makes the call when the breaker allows it and records its outcome.
+func call[T any](s *Store, fn func() (T, error)) (T, error) {
+	if err := s.breaker.Allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	v, err := fn()
+	s.breaker.Record(isFailure(err))
+
+	return v, err
+}
@@ -0,0 +1,105 @@
+package productbreaker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productbreaker"
+	"github.com/ardanlabs/service/foundation/breaker"
+	"github.com/google/uuid"
+)
+
+// failingStore fails its queries while failing is set. Only the methods used
+// by the test are implemented.
+type failingStore struct {
+	productbus.Storer
+	failing bool
+	calls   int
+}
+
+var errDB = errors.New("connection refused")
+
+func (s *failingStore) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	s.calls++
+
+	if s.failing {
+		return productbus.Product{}, fmt.Errorf("db: %w", errDB)
+	}
+
+	return productbus.Product{ID: productID}, nil
+}
+
+func (s *failingStore) Delete(ctx context.Context, prd productbus.Product) error {
+	s.calls++
+	return fmt.Errorf("db: %w", productbus.ErrNotFound)
+}
+
+func Test_Breaker(t *testing.T) {
+	fake := failingStore{failing: true}
+	store := productbreaker.NewStore(&fake, breaker.New(breaker.Policy{
+		ErrorRate: 0.5,
+		MinCalls:  3,
+		Window:    time.Minute,
+		Cooldown:  50 * time.Millisecond,
+	}))
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	for range 3 {
+		if _, err := store.QueryByID(ctx, uuid.Nil, id); !errors.Is(err, errDB) {
+			t.Fatalf("Should get back the error of the store, got %v", err)
+		}
+	}
+
+	if _, err := store.QueryByID(ctx, uuid.Nil, id); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("Should fail fast once the breaker is open, got %v", err)
+	}
+
+	if fake.calls != 3 {
+		t.Fatalf("Should not reach the store while the breaker is open, got %d calls", fake.calls)
+	}
+
+	fake.failing = false
+	time.Sleep(60 * time.Millisecond)
+
+	prd, err := store.QueryByID(ctx, uuid.Nil, id)
+	if err != nil {
+		t.Fatalf("Should probe the store once the cooldown is over: %s", err)
+	}
+
+	if prd.ID != id {
+		t.Fatalf("Should get back the product of the store, got %s", prd.ID)
+	}
+
+	for range 5 {
+		if _, err := store.QueryByID(ctx, uuid.Nil, id); err != nil {
+			t.Fatalf("Should be closed once the probe succeeded: %s", err)
+		}
+	}
+}
+
+func Test_BreakerOutcomes(t *testing.T) {
+	fake := failingStore{}
+	store := productbreaker.NewStore(&fake, breaker.New(breaker.Policy{
+		ErrorRate: 0.5,
+		MinCalls:  1,
+		Window:    time.Minute,
+	}))
+
+	ctx := context.Background()
+
+	for range 5 {
+		if err := store.Delete(ctx, productbus.Product{}); !errors.Is(err, productbus.ErrNotFound) {
+			t.Fatalf("Should get back the error of the store, got %v", err)
+		}
+	}
+
+	if fake.calls != 5 {
+		t.Fatalf("Should not count a product that isn't found as a failure, got %d calls", fake.calls)
+	}
+}
@@ -0,0 +1,477 @@
+// Package productcache contains product related CRUD functionality with a
+// read-through cache of the products looked up by ID.
+//
+// A write invalidates the cached product once the statement succeeds, which
+// for a write made inside a transaction is before the transaction commits.
+// A lookup or a background refresh racing with that transaction can cache
+// the old product again, so a product can be stale for up to the hard TTL
+// of the policy.
+//
+// With a soft TTL set, a product cached for longer than the soft TTL is
+// still served while a background refresh replaces it. Past the hard TTL the
+// product is looked up again before the lookup returns.
+package productcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/google/uuid"
+	"github.com/viccon/sturdyc"
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshTimeout bounds how long a background refresh can take.
+const refreshTimeout = 5 * time.Second
+
+// Entry is a cached product along with the time it was cached.
+type Entry struct {
+	Product  productbus.Product
+	CachedAt time.Time
+}
+
+// Cache is the storage behind the store. Caching is best effort so an
+// implementation backed by a remote cache like Redis reports a failed read
+// as a miss and drops failed writes.
+type Cache interface {
+	Get(ctx context.Context, key string) (Entry, bool)
+	Set(ctx context.Context, key string, entry Entry)
+	Delete(ctx context.Context, key string)
+}
+
+// Policy defines how long a cached product is served.
+type Policy struct {
+	// SoftTTL is the age after which a cached product is refreshed in the
+	// background while still being served. Products are only refreshed
+	// once they expire when it's zero or not below the hard TTL.
+	SoftTTL time.Duration
+
+	// HardTTL is the age after which a cached product is no longer served.
+	// Products are served for as long as the cache holds them when it's
+	// zero.
+	HardTTL time.Duration
+}
+
+func (p Policy) staleWhileRevalidate() bool {
+	return p.SoftTTL > 0 && (p.HardTTL <= 0 || p.SoftTTL < p.HardTTL)
+}
+
+// =============================================================================
+
+// Memory is an in memory Cache. Products expire after the TTL and the
+// oldest entries are evicted once the cache is full.
+type Memory struct {
+	client *sturdyc.Client[Entry]
+}
+
+// NewMemory constructs an in memory cache holding up to size products.
+func NewMemory(size int, ttl time.Duration) *Memory {
+	const numShards = 10
+	const evictionPercentage = 10
+
+	return &Memory{
+		client: sturdyc.New[Entry](size, numShards, ttl, evictionPercentage),
+	}
+}
+
+// Get implements the Cache interface.
+func (m *Memory) Get(ctx context.Context, key string) (Entry, bool) {
+	return m.client.Get(key)
+}
+
+// Set implements the Cache interface.
+func (m *Memory) Set(ctx context.Context, key string, entry Entry) {
+	m.client.Set(key, entry)
+}
+
+// Delete implements the Cache interface.
+func (m *Memory) Delete(ctx context.Context, key string) {
+	m.client.Delete(key)
+}
+
+// =============================================================================
+
+// Store manages the set of APIs for product data and caching.
+type Store struct {
+	storer productbus.Storer
+	cache  Cache
+	policy Policy
+
+	// refreshes collapses the background refreshes of a product so a burst
+	// of stale hits triggers a single lookup.
+	refreshes *singleflight.Group
+
+	// inTx is set for the stores of transactions, which invalidate the
+	// products they write but always read from the database.
+	inTx bool
+}
+
+// NewStore constructs the api for data and caching access.
+func NewStore(storer productbus.Storer, cache Cache, policy Policy) *Store {
+	return &Store{
+		storer:    storer,
+		cache:     cache,
+		policy:    policy,
+		refreshes: &singleflight.Group{},
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB value with a
+// sqlx DB value that is currently inside a transaction. The store doesn't
+// read from the cache since the transaction has to see its own writes.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:    storer,
+		cache:     s.cache,
+		policy:    s.policy,
+		refreshes: s.refreshes,
+		inTx:      true,
+	}
+
+	return &store, nil
+}
+
+// QueryByID finds the product identified by a given ID, from the cache when
+// it holds the product. A product past the soft TTL is served while it's
+// refreshed in the background.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	if s.inTx {
+		return s.storer.QueryByID(ctx, tenantID, productID)
+	}
+
+	// Product IDs are unique across tenants so the tenant is checked on the
+	// cached product instead of being part of the key.
+	entry, exists := s.cache.Get(ctx, productID.String())
+	if exists && entry.Product.TenantID == tenantID {
+		age := time.Since(entry.CachedAt)
+
+		switch {
+		case s.policy.HardTTL > 0 && age >= s.policy.HardTTL:
+			// Too old to be served, looked up below.
+
+		case s.policy.staleWhileRevalidate() && age >= s.policy.SoftTTL:
+			s.refresh(ctx, tenantID, productID)
+			return entry.Product, nil
+
+		default:
+			return entry.Product, nil
+		}
+	}
+
+	prd, err := s.storer.QueryByID(ctx, tenantID, productID)
+	if err != nil {
+		return productbus.Product{}, err
+	}
+
+	s.cache.Set(ctx, productID.String(), Entry{Product: prd, CachedAt: time.Now()})
+
+	return prd, nil
+}
+
+// refresh looks the product up again in the background and caches the
+// result. Only one refresh of a product runs at a time. A product that no
+// longer exists is removed from the cache, any other failure leaves the
+// cached product to be served until the hard TTL.
+func (s *Store) refresh(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) {
+	ctx = context.WithoutCancel(ctx)
+
+	// The result is delivered on a buffered channel nobody reads, so only
+	// the first caller starts a lookup and nothing blocks.
+	s.refreshes.DoChan(productID.String(), func() (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+		defer cancel()
+
+		prd, err := s.storer.QueryByID(ctx, tenantID, productID)
+		switch {
+		case err == nil:
+			s.cache.Set(ctx, productID.String(), Entry{Product: prd, CachedAt: time.Now()})
+		case errors.Is(err, productbus.ErrNotFound):
+			s.invalidate(ctx, productID)
+		}
+
+		return nil, err
+	})
+}
+
+// Update modifies data about a product and invalidates the cached product.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	if err := s.storer.Update(ctx, prd, version); err != nil {
+		return err
+	}
+
+	s.invalidate(ctx, prd.ID)
+
+	return nil
+}
+
+// Upsert adds the product or applies it to the product it conflicts with and
+// invalidates the cached product.
+func (s *Store) Upsert(ctx context.Context, prd productbus.Product, target productbus.ConflictTarget) (productbus.Upserted, error) {
+	res, err := s.storer.Upsert(ctx, prd, target)
+	if err != nil {
+		return productbus.Upserted{}, err
+	}
+
+	s.invalidate(ctx, res.Product.ID)
+
+	return res, nil
+}
+
+// Delete marks the product as deleted and invalidates the cached product.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	if err := s.storer.Delete(ctx, prd); err != nil {
+		return err
+	}
+
+	s.invalidate(ctx, prd.ID)
+
+	return nil
+}
+
+// DeleteByFilter marks every product matching the filter as deleted and
+// invalidates the cached products.
+func (s *Store) DeleteByFilter(ctx context.Context, filter productbus.QueryFilter, now time.Time) ([]productbus.Product, error) {
+	prds, err := s.storer.DeleteByFilter(ctx, filter, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, prd := range prds {
+		s.invalidate(ctx, prd.ID)
+	}
+
+	return prds, nil
+}
+
+// AdjustPriceByFilter adjusts the cost of every product matching the filter
+// and invalidates the cached products.
+func (s *Store) AdjustPriceByFilter(ctx context.Context, filter productbus.QueryFilter, adj productbus.PriceAdjustment, now time.Time) ([]productbus.PriceAdjusted, error) {
+	adjusted, err := s.storer.AdjustPriceByFilter(ctx, filter, adj, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pa := range adjusted {
+		s.invalidate(ctx, pa.Product.ID)
+	}
+
+	return adjusted, nil
+}
+
+// AdjustStock changes the quantity of the product by delta and invalidates
+// the cached product.
+func (s *Store) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (productbus.Product, error) {
+	prd, err := s.storer.AdjustStock(ctx, tenantID, productID, delta, now)
+	if err != nil {
+		return productbus.Product{}, err
+	}
+
+	s.invalidate(ctx, productID)
+
+	return prd, nil
+}
+
+// Touch sets the update date of the product to now and invalidates the
+// cached product.
+func (s *Store) Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (productbus.Product, error) {
+	prd, err := s.storer.Touch(ctx, tenantID, productID, now)
+	if err != nil {
+		return productbus.Product{}, err
+	}
+
+	s.invalidate(ctx, productID)
+
+	return prd, nil
+}
+
+// Create adds a Product.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	return s.storer.Create(ctx, prd)
+}
+
+// CreateUniqueName adds a Product unless its name is already in use.
+func (s *Store) CreateUniqueName(ctx context.Context, prd productbus.Product) error {
+	return s.storer.CreateUniqueName(ctx, prd)
+}
+
+// Query retrieves a list of existing products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) ([]productbus.Product, error) {
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByCursor retrieves the window of products that follow the cursor.
+func (s *Store) QueryByCursor(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, rows int) ([]productbus.Product, error) {
+	return s.storer.QueryByCursor(ctx, filter, cursor, rows)
+}
+
+// Count returns the number of products matching the filter.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// Summarize returns the totals of the products matching the filter.
+func (s *Store) Summarize(ctx context.Context, filter productbus.QueryFilter) (productbus.Summary, error) {
+	return s.storer.Summarize(ctx, filter)
+}
+
+// Search retrieves the products matching the full text query.
+func (s *Store) Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) ([]productbus.SearchResult, error) {
+	return s.storer.Search(ctx, tenantID, query, page)
+}
+
+// SearchCount returns the number of products matching the full text query.
+func (s *Store) SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (int, error) {
+	return s.storer.SearchCount(ctx, tenantID, query)
+}
+
+// FuzzySearch retrieves the products whose name is similar to the query.
+func (s *Store) FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) ([]productbus.SearchResult, error) {
+	return s.storer.FuzzySearch(ctx, tenantID, query, threshold, page)
+}
+
+// FuzzySearchCount returns the number of products whose name is similar to
+// the query.
+func (s *Store) FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (int, error) {
+	return s.storer.FuzzySearchCount(ctx, tenantID, query, threshold)
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (productbus.Product, error) {
+	return s.storer.QueryBySKU(ctx, tenantID, sku)
+}
+
+// NameExists reports whether a product with the name exists.
+func (s *Store) NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (bool, error) {
+	return s.storer.NameExists(ctx, tenantID, name)
+}
+
+// SKUExists reports whether a product uses the sku.
+func (s *Store) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error) {
+	return s.storer.SKUExists(ctx, tenantID, sku)
+}
+
+// NextSKUSequence returns the next value of the sku sequence.
+func (s *Store) NextSKUSequence(ctx context.Context) (int64, error) {
+	return s.storer.NextSKUSequence(ctx)
+}
+
+// QueryByIDs finds the products identified by the given IDs.
+func (s *Store) QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]productbus.Product, error) {
+	return s.storer.QueryByIDs(ctx, tenantID, productIDs)
+}
+
+// QueryByUserID finds the products of a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]productbus.Product, error) {
+	return s.storer.QueryByUserID(ctx, tenantID, userID)
+}
+
+// CreatePriceChange records a change of cost.
+func (s *Store) CreatePriceChange(ctx context.Context, pc productbus.PriceChange) error {
+	return s.storer.CreatePriceChange(ctx, pc)
+}
+
+// QueryPriceHistory retrieves the cost changes of a product.
+func (s *Store) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]productbus.PriceChange, error) {
+	return s.storer.QueryPriceHistory(ctx, productID, page)
+}
+
+// CountPriceHistory returns the number of cost changes of a product.
+func (s *Store) CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error) {
+	return s.storer.CountPriceHistory(ctx, productID)
+}
+
+// QuerySimilar retrieves the products of the same category as the product
+// whose cost is within the band of its cost.
+func (s *Store) QuerySimilar(ctx context.Context, prd productbus.Product, band float64, page page.Page) ([]productbus.Product, error) {
+	return s.storer.QuerySimilar(ctx, prd, band, page)
+}
+
+// CountSimilar returns the number of products of the same category as the
+// product whose cost is within the band of its cost.
+func (s *Store) CountSimilar(ctx context.Context, prd productbus.Product, band float64) (int, error) {
+	return s.storer.CountSimilar(ctx, prd, band)
+}
+
+// QueryIdempotencyKey returns the product created with the key.
+func (s *Store) QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (uuid.UUID, error) {
+	return s.storer.QueryIdempotencyKey(ctx, userID, key, since)
+}
+
+// CreateIdempotencyKey records the key of a create.
+func (s *Store) CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) error {
+	return s.storer.CreateIdempotencyKey(ctx, userID, key, productID, now, since)
+}
+
+// RecordView records that the user viewed the product.
+func (s *Store) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) error {
+	return s.storer.RecordView(ctx, userID, productID, now, keep)
+}
+
+// QueryRecentlyViewed retrieves the products the user viewed most recently.
+func (s *Store) QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]productbus.Product, error) {
+	return s.storer.QueryRecentlyViewed(ctx, tenantID, userID, limit)
+}
+
+// QueryPopular retrieves the products of the tenant that were viewed, the
+// most viewed first.
+func (s *Store) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]productbus.Popular, error) {
+	return s.storer.QueryPopular(ctx, tenantID, live, page)
+}
+
+// CountPopular returns the number of products QueryPopular ranks.
+func (s *Store) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error) {
+	return s.storer.CountPopular(ctx, tenantID, live)
+}
+
+// RefreshPopular recounts the views of the popular products.
+func (s *Store) RefreshPopular(ctx context.Context, now time.Time) error {
+	return s.storer.RefreshPopular(ctx, now)
+}
+
+// PopularRefreshed returns when the popular products were last refreshed.
+func (s *Store) PopularRefreshed(ctx context.Context) (time.Time, error) {
+	return s.storer.PopularRefreshed(ctx)
+}
+
+// SetPendingImage records the image being uploaded for the product. The
+// cached product stays valid since the pending image isn't part of it.
+func (s *Store) SetPendingImage(ctx context.Context, prd productbus.Product, key string) error {
+	return s.storer.SetPendingImage(ctx, prd, key)
+}
+
+// ConfirmImage makes the pending image the image of the product and
+// invalidates the cached product.
+func (s *Store) ConfirmImage(ctx context.Context, prd productbus.Product, key string, url string, now time.Time) (productbus.Product, error) {
+	prd, err := s.storer.ConfirmImage(ctx, prd, key, url, now)
+	if err != nil {
+		return productbus.Product{}, err
+	}
+
+	s.invalidate(ctx, prd.ID)
+
+	return prd, nil
+}
+
+// WithSavepoint runs fn inside a savepoint. Products written by fn are
+// invalidated even when the savepoint is rolled back, which only costs a
+// cache miss.
+func (s *Store) WithSavepoint(ctx context.Context, fn func() error) error {
+	return s.storer.WithSavepoint(ctx, fn)
+}
+
+// invalidate removes the product from the cache.
+func (s *Store) invalidate(ctx context.Context, productID uuid.UUID) {
+	s.cache.Delete(ctx, productID.String())
+}
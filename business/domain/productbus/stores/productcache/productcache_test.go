@@ -0,0 +1,265 @@
+package productcache_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productcache"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// fakeStore holds a single product and counts the lookups that reach it.
+type fakeStore struct {
+	productbus.Storer
+	mu      sync.Mutex
+	prd     productbus.Product
+	lookups int
+}
+
+func (s *fakeStore) setDescription(description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prd.Description = description
+}
+
+func (s *fakeStore) lookupCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lookups
+}
+
+func (s *fakeStore) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	return s, nil
+}
+
+func (s *fakeStore) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lookups++
+
+	if s.prd.ID != productID || s.prd.TenantID != tenantID {
+		return productbus.Product{}, productbus.ErrNotFound
+	}
+
+	return s.prd, nil
+}
+
+func (s *fakeStore) Update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	s.prd = prd
+	return nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, prd productbus.Product) error {
+	s.prd = productbus.Product{}
+	return nil
+}
+
+func (s *fakeStore) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (productbus.Product, error) {
+	s.prd.Description = "adjusted"
+	return s.prd, nil
+}
+
+func newStore() (*productcache.Store, *fakeStore) {
+	return newStoreWithPolicy(productcache.Policy{})
+}
+
+func newStoreWithPolicy(policy productcache.Policy) (*productcache.Store, *fakeStore) {
+	fake := fakeStore{
+		prd: productbus.Product{
+			ID:          uuid.New(),
+			TenantID:    uuid.New(),
+			Description: "original",
+		},
+	}
+
+	return productcache.NewStore(&fake, productcache.NewMemory(100, time.Minute), policy), &fake
+}
+
+func Test_ReadThrough(t *testing.T) {
+	store, fake := newStore()
+	ctx := context.Background()
+
+	for range 3 {
+		prd, err := store.QueryByID(ctx, fake.prd.TenantID, fake.prd.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query the product: %s", err)
+		}
+
+		if prd.ID != fake.prd.ID {
+			t.Fatalf("Should get back product %s, got %s", fake.prd.ID, prd.ID)
+		}
+	}
+
+	if fake.lookups != 1 {
+		t.Fatalf("Should reach the store once, got %d lookups", fake.lookups)
+	}
+
+	// The cached product isn't handed out to another tenant.
+	if _, err := store.QueryByID(ctx, uuid.New(), fake.prd.ID); err == nil {
+		t.Fatal("Should not get the product of another tenant")
+	}
+}
+
+func Test_Invalidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, store productbus.Storer, prd productbus.Product) error
+		exp   string
+	}{
+		{
+			name: "update",
+			write: func(ctx context.Context, store productbus.Storer, prd productbus.Product) error {
+				prd.Description = "updated"
+				return store.Update(ctx, prd, prd.DateUpdated)
+			},
+			exp: "updated",
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, store productbus.Storer, prd productbus.Product) error {
+				return store.Delete(ctx, prd)
+			},
+		},
+		{
+			name: "adjuststock",
+			write: func(ctx context.Context, store productbus.Storer, prd productbus.Product) error {
+				_, err := store.AdjustStock(ctx, prd.TenantID, prd.ID, 1, time.Now())
+				return err
+			},
+			exp: "adjusted",
+		},
+		{
+			name: "update-in-tx",
+			write: func(ctx context.Context, store productbus.Storer, prd productbus.Product) error {
+				txStore, err := store.NewWithTx(nil)
+				if err != nil {
+					return err
+				}
+
+				prd.Description = "updated"
+				return txStore.Update(ctx, prd, prd.DateUpdated)
+			},
+			exp: "updated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, fake := newStore()
+			ctx := context.Background()
+			prd := fake.prd
+
+			if _, err := store.QueryByID(ctx, prd.TenantID, prd.ID); err != nil {
+				t.Fatalf("Should be able to query the product: %s", err)
+			}
+
+			if err := tt.write(ctx, store, prd); err != nil {
+				t.Fatalf("Should be able to write the product: %s", err)
+			}
+
+			got, err := store.QueryByID(ctx, prd.TenantID, prd.ID)
+
+			switch tt.exp {
+			case "":
+				if err == nil {
+					t.Fatal("Should not get back the deleted product from the cache")
+				}
+
+			default:
+				if err != nil {
+					t.Fatalf("Should be able to query the product: %s", err)
+				}
+
+				if got.Description != tt.exp {
+					t.Fatalf("Should get back the written product %q, got %q", tt.exp, got.Description)
+				}
+			}
+
+			if fake.lookups != 2 {
+				t.Fatalf("Should reach the store again after the write, got %d lookups", fake.lookups)
+			}
+		})
+	}
+}
+
+func Test_StaleWhileRevalidate(t *testing.T) {
+	store, fake := newStoreWithPolicy(productcache.Policy{
+		SoftTTL: 20 * time.Millisecond,
+		HardTTL: time.Hour,
+	})
+	ctx := context.Background()
+	prd := fake.prd
+
+	if _, err := store.QueryByID(ctx, prd.TenantID, prd.ID); err != nil {
+		t.Fatalf("Should be able to query the product: %s", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	fake.setDescription("refreshed")
+
+	// The stale product is served right away while it's refreshed.
+	got, err := store.QueryByID(ctx, prd.TenantID, prd.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the product: %s", err)
+	}
+
+	if got.Description != "original" {
+		t.Fatalf("Should get back the stale product, got %q", got.Description)
+	}
+
+	// Stale hits made while the refresh is in flight share it, so the store
+	// is only reached once more however long the refresh takes.
+	deadline := time.Now().Add(time.Second)
+	for got.Description != "refreshed" {
+		if time.Now().After(deadline) {
+			t.Fatal("Should refresh the product in the background")
+		}
+		time.Sleep(time.Millisecond)
+
+		got, err = store.QueryByID(ctx, prd.TenantID, prd.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query the product: %s", err)
+		}
+	}
+
+	if lookups := fake.lookupCount(); lookups != 2 {
+		t.Fatalf("Should serve the refreshed product from the cache, got %d lookups", lookups)
+	}
+}
+
+func Test_HardTTL(t *testing.T) {
+	store, fake := newStoreWithPolicy(productcache.Policy{
+		SoftTTL: 10 * time.Millisecond,
+		HardTTL: 20 * time.Millisecond,
+	})
+	ctx := context.Background()
+	prd := fake.prd
+
+	if _, err := store.QueryByID(ctx, prd.TenantID, prd.ID); err != nil {
+		t.Fatalf("Should be able to query the product: %s", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	fake.setDescription("refreshed")
+
+	// Past the hard TTL the product is looked up before returning.
+	got, err := store.QueryByID(ctx, prd.TenantID, prd.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the product: %s", err)
+	}
+
+	if got.Description != "refreshed" {
+		t.Fatalf("Should get back the looked up product, got %q", got.Description)
+	}
+
+	if lookups := fake.lookupCount(); lookups != 2 {
+		t.Fatalf("Should reach the store again, got %d lookups", lookups)
+	}
+}
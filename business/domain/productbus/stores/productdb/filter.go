@@ -3,26 +3,58 @@ package productdb
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/ardanlabs/service/business/domain/productbus"
 )
 
 func (s *Store) applyFilter(filter productbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	writeWhere(s.filterClauses(filter, data), buf)
+}
+
+func (s *Store) filterClauses(filter productbus.QueryFilter, data map[string]any) []string {
 	var wc []string
 
+	if filter.TenantID != nil {
+		data["tenant_id"] = filter.TenantID
+		wc = append(wc, "tenant_id = :tenant_id")
+	}
+
 	if filter.ID != nil {
 		data["product_id"] = filter.ID
 		wc = append(wc, "product_id = :product_id")
 	}
 
+	if len(filter.IDs) > 0 {
+		ids := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			ids[i] = id.String()
+		}
+
+		// The ids are bound as a single array so the filter works with every
+		// query it's applied to, without expanding the query per id.
+		data["product_ids"] = "{" + strings.Join(ids, ",") + "}"
+		wc = append(wc, "product_id = ANY(CAST(:product_ids AS uuid[]))")
+	}
+
+	if filter.SKU != nil {
+		data["sku"] = filter.SKU.String()
+		wc = append(wc, "sku = :sku")
+	}
+
 	if filter.Name != nil {
 		data["name"] = fmt.Sprintf("%%%s%%", filter.Name)
 		wc = append(wc, "name LIKE :name")
 	}
 
+	if filter.NameLike != nil {
+		data["name_like"] = "%" + escapeLike(*filter.NameLike) + "%"
+		wc = append(wc, "name ILIKE :name_like ESCAPE '\\'")
+	}
+
 	if filter.Cost != nil {
-		data["cost"] = filter.Cost
+		data["cost"] = filter.Cost.String()
 		wc = append(wc, "cost = :cost")
 	}
 
@@ -31,6 +63,91 @@ func (s *Store) applyFilter(filter productbus.QueryFilter, data map[string]any,
 		wc = append(wc, "quantity = :quantity")
 	}
 
+	if filter.MinCost != nil {
+		data["min_cost"] = filter.MinCost.String()
+		wc = append(wc, "cost >= :min_cost")
+	}
+
+	if filter.MaxCost != nil {
+		data["max_cost"] = filter.MaxCost.String()
+		wc = append(wc, "cost <= :max_cost")
+	}
+
+	if filter.MinQuantity != nil {
+		data["min_quantity"] = filter.MinQuantity
+		wc = append(wc, "quantity >= :min_quantity")
+	}
+
+	if filter.MaxQuantity != nil {
+		data["max_quantity"] = filter.MaxQuantity
+		wc = append(wc, "quantity <= :max_quantity")
+	}
+
+	if filter.CategoryID != nil {
+		data["category_id"] = filter.CategoryID
+		wc = append(wc, "category_id = :category_id")
+	}
+
+	if len(filter.Tags) > 0 {
+		tags := toDBTags(filter.Tags)
+		data["filter_tags"] = tags
+
+		const tagged = `FROM product_tags AS pt JOIN tags AS t ON t.tag_id = pt.tag_id WHERE pt.product_id = products.product_id AND t.name = ANY(CAST(:filter_tags AS TEXT[]))`
+
+		switch filter.TagMatch {
+		case productbus.TagMatchAny:
+			wc = append(wc, "EXISTS (SELECT 1 "+tagged+")")
+
+		default:
+			// A product holds each tag once, so it has every tag of the filter
+			// when it has as many of them as the filter holds distinct tags.
+			data["filter_tag_count"] = len(slices.Compact(slices.Sorted(slices.Values(tags))))
+			wc = append(wc, "(SELECT count(1) "+tagged+") = :filter_tag_count")
+		}
+	}
+
+	if filter.CreatedAfter != nil {
+		data["created_after"] = filter.CreatedAfter.UTC()
+		wc = append(wc, "date_created >= :created_after")
+	}
+
+	if filter.CreatedBefore != nil {
+		data["created_before"] = filter.CreatedBefore.UTC()
+		wc = append(wc, "date_created <= :created_before")
+	}
+
+	if filter.UpdatedAfter != nil {
+		data["updated_after"] = filter.UpdatedAfter.UTC()
+		wc = append(wc, "date_updated >= :updated_after")
+	}
+
+	if filter.UpdatedBefore != nil {
+		data["updated_before"] = filter.UpdatedBefore.UTC()
+		wc = append(wc, "date_updated <= :updated_before")
+	}
+
+	// The predicate of products_active_idx is repeated as is so the planner
+	// can serve the active products from the partial index.
+	active := filter.Active != nil && *filter.Active
+	if active {
+		wc = append(wc, "quantity > 0")
+	}
+
+	if active || filter.IncludeDeleted == nil || !*filter.IncludeDeleted {
+		wc = append(wc, "date_deleted IS NULL")
+	}
+
+	return wc
+}
+
+// escapeLike escapes the characters that have a special meaning in a LIKE
+// pattern so user input is matched literally.
+func escapeLike(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(value)
+}
+
+func writeWhere(wc []string, buf *bytes.Buffer) {
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
@@ -1,47 +1,82 @@
 package productdb
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbarray"
 	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/business/types/tag"
 	"github.com/google/uuid"
 )
 
 type product struct {
-	ID          uuid.UUID `db:"product_id"`
-	UserID      uuid.UUID `db:"user_id"`
-	Name        string    `db:"name"`
-	Cost        float64   `db:"cost"`
-	Quantity    int       `db:"quantity"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
+	ID          uuid.UUID      `db:"product_id"`
+	TenantID    uuid.UUID      `db:"tenant_id"`
+	UserID      uuid.UUID      `db:"user_id"`
+	SKU         string         `db:"sku"`
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Cost        string         `db:"cost"`
+	Quantity    int            `db:"quantity"`
+	CategoryID  uuid.NullUUID  `db:"category_id"`
+	Tags        dbarray.String `db:"tags"`
+	ImageURL    sql.NullString `db:"image_url"`
+	DateCreated time.Time      `db:"date_created"`
+	DateUpdated time.Time      `db:"date_updated"`
+	DateDeleted sql.NullTime   `db:"date_deleted"`
 }
 
 func toDBProduct(bus productbus.Product) product {
 	db := product{
 		ID:          bus.ID,
+		TenantID:    bus.TenantID,
 		UserID:      bus.UserID,
+		SKU:         bus.SKU.String(),
 		Name:        bus.Name.String(),
-		Cost:        bus.Cost.Value(),
+		Description: bus.Description,
+		Cost:        bus.Cost.String(),
 		Quantity:    bus.Quantity.Value(),
+		Tags:        toDBTags(bus.Tags),
+		ImageURL:    sql.NullString{String: bus.ImageURL, Valid: bus.ImageURL != ""},
 		DateCreated: bus.DateCreated.UTC(),
 		DateUpdated: bus.DateUpdated.UTC(),
 	}
 
+	if bus.CategoryID != nil {
+		db.CategoryID = uuid.NullUUID{
+			UUID:  *bus.CategoryID,
+			Valid: true,
+		}
+	}
+
+	if bus.DateDeleted != nil {
+		db.DateDeleted = sql.NullTime{
+			Time:  bus.DateDeleted.UTC(),
+			Valid: true,
+		}
+	}
+
 	return db
 }
 
 func toBusProduct(db product) (productbus.Product, error) {
+	sku, err := sku.Parse(db.SKU)
+	if err != nil {
+		return productbus.Product{}, fmt.Errorf("parse sku: %w", err)
+	}
+
 	name, err := name.Parse(db.Name)
 	if err != nil {
 		return productbus.Product{}, fmt.Errorf("parse name: %w", err)
 	}
 
-	cost, err := money.Parse(db.Cost)
+	cost, err := money.ParseString(db.Cost)
 	if err != nil {
 		return productbus.Product{}, fmt.Errorf("parse cost: %w", err)
 	}
@@ -51,19 +86,54 @@ func toBusProduct(db product) (productbus.Product, error) {
 		return productbus.Product{}, fmt.Errorf("parse quantity: %w", err)
 	}
 
+	var tags []tag.Tag
+	for _, v := range db.Tags {
+		t, err := tag.Parse(v)
+		if err != nil {
+			return productbus.Product{}, fmt.Errorf("parse tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+
 	bus := productbus.Product{
 		ID:          db.ID,
+		TenantID:    db.TenantID,
 		UserID:      db.UserID,
+		SKU:         sku,
 		Name:        name,
+		Description: db.Description,
 		Cost:        cost,
 		Quantity:    quantity,
+		Tags:        tags,
+		ImageURL:    db.ImageURL.String,
 		DateCreated: db.DateCreated.In(time.Local),
 		DateUpdated: db.DateUpdated.In(time.Local),
 	}
 
+	if db.CategoryID.Valid {
+		categoryID := db.CategoryID.UUID
+		bus.CategoryID = &categoryID
+	}
+
+	if db.DateDeleted.Valid {
+		dateDeleted := db.DateDeleted.Time.In(time.Local)
+		bus.DateDeleted = &dateDeleted
+	}
+
 	return bus, nil
 }
 
+// toDBTags returns the names of the tags. The slice is never nil so it's
+// bound as an empty array rather than as null.
+func toDBTags(tags []tag.Tag) dbarray.String {
+	names := make(dbarray.String, len(tags))
+	for i, t := range tags {
+		names[i] = t.String()
+	}
+
+	return names
+}
+
 func toBusProducts(dbs []product) ([]productbus.Product, error) {
 	bus := make([]productbus.Product, len(dbs))
 
@@ -77,3 +147,138 @@ func toBusProducts(dbs []product) ([]productbus.Product, error) {
 
 	return bus, nil
 }
+
+type searchResult struct {
+	product
+	Rank float64 `db:"rank"`
+}
+
+func toBusSearchResults(dbs []searchResult) ([]productbus.SearchResult, error) {
+	bus := make([]productbus.SearchResult, len(dbs))
+
+	for i, db := range dbs {
+		prd, err := toBusProduct(db.product)
+		if err != nil {
+			return nil, err
+		}
+
+		bus[i] = productbus.SearchResult{
+			Product: prd,
+			Rank:    db.Rank,
+		}
+	}
+
+	return bus, nil
+}
+
+type popular struct {
+	product
+	Views int `db:"views"`
+}
+
+func toBusPopular(dbs []popular) ([]productbus.Popular, error) {
+	bus := make([]productbus.Popular, len(dbs))
+
+	for i, db := range dbs {
+		prd, err := toBusProduct(db.product)
+		if err != nil {
+			return nil, err
+		}
+
+		bus[i] = productbus.Popular{
+			Product: prd,
+			Views:   db.Views,
+		}
+	}
+
+	return bus, nil
+}
+
+type priceChange struct {
+	ID          uuid.UUID `db:"history_id"`
+	ProductID   uuid.UUID `db:"product_id"`
+	OldCost     string    `db:"old_cost"`
+	NewCost     string    `db:"new_cost"`
+	DateChanged time.Time `db:"date_changed"`
+}
+
+// priceAdjusted is a product as it is after a price adjustment along with
+// what the adjustment changed.
+type priceAdjusted struct {
+	product
+	HistoryID      uuid.UUID `db:"history_id"`
+	OldCost        string    `db:"old_cost"`
+	OldDateUpdated time.Time `db:"old_date_updated"`
+}
+
+func toBusPriceAdjusted(dbs []priceAdjusted) ([]productbus.PriceAdjusted, error) {
+	bus := make([]productbus.PriceAdjusted, len(dbs))
+
+	for i, db := range dbs {
+		prd, err := toBusProduct(db.product)
+		if err != nil {
+			return nil, err
+		}
+
+		oldCost, err := money.ParseString(db.OldCost)
+		if err != nil {
+			return nil, fmt.Errorf("parse old cost: %w", err)
+		}
+
+		before := prd
+		before.Cost = oldCost
+		before.DateUpdated = db.OldDateUpdated.In(time.Local)
+
+		bus[i] = productbus.PriceAdjusted{
+			Product: prd,
+			Before:  before,
+			Change: productbus.PriceChange{
+				ID:          db.HistoryID,
+				ProductID:   prd.ID,
+				OldCost:     oldCost,
+				NewCost:     prd.Cost,
+				DateChanged: prd.DateUpdated,
+			},
+		}
+	}
+
+	return bus, nil
+}
+
+func toDBPriceChange(bus productbus.PriceChange) priceChange {
+	db := priceChange{
+		ID:          bus.ID,
+		ProductID:   bus.ProductID,
+		OldCost:     bus.OldCost.String(),
+		NewCost:     bus.NewCost.String(),
+		DateChanged: bus.DateChanged.UTC(),
+	}
+
+	return db
+}
+
+func toBusPriceChanges(dbs []priceChange) ([]productbus.PriceChange, error) {
+	bus := make([]productbus.PriceChange, len(dbs))
+
+	for i, db := range dbs {
+		oldCost, err := money.ParseString(db.OldCost)
+		if err != nil {
+			return nil, fmt.Errorf("parse old cost: %w", err)
+		}
+
+		newCost, err := money.ParseString(db.NewCost)
+		if err != nil {
+			return nil, fmt.Errorf("parse new cost: %w", err)
+		}
+
+		bus[i] = productbus.PriceChange{
+			ID:          db.ID,
+			ProductID:   db.ProductID,
+			OldCost:     oldCost,
+			NewCost:     newCost,
+			DateChanged: db.DateChanged.In(time.Local),
+		}
+	}
+
+	return bus, nil
+}
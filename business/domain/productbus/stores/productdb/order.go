@@ -1,25 +1,116 @@
 package productdb
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/google/uuid"
 )
 
+// orderByFields maps the business order fields to the sort expressions. Names
+// are sorted without regard to case or accents so "éclair" sorts next to
+// "Eclair" and "apple" before "Zebra".
 var orderByFields = map[string]string{
-	productbus.OrderByProductID: "product_id",
-	productbus.OrderByUserID:    "user_id",
-	productbus.OrderByName:      "name",
-	productbus.OrderByCost:      "cost",
-	productbus.OrderByQuantity:  "quantity",
+	productbus.OrderByProductID:   "product_id",
+	productbus.OrderByUserID:      "user_id",
+	productbus.OrderByName:        "LOWER(unaccent(name))",
+	productbus.OrderByCost:        "cost",
+	productbus.OrderByQuantity:    "quantity",
+	productbus.OrderByDateCreated: "date_created",
+	productbus.OrderByDateUpdated: "date_updated",
 }
 
-func orderByClause(orderBy order.By) (string, error) {
-	by, exists := orderByFields[orderBy.Field]
+// orderByTerm returns the sort term of the field in the direction, followed
+// by the placement of its nulls when the client asked for one. None of the
+// fields hold nulls, so they're left without a placement by default and
+// their indexes can serve both directions.
+func orderByTerm(by string, ob order.By) string {
+	term := by + " " + ob.Direction
+	if ob.Nulls != "" {
+		term += " " + ob.Nulls
+	}
+
+	return term
+}
+
+func orderByClause(orderBy []order.By) (string, error) {
+	if len(orderBy) == 0 {
+		return "", errors.New("no order specified")
+	}
+
+	clauses := make([]string, len(orderBy))
+	for i, ob := range orderBy {
+		by, exists := orderByFields[ob.Field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", ob.Field)
+		}
+
+		clauses[i] = orderByTerm(by, ob)
+	}
+
+	return " ORDER BY " + strings.Join(clauses, ", "), nil
+}
+
+// cursorTypes provides the column type used to cast a cursor value back into
+// something the database can compare against the sort column.
+var cursorTypes = map[string]string{
+	productbus.OrderByProductID:   "UUID",
+	productbus.OrderByUserID:      "UUID",
+	productbus.OrderByName:        "TEXT",
+	productbus.OrderByCost:        "NUMERIC",
+	productbus.OrderByQuantity:    "INT",
+	productbus.OrderByDateCreated: "TIMESTAMP",
+	productbus.OrderByDateUpdated: "TIMESTAMP",
+}
+
+// cursorNormalize applies to a cursor value the same normalization the sort
+// expression applies to the column so the keyset comparison agrees with the
+// ordering.
+var cursorNormalize = map[string]string{
+	productbus.OrderByName: "LOWER(unaccent(%s))",
+}
+
+// keysetClauses returns the predicate that skips every row up to and
+// including the cursor position and the ordering that goes with it. The
+// product id is always added as a tie breaker so the window is stable.
+func keysetClauses(cursor productbus.Cursor, data map[string]any) (string, string, error) {
+	by, exists := orderByFields[cursor.OrderBy.Field]
 	if !exists {
-		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+		return "", "", fmt.Errorf("field %q does not exist", cursor.OrderBy.Field)
+	}
+
+	orderBy := " ORDER BY " + orderByTerm(by, cursor.OrderBy)
+	if by != "product_id" {
+		orderBy += ", product_id " + cursor.OrderBy.Direction
+	}
+
+	if cursor.ID == uuid.Nil {
+		return "", orderBy, nil
+	}
+
+	op := ">"
+	if cursor.OrderBy.Direction == order.DESC {
+		op = "<"
+	}
+
+	data["cursor_value"] = cursor.Value
+	data["cursor_id"] = cursor.ID
+
+	value := fmt.Sprintf("CAST(CAST(:cursor_value AS TEXT) AS %s)", cursorTypes[cursor.OrderBy.Field])
+	if norm, exists := cursorNormalize[cursor.OrderBy.Field]; exists {
+		value = fmt.Sprintf(norm, value)
+	}
+
+	var where string
+	switch by {
+	case "product_id":
+		where = fmt.Sprintf("product_id %s :cursor_id", op)
+	default:
+		where = fmt.Sprintf("(%s, product_id) %s (%s, :cursor_id)", by, op, value)
 	}
 
-	return " ORDER BY " + by + " " + orderBy.Direction, nil
+	return where, orderBy, nil
 }
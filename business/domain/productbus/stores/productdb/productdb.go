@@ -4,13 +4,19 @@ package productdb
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbarray"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -47,55 +53,328 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error)
 }
 
 // Create adds a Product to the sqldb. It returns the created Product with
-// fields like ID and DateCreated populated.
+// fields like ID and DateCreated populated. A product with tags is stored
+// along with its tags in a transaction, started when the store isn't
+// already using one.
 func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	if len(prd.Tags) == 0 {
+		return s.create(ctx, prd)
+	}
+
+	return s.withTx(ctx, func(store *Store) error {
+		if err := store.create(ctx, prd); err != nil {
+			return err
+		}
+
+		return store.setTags(ctx, prd)
+	})
+}
+
+func (s *Store) create(ctx context.Context, prd productbus.Product) error {
 	const q = `
 	INSERT INTO products
-		(product_id, user_id, name, cost, quantity, date_created, date_updated)
+		(product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted)
 	VALUES
-		(:product_id, :user_id, :name, :cost, :quantity, :date_created, :date_updated)`
+		(:product_id, :tenant_id, :user_id, :sku, :name, :description, :cost, :quantity, :category_id, :image_url, :date_created, :date_updated, :date_deleted)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", productbus.ErrDuplicateSKU)
+		}
+		if errors.Is(err, sqldb.ErrDBForeignKey) {
+			return fmt.Errorf("namedexeccontext: %w", productbus.ErrCategoryNotFound)
+		}
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
 	return nil
 }
 
-// Update modifies data about a productbus. It will error if the specified ID is
-// invalid or does not reference an existing productbus.
-func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
+// CreateUniqueName adds the product unless another product of the tenant
+// already uses the name, ignoring case, in which case ErrDuplicateName is
+// returned. The name is locked until the transaction ends so no other
+// product can take it between the check and the insert. A transaction is
+// started when the store isn't already using one.
+func (s *Store) CreateUniqueName(ctx context.Context, prd productbus.Product) error {
+	db, ok := s.db.(*sqlx.DB)
+	if !ok {
+		return s.createUniqueName(ctx, prd)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	store := Store{
+		log: s.log,
+		db:  tx,
+	}
+
+	if err := store.createUniqueName(ctx, prd); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) createUniqueName(ctx context.Context, prd productbus.Product) error {
+	data := struct {
+		Key string `db:"key"`
+	}{
+		Key: "products.name/" + prd.TenantID.String() + "/" + prd.Name.String(),
+	}
+
+	const q = `
+	SELECT pg_advisory_xact_lock(hashtextextended(lower(:key), 0))`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("lock: namedexeccontext: %w", err)
+	}
+
+	exists, err := s.NameExists(ctx, prd.TenantID, prd.Name)
+	if err != nil {
+		return fmt.Errorf("nameexists: %w", err)
+	}
+
+	if exists {
+		return productbus.ErrDuplicateName
+	}
+
+	return s.Create(ctx, prd)
+}
+
+// Upsert adds the product or, when it conflicts with an existing product on
+// the target, applies its data to that product. The owner and creation date
+// of an existing product are kept. The existing product is locked first so
+// the state it had before the update can be reported.
+func (s *Store) Upsert(ctx context.Context, prd productbus.Product, target productbus.ConflictTarget) (productbus.Upserted, error) {
+	var match, conflict, setSKU string
+	var notFound error
+
+	switch target {
+	case productbus.ConflictSKU:
+		match = "sku = :sku AND tenant_id = :tenant_id"
+		conflict = "(tenant_id, sku)"
+		notFound = productbus.ErrDuplicateSKU
+
+	case productbus.ConflictID:
+		match = "product_id = :product_id AND tenant_id = :tenant_id"
+		conflict = "(product_id)"
+		setSKU = `"sku" = EXCLUDED.sku,`
+		notFound = productbus.ErrNotFound
+
+	default:
+		return productbus.Upserted{}, fmt.Errorf("unknown conflict target: %q", target)
+	}
+
+	dbPrd := toDBProduct(prd)
+
+	qBefore := `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+	FROM
+		products
+	WHERE
+		` + match + `
+	FOR UPDATE`
+
+	var before *productbus.Product
+
+	var dbBefore product
+	err := sqldb.NamedQueryStruct(ctx, s.log, s.db, qBefore, dbPrd, &dbBefore)
+	switch {
+	case err == nil:
+		if dbBefore.DateDeleted.Valid {
+			return productbus.Upserted{}, fmt.Errorf("deleted: productID[%s]: %w", dbBefore.ID, notFound)
+		}
+
+		busBefore, err := toBusProduct(dbBefore)
+		if err != nil {
+			return productbus.Upserted{}, err
+		}
+		before = &busBefore
+
+	case !errors.Is(err, sqldb.ErrDBNotFound):
+		return productbus.Upserted{}, fmt.Errorf("namedquerystruct: before: %w", err)
+	}
+
+	// xmax is only zero for a row version the statement inserted.
+	q := `
+	INSERT INTO products
+		(product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted)
+	VALUES
+		(:product_id, :tenant_id, :user_id, :sku, :name, :description, :cost, :quantity, :category_id, :image_url, :date_created, :date_updated, :date_deleted)
+	ON CONFLICT ` + conflict + ` DO UPDATE SET
+		` + setSKU + `
+		"name" = EXCLUDED.name,
+		"description" = EXCLUDED.description,
+		"cost" = EXCLUDED.cost,
+		"quantity" = EXCLUDED.quantity,
+		"category_id" = EXCLUDED.category_id,
+		"date_updated" = EXCLUDED.date_updated
+	WHERE
+		products.tenant_id = EXCLUDED.tenant_id AND
+		products.date_deleted IS NULL
+	RETURNING
+		product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags, (xmax = 0) AS created`
+
+	var dbRes struct {
+		product
+		Created bool `db:"created"`
+	}
+
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, dbPrd, &dbRes); err != nil {
+		switch {
+		case errors.Is(err, sqldb.ErrDBNotFound):
+			return productbus.Upserted{}, fmt.Errorf("namedquerystruct: %w", notFound)
+		case errors.Is(err, sqldb.ErrDBDuplicatedEntry):
+			return productbus.Upserted{}, fmt.Errorf("namedquerystruct: %w", productbus.ErrDuplicateSKU)
+		case errors.Is(err, sqldb.ErrDBForeignKey):
+			return productbus.Upserted{}, fmt.Errorf("namedquerystruct: %w", productbus.ErrCategoryNotFound)
+		}
+		return productbus.Upserted{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	busPrd, err := toBusProduct(dbRes.product)
+	if err != nil {
+		return productbus.Upserted{}, err
+	}
+
+	// The stored row carries the id of the product that was updated, which
+	// isn't the id given to the product when it matched on its sku.
+	busPrd.Tags = prd.Tags
+	if err := s.setTags(ctx, busPrd); err != nil {
+		return productbus.Upserted{}, err
+	}
+
+	res := productbus.Upserted{
+		Product: busPrd,
+		Created: dbRes.Created,
+	}
+
+	if !res.Created {
+		res.Before = before
+	}
+
+	return res, nil
+}
+
+// Update modifies data about a productbus. The change is only applied when
+// the stored product still has the specified version as its date updated,
+// otherwise productbus.ErrVersionConflict is returned. The tags of the
+// product are replaced in the same transaction.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	return s.withTx(ctx, func(store *Store) error {
+		if err := store.update(ctx, prd, version); err != nil {
+			return err
+		}
+
+		return store.setTags(ctx, prd)
+	})
+}
+
+func (s *Store) update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	dbPrd := toDBProduct(prd)
+
+	data := map[string]any{
+		"product_id":   dbPrd.ID,
+		"tenant_id":    dbPrd.TenantID,
+		"sku":          dbPrd.SKU,
+		"name":         dbPrd.Name,
+		"description":  dbPrd.Description,
+		"cost":         dbPrd.Cost,
+		"quantity":     dbPrd.Quantity,
+		"category_id":  dbPrd.CategoryID,
+		"date_updated": dbPrd.DateUpdated,
+		"date_deleted": dbPrd.DateDeleted,
+		"version":      version.UTC(),
+	}
+
 	const q = `
 	UPDATE
 		products
 	SET
+		"sku" = :sku,
 		"name" = :name,
+		"description" = :description,
 		"cost" = :cost,
 		"quantity" = :quantity,
-		"date_updated" = :date_updated
+		"category_id" = :category_id,
+		"date_updated" = :date_updated,
+		"date_deleted" = :date_deleted
 	WHERE
-		product_id = :product_id`
+		product_id = :product_id AND
+		tenant_id = :tenant_id AND
+		date_updated = :version
+	RETURNING
+		product_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dest struct {
+		ID uuid.UUID `db:"product_id"`
+	}
+
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.ErrVersionConflict
+		}
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedquerystruct: %w", productbus.ErrDuplicateSKU)
+		}
+		if errors.Is(err, sqldb.ErrDBForeignKey) {
+			return fmt.Errorf("namedquerystruct: %w", productbus.ErrCategoryNotFound)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
 	}
 
 	return nil
 }
 
-// Delete removes the product identified by a given ID.
+// Delete marks the product identified by a given ID as deleted. The row is
+// kept so the product can be audited or restored later, but its tags are
+// removed so a deleted product doesn't count as using them. Deleting a
+// product that is already deleted leaves the original deletion time in
+// place.
 func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	dbPrd := toDBProduct(prd)
+
 	data := struct {
-		ID string `db:"product_id"`
+		ID          uuid.UUID    `db:"product_id"`
+		TenantID    uuid.UUID    `db:"tenant_id"`
+		DateUpdated time.Time    `db:"date_updated"`
+		DateDeleted sql.NullTime `db:"date_deleted"`
 	}{
-		ID: prd.ID.String(),
+		ID:          dbPrd.ID,
+		TenantID:    dbPrd.TenantID,
+		DateUpdated: dbPrd.DateUpdated,
+		DateDeleted: dbPrd.DateDeleted,
 	}
 
 	const q = `
+	WITH deleted AS (
+		UPDATE
+			products
+		SET
+			"date_updated" = :date_updated,
+			"date_deleted" = :date_deleted
+		WHERE
+			product_id = :product_id AND
+			tenant_id = :tenant_id AND
+			date_deleted IS NULL
+		RETURNING
+			product_id
+	)
 	DELETE FROM
-		products
+		product_tags AS pt
+	USING
+		deleted
 	WHERE
-		product_id = :product_id`
+		pt.product_id = deleted.product_id`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -104,16 +383,235 @@ func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
 	return nil
 }
 
+// DeleteByFilter marks every product matching the filter as deleted and
+// returns the products as they were before they were deleted. Their tags
+// are removed like Delete does.
+func (s *Store) DeleteByFilter(ctx context.Context, filter productbus.QueryFilter, now time.Time) ([]productbus.Product, error) {
+	data := map[string]any{
+		"now": now.UTC(),
+	}
+
+	// The matching rows are selected in a CTE so their state from before
+	// the update can be returned.
+	const q = `
+	WITH old AS (
+		SELECT
+			product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+		FROM
+			products`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+	buf.WriteString(`
+		FOR UPDATE
+	), untagged AS (
+		DELETE FROM
+			product_tags AS pt
+		USING
+			old
+		WHERE
+			pt.product_id = old.product_id
+	)
+	UPDATE
+		products p
+	SET
+		"date_updated" = :now,
+		"date_deleted" = :now
+	FROM
+		old
+	WHERE
+		p.product_id = old.product_id
+	RETURNING
+		old.*`)
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
+// AdjustPriceByFilter adjusts the cost of every product matching the filter
+// and records the changes in the price history with a single statement. The
+// products table rejects negative costs, so an adjustment that would produce
+// one fails as a whole with productbus.ErrInvalidCost.
+func (s *Store) AdjustPriceByFilter(ctx context.Context, filter productbus.QueryFilter, adj productbus.PriceAdjustment, now time.Time) ([]productbus.PriceAdjusted, error) {
+	data := map[string]any{
+		"factor":       strconv.FormatFloat(1+adj.Percent/100, 'f', -1, 64),
+		"amount_cents": adj.AmountCents,
+		"now":          now.UTC(),
+	}
+
+	const q = `
+	WITH old AS (
+		SELECT
+			product_id, tenant_id, user_id, sku, name, description, cost AS old_cost, quantity, category_id, image_url, date_created, date_updated AS old_date_updated, date_deleted,
+			ROUND(cost * CAST(:factor AS NUMERIC) + CAST(:amount_cents AS NUMERIC) / 100, 2) AS new_cost
+		FROM
+			products`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+	buf.WriteString(`
+		FOR UPDATE
+	), changed AS (
+		UPDATE
+			products p
+		SET
+			"cost" = old.new_cost,
+			"date_updated" = :now
+		FROM
+			old
+		WHERE
+			p.product_id = old.product_id AND
+			old.new_cost <> old.old_cost
+		RETURNING
+			p.product_id
+	), history AS (
+		INSERT INTO product_price_history
+			(history_id, product_id, old_cost, new_cost, date_changed)
+		SELECT
+			gen_random_uuid(), old.product_id, old.old_cost, old.new_cost, :now
+		FROM
+			old
+		JOIN
+			changed ON changed.product_id = old.product_id
+		RETURNING
+			history_id, product_id, date_changed
+	)
+	SELECT
+		old.product_id, old.tenant_id, old.user_id, old.sku, old.name, old.description, old.new_cost AS cost, old.quantity, old.category_id, old.image_url, old.date_created,
+		history.date_changed AS date_updated, old.date_deleted, product_tag_names(old.product_id) AS tags,
+		history.history_id, old.old_cost, old.old_date_updated
+	FROM
+		old
+	JOIN
+		history ON history.product_id = old.product_id`)
+
+	var dbAdjusted []priceAdjusted
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbAdjusted); err != nil {
+		if errors.Is(err, sqldb.ErrDBCheckViolation) {
+			return nil, fmt.Errorf("namedqueryslice: %w", productbus.ErrInvalidCost)
+		}
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusPriceAdjusted(dbAdjusted)
+}
+
+// AdjustStock changes the quantity of the product by delta in a single
+// statement so concurrent adjustments can't take the quantity below zero.
+// Only a product of the specified tenant is changed. When nothing changes
+// productbus.ErrNotFound is returned if the product doesn't exist, otherwise
+// productbus.ErrInsufficientStock.
+func (s *Store) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (productbus.Product, error) {
+	data := struct {
+		ID          uuid.UUID `db:"product_id"`
+		TenantID    uuid.UUID `db:"tenant_id"`
+		Delta       int       `db:"delta"`
+		DateUpdated time.Time `db:"date_updated"`
+	}{
+		ID:          productID,
+		TenantID:    tenantID,
+		Delta:       delta,
+		DateUpdated: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		products
+	SET
+		"quantity" = quantity + :delta,
+		"date_updated" = :date_updated
+	WHERE
+		product_id = :product_id AND
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL AND
+		quantity + :delta >= 0
+	RETURNING
+		product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags`
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if !errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, fmt.Errorf("namedquerystruct: %w", err)
+		}
+
+		// Nothing was changed, either there is no such product or the
+		// adjustment would take its quantity below zero.
+		const qExists = `
+		SELECT
+			product_id
+		FROM
+			products
+		WHERE
+			product_id = :product_id AND
+			tenant_id = :tenant_id AND
+			date_deleted IS NULL`
+
+		var exists struct {
+			ID uuid.UUID `db:"product_id"`
+		}
+		if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, qExists, data, &exists); err != nil {
+			if errors.Is(err, sqldb.ErrDBNotFound) {
+				return productbus.Product{}, productbus.ErrNotFound
+			}
+			return productbus.Product{}, fmt.Errorf("namedquerystruct: exists: %w", err)
+		}
+
+		return productbus.Product{}, productbus.ErrInsufficientStock
+	}
+
+	return toBusProduct(dbPrd)
+}
+
+// Touch sets the update date of the product to now without changing
+// anything else. Only a product of the specified tenant is changed.
+func (s *Store) Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (productbus.Product, error) {
+	data := struct {
+		ID          uuid.UUID `db:"product_id"`
+		TenantID    uuid.UUID `db:"tenant_id"`
+		DateUpdated time.Time `db:"date_updated"`
+	}{
+		ID:          productID,
+		TenantID:    tenantID,
+		DateUpdated: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		products
+	SET
+		"date_updated" = :date_updated
+	WHERE
+		product_id = :product_id AND
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL
+	RETURNING
+		product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags`
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, productbus.ErrNotFound
+		}
+		return productbus.Product{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toBusProduct(dbPrd)
+}
+
 // Query gets all Products from the database.
-func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy order.By, page page.Page) ([]productbus.Product, error) {
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) ([]productbus.Product, error) {
 	data := map[string]any{
-		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"offset":        page.Offset(),
 		"rows_per_page": page.RowsPerPage(),
 	}
 
 	const q = `
 	SELECT
-	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
 	FROM
 		products`
 
@@ -136,6 +634,43 @@ func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderB
 	return toBusProducts(dbPrds)
 }
 
+// QueryByCursor gets the window of Products that follow the cursor position
+// using a keyset predicate instead of an offset.
+func (s *Store) QueryByCursor(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, rows int) ([]productbus.Product, error) {
+	data := map[string]any{
+		"rows_per_page": rows,
+	}
+
+	const q = `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+	FROM
+		products`
+
+	buf := bytes.NewBufferString(q)
+
+	where, orderByClause, err := keysetClauses(cursor, data)
+	if err != nil {
+		return nil, err
+	}
+
+	wc := s.filterClauses(filter, data)
+	if where != "" {
+		wc = append(wc, where)
+	}
+
+	writeWhere(wc, buf)
+	buf.WriteString(orderByClause)
+	buf.WriteString(" FETCH FIRST :rows_per_page ROWS ONLY")
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
 // Count returns the total number of users in the DB.
 func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
 	data := map[string]any{}
@@ -161,53 +696,1057 @@ func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int,
 	return count.Count, nil
 }
 
-// QueryByID finds the product identified by a given ID.
-func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
-	data := struct {
-		ID string `db:"product_id"`
-	}{
-		ID: productID.String(),
+// Summarize returns the totals of the products matching the filter in a
+// single aggregate query. The sums and averages of no rows are null, so they
+// are turned into zeros.
+func (s *Store) Summarize(ctx context.Context, filter productbus.QueryFilter) (productbus.Summary, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1) AS count,
+		COALESCE(sum(cost * quantity * 100), 0)::bigint AS inventory_value_cents,
+		COALESCE(round(avg(cost) * 100), 0)::bigint AS average_cost_cents,
+		count(1) FILTER (WHERE quantity = 0) AS out_of_stock
+	FROM
+		products`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var sum struct {
+		Count               int   `db:"count"`
+		InventoryValueCents int64 `db:"inventory_value_cents"`
+		AverageCostCents    int64 `db:"average_cost_cents"`
+		OutOfStock          int   `db:"out_of_stock"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &sum); err != nil {
+		return productbus.Summary{}, fmt.Errorf("db: %w", err)
+	}
+
+	return productbus.Summary(sum), nil
+}
+
+// searchVector is the document a full text search is matched against. It must
+// match the expression of the products_search_idx index so the index is used.
+const searchVector = `to_tsvector('english', name || ' ' || description)`
+
+// Search retrieves the products matching the full text query ordered by the
+// rank of the match.
+func (s *Store) Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) ([]productbus.SearchResult, error) {
+	data := map[string]any{
+		"tenant_id":     tenantID,
+		"query":         query,
+		"offset":        page.Offset(),
+		"rows_per_page": page.RowsPerPage(),
 	}
 
 	const q = `
 	SELECT
-	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags,
+	    ts_rank(` + searchVector + `, plainto_tsquery('english', :query)) AS rank
 	FROM
 		products
 	WHERE
-		product_id = :product_id`
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL AND
+		` + searchVector + ` @@ plainto_tsquery('english', :query)
+	ORDER BY
+		rank DESC, product_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
 
-	var dbPrd product
-	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
-		if errors.Is(err, sqldb.ErrDBNotFound) {
-			return productbus.Product{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
-		}
-		return productbus.Product{}, fmt.Errorf("db: %w", err)
+	var dbResults []searchResult
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbResults); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
 	}
 
-	return toBusProduct(dbPrd)
+	return toBusSearchResults(dbResults)
 }
 
-// QueryByUserID finds the product identified by a given User ID.
-func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
-	data := struct {
-		ID string `db:"user_id"`
-	}{
-		ID: userID.String(),
+// SearchCount returns the number of products matching the full text query.
+func (s *Store) SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (int, error) {
+	data := map[string]any{
+		"tenant_id": tenantID,
+		"query":     query,
 	}
 
 	const q = `
 	SELECT
-	    product_id, user_id, name, cost, quantity, date_created, date_updated
+		count(1)
 	FROM
 		products
 	WHERE
-		user_id = :user_id`
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL AND
+		` + searchVector + ` @@ plainto_tsquery('english', :query)`
 
-	var dbPrds []product
-	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
-		return nil, fmt.Errorf("db: %w", err)
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
 	}
 
-	return toBusProducts(dbPrds)
+	return count.Count, nil
+}
+
+// FuzzySearch retrieves the products whose name has at least the threshold
+// of trigram similarity with the query, most similar first.
+func (s *Store) FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) ([]productbus.SearchResult, error) {
+	data := map[string]any{
+		"tenant_id":     tenantID,
+		"query":         query,
+		"offset":        page.Offset(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags,
+	    similarity(name, :query) AS rank
+	FROM
+		products
+	WHERE
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL AND
+		name % :query
+	ORDER BY
+		rank DESC, product_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbResults []searchResult
+	err := s.withSimilarityThreshold(ctx, threshold, func(store *Store) error {
+		return sqldb.NamedQuerySlice(ctx, store.log, store.db, q, data, &dbResults)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusSearchResults(dbResults)
+}
+
+// FuzzySearchCount returns the number of products whose name has at least
+// the threshold of trigram similarity with the query.
+func (s *Store) FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (int, error) {
+	data := map[string]any{
+		"tenant_id": tenantID,
+		"query":     query,
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		products
+	WHERE
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL AND
+		name % :query`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	err := s.withSimilarityThreshold(ctx, threshold, func(store *Store) error {
+		return sqldb.NamedQueryStruct(ctx, store.log, store.db, q, data, &count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// setTags replaces the tags of the product, adding the tags the tenant
+// doesn't use yet.
+func (s *Store) setTags(ctx context.Context, prd productbus.Product) error {
+	data := struct {
+		ProductID uuid.UUID      `db:"product_id"`
+		TenantID  uuid.UUID      `db:"tenant_id"`
+		Tags      dbarray.String `db:"tags"`
+	}{
+		ProductID: prd.ID,
+		TenantID:  prd.TenantID,
+		Tags:      toDBTags(prd.Tags),
+	}
+
+	const add = `
+	INSERT INTO tags
+		(tag_id, tenant_id, name)
+	SELECT
+		gen_random_uuid(), :tenant_id, name
+	FROM
+		unnest(CAST(:tags AS TEXT[])) AS name
+	ON CONFLICT (tenant_id, name) DO NOTHING`
+
+	const unlink = `
+	DELETE FROM
+		product_tags
+	WHERE
+		product_id = :product_id AND
+		tag_id NOT IN (
+			SELECT tag_id FROM tags WHERE tenant_id = :tenant_id AND name = ANY(CAST(:tags AS TEXT[]))
+		)`
+
+	const link = `
+	INSERT INTO product_tags
+		(product_id, tag_id)
+	SELECT
+		:product_id, tag_id
+	FROM
+		tags
+	WHERE
+		tenant_id = :tenant_id AND
+		name = ANY(CAST(:tags AS TEXT[]))
+	ON CONFLICT DO NOTHING`
+
+	if len(prd.Tags) > 0 {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, add, data); err != nil {
+			return fmt.Errorf("add: namedexeccontext: %w", err)
+		}
+	}
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, unlink, data); err != nil {
+		return fmt.Errorf("unlink: namedexeccontext: %w", err)
+	}
+
+	if len(prd.Tags) > 0 {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, link, data); err != nil {
+			return fmt.Errorf("link: namedexeccontext: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// withTx runs fn with a store using a transaction, so its writes are applied
+// together. A store already using one runs fn with itself.
+func (s *Store) withTx(ctx context.Context, fn func(store *Store) error) error {
+	db, ok := s.db.(*sqlx.DB)
+	if !ok {
+		return fn(s)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	store := Store{
+		log: s.log,
+		db:  tx,
+	}
+
+	if err := fn(&store); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// withSimilarityThreshold runs fn with the threshold the % operator matches
+// names with. Using the operator, rather than comparing the similarity,
+// lets the products_name_trgm_idx index be used. The threshold only lasts
+// for the transaction so a store outside of one starts its own.
+func (s *Store) withSimilarityThreshold(ctx context.Context, threshold float64, fn func(store *Store) error) error {
+	db, ok := s.db.(*sqlx.DB)
+	if !ok {
+		if err := s.setSimilarityThreshold(ctx, threshold); err != nil {
+			return err
+		}
+
+		return fn(s)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	store := Store{
+		log: s.log,
+		db:  tx,
+	}
+
+	if err := store.setSimilarityThreshold(ctx, threshold); err != nil {
+		return err
+	}
+
+	if err := fn(&store); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) setSimilarityThreshold(ctx context.Context, threshold float64) error {
+	data := struct {
+		Threshold string `db:"threshold"`
+	}{
+		Threshold: strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
+
+	const q = `
+	SELECT set_config('pg_trgm.similarity_threshold', :threshold, true)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("threshold: namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID finds the product identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	data := struct {
+		ID       string `db:"product_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		ID:       productID.String(),
+		TenantID: tenantID.String(),
+	}
+
+	const q = `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+	FROM
+		products
+	WHERE
+		product_id = :product_id AND
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL`
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
+		}
+		return productbus.Product{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusProduct(dbPrd)
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (productbus.Product, error) {
+	data := struct {
+		SKU      string `db:"sku"`
+		TenantID string `db:"tenant_id"`
+	}{
+		SKU:      sku.String(),
+		TenantID: tenantID.String(),
+	}
+
+	const q = `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+	FROM
+		products
+	WHERE
+		sku = :sku AND
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL`
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
+		}
+		return productbus.Product{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusProduct(dbPrd)
+}
+
+// NameExists reports whether a product with the name, ignoring case, exists.
+// Only the existence is checked so no product row is loaded.
+func (s *Store) NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (bool, error) {
+	data := struct {
+		Name     string `db:"name"`
+		TenantID string `db:"tenant_id"`
+	}{
+		Name:     name.String(),
+		TenantID: tenantID.String(),
+	}
+
+	const q = `
+	SELECT EXISTS (
+		SELECT
+			1
+		FROM
+			products
+		WHERE
+			tenant_id = :tenant_id AND
+			lower(name) = lower(:name) AND
+			date_deleted IS NULL
+		LIMIT 1
+	) AS exists`
+
+	var result struct {
+		Exists bool `db:"exists"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &result); err != nil {
+		return false, fmt.Errorf("db: %w", err)
+	}
+
+	return result.Exists, nil
+}
+
+// SKUExists reports whether a product of the tenant uses the sku. Deleted
+// products are included since they keep their sku.
+func (s *Store) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error) {
+	data := struct {
+		SKU      string `db:"sku"`
+		TenantID string `db:"tenant_id"`
+	}{
+		SKU:      sku.String(),
+		TenantID: tenantID.String(),
+	}
+
+	const q = `
+	SELECT EXISTS (
+		SELECT
+			1
+		FROM
+			products
+		WHERE
+			tenant_id = :tenant_id AND
+			sku = :sku
+	) AS exists`
+
+	var result struct {
+		Exists bool `db:"exists"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &result); err != nil {
+		return false, fmt.Errorf("db: %w", err)
+	}
+
+	return result.Exists, nil
+}
+
+// NextSKUSequence returns the next value of the sequence numbering the
+// generated skus. Values are never handed out twice, even when the
+// transaction asking for one is rolled back.
+func (s *Store) NextSKUSequence(ctx context.Context) (int64, error) {
+	const q = `
+	SELECT nextval('product_sku_seq') AS value`
+
+	var result struct {
+		Value int64 `db:"value"`
+	}
+	if err := sqldb.QueryStruct(ctx, s.log, s.db, q, &result); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return result.Value, nil
+}
+
+// QueryByIDs finds the products identified by the given IDs.
+func (s *Store) QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]productbus.Product, error) {
+	data := struct {
+		IDs      []uuid.UUID `db:"product_ids"`
+		TenantID uuid.UUID   `db:"tenant_id"`
+	}{
+		IDs:      productIDs,
+		TenantID: tenantID,
+	}
+
+	const q = `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+	FROM
+		products
+	WHERE
+		product_id IN (:product_ids) AND
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL`
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
+// QueryByUserID finds the product identified by a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]productbus.Product, error) {
+	data := struct {
+		ID       string `db:"user_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		ID:       userID.String(),
+		TenantID: tenantID.String(),
+	}
+
+	const q = `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+	FROM
+		products
+	WHERE
+		user_id = :user_id AND
+		tenant_id = :tenant_id AND
+		date_deleted IS NULL`
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
+// QueryIdempotencyKey returns the id of the product the user created with
+// the specified key since the specified time.
+func (s *Store) QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (uuid.UUID, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+		Key    string    `db:"idempotency_key"`
+		Since  time.Time `db:"since"`
+	}{
+		UserID: userID,
+		Key:    key,
+		Since:  since.UTC(),
+	}
+
+	const q = `
+	SELECT
+		product_id
+	FROM
+		product_idempotency
+	WHERE
+		user_id = :user_id AND
+		idempotency_key = :idempotency_key AND
+		date_created >= :since`
+
+	var dest struct {
+		ID uuid.UUID `db:"product_id"`
+	}
+
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return uuid.UUID{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
+		}
+		return uuid.UUID{}, fmt.Errorf("db: %w", err)
+	}
+
+	return dest.ID, nil
+}
+
+// CreateIdempotencyKey records the product created by the user with the
+// specified key. A key that expired before the specified time is replaced,
+// otherwise productbus.ErrIdempotencyKeyInUse is returned.
+func (s *Store) CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) error {
+	data := struct {
+		UserID      uuid.UUID `db:"user_id"`
+		Key         string    `db:"idempotency_key"`
+		ProductID   uuid.UUID `db:"product_id"`
+		DateCreated time.Time `db:"date_created"`
+		Since       time.Time `db:"since"`
+	}{
+		UserID:      userID,
+		Key:         key,
+		ProductID:   productID,
+		DateCreated: now.UTC(),
+		Since:       since.UTC(),
+	}
+
+	const q = `
+	INSERT INTO product_idempotency
+		(user_id, idempotency_key, product_id, date_created)
+	VALUES
+		(:user_id, :idempotency_key, :product_id, :date_created)
+	ON CONFLICT (user_id, idempotency_key) DO UPDATE SET
+		product_id = EXCLUDED.product_id,
+		date_created = EXCLUDED.date_created
+	WHERE
+		product_idempotency.date_created < :since
+	RETURNING
+		product_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"product_id"`
+	}
+
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.ErrIdempotencyKeyInUse
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// RecordView records that the user viewed the product. Viewing a product
+// again only moves it to the front, and views past the most recent keep are
+// dropped.
+func (s *Store) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) error {
+	data := struct {
+		UserID     uuid.UUID `db:"user_id"`
+		ProductID  uuid.UUID `db:"product_id"`
+		DateViewed time.Time `db:"date_viewed"`
+		Keep       int       `db:"keep"`
+	}{
+		UserID:     userID,
+		ProductID:  productID,
+		DateViewed: now.UTC(),
+		Keep:       keep,
+	}
+
+	const upsert = `
+	INSERT INTO product_views
+		(user_id, product_id, date_viewed)
+	VALUES
+		(:user_id, :product_id, :date_viewed)
+	ON CONFLICT (user_id, product_id) DO UPDATE SET
+		date_viewed = GREATEST(product_views.date_viewed, EXCLUDED.date_viewed)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, upsert, data); err != nil {
+		return fmt.Errorf("namedexeccontext: upsert: %w", err)
+	}
+
+	const trim = `
+	DELETE FROM
+		product_views
+	WHERE
+		user_id = :user_id AND
+		product_id NOT IN (
+			SELECT
+				product_id
+			FROM
+				product_views
+			WHERE
+				user_id = :user_id
+			ORDER BY
+				date_viewed DESC
+			LIMIT :keep
+		)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, trim, data); err != nil {
+		return fmt.Errorf("namedexeccontext: trim: %w", err)
+	}
+
+	return nil
+}
+
+// SetPendingImage records the key of the image being uploaded for the
+// product, replacing the key of an earlier upload that wasn't confirmed.
+func (s *Store) SetPendingImage(ctx context.Context, prd productbus.Product, key string) error {
+	data := struct {
+		ID  uuid.UUID `db:"product_id"`
+		Key string    `db:"pending_image_key"`
+	}{
+		ID:  prd.ID,
+		Key: key,
+	}
+
+	const q = `
+	UPDATE
+		products
+	SET
+		"pending_image_key" = :pending_image_key
+	WHERE
+		product_id = :product_id AND
+		date_deleted IS NULL`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmImage sets the image url of the product when the key is the one of
+// its pending image, and clears the pending image. Otherwise
+// productbus.ErrNoPendingImage is returned.
+func (s *Store) ConfirmImage(ctx context.Context, prd productbus.Product, key string, url string, now time.Time) (productbus.Product, error) {
+	data := struct {
+		ID          uuid.UUID `db:"product_id"`
+		Key         string    `db:"pending_image_key"`
+		ImageURL    string    `db:"image_url"`
+		DateUpdated time.Time `db:"date_updated"`
+	}{
+		ID:          prd.ID,
+		Key:         key,
+		ImageURL:    url,
+		DateUpdated: now.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		products
+	SET
+		"image_url" = :image_url,
+		"pending_image_key" = NULL,
+		"date_updated" = :date_updated
+	WHERE
+		product_id = :product_id AND
+		pending_image_key = :pending_image_key AND
+		date_deleted IS NULL
+	RETURNING
+		product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags`
+
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, productbus.ErrNoPendingImage
+		}
+		return productbus.Product{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toBusProduct(dbPrd)
+}
+
+// QueryRecentlyViewed retrieves up to limit products of the tenant the user
+// viewed, the most recently viewed first.
+func (s *Store) QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]productbus.Product, error) {
+	data := struct {
+		TenantID uuid.UUID `db:"tenant_id"`
+		UserID   uuid.UUID `db:"user_id"`
+		Limit    int       `db:"limit"`
+	}{
+		TenantID: tenantID,
+		UserID:   userID,
+		Limit:    limit,
+	}
+
+	const q = `
+	SELECT
+		p.product_id, p.tenant_id, p.user_id, p.sku, p.name, p.description, p.cost, p.quantity, p.category_id, p.image_url, p.date_created, p.date_updated, p.date_deleted, product_tag_names(p.product_id) AS tags
+	FROM
+		product_views AS v
+	JOIN
+		products AS p ON p.product_id = v.product_id
+	WHERE
+		v.user_id = :user_id AND
+		p.tenant_id = :tenant_id AND
+		p.date_deleted IS NULL
+	ORDER BY
+		v.date_viewed DESC
+	LIMIT :limit`
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
+// popularLive ranks the products of the tenant by their views the way the
+// popular_products materialized view does, for when the view is stale.
+const popularLive = `
+	SELECT
+		p.product_id,
+		count(1) AS views
+	FROM
+		product_views AS v
+	JOIN
+		products AS p ON p.product_id = v.product_id
+	WHERE
+		p.tenant_id = :tenant_id AND
+		p.date_deleted IS NULL
+	GROUP BY
+		p.product_id`
+
+// popularSource returns the ranking of the products of the tenant, read from
+// the materialized view unless live is set.
+func popularSource(live bool) string {
+	if live {
+		return "(" + popularLive + ")"
+	}
+
+	return `(
+	SELECT
+		product_id, views
+	FROM
+		popular_products
+	WHERE
+		tenant_id = :tenant_id)`
+}
+
+// QueryPopular retrieves the products of the tenant that were viewed, the
+// most viewed first. The views are counted from product_views when live is
+// set and read from the popular_products materialized view otherwise.
+// Products deleted since the view was refreshed are left out.
+func (s *Store) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]productbus.Popular, error) {
+	data := map[string]any{
+		"tenant_id":     tenantID,
+		"offset":        page.Offset(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	q := `
+	SELECT
+		p.product_id, p.tenant_id, p.user_id, p.sku, p.name, p.description, p.cost, p.quantity, p.category_id, p.image_url, p.date_created, p.date_updated, p.date_deleted, product_tag_names(p.product_id) AS tags,
+		r.views
+	FROM
+		` + popularSource(live) + ` AS r
+	JOIN
+		products AS p ON p.product_id = r.product_id
+	WHERE
+		p.date_deleted IS NULL
+	ORDER BY
+		r.views DESC, p.product_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbPopular []popular
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPopular); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusPopular(dbPopular)
+}
+
+// CountPopular returns the number of products of the tenant QueryPopular
+// ranks.
+func (s *Store) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error) {
+	data := map[string]any{
+		"tenant_id": tenantID,
+	}
+
+	q := `
+	SELECT
+		count(1)
+	FROM
+		` + popularSource(live) + ` AS r
+	JOIN
+		products AS p ON p.product_id = r.product_id
+	WHERE
+		p.date_deleted IS NULL`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// RefreshPopular recounts the views of the popular_products materialized
+// view and records that it's as of now. The view keeps being read while
+// it's refreshed.
+func (s *Store) RefreshPopular(ctx context.Context, now time.Time) error {
+	const refresh = `
+	REFRESH MATERIALIZED VIEW CONCURRENTLY popular_products`
+
+	if err := sqldb.ExecContext(ctx, s.log, s.db, refresh); err != nil {
+		return fmt.Errorf("execcontext: refresh: %w", err)
+	}
+
+	data := struct {
+		DateRefreshed time.Time `db:"date_refreshed"`
+	}{
+		DateRefreshed: now.UTC(),
+	}
+
+	const record = `
+	INSERT INTO view_refreshes
+		(view_name, date_refreshed)
+	VALUES
+		('popular_products', :date_refreshed)
+	ON CONFLICT (view_name) DO UPDATE SET
+		date_refreshed = GREATEST(view_refreshes.date_refreshed, EXCLUDED.date_refreshed)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, record, data); err != nil {
+		return fmt.Errorf("namedexeccontext: record: %w", err)
+	}
+
+	return nil
+}
+
+// PopularRefreshed returns when the popular_products materialized view was
+// last refreshed, or the zero time when it never was.
+func (s *Store) PopularRefreshed(ctx context.Context) (time.Time, error) {
+	const q = `
+	SELECT
+		date_refreshed
+	FROM
+		view_refreshes
+	WHERE
+		view_name = 'popular_products'`
+
+	var result struct {
+		DateRefreshed time.Time `db:"date_refreshed"`
+	}
+	if err := sqldb.QueryStruct(ctx, s.log, s.db, q, &result); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("db: %w", err)
+	}
+
+	return result.DateRefreshed.In(time.Local), nil
+}
+
+// CreatePriceChange records a change of cost of a product.
+func (s *Store) CreatePriceChange(ctx context.Context, pc productbus.PriceChange) error {
+	const q = `
+	INSERT INTO product_price_history
+		(history_id, product_id, old_cost, new_cost, date_changed)
+	VALUES
+		(:history_id, :product_id, :old_cost, :new_cost, :date_changed)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPriceChange(pc)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryPriceHistory retrieves the cost changes of a product, oldest first.
+func (s *Store) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]productbus.PriceChange, error) {
+	data := map[string]any{
+		"product_id":    productID,
+		"offset":        page.Offset(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		history_id, product_id, old_cost, new_cost, date_changed
+	FROM
+		product_price_history
+	WHERE
+		product_id = :product_id
+	ORDER BY
+		date_changed, history_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbHistory []priceChange
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbHistory); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusPriceChanges(dbHistory)
+}
+
+// CountPriceHistory returns the number of cost changes of a product.
+func (s *Store) CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error) {
+	data := map[string]any{
+		"product_id": productID,
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		product_price_history
+	WHERE
+		product_id = :product_id`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// similarWhere limits the products to the ones of the same category as the
+// product, other than itself, whose cost is within the band of its cost.
+const similarWhere = `
+	WHERE
+		tenant_id = :tenant_id AND
+		category_id = :category_id AND
+		product_id <> :product_id AND
+		date_deleted IS NULL AND
+		cost BETWEEN CAST(:cost AS NUMERIC) * (1 - CAST(:band AS NUMERIC)) AND CAST(:cost AS NUMERIC) * (1 + CAST(:band AS NUMERIC))`
+
+// QuerySimilar retrieves the products of the same category as the product
+// whose cost is within the band of its cost, closest in cost first.
+func (s *Store) QuerySimilar(ctx context.Context, prd productbus.Product, band float64, page page.Page) ([]productbus.Product, error) {
+	data := similarData(prd, band)
+	data["offset"] = page.Offset()
+	data["rows_per_page"] = page.RowsPerPage()
+
+	const q = `
+	SELECT
+	    product_id, tenant_id, user_id, sku, name, description, cost, quantity, category_id, image_url, date_created, date_updated, date_deleted, product_tag_names(product_id) AS tags
+	FROM
+		products` + similarWhere + `
+	ORDER BY
+		abs(cost - CAST(:cost AS NUMERIC)), product_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbPrds []product
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusProducts(dbPrds)
+}
+
+// CountSimilar returns the number of products of the same category as the
+// product whose cost is within the band of its cost.
+func (s *Store) CountSimilar(ctx context.Context, prd productbus.Product, band float64) (int, error) {
+	data := similarData(prd, band)
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		products` + similarWhere
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+func similarData(prd productbus.Product, band float64) map[string]any {
+	dbPrd := toDBProduct(prd)
+
+	return map[string]any{
+		"tenant_id":   dbPrd.TenantID,
+		"category_id": dbPrd.CategoryID,
+		"product_id":  dbPrd.ID,
+		"cost":        dbPrd.Cost,
+		"band":        band,
+	}
+}
+
+// WithSavepoint runs fn inside a savepoint. When fn fails the writes it made
+// are rolled back and the rest of the transaction stays usable. Savepoints
+// only exist inside a transaction so the store must come from NewWithTx.
+func (s *Store) WithSavepoint(ctx context.Context, fn func() error) error {
+	if _, ok := s.db.(*sqlx.Tx); !ok {
+		return fmt.Errorf("savepoint requires a transaction: %w", productbus.ErrSavepoint)
+	}
+
+	if err := sqldb.ExecContext(ctx, s.log, s.db, `SAVEPOINT product_savepoint`); err != nil {
+		return fmt.Errorf("savepoint: %w: %w", productbus.ErrSavepoint, err)
+	}
+
+	if err := fn(); err != nil {
+		if rbErr := sqldb.ExecContext(ctx, s.log, s.db, `ROLLBACK TO SAVEPOINT product_savepoint`); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint: %w: %w", productbus.ErrSavepoint, rbErr)
+		}
+		return err
+	}
+
+	if err := sqldb.ExecContext(ctx, s.log, s.db, `RELEASE SAVEPOINT product_savepoint`); err != nil {
+		return fmt.Errorf("release savepoint: %w: %w", productbus.ErrSavepoint, err)
+	}
+
+	return nil
 }
@@ -0,0 +1,265 @@
+// Package productflight contains product related CRUD functionality that
+// collapses concurrent lookups of the same product into one database round
+// trip.
+//
+// Concurrent QueryByID calls for the same tenant and product share the
+// result of the first call. A store returned by NewWithTx doesn't share
+// lookups since a transaction has to see its own writes.
+package productflight
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// Store manages the set of APIs for product data access with shared
+// lookups.
+type Store struct {
+	storer productbus.Storer
+	group  singleflight.Group
+}
+
+// NewStore constructs the api for data access with shared lookups.
+func NewStore(storer productbus.Storer) *Store {
+	return &Store{
+		storer: storer,
+	}
+}
+
+// NewWithTx returns the transactional store of the wrapped store. Lookups
+// inside a transaction aren't shared.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	return s.storer.NewWithTx(tx)
+}
+
+// QueryByID finds the product identified by a given ID. Callers asking for
+// the same product while a lookup is in flight get the result of that
+// lookup. The lookup isn't canceled when the caller that started it goes
+// away, but every caller stops waiting when its own context is done.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	key := tenantID.String() + "/" + productID.String()
+
+	ch := s.group.DoChan(key, func() (any, error) {
+		return s.storer.QueryByID(context.WithoutCancel(ctx), tenantID, productID)
+	})
+
+	select {
+	case <-ctx.Done():
+		return productbus.Product{}, ctx.Err()
+
+	case res := <-ch:
+		if res.Err != nil {
+			return productbus.Product{}, res.Err
+		}
+
+		return res.Val.(productbus.Product), nil
+	}
+}
+
+// Create adds a Product.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	return s.storer.Create(ctx, prd)
+}
+
+// CreateUniqueName adds a Product unless its name is already in use.
+func (s *Store) CreateUniqueName(ctx context.Context, prd productbus.Product) error {
+	return s.storer.CreateUniqueName(ctx, prd)
+}
+
+// Update modifies data about a product.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	return s.storer.Update(ctx, prd, version)
+}
+
+// Upsert adds the product or applies it to the product it conflicts with.
+func (s *Store) Upsert(ctx context.Context, prd productbus.Product, target productbus.ConflictTarget) (productbus.Upserted, error) {
+	return s.storer.Upsert(ctx, prd, target)
+}
+
+// Delete marks the product as deleted.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	return s.storer.Delete(ctx, prd)
+}
+
+// DeleteByFilter marks every product matching the filter as deleted.
+func (s *Store) DeleteByFilter(ctx context.Context, filter productbus.QueryFilter, now time.Time) ([]productbus.Product, error) {
+	return s.storer.DeleteByFilter(ctx, filter, now)
+}
+
+// AdjustPriceByFilter adjusts the cost of every product matching the filter.
+func (s *Store) AdjustPriceByFilter(ctx context.Context, filter productbus.QueryFilter, adj productbus.PriceAdjustment, now time.Time) ([]productbus.PriceAdjusted, error) {
+	return s.storer.AdjustPriceByFilter(ctx, filter, adj, now)
+}
+
+// AdjustStock changes the quantity of the product by delta.
+func (s *Store) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (productbus.Product, error) {
+	return s.storer.AdjustStock(ctx, tenantID, productID, delta, now)
+}
+
+// Touch sets the update date of the product to now.
+func (s *Store) Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (productbus.Product, error) {
+	return s.storer.Touch(ctx, tenantID, productID, now)
+}
+
+// Query retrieves a list of existing products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) ([]productbus.Product, error) {
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByCursor retrieves the window of products that follow the cursor.
+func (s *Store) QueryByCursor(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, rows int) ([]productbus.Product, error) {
+	return s.storer.QueryByCursor(ctx, filter, cursor, rows)
+}
+
+// Count returns the number of products matching the filter.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// Summarize returns the totals of the products matching the filter.
+func (s *Store) Summarize(ctx context.Context, filter productbus.QueryFilter) (productbus.Summary, error) {
+	return s.storer.Summarize(ctx, filter)
+}
+
+// Search retrieves the products matching the full text query.
+func (s *Store) Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) ([]productbus.SearchResult, error) {
+	return s.storer.Search(ctx, tenantID, query, page)
+}
+
+// SearchCount returns the number of products matching the full text query.
+func (s *Store) SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (int, error) {
+	return s.storer.SearchCount(ctx, tenantID, query)
+}
+
+// FuzzySearch retrieves the products whose name is similar to the query.
+func (s *Store) FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) ([]productbus.SearchResult, error) {
+	return s.storer.FuzzySearch(ctx, tenantID, query, threshold, page)
+}
+
+// FuzzySearchCount returns the number of products whose name is similar to
+// the query.
+func (s *Store) FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (int, error) {
+	return s.storer.FuzzySearchCount(ctx, tenantID, query, threshold)
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (productbus.Product, error) {
+	return s.storer.QueryBySKU(ctx, tenantID, sku)
+}
+
+// NameExists reports whether a product with the name exists.
+func (s *Store) NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (bool, error) {
+	return s.storer.NameExists(ctx, tenantID, name)
+}
+
+// SKUExists reports whether a product uses the sku.
+func (s *Store) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error) {
+	return s.storer.SKUExists(ctx, tenantID, sku)
+}
+
+// NextSKUSequence returns the next value of the sku sequence.
+func (s *Store) NextSKUSequence(ctx context.Context) (int64, error) {
+	return s.storer.NextSKUSequence(ctx)
+}
+
+// QueryByIDs finds the products identified by the given IDs.
+func (s *Store) QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]productbus.Product, error) {
+	return s.storer.QueryByIDs(ctx, tenantID, productIDs)
+}
+
+// QueryByUserID finds the products of a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]productbus.Product, error) {
+	return s.storer.QueryByUserID(ctx, tenantID, userID)
+}
+
+// CreatePriceChange records a change of cost.
+func (s *Store) CreatePriceChange(ctx context.Context, pc productbus.PriceChange) error {
+	return s.storer.CreatePriceChange(ctx, pc)
+}
+
+// QueryPriceHistory retrieves the cost changes of a product.
+func (s *Store) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]productbus.PriceChange, error) {
+	return s.storer.QueryPriceHistory(ctx, productID, page)
+}
+
+// CountPriceHistory returns the number of cost changes of a product.
+func (s *Store) CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error) {
+	return s.storer.CountPriceHistory(ctx, productID)
+}
+
+// QuerySimilar retrieves the products of the same category as the product
+// whose cost is within the band of its cost.
+func (s *Store) QuerySimilar(ctx context.Context, prd productbus.Product, band float64, page page.Page) ([]productbus.Product, error) {
+	return s.storer.QuerySimilar(ctx, prd, band, page)
+}
+
+// CountSimilar returns the number of products of the same category as the
+// product whose cost is within the band of its cost.
+func (s *Store) CountSimilar(ctx context.Context, prd productbus.Product, band float64) (int, error) {
+	return s.storer.CountSimilar(ctx, prd, band)
+}
+
+// QueryIdempotencyKey returns the product created with the key.
+func (s *Store) QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (uuid.UUID, error) {
+	return s.storer.QueryIdempotencyKey(ctx, userID, key, since)
+}
+
+// CreateIdempotencyKey records the key of a create.
+func (s *Store) CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) error {
+	return s.storer.CreateIdempotencyKey(ctx, userID, key, productID, now, since)
+}
+
+// RecordView records that the user viewed the product.
+func (s *Store) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) error {
+	return s.storer.RecordView(ctx, userID, productID, now, keep)
+}
+
+// QueryRecentlyViewed retrieves the products the user viewed most recently.
+func (s *Store) QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]productbus.Product, error) {
+	return s.storer.QueryRecentlyViewed(ctx, tenantID, userID, limit)
+}
+
+// QueryPopular retrieves the products of the tenant that were viewed, the
+// most viewed first.
+func (s *Store) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]productbus.Popular, error) {
+	return s.storer.QueryPopular(ctx, tenantID, live, page)
+}
+
+// CountPopular returns the number of products QueryPopular ranks.
+func (s *Store) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error) {
+	return s.storer.CountPopular(ctx, tenantID, live)
+}
+
+// RefreshPopular recounts the views of the popular products.
+func (s *Store) RefreshPopular(ctx context.Context, now time.Time) error {
+	return s.storer.RefreshPopular(ctx, now)
+}
+
+// PopularRefreshed returns when the popular products were last refreshed.
+func (s *Store) PopularRefreshed(ctx context.Context) (time.Time, error) {
+	return s.storer.PopularRefreshed(ctx)
+}
+
+// SetPendingImage records the image being uploaded for the product.
+func (s *Store) SetPendingImage(ctx context.Context, prd productbus.Product, key string) error {
+	return s.storer.SetPendingImage(ctx, prd, key)
+}
+
+// ConfirmImage makes the pending image the image of the product.
+func (s *Store) ConfirmImage(ctx context.Context, prd productbus.Product, key string, url string, now time.Time) (productbus.Product, error) {
+	return s.storer.ConfirmImage(ctx, prd, key, url, now)
+}
+
+// WithSavepoint runs fn inside a savepoint.
+func (s *Store) WithSavepoint(ctx context.Context, fn func() error) error {
+	return s.storer.WithSavepoint(ctx, fn)
+}
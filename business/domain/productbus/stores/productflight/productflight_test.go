@@ -0,0 +1,95 @@
+package productflight_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productflight"
+	"github.com/google/uuid"
+)
+
+// blockingStore counts the lookups and holds them until release is closed.
+type blockingStore struct {
+	productbus.Storer
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *blockingStore) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	s.calls.Add(1)
+	<-s.release
+
+	return productbus.Product{ID: productID}, nil
+}
+
+func Test_QueryByID(t *testing.T) {
+	const goroutines = 50
+
+	fake := blockingStore{release: make(chan struct{})}
+	store := productflight.NewStore(&fake)
+
+	productID := uuid.New()
+
+	var started, done sync.WaitGroup
+	started.Add(goroutines)
+	done.Add(goroutines)
+
+	results := make([]productbus.Product, goroutines)
+	errs := make([]error, goroutines)
+
+	for i := range goroutines {
+		go func() {
+			defer done.Done()
+			started.Done()
+			results[i], errs[i] = store.QueryByID(context.Background(), uuid.Nil, productID)
+		}()
+	}
+
+	// Give every goroutine the time to join the lookup in flight before the
+	// store answers.
+	started.Wait()
+	time.Sleep(100 * time.Millisecond)
+	close(fake.release)
+	done.Wait()
+
+	if calls := fake.calls.Load(); calls != 1 {
+		t.Fatalf("Should call the store once, got %d calls", calls)
+	}
+
+	for i := range goroutines {
+		if errs[i] != nil {
+			t.Fatalf("Should be able to query the product: %s", errs[i])
+		}
+
+		if results[i].ID != productID {
+			t.Fatalf("Should get back product %s, got %s", productID, results[i].ID)
+		}
+	}
+
+	// A lookup made after the shared one completed goes to the store again.
+	if _, err := store.QueryByID(context.Background(), uuid.Nil, productID); err != nil {
+		t.Fatalf("Should be able to query the product: %s", err)
+	}
+
+	if calls := fake.calls.Load(); calls != 2 {
+		t.Fatalf("Should call the store again, got %d calls", calls)
+	}
+}
+
+func Test_QueryByIDCanceled(t *testing.T) {
+	fake := blockingStore{release: make(chan struct{})}
+	defer close(fake.release)
+
+	store := productflight.NewStore(&fake)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := store.QueryByID(ctx, uuid.Nil, uuid.New()); err != context.DeadlineExceeded {
+		t.Fatalf("Should stop waiting when the context is done, got %v", err)
+	}
+}
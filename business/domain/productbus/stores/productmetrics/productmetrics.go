@@ -0,0 +1,334 @@
+// Package productmetrics contains product related CRUD functionality that
+// records the rate, errors and duration of every store operation.
+package productmetrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics holds the collectors shared by a store and the stores it creates
+// for transactions.
+type metrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// Store manages the set of APIs for product data access with metrics.
+type Store struct {
+	storer  productbus.Storer
+	metrics *metrics
+}
+
+// NewStore constructs the api for data access with metrics. The collectors
+// are registered with the registerer, which panics when they were already
+// registered, so a single store is expected per registerer.
+func NewStore(storer productbus.Storer, reg prometheus.Registerer) *Store {
+	m := metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "product_store_requests_total",
+			Help: "Number of product store operations.",
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "product_store_errors_total",
+			Help: "Number of product store operations that failed.",
+		}, []string{"method"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "product_store_duration_seconds",
+			Help:    "Duration of product store operations.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+
+	reg.MustRegister(m.requests, m.errors, m.duration)
+
+	return &Store{
+		storer:  storer,
+		metrics: &m,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the wrapped store with
+// its transactional version. The metrics are shared with this store.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		storer:  storer,
+		metrics: s.metrics,
+	}
+
+	return &store, nil
+}
+
+// Create adds a Product to the sqldb.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) (err error) {
+	defer s.record("create", time.Now(), &err)
+	return s.storer.Create(ctx, prd)
+}
+
+// CreateUniqueName adds a Product unless its name is already in use.
+func (s *Store) CreateUniqueName(ctx context.Context, prd productbus.Product) (err error) {
+	defer s.record("createuniquename", time.Now(), &err)
+	return s.storer.CreateUniqueName(ctx, prd)
+}
+
+// Update modifies data about a product.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, version time.Time) (err error) {
+	defer s.record("update", time.Now(), &err)
+	return s.storer.Update(ctx, prd, version)
+}
+
+// Upsert adds the product or applies it to the product it conflicts with.
+func (s *Store) Upsert(ctx context.Context, prd productbus.Product, target productbus.ConflictTarget) (_ productbus.Upserted, err error) {
+	defer s.record("upsert", time.Now(), &err)
+	return s.storer.Upsert(ctx, prd, target)
+}
+
+// Delete marks the product as deleted.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) (err error) {
+	defer s.record("delete", time.Now(), &err)
+	return s.storer.Delete(ctx, prd)
+}
+
+// DeleteByFilter marks every product matching the filter as deleted.
+func (s *Store) DeleteByFilter(ctx context.Context, filter productbus.QueryFilter, now time.Time) (_ []productbus.Product, err error) {
+	defer s.record("deletebyfilter", time.Now(), &err)
+	return s.storer.DeleteByFilter(ctx, filter, now)
+}
+
+// AdjustPriceByFilter adjusts the cost of every product matching the filter.
+func (s *Store) AdjustPriceByFilter(ctx context.Context, filter productbus.QueryFilter, adj productbus.PriceAdjustment, now time.Time) (_ []productbus.PriceAdjusted, err error) {
+	defer s.record("adjustpricebyfilter", time.Now(), &err)
+	return s.storer.AdjustPriceByFilter(ctx, filter, adj, now)
+}
+
+// AdjustStock changes the quantity of the product by delta.
+func (s *Store) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (_ productbus.Product, err error) {
+	defer s.record("adjuststock", time.Now(), &err)
+	return s.storer.AdjustStock(ctx, tenantID, productID, delta, now)
+}
+
+// Touch sets the update date of the product to now.
+func (s *Store) Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (_ productbus.Product, err error) {
+	defer s.record("touch", time.Now(), &err)
+	return s.storer.Touch(ctx, tenantID, productID, now)
+}
+
+// Query retrieves a list of existing products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) (_ []productbus.Product, err error) {
+	defer s.record("query", time.Now(), &err)
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByCursor retrieves the window of products that follow the cursor.
+func (s *Store) QueryByCursor(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, rows int) (_ []productbus.Product, err error) {
+	defer s.record("querybycursor", time.Now(), &err)
+	return s.storer.QueryByCursor(ctx, filter, cursor, rows)
+}
+
+// Count returns the number of products matching the filter.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (_ int, err error) {
+	defer s.record("count", time.Now(), &err)
+	return s.storer.Count(ctx, filter)
+}
+
+// Summarize returns the totals of the products matching the filter.
+func (s *Store) Summarize(ctx context.Context, filter productbus.QueryFilter) (_ productbus.Summary, err error) {
+	defer s.record("summarize", time.Now(), &err)
+	return s.storer.Summarize(ctx, filter)
+}
+
+// Search retrieves the products matching the full text query.
+func (s *Store) Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) (_ []productbus.SearchResult, err error) {
+	defer s.record("search", time.Now(), &err)
+	return s.storer.Search(ctx, tenantID, query, page)
+}
+
+// SearchCount returns the number of products matching the full text query.
+func (s *Store) SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (_ int, err error) {
+	defer s.record("searchcount", time.Now(), &err)
+	return s.storer.SearchCount(ctx, tenantID, query)
+}
+
+// FuzzySearch retrieves the products whose name is similar to the query.
+func (s *Store) FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) (_ []productbus.SearchResult, err error) {
+	defer s.record("fuzzysearch", time.Now(), &err)
+	return s.storer.FuzzySearch(ctx, tenantID, query, threshold, page)
+}
+
+// FuzzySearchCount returns the number of products whose name is similar to
+// the query.
+func (s *Store) FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (_ int, err error) {
+	defer s.record("fuzzysearchcount", time.Now(), &err)
+	return s.storer.FuzzySearchCount(ctx, tenantID, query, threshold)
+}
+
+// QueryByID finds the product identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (_ productbus.Product, err error) {
+	defer s.record("querybyid", time.Now(), &err)
+	return s.storer.QueryByID(ctx, tenantID, productID)
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (_ productbus.Product, err error) {
+	defer s.record("querybysku", time.Now(), &err)
+	return s.storer.QueryBySKU(ctx, tenantID, sku)
+}
+
+// NameExists reports whether a product with the name exists.
+func (s *Store) NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (_ bool, err error) {
+	defer s.record("nameexists", time.Now(), &err)
+	return s.storer.NameExists(ctx, tenantID, name)
+}
+
+// SKUExists reports whether a product uses the sku.
+func (s *Store) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (_ bool, err error) {
+	defer s.record("skuexists", time.Now(), &err)
+	return s.storer.SKUExists(ctx, tenantID, sku)
+}
+
+// NextSKUSequence returns the next value of the sku sequence.
+func (s *Store) NextSKUSequence(ctx context.Context) (_ int64, err error) {
+	defer s.record("nextskusequence", time.Now(), &err)
+	return s.storer.NextSKUSequence(ctx)
+}
+
+// QueryByIDs finds the products identified by the given IDs.
+func (s *Store) QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) (_ []productbus.Product, err error) {
+	defer s.record("querybyids", time.Now(), &err)
+	return s.storer.QueryByIDs(ctx, tenantID, productIDs)
+}
+
+// QueryByUserID finds the products of a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (_ []productbus.Product, err error) {
+	defer s.record("querybyuserid", time.Now(), &err)
+	return s.storer.QueryByUserID(ctx, tenantID, userID)
+}
+
+// CreatePriceChange records a change of cost.
+func (s *Store) CreatePriceChange(ctx context.Context, pc productbus.PriceChange) (err error) {
+	defer s.record("createpricechange", time.Now(), &err)
+	return s.storer.CreatePriceChange(ctx, pc)
+}
+
+// QueryPriceHistory retrieves the cost changes of a product.
+func (s *Store) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) (_ []productbus.PriceChange, err error) {
+	defer s.record("querypricehistory", time.Now(), &err)
+	return s.storer.QueryPriceHistory(ctx, productID, page)
+}
+
+// CountPriceHistory returns the number of cost changes of a product.
+func (s *Store) CountPriceHistory(ctx context.Context, productID uuid.UUID) (_ int, err error) {
+	defer s.record("countpricehistory", time.Now(), &err)
+	return s.storer.CountPriceHistory(ctx, productID)
+}
+
+// QuerySimilar retrieves the products of the same category as the product
+// whose cost is within the band of its cost.
+func (s *Store) QuerySimilar(ctx context.Context, prd productbus.Product, band float64, page page.Page) (_ []productbus.Product, err error) {
+	defer s.record("querysimilar", time.Now(), &err)
+	return s.storer.QuerySimilar(ctx, prd, band, page)
+}
+
+// CountSimilar returns the number of products of the same category as the
+// product whose cost is within the band of its cost.
+func (s *Store) CountSimilar(ctx context.Context, prd productbus.Product, band float64) (_ int, err error) {
+	defer s.record("countsimilar", time.Now(), &err)
+	return s.storer.CountSimilar(ctx, prd, band)
+}
+
+// QueryIdempotencyKey finds the product created with the key.
+func (s *Store) QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (_ uuid.UUID, err error) {
+	defer s.record("queryidempotencykey", time.Now(), &err)
+	return s.storer.QueryIdempotencyKey(ctx, userID, key, since)
+}
+
+// CreateIdempotencyKey records the key of a create.
+func (s *Store) CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) (err error) {
+	defer s.record("createidempotencykey", time.Now(), &err)
+	return s.storer.CreateIdempotencyKey(ctx, userID, key, productID, now, since)
+}
+
+// RecordView records that the user viewed the product.
+func (s *Store) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) (err error) {
+	defer s.record("recordview", time.Now(), &err)
+	return s.storer.RecordView(ctx, userID, productID, now, keep)
+}
+
+// QueryRecentlyViewed retrieves the products the user viewed most recently.
+func (s *Store) QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) (_ []productbus.Product, err error) {
+	defer s.record("queryrecentlyviewed", time.Now(), &err)
+	return s.storer.QueryRecentlyViewed(ctx, tenantID, userID, limit)
+}
+
+// QueryPopular retrieves the products of the tenant that were viewed, the
+// most viewed first.
+func (s *Store) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) (_ []productbus.Popular, err error) {
+	defer s.record("querypopular", time.Now(), &err)
+	return s.storer.QueryPopular(ctx, tenantID, live, page)
+}
+
+// CountPopular returns the number of products QueryPopular ranks.
+func (s *Store) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (_ int, err error) {
+	defer s.record("countpopular", time.Now(), &err)
+	return s.storer.CountPopular(ctx, tenantID, live)
+}
+
+// RefreshPopular recounts the views of the popular products.
+func (s *Store) RefreshPopular(ctx context.Context, now time.Time) (err error) {
+	defer s.record("refreshpopular", time.Now(), &err)
+	return s.storer.RefreshPopular(ctx, now)
+}
+
+// PopularRefreshed returns when the popular products were last refreshed.
+func (s *Store) PopularRefreshed(ctx context.Context) (_ time.Time, err error) {
+	defer s.record("popularrefreshed", time.Now(), &err)
+	return s.storer.PopularRefreshed(ctx)
+}
+
+// SetPendingImage records the image being uploaded for the product.
+func (s *Store) SetPendingImage(ctx context.Context, prd productbus.Product, key string) (err error) {
+	defer s.record("setpendingimage", time.Now(), &err)
+	return s.storer.SetPendingImage(ctx, prd, key)
+}
+
+// ConfirmImage makes the pending image the image of the product.
+func (s *Store) ConfirmImage(ctx context.Context, prd productbus.Product, key string, url string, now time.Time) (_ productbus.Product, err error) {
+	defer s.record("confirmimage", time.Now(), &err)
+	return s.storer.ConfirmImage(ctx, prd, key, url, now)
+}
+
+// WithSavepoint runs fn inside a savepoint. It isn't recorded since its
+// duration is the one of the operations fn runs, which are recorded.
+func (s *Store) WithSavepoint(ctx context.Context, fn func() error) error {
+	return s.storer.WithSavepoint(ctx, fn)
+}
+
+// =============================================================================
+
+// record updates the metrics of the method once it returns. A product that
+// isn't found is the answer to the query rather than a failure, so it isn't
+// counted as an error.
+func (s *Store) record(method string, start time.Time, err *error) {
+	s.metrics.requests.WithLabelValues(method).Inc()
+	s.metrics.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+	if *err != nil && !errors.Is(*err, productbus.ErrNotFound) {
+		s.metrics.errors.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,101 @@
+package productmetrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productmetrics"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fakeStore returns the configured error from the methods used by the
+// tests.
+type fakeStore struct {
+	productbus.Storer
+	err error
+}
+
+func (s *fakeStore) Create(ctx context.Context, prd productbus.Product) error {
+	return s.err
+}
+
+func (s *fakeStore) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	return productbus.Product{}, s.err
+}
+
+func Test_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	fake := fakeStore{}
+	store := productmetrics.NewStore(&fake, reg)
+
+	ctx := context.Background()
+
+	if err := store.Create(ctx, productbus.Product{}); err != nil {
+		t.Fatalf("Should be able to create: %s", err)
+	}
+
+	fake.err = errors.New("failed")
+	if err := store.Create(ctx, productbus.Product{}); !errors.Is(err, fake.err) {
+		t.Fatalf("Should get back the store error, got %v", err)
+	}
+
+	fake.err = productbus.ErrNotFound
+	if _, err := store.QueryByID(ctx, uuid.Nil, uuid.New()); !errors.Is(err, productbus.ErrNotFound) {
+		t.Fatalf("Should get back not found, got %v", err)
+	}
+
+	tests := []struct {
+		metric string
+		method string
+		exp    float64
+	}{
+		{metric: "product_store_requests_total", method: "create", exp: 2},
+		{metric: "product_store_errors_total", method: "create", exp: 1},
+		{metric: "product_store_duration_seconds", method: "create", exp: 2},
+		{metric: "product_store_requests_total", method: "querybyid", exp: 1},
+		{metric: "product_store_errors_total", method: "querybyid", exp: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric+"-"+tt.method, func(t *testing.T) {
+			if got := value(t, reg, tt.metric, tt.method); got != tt.exp {
+				t.Errorf("Should get %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
+
+// value returns the counter value, or the sample count for a histogram, of
+// the metric with the method label. Zero is returned when the metric wasn't
+// recorded for the method.
+func value(t *testing.T, reg *prometheus.Registry, metric string, method string) float64 {
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Should be able to gather the metrics: %s", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != metric {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() != "method" || label.GetValue() != method {
+					continue
+				}
+
+				if h := m.GetHistogram(); h != nil {
+					return float64(h.GetSampleCount())
+				}
+
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
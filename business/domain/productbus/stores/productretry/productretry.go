@@ -0,0 +1,377 @@
+// Package productretry contains product related CRUD functionality that
+// retries writes failing with a transient database error.
+//
+// A failed statement aborts the transaction it runs in, so writes made
+// through a store returned by NewWithTx aren't retried. A transaction has to
+// be retried as a whole by the caller.
+package productretry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Policy defines how a failed write is retried.
+type Policy struct {
+	// Attempts is the total number of times a write is tried, including
+	// the first one. A value below 1 is treated as 1.
+	Attempts int
+
+	// Backoff is the delay before the first retry. The delay doubles with
+	// every retry and is randomized so concurrent writers spread out.
+	Backoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+}
+
+// Store manages the set of APIs for product data access with retries.
+type Store struct {
+	log    *logger.Logger
+	storer productbus.Storer
+	policy Policy
+}
+
+// NewStore constructs the api for data access with retries.
+func NewStore(log *logger.Logger, storer productbus.Storer, policy Policy) *Store {
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+
+	if policy.Backoff <= 0 {
+		policy.Backoff = 50 * time.Millisecond
+	}
+
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = policy.Backoff
+	}
+
+	return &Store{
+		log:    log,
+		storer: storer,
+		policy: policy,
+	}
+}
+
+// NewWithTx returns the transactional store of the wrapped store. Writes
+// inside a transaction aren't retried.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	return s.storer.NewWithTx(tx)
+}
+
+// Create adds a Product, retrying transient failures.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	return s.retry(ctx, "create", func() error {
+		return s.storer.Create(ctx, prd)
+	})
+}
+
+// CreateUniqueName adds a Product unless its name is already in use,
+// retrying transient failures.
+func (s *Store) CreateUniqueName(ctx context.Context, prd productbus.Product) error {
+	return s.retry(ctx, "createuniquename", func() error {
+		return s.storer.CreateUniqueName(ctx, prd)
+	})
+}
+
+// Update modifies data about a product, retrying transient failures.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	return s.retry(ctx, "update", func() error {
+		return s.storer.Update(ctx, prd, version)
+	})
+}
+
+// Upsert adds the product or applies it to the product it conflicts with,
+// retrying transient failures.
+func (s *Store) Upsert(ctx context.Context, prd productbus.Product, target productbus.ConflictTarget) (productbus.Upserted, error) {
+	var res productbus.Upserted
+	err := s.retry(ctx, "upsert", func() error {
+		var err error
+		res, err = s.storer.Upsert(ctx, prd, target)
+		return err
+	})
+
+	return res, err
+}
+
+// Delete marks the product as deleted, retrying transient failures.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	return s.retry(ctx, "delete", func() error {
+		return s.storer.Delete(ctx, prd)
+	})
+}
+
+// DeleteByFilter marks every product matching the filter as deleted,
+// retrying transient failures.
+func (s *Store) DeleteByFilter(ctx context.Context, filter productbus.QueryFilter, now time.Time) ([]productbus.Product, error) {
+	var prds []productbus.Product
+	err := s.retry(ctx, "deletebyfilter", func() error {
+		var err error
+		prds, err = s.storer.DeleteByFilter(ctx, filter, now)
+		return err
+	})
+
+	return prds, err
+}
+
+// AdjustPriceByFilter adjusts the cost of every product matching the
+// filter, retrying transient failures.
+func (s *Store) AdjustPriceByFilter(ctx context.Context, filter productbus.QueryFilter, adj productbus.PriceAdjustment, now time.Time) ([]productbus.PriceAdjusted, error) {
+	var adjusted []productbus.PriceAdjusted
+	err := s.retry(ctx, "adjustpricebyfilter", func() error {
+		var err error
+		adjusted, err = s.storer.AdjustPriceByFilter(ctx, filter, adj, now)
+		return err
+	})
+
+	return adjusted, err
+}
+
+// AdjustStock changes the quantity of the product by delta, retrying
+// transient failures.
+func (s *Store) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (productbus.Product, error) {
+	var prd productbus.Product
+	err := s.retry(ctx, "adjuststock", func() error {
+		var err error
+		prd, err = s.storer.AdjustStock(ctx, tenantID, productID, delta, now)
+		return err
+	})
+
+	return prd, err
+}
+
+// Touch sets the update date of the product to now, retrying transient
+// failures.
+func (s *Store) Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (productbus.Product, error) {
+	var prd productbus.Product
+	err := s.retry(ctx, "touch", func() error {
+		var err error
+		prd, err = s.storer.Touch(ctx, tenantID, productID, now)
+		return err
+	})
+
+	return prd, err
+}
+
+// CreatePriceChange records a change of cost, retrying transient failures.
+func (s *Store) CreatePriceChange(ctx context.Context, pc productbus.PriceChange) error {
+	return s.retry(ctx, "createpricechange", func() error {
+		return s.storer.CreatePriceChange(ctx, pc)
+	})
+}
+
+// CreateIdempotencyKey records the key of a create, retrying transient
+// failures.
+func (s *Store) CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) error {
+	return s.retry(ctx, "createidempotencykey", func() error {
+		return s.storer.CreateIdempotencyKey(ctx, userID, key, productID, now, since)
+	})
+}
+
+// RecordView records that the user viewed the product.
+func (s *Store) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) error {
+	return s.storer.RecordView(ctx, userID, productID, now, keep)
+}
+
+// QueryRecentlyViewed retrieves the products the user viewed most recently.
+func (s *Store) QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]productbus.Product, error) {
+	return s.storer.QueryRecentlyViewed(ctx, tenantID, userID, limit)
+}
+
+// QueryPopular retrieves the products of the tenant that were viewed, the
+// most viewed first.
+func (s *Store) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]productbus.Popular, error) {
+	return s.storer.QueryPopular(ctx, tenantID, live, page)
+}
+
+// CountPopular returns the number of products QueryPopular ranks.
+func (s *Store) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error) {
+	return s.storer.CountPopular(ctx, tenantID, live)
+}
+
+// RefreshPopular recounts the views of the popular products.
+func (s *Store) RefreshPopular(ctx context.Context, now time.Time) error {
+	return s.storer.RefreshPopular(ctx, now)
+}
+
+// PopularRefreshed returns when the popular products were last refreshed.
+func (s *Store) PopularRefreshed(ctx context.Context) (time.Time, error) {
+	return s.storer.PopularRefreshed(ctx)
+}
+
+// SetPendingImage records the image being uploaded for the product, retrying
+// transient failures.
+func (s *Store) SetPendingImage(ctx context.Context, prd productbus.Product, key string) error {
+	return s.retry(ctx, "setpendingimage", func() error {
+		return s.storer.SetPendingImage(ctx, prd, key)
+	})
+}
+
+// ConfirmImage makes the pending image the image of the product, retrying
+// transient failures.
+func (s *Store) ConfirmImage(ctx context.Context, prd productbus.Product, key string, url string, now time.Time) (productbus.Product, error) {
+	var res productbus.Product
+	err := s.retry(ctx, "confirmimage", func() error {
+		var err error
+		res, err = s.storer.ConfirmImage(ctx, prd, key, url, now)
+		return err
+	})
+
+	return res, err
+}
+
+// WithSavepoint runs fn inside a savepoint. Savepoints only exist inside a
+// transaction so nothing is retried.
+func (s *Store) WithSavepoint(ctx context.Context, fn func() error) error {
+	return s.storer.WithSavepoint(ctx, fn)
+}
+
+// Query retrieves a list of existing products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) ([]productbus.Product, error) {
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByCursor retrieves the window of products that follow the cursor.
+func (s *Store) QueryByCursor(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, rows int) ([]productbus.Product, error) {
+	return s.storer.QueryByCursor(ctx, filter, cursor, rows)
+}
+
+// Count returns the number of products matching the filter.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	return s.storer.Count(ctx, filter)
+}
+
+// Summarize returns the totals of the products matching the filter.
+func (s *Store) Summarize(ctx context.Context, filter productbus.QueryFilter) (productbus.Summary, error) {
+	return s.storer.Summarize(ctx, filter)
+}
+
+// Search retrieves the products matching the full text query.
+func (s *Store) Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) ([]productbus.SearchResult, error) {
+	return s.storer.Search(ctx, tenantID, query, page)
+}
+
+// SearchCount returns the number of products matching the full text query.
+func (s *Store) SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (int, error) {
+	return s.storer.SearchCount(ctx, tenantID, query)
+}
+
+// FuzzySearch retrieves the products whose name is similar to the query.
+func (s *Store) FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) ([]productbus.SearchResult, error) {
+	return s.storer.FuzzySearch(ctx, tenantID, query, threshold, page)
+}
+
+// FuzzySearchCount returns the number of products whose name is similar to
+// the query.
+func (s *Store) FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (int, error) {
+	return s.storer.FuzzySearchCount(ctx, tenantID, query, threshold)
+}
+
+// QueryByID finds the product identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	return s.storer.QueryByID(ctx, tenantID, productID)
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (productbus.Product, error) {
+	return s.storer.QueryBySKU(ctx, tenantID, sku)
+}
+
+// NameExists reports whether a product with the name exists.
+func (s *Store) NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (bool, error) {
+	return s.storer.NameExists(ctx, tenantID, name)
+}
+
+// SKUExists reports whether a product uses the sku.
+func (s *Store) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error) {
+	return s.storer.SKUExists(ctx, tenantID, sku)
+}
+
+// NextSKUSequence returns the next value of the sku sequence.
+func (s *Store) NextSKUSequence(ctx context.Context) (int64, error) {
+	return s.storer.NextSKUSequence(ctx)
+}
+
+// QueryByIDs finds the products identified by the given IDs.
+func (s *Store) QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]productbus.Product, error) {
+	return s.storer.QueryByIDs(ctx, tenantID, productIDs)
+}
+
+// QueryByUserID finds the products of a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]productbus.Product, error) {
+	return s.storer.QueryByUserID(ctx, tenantID, userID)
+}
+
+// QueryPriceHistory retrieves the cost changes of a product.
+func (s *Store) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]productbus.PriceChange, error) {
+	return s.storer.QueryPriceHistory(ctx, productID, page)
+}
+
+// CountPriceHistory returns the number of cost changes of a product.
+func (s *Store) CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error) {
+	return s.storer.CountPriceHistory(ctx, productID)
+}
+
+// QuerySimilar retrieves the products of the same category as the product
+// whose cost is within the band of its cost.
+func (s *Store) QuerySimilar(ctx context.Context, prd productbus.Product, band float64, page page.Page) ([]productbus.Product, error) {
+	return s.storer.QuerySimilar(ctx, prd, band, page)
+}
+
+// CountSimilar returns the number of products of the same category as the
+// product whose cost is within the band of its cost.
+func (s *Store) CountSimilar(ctx context.Context, prd productbus.Product, band float64) (int, error) {
+	return s.storer.CountSimilar(ctx, prd, band)
+}
+
+// QueryIdempotencyKey finds the product created with the key.
+func (s *Store) QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (uuid.UUID, error) {
+	return s.storer.QueryIdempotencyKey(ctx, userID, key, since)
+}
+
+// =============================================================================
+
+// retry runs the write until it succeeds, fails with an error that isn't
+// retryable or runs out of attempts. The error of the last attempt is
+// returned unchanged.
+func (s *Store) retry(ctx context.Context, op string, write func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err == nil || attempt >= s.policy.Attempts || !sqldb.IsRetryable(err) {
+			return err
+		}
+
+		delay := s.backoff(attempt)
+
+		s.log.Info(ctx, "productretry", "status", "retrying write", "op", op, "attempt", attempt, "delay", delay, "err", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// backoff returns the delay before the retry that follows the attempt. The
+// exponential delay is jittered between half and all of its value.
+func (s *Store) backoff(attempt int) time.Duration {
+	delay := s.policy.MaxBackoff
+	if shift := attempt - 1; shift < 32 {
+		delay = min(s.policy.Backoff<<shift, s.policy.MaxBackoff)
+	}
+
+	half := delay / 2
+
+	return half + rand.N(half+1)
+}
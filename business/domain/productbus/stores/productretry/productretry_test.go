@@ -0,0 +1,99 @@
+package productretry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productretry"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// failingStore fails the first writes with the configured error and then
+// succeeds. Only the methods used by the tests are implemented.
+type failingStore struct {
+	productbus.Storer
+	failures int
+	err      error
+	calls    int
+}
+
+func (s *failingStore) Create(ctx context.Context, prd productbus.Product) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return s.err
+	}
+
+	return nil
+}
+
+func Test_Retry(t *testing.T) {
+	errSerialization := &pgconn.PgError{Code: "40001"}
+	errDeadlock := &pgconn.PgError{Code: "40P01"}
+	errUnique := &pgconn.PgError{Code: "23505"}
+	errOther := errors.New("other")
+
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		expErr   error
+		expCalls int
+	}{
+		{name: "success", failures: 0, expCalls: 1},
+		{name: "serialization", failures: 2, err: errSerialization, expCalls: 3},
+		{name: "deadlock", failures: 1, err: errDeadlock, expCalls: 2},
+		{name: "exhausted", failures: 5, err: errSerialization, expErr: errSerialization, expCalls: 3},
+		{name: "unique", failures: 1, err: errUnique, expErr: errUnique, expCalls: 1},
+		{name: "other", failures: 1, err: errOther, expErr: errOther, expCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+			fake := failingStore{failures: tt.failures, err: tt.err}
+			store := productretry.NewStore(log, &fake, productretry.Policy{
+				Attempts:   3,
+				Backoff:    time.Millisecond,
+				MaxBackoff: 2 * time.Millisecond,
+			})
+
+			err := store.Create(context.Background(), productbus.Product{})
+			if err != tt.expErr {
+				t.Fatalf("Should get error %v, got %v", tt.expErr, err)
+			}
+
+			if fake.calls != tt.expCalls {
+				t.Errorf("Should make %d calls, got %d", tt.expCalls, fake.calls)
+			}
+		})
+	}
+}
+
+func Test_RetryCanceled(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	errSerialization := &pgconn.PgError{Code: "40001"}
+
+	fake := failingStore{failures: 5, err: errSerialization}
+	store := productretry.NewStore(log, &fake, productretry.Policy{
+		Attempts: 3,
+		Backoff:  time.Hour,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := store.Create(ctx, productbus.Product{}); err != errSerialization {
+		t.Fatalf("Should get the serialization error, got %v", err)
+	}
+
+	if fake.calls != 1 {
+		t.Errorf("Should stop retrying once the context is done, got %d calls", fake.calls)
+	}
+}
@@ -0,0 +1,343 @@
+// Package productslow contains product related CRUD functionality that
+// logs the store operations taking longer than a threshold.
+//
+// Slow operations are logged at WARN level with the parameters they were
+// called with, such as the filter, order and page of a query. Products
+// aren't personal data so nothing is redacted.
+package productslow
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/sku"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for product data access that logs slow
+// operations.
+type Store struct {
+	log       *logger.Logger
+	storer    productbus.Storer
+	threshold time.Duration
+}
+
+// NewStore constructs the api for data access that logs the operations
+// taking at least threshold.
+func NewStore(log *logger.Logger, storer productbus.Storer, threshold time.Duration) *Store {
+	return &Store{
+		log:       log,
+		storer:    storer,
+		threshold: threshold,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the wrapped store with
+// its transactional version. The threshold is the one of this store.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:       s.log,
+		storer:    storer,
+		threshold: s.threshold,
+	}
+
+	return &store, nil
+}
+
+// Create adds a Product .
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	defer s.observe(ctx, "create", time.Now(), "product_id", prd.ID)
+	return s.storer.Create(ctx, prd)
+}
+
+// CreateUniqueName adds a Product unless its name is already in use.
+func (s *Store) CreateUniqueName(ctx context.Context, prd productbus.Product) error {
+	defer s.observe(ctx, "createuniquename", time.Now(), "product_id", prd.ID, "name", prd.Name)
+	return s.storer.CreateUniqueName(ctx, prd)
+}
+
+// Update modifies data about a product.
+func (s *Store) Update(ctx context.Context, prd productbus.Product, version time.Time) error {
+	defer s.observe(ctx, "update", time.Now(), "product_id", prd.ID)
+	return s.storer.Update(ctx, prd, version)
+}
+
+// Upsert adds the product or applies it to the product it conflicts with.
+func (s *Store) Upsert(ctx context.Context, prd productbus.Product, target productbus.ConflictTarget) (productbus.Upserted, error) {
+	defer s.observe(ctx, "upsert", time.Now(), "sku", prd.SKU, "conflict", target)
+	return s.storer.Upsert(ctx, prd, target)
+}
+
+// Delete marks the product as deleted.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	defer s.observe(ctx, "delete", time.Now(), "product_id", prd.ID)
+	return s.storer.Delete(ctx, prd)
+}
+
+// DeleteByFilter marks every product matching the filter as deleted.
+func (s *Store) DeleteByFilter(ctx context.Context, filter productbus.QueryFilter, now time.Time) ([]productbus.Product, error) {
+	defer s.observe(ctx, "deletebyfilter", time.Now(), "filter", filterValue(filter))
+	return s.storer.DeleteByFilter(ctx, filter, now)
+}
+
+// AdjustPriceByFilter adjusts the cost of every product matching the filter.
+func (s *Store) AdjustPriceByFilter(ctx context.Context, filter productbus.QueryFilter, adj productbus.PriceAdjustment, now time.Time) ([]productbus.PriceAdjusted, error) {
+	defer s.observe(ctx, "adjustpricebyfilter", time.Now(), "filter", filterValue(filter))
+	return s.storer.AdjustPriceByFilter(ctx, filter, adj, now)
+}
+
+// AdjustStock changes the quantity of the product by delta.
+func (s *Store) AdjustStock(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, delta int, now time.Time) (productbus.Product, error) {
+	defer s.observe(ctx, "adjuststock", time.Now(), "product_id", productID, "delta", delta)
+	return s.storer.AdjustStock(ctx, tenantID, productID, delta, now)
+}
+
+// Touch sets the update date of the product to now.
+func (s *Store) Touch(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID, now time.Time) (productbus.Product, error) {
+	defer s.observe(ctx, "touch", time.Now(), "product_id", productID)
+	return s.storer.Touch(ctx, tenantID, productID, now)
+}
+
+// Query retrieves a list of existing products.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) ([]productbus.Product, error) {
+	defer s.observe(ctx, "query", time.Now(), "filter", filterValue(filter), "order_by", orderValue(orderBy), "page", page.String())
+	return s.storer.Query(ctx, filter, orderBy, page)
+}
+
+// QueryByCursor retrieves the window of products that follow the cursor.
+func (s *Store) QueryByCursor(ctx context.Context, filter productbus.QueryFilter, cursor productbus.Cursor, rows int) ([]productbus.Product, error) {
+	defer s.observe(ctx, "querybycursor", time.Now(), "filter", filterValue(filter), "rows", rows)
+	return s.storer.QueryByCursor(ctx, filter, cursor, rows)
+}
+
+// Count returns the number of products matching the filter.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	defer s.observe(ctx, "count", time.Now(), "filter", filterValue(filter))
+	return s.storer.Count(ctx, filter)
+}
+
+// Summarize returns the totals of the products matching the filter.
+func (s *Store) Summarize(ctx context.Context, filter productbus.QueryFilter) (productbus.Summary, error) {
+	defer s.observe(ctx, "summarize", time.Now(), "filter", filterValue(filter))
+	return s.storer.Summarize(ctx, filter)
+}
+
+// Search retrieves the products matching the full text query.
+func (s *Store) Search(ctx context.Context, tenantID uuid.UUID, query string, page page.Page) ([]productbus.SearchResult, error) {
+	defer s.observe(ctx, "search", time.Now(), "tenant_id", tenantID, "query", query, "page", page.String())
+	return s.storer.Search(ctx, tenantID, query, page)
+}
+
+// SearchCount returns the number of products matching the full text query.
+func (s *Store) SearchCount(ctx context.Context, tenantID uuid.UUID, query string) (int, error) {
+	defer s.observe(ctx, "searchcount", time.Now(), "tenant_id", tenantID, "query", query)
+	return s.storer.SearchCount(ctx, tenantID, query)
+}
+
+// FuzzySearch retrieves the products whose name is similar to the query.
+func (s *Store) FuzzySearch(ctx context.Context, tenantID uuid.UUID, query string, threshold float64, page page.Page) ([]productbus.SearchResult, error) {
+	defer s.observe(ctx, "fuzzysearch", time.Now(), "tenant_id", tenantID, "query", query, "threshold", threshold, "page", page.String())
+	return s.storer.FuzzySearch(ctx, tenantID, query, threshold, page)
+}
+
+// FuzzySearchCount returns the number of products whose name is similar to
+// the query.
+func (s *Store) FuzzySearchCount(ctx context.Context, tenantID uuid.UUID, query string, threshold float64) (int, error) {
+	defer s.observe(ctx, "fuzzysearchcount", time.Now(), "tenant_id", tenantID, "query", query, "threshold", threshold)
+	return s.storer.FuzzySearchCount(ctx, tenantID, query, threshold)
+}
+
+// QueryByID finds the product identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, tenantID uuid.UUID, productID uuid.UUID) (productbus.Product, error) {
+	defer s.observe(ctx, "querybyid", time.Now(), "product_id", productID)
+	return s.storer.QueryByID(ctx, tenantID, productID)
+}
+
+// QueryBySKU finds the product identified by a given SKU.
+func (s *Store) QueryBySKU(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (productbus.Product, error) {
+	defer s.observe(ctx, "querybysku", time.Now(), "tenant_id", tenantID, "sku", sku)
+	return s.storer.QueryBySKU(ctx, tenantID, sku)
+}
+
+// NameExists reports whether a product with the name exists.
+func (s *Store) NameExists(ctx context.Context, tenantID uuid.UUID, name name.Name) (bool, error) {
+	defer s.observe(ctx, "nameexists", time.Now(), "tenant_id", tenantID, "name", name)
+	return s.storer.NameExists(ctx, tenantID, name)
+}
+
+// SKUExists reports whether a product uses the sku.
+func (s *Store) SKUExists(ctx context.Context, tenantID uuid.UUID, sku sku.SKU) (bool, error) {
+	defer s.observe(ctx, "skuexists", time.Now(), "tenant_id", tenantID, "sku", sku)
+	return s.storer.SKUExists(ctx, tenantID, sku)
+}
+
+// NextSKUSequence returns the next value of the sku sequence.
+func (s *Store) NextSKUSequence(ctx context.Context) (int64, error) {
+	defer s.observe(ctx, "nextskusequence", time.Now())
+	return s.storer.NextSKUSequence(ctx)
+}
+
+// QueryByIDs finds the products identified by the given IDs.
+func (s *Store) QueryByIDs(ctx context.Context, tenantID uuid.UUID, productIDs []uuid.UUID) ([]productbus.Product, error) {
+	defer s.observe(ctx, "querybyids", time.Now(), "tenant_id", tenantID, "product_ids", len(productIDs))
+	return s.storer.QueryByIDs(ctx, tenantID, productIDs)
+}
+
+// QueryByUserID finds the products of a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]productbus.Product, error) {
+	defer s.observe(ctx, "querybyuserid", time.Now(), "user_id", userID)
+	return s.storer.QueryByUserID(ctx, tenantID, userID)
+}
+
+// CreatePriceChange records a change of cost.
+func (s *Store) CreatePriceChange(ctx context.Context, pc productbus.PriceChange) error {
+	defer s.observe(ctx, "createpricechange", time.Now(), "product_id", pc.ProductID)
+	return s.storer.CreatePriceChange(ctx, pc)
+}
+
+// QueryPriceHistory retrieves the cost changes of a product.
+func (s *Store) QueryPriceHistory(ctx context.Context, productID uuid.UUID, page page.Page) ([]productbus.PriceChange, error) {
+	defer s.observe(ctx, "querypricehistory", time.Now(), "product_id", productID, "page", page.String())
+	return s.storer.QueryPriceHistory(ctx, productID, page)
+}
+
+// CountPriceHistory returns the number of cost changes of a product.
+func (s *Store) CountPriceHistory(ctx context.Context, productID uuid.UUID) (int, error) {
+	defer s.observe(ctx, "countpricehistory", time.Now(), "product_id", productID)
+	return s.storer.CountPriceHistory(ctx, productID)
+}
+
+// QuerySimilar retrieves the products of the same category as the product
+// whose cost is within the band of its cost.
+func (s *Store) QuerySimilar(ctx context.Context, prd productbus.Product, band float64, page page.Page) ([]productbus.Product, error) {
+	defer s.observe(ctx, "querysimilar", time.Now(), "product_id", prd.ID, "band", band, "page", page.String())
+	return s.storer.QuerySimilar(ctx, prd, band, page)
+}
+
+// CountSimilar returns the number of products of the same category as the
+// product whose cost is within the band of its cost.
+func (s *Store) CountSimilar(ctx context.Context, prd productbus.Product, band float64) (int, error) {
+	defer s.observe(ctx, "countsimilar", time.Now(), "product_id", prd.ID, "band", band)
+	return s.storer.CountSimilar(ctx, prd, band)
+}
+
+// QueryIdempotencyKey finds the product created with the key.
+func (s *Store) QueryIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, since time.Time) (uuid.UUID, error) {
+	defer s.observe(ctx, "queryidempotencykey", time.Now(), "user_id", userID)
+	return s.storer.QueryIdempotencyKey(ctx, userID, key, since)
+}
+
+// CreateIdempotencyKey records the key of a create.
+func (s *Store) CreateIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, productID uuid.UUID, now time.Time, since time.Time) error {
+	defer s.observe(ctx, "createidempotencykey", time.Now(), "user_id", userID, "product_id", productID)
+	return s.storer.CreateIdempotencyKey(ctx, userID, key, productID, now, since)
+}
+
+// RecordView records that the user viewed the product.
+func (s *Store) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time, keep int) error {
+	defer s.observe(ctx, "recordview", time.Now(), "user_id", userID, "product_id", productID)
+	return s.storer.RecordView(ctx, userID, productID, now, keep)
+}
+
+// QueryRecentlyViewed retrieves the products the user viewed most recently.
+func (s *Store) QueryRecentlyViewed(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]productbus.Product, error) {
+	defer s.observe(ctx, "queryrecentlyviewed", time.Now(), "user_id", userID, "limit", limit)
+	return s.storer.QueryRecentlyViewed(ctx, tenantID, userID, limit)
+}
+
+// QueryPopular retrieves the products of the tenant that were viewed, the
+// most viewed first.
+func (s *Store) QueryPopular(ctx context.Context, tenantID uuid.UUID, live bool, page page.Page) ([]productbus.Popular, error) {
+	defer s.observe(ctx, "querypopular", time.Now(), "live", live, "page", page.String())
+	return s.storer.QueryPopular(ctx, tenantID, live, page)
+}
+
+// CountPopular returns the number of products QueryPopular ranks.
+func (s *Store) CountPopular(ctx context.Context, tenantID uuid.UUID, live bool) (int, error) {
+	defer s.observe(ctx, "countpopular", time.Now(), "live", live)
+	return s.storer.CountPopular(ctx, tenantID, live)
+}
+
+// RefreshPopular recounts the views of the popular products.
+func (s *Store) RefreshPopular(ctx context.Context, now time.Time) error {
+	defer s.observe(ctx, "refreshpopular", time.Now())
+	return s.storer.RefreshPopular(ctx, now)
+}
+
+// PopularRefreshed returns when the popular products were last refreshed.
+func (s *Store) PopularRefreshed(ctx context.Context) (time.Time, error) {
+	defer s.observe(ctx, "popularrefreshed", time.Now())
+	return s.storer.PopularRefreshed(ctx)
+}
+
+// SetPendingImage records the image being uploaded for the product.
+func (s *Store) SetPendingImage(ctx context.Context, prd productbus.Product, key string) error {
+	defer s.observe(ctx, "setpendingimage", time.Now(), "product_id", prd.ID)
+	return s.storer.SetPendingImage(ctx, prd, key)
+}
+
+// ConfirmImage makes the pending image the image of the product.
+func (s *Store) ConfirmImage(ctx context.Context, prd productbus.Product, key string, url string, now time.Time) (productbus.Product, error) {
+	defer s.observe(ctx, "confirmimage", time.Now(), "product_id", prd.ID)
+	return s.storer.ConfirmImage(ctx, prd, key, url, now)
+}
+
+// WithSavepoint runs fn inside a savepoint. It isn't timed since its
+// duration is the one of the operations fn runs, which are timed.
+func (s *Store) WithSavepoint(ctx context.Context, fn func() error) error {
+	return s.storer.WithSavepoint(ctx, fn)
+}
+
+// =============================================================================
+
+// observe logs the method with its parameters when it took at least the
+// threshold to return.
+func (s *Store) observe(ctx context.Context, method string, start time.Time, args ...any) {
+	elapsed := time.Since(start)
+	if elapsed < s.threshold {
+		return
+	}
+
+	args = append([]any{"method", method, "elapsed", elapsed.String()}, args...)
+
+	// The caller skips observe and the method it was deferred by so the
+	// file logged is the one that called the store.
+	s.log.Warnc(ctx, 5, "slow product store operation", args...)
+}
+
+// filterValue defers formatting a filter until an operation is logged.
+type filterValue productbus.QueryFilter
+
+// LogValue implements the slog.LogValuer interface.
+func (f filterValue) LogValue() slog.Value {
+	return slog.StringValue(productbus.QueryFilter(f).String())
+}
+
+// orderValue defers formatting an order until an operation is logged.
+type orderValue []order.By
+
+// LogValue implements the slog.LogValuer interface.
+func (o orderValue) LogValue() slog.Value {
+	fields := make([]string, len(o))
+	for i, by := range o {
+		fields[i] = by.Field + "," + by.Direction
+		if by.Nulls != "" {
+			fields[i] += "," + by.Nulls
+		}
+	}
+
+	return slog.StringValue(strings.Join(fields, ";"))
+}
@@ -0,0 +1,67 @@
+package productslow_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productslow"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// sleepingStore takes the configured delay to answer a query. Only the
+// methods used by the test are implemented.
+type sleepingStore struct {
+	productbus.Storer
+	delay time.Duration
+	prds  []productbus.Product
+}
+
+func (s *sleepingStore) Query(ctx context.Context, filter productbus.QueryFilter, orderBy []order.By, page page.Page) ([]productbus.Product, error) {
+	time.Sleep(s.delay)
+	return s.prds, nil
+}
+
+func Test_Slow(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	fake := sleepingStore{prds: []productbus.Product{{}}}
+	store := productslow.NewStore(log, &fake, 20*time.Millisecond)
+
+	quantity := 3
+	filter := productbus.QueryFilter{Quantity: &quantity}
+	orderBy := []order.By{order.NewBy("name", order.ASC)}
+
+	ctx := context.Background()
+
+	prds, err := store.Query(ctx, filter, orderBy, page.MustParse("1", "10"))
+	if err != nil {
+		t.Fatalf("Should be able to query: %s", err)
+	}
+
+	if len(prds) != 1 {
+		t.Fatalf("Should get back the products of the store, got %d", len(prds))
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("Should not log a fast query, got %s", buf.String())
+	}
+
+	fake.delay = 30 * time.Millisecond
+	if _, err := store.Query(ctx, filter, orderBy, page.MustParse("1", "10")); err != nil {
+		t.Fatalf("Should be able to query: %s", err)
+	}
+
+	out := buf.String()
+	for _, exp := range []string{`"level":"WARN"`, `"method":"query"`, `"filter":"quantity=\"3\";"`, `"order_by":"name,ASC"`, `"elapsed":`} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Should log %s, got %s", exp, out)
+		}
+	}
+}
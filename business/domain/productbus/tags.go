@@ -0,0 +1,33 @@
+package productbus
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ardanlabs/service/business/types/tag"
+)
+
+// TagMatch sets how the tags of a filter must match the tags of a product.
+type TagMatch string
+
+// Set of the ways the tags of a filter can match. TagMatchAll is used when
+// none is set.
+const (
+	TagMatchAll TagMatch = "all"
+	TagMatchAny TagMatch = "any"
+)
+
+// normalizeTags returns the tags sorted by name without duplicates, the
+// order the store returns them in. Names are compared byte by byte.
+func normalizeTags(tags []tag.Tag) []tag.Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tags = slices.Clone(tags)
+	slices.SortFunc(tags, func(a, b tag.Tag) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return slices.CompactFunc(tags, tag.Tag.Equal)
+}
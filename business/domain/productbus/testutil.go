@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"strings"
 
 	"github.com/ardanlabs/service/business/types/money"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/ardanlabs/service/business/types/sku"
 	"github.com/google/uuid"
 )
 
@@ -20,6 +22,7 @@ func TestGenerateNewProducts(n int, userID uuid.UUID) []NewProduct {
 		idx++
 
 		np := NewProduct{
+			SKU:      TestGenerateSKU(),
 			Name:     name.MustParse(fmt.Sprintf("Name%d", idx)),
 			Cost:     money.MustParse(float64(rand.Intn(500))),
 			Quantity: quantity.MustParse(rand.Intn(50)),
@@ -32,6 +35,12 @@ func TestGenerateNewProducts(n int, userID uuid.UUID) []NewProduct {
 	return newPrds
 }
 
+// TestGenerateSKU is a helper method for testing that returns a SKU that
+// isn't used by any other product.
+func TestGenerateSKU() sku.SKU {
+	return sku.MustParse("SKU-" + strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")))
+}
+
 // TestGenerateSeedProducts is a helper method for testing.
 func TestGenerateSeedProducts(ctx context.Context, n int, api *Business, userID uuid.UUID) ([]Product, error) {
 	newPrds := TestGenerateNewProducts(n, userID)
@@ -0,0 +1,50 @@
+package productbus
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// filterAttribute summarizes a filter for a span by listing the fields that
+// are set. The values are left out so spans don't carry user provided data.
+func filterAttribute(filter QueryFilter) attribute.KeyValue {
+	fields := []string{}
+
+	add := func(set bool, field string) {
+		if set {
+			fields = append(fields, field)
+		}
+	}
+
+	add(filter.ID != nil, "id")
+	add(filter.SKU != nil, "sku")
+	add(filter.Name != nil, "name")
+	add(filter.NameLike != nil, "name_like")
+	add(filter.Cost != nil, "cost")
+	add(filter.Quantity != nil, "quantity")
+	add(filter.MinCost != nil, "min_cost")
+	add(filter.MaxCost != nil, "max_cost")
+	add(filter.MinQuantity != nil, "min_quantity")
+	add(filter.MaxQuantity != nil, "max_quantity")
+	add(filter.CategoryID != nil, "category_id")
+	add(len(filter.Tags) > 0, "tags")
+	add(filter.CreatedAfter != nil, "created_after")
+	add(filter.CreatedBefore != nil, "created_before")
+	add(filter.UpdatedAfter != nil, "updated_after")
+	add(filter.UpdatedBefore != nil, "updated_before")
+	add(filter.IncludeDeleted != nil && *filter.IncludeDeleted, "include_deleted")
+	add(filter.Active != nil && *filter.Active, "active")
+
+	return attribute.StringSlice("product.filter", fields)
+}
+
+// endSpan records the error on the span, if there is one, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,86 @@
+package productbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/tenant"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// MaxViewsPerUser is the number of recently viewed products kept per user.
+// Older views are dropped as new ones are recorded.
+const MaxViewsPerUser = 50
+
+// maxPendingViews bounds the number of views being recorded at once. Views
+// past it are dropped rather than queued since the history is best effort.
+const maxPendingViews = 100
+
+// recordViewTimeout bounds how long recording a view can take.
+const recordViewTimeout = 5 * time.Second
+
+// viewRecorder records product views in the background so reads don't wait
+// for the write. It holds the storer of the business it was created for, so
+// a business using a transaction still records views outside of it.
+type viewRecorder struct {
+	log     *logger.Logger
+	storer  Storer
+	pending chan struct{}
+}
+
+func newViewRecorder(log *logger.Logger, storer Storer) *viewRecorder {
+	return &viewRecorder{
+		log:     log,
+		storer:  storer,
+		pending: make(chan struct{}, maxPendingViews),
+	}
+}
+
+func (vr *viewRecorder) record(ctx context.Context, userID uuid.UUID, productID uuid.UUID, now time.Time) {
+	select {
+	case vr.pending <- struct{}{}:
+	default:
+		vr.log.Info(ctx, "recordview", "status", "dropped, too many pending views", "user_id", userID, "product_id", productID)
+		return
+	}
+
+	// The view outlives the request that triggered it.
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer func() { <-vr.pending }()
+
+		ctx, cancel := context.WithTimeout(ctx, recordViewTimeout)
+		defer cancel()
+
+		if err := vr.storer.RecordView(ctx, userID, productID, now, MaxViewsPerUser); err != nil {
+			vr.log.Error(ctx, "recordview", "user_id", userID, "product_id", productID, "err", err)
+		}
+	}()
+}
+
+// =============================================================================
+
+// RecordView notes that the user viewed the product. It returns without
+// waiting for the view to be stored, and a view that can't be stored is only
+// logged.
+func (b *Business) RecordView(ctx context.Context, userID uuid.UUID, productID uuid.UUID) {
+	b.views.record(ctx, userID, productID, time.Now())
+}
+
+// QueryRecentlyViewed returns up to limit products the user viewed, the most
+// recently viewed first. Products that were deleted since are left out.
+func (b *Business) QueryRecentlyViewed(ctx context.Context, userID uuid.UUID, limit int) ([]Product, error) {
+	ctx, span := otel.AddSpan(ctx, "business.productbus.queryrecentlyviewed")
+	defer span.End()
+
+	prds, err := b.storer.QueryRecentlyViewed(ctx, tenant.Get(ctx), userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return prds, nil
+}
@@ -109,7 +109,7 @@ func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
 // Query retrieves a list of existing users from the database.
 func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
 	data := map[string]any{
-		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"offset":        page.Offset(),
 		"rows_per_page": page.RowsPerPage(),
 	}
 
@@ -8,6 +8,10 @@ import (
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
 type QueryFilter struct {
+	// TenantID is always replaced with the tenant of the context by the
+	// business layer so a query can't reach the products of another tenant.
+	TenantID *uuid.UUID
+
 	ID       *uuid.UUID
 	Name     *name.Name
 	Cost     *float64
@@ -11,6 +11,11 @@ import (
 func (s *Store) applyFilter(filter vproductbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
 	var wc []string
 
+	if filter.TenantID != nil {
+		data["tenant_id"] = filter.TenantID
+		wc = append(wc, "tenant_id = :tenant_id")
+	}
+
 	if filter.ID != nil {
 		data["product_id"] = filter.ID
 		wc = append(wc, "product_id = :product_id")
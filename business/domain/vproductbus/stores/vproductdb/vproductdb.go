@@ -31,7 +31,7 @@ func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
 // Query retrieves a list of existing products from the database.
 func (s *Store) Query(ctx context.Context, filter vproductbus.QueryFilter, orderBy order.By, page page.Page) ([]vproductbus.Product, error) {
 	data := map[string]any{
-		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"offset":        page.Offset(),
 		"rows_per_page": page.RowsPerPage(),
 	}
 
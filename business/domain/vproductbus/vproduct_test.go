@@ -12,9 +12,11 @@ import (
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
 	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/tenant"
 	"github.com/ardanlabs/service/business/sdk/unitest"
 	"github.com/ardanlabs/service/business/types/role"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 func Test_VProduct(t *testing.T) {
@@ -150,6 +152,27 @@ func query(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				return cmp.Diff(gotResp, expResp)
 			},
 		},
+		{
+			Name:    "other-tenant",
+			ExpResp: []vproductbus.Product{},
+			ExcFunc: func(ctx context.Context) any {
+				ctx = tenant.Set(ctx, uuid.New())
+
+				filter := vproductbus.QueryFilter{
+					Name: dbtest.NamePointer("Name"),
+				}
+
+				resp, err := busDomain.VProduct.Query(ctx, filter, vproductbus.DefaultOrderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
 	}
 
 	return table
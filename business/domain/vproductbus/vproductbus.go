@@ -7,6 +7,7 @@ import (
 
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/tenant"
 	"github.com/ardanlabs/service/foundation/otel"
 )
 
@@ -34,7 +35,7 @@ func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.
 	ctx, span := otel.AddSpan(ctx, "business.vproductbus.query")
 	defer span.End()
 
-	users, err := b.storer.Query(ctx, filter, orderBy, page)
+	users, err := b.storer.Query(ctx, scopeFilter(ctx, filter), orderBy, page)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
@@ -47,5 +48,13 @@ func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
 	ctx, span := otel.AddSpan(ctx, "business.vproductbus.count")
 	defer span.End()
 
-	return b.storer.Count(ctx, filter)
+	return b.storer.Count(ctx, scopeFilter(ctx, filter))
+}
+
+// scopeFilter limits the filter to the products of the tenant of the context.
+func scopeFilter(ctx context.Context, filter QueryFilter) QueryFilter {
+	tenantID := tenant.Get(ctx)
+	filter.TenantID = &tenantID
+
+	return filter
 }
@@ -5,6 +5,8 @@ import (
 
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/service/business/domain/categorybus"
+	"github.com/ardanlabs/service/business/domain/categorybus/stores/categorydb"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
 	"github.com/ardanlabs/service/business/domain/productbus"
@@ -25,6 +27,7 @@ import (
 type BusDomain struct {
 	Delegate *delegate.Delegate
 	Audit    *auditbus.Business
+	Category *categorybus.Business
 	Home     *homebus.Business
 	Product  *productbus.Business
 	User     userbus.ExtBusiness
@@ -42,10 +45,12 @@ func newBusDomains(log *logger.Logger, db *sqlx.DB) BusDomain {
 	productBus := productbus.NewBusiness(log, userBus, delegate, productdb.NewStore(log, db))
 	homeBus := homebus.NewBusiness(log, userBus, delegate, homedb.NewStore(log, db))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(log, db))
+	categoryBus := categorybus.NewBusiness(log, categorydb.NewStore(log, db))
 
 	return BusDomain{
 		Delegate: delegate,
 		Audit:    auditBus,
+		Category: categoryBus,
 		Home:     homeBus,
 		Product:  productBus,
 		User:     userBus,
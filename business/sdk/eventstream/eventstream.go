@@ -0,0 +1,117 @@
+// Package eventstream provides support for streaming domain events to the
+// clients subscribed to them. It plugs into the delegate system like the
+// webhook package so domains don't need to know the events are streamed.
+package eventstream
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Hub fans the events it's sent out to every subscription.
+type Hub struct {
+	log    *logger.Logger
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// New constructs a hub without subscriptions.
+func New(log *logger.Logger) *Hub {
+	return &Hub{
+		log:  log,
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Register adds the hub to the delegate for the specified domain and set of
+// actions.
+func (h *Hub) Register(dlg *delegate.Delegate, domain string, actions ...string) {
+	for _, action := range actions {
+		dlg.Register(domain, action, h.Send)
+	}
+}
+
+// Send passes the event to every subscription. A subscription that isn't
+// keeping up misses the event rather than holding up the caller. Send
+// implements the delegate.Func signature.
+func (h *Hub) Send(ctx context.Context, data delegate.Data) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		select {
+		case sub.events <- data:
+		default:
+			h.log.Warn(ctx, "eventstream", "status", "subscription full, event dropped", "domain", data.Domain, "action", data.Action)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns a subscription buffering up to size events. The
+// subscription must be closed once it's no longer read.
+func (h *Hub) Subscribe(size int) *Subscription {
+	sub := Subscription{
+		hub:    h,
+		events: make(chan delegate.Data, size),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(sub.events)
+		return &sub
+	}
+
+	h.subs[&sub] = struct{}{}
+
+	return &sub
+}
+
+// Close ends every subscription so the streams reading them finish, like
+// when the service shuts down. Later subscriptions are ended right away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// =============================================================================
+
+// Subscription receives the events sent to a hub.
+type Subscription struct {
+	hub    *Hub
+	events chan delegate.Data
+}
+
+// Events returns the channel the events are received on. It's closed when
+// the subscription or the hub is closed.
+func (s *Subscription) Events() <-chan delegate.Data {
+	return s.events
+}
+
+// Close stops the events from being received. It's safe to call more than
+// once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if _, exists := s.hub.subs[s]; !exists {
+		return
+	}
+
+	delete(s.hub.subs, s)
+	close(s.events)
+}
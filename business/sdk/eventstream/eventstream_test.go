@@ -0,0 +1,64 @@
+package eventstream_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/eventstream"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Hub(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	ctx := context.Background()
+
+	hub := eventstream.New(log)
+
+	dlg := delegate.New(log)
+	hub.Register(dlg, "product", "created")
+
+	first := hub.Subscribe(1)
+	second := hub.Subscribe(1)
+
+	data := delegate.Data{Domain: "product", Action: "created", RawParams: []byte(`{}`)}
+	if err := dlg.Call(ctx, data); err != nil {
+		t.Fatalf("Should be able to call the delegate: %s", err)
+	}
+
+	for i, sub := range []*eventstream.Subscription{first, second} {
+		if got := <-sub.Events(); got.Action != "created" {
+			t.Errorf("subscription[%d]: Should receive the event, got %v", i, got)
+		}
+	}
+
+	second.Close()
+	second.Close()
+
+	if _, ok := <-second.Events(); ok {
+		t.Error("Should end a closed subscription")
+	}
+
+	// The second event is dropped since the first one isn't read.
+	for range 2 {
+		if err := hub.Send(ctx, data); err != nil {
+			t.Fatalf("Should not block on a full subscription: %s", err)
+		}
+	}
+
+	hub.Close()
+
+	var got int
+	for range first.Events() {
+		got++
+	}
+
+	if got != 1 {
+		t.Errorf("Should buffer up to the size of the subscription, got %d", got)
+	}
+
+	if _, ok := <-hub.Subscribe(1).Events(); ok {
+		t.Error("Should end the subscriptions made after the hub is closed")
+	}
+}
@@ -79,4 +79,207 @@ CREATE TABLE audit (
     timestamp   TIMESTAMP NOT NULL,
 
     PRIMARY KEY (id)
-);
\ No newline at end of file
+);
+
+-- Version: 1.06
+-- Description: Add soft delete support to products
+ALTER TABLE products ADD COLUMN date_deleted TIMESTAMP NULL;
+
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.date_deleted IS NULL;
+
+-- Version: 1.07
+-- Description: Create table product_idempotency
+CREATE TABLE product_idempotency (
+    user_id         UUID      NOT NULL,
+    idempotency_key TEXT      NOT NULL,
+    product_id      UUID      NOT NULL,
+    date_created    TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user_id, idempotency_key),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.08
+-- Description: Add unaccent support for name ordering
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- Version: 1.09
+-- Description: Create table categories
+CREATE TABLE categories (
+    category_id  UUID      NOT NULL,
+    name         TEXT      UNIQUE NOT NULL,
+    date_created TIMESTAMP NOT NULL,
+    date_updated TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (category_id)
+);
+
+ALTER TABLE products ADD COLUMN category_id UUID NULL REFERENCES categories(category_id) ON DELETE RESTRICT;
+
+-- Version: 1.10
+-- Description: Add description and full text search to products
+ALTER TABLE products ADD COLUMN description TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX products_search_idx ON products USING GIN (to_tsvector('english', name || ' ' || description));
+
+-- Version: 1.11
+-- Description: Create table product_price_history
+CREATE TABLE product_price_history (
+    history_id   UUID           NOT NULL,
+    product_id   UUID           NOT NULL,
+    old_cost     NUMERIC(10, 2) NOT NULL,
+    new_cost     NUMERIC(10, 2) NOT NULL,
+    date_changed TIMESTAMP      NOT NULL,
+
+    PRIMARY KEY (history_id),
+    FOREIGN KEY (product_id) REFERENCES products(product_id) ON DELETE CASCADE
+);
+
+CREATE INDEX product_price_history_product_idx ON product_price_history (product_id, date_changed);
+
+-- Version: 1.12
+-- Description: Add a unique sku to products
+ALTER TABLE products ADD COLUMN sku TEXT NULL;
+
+UPDATE products SET sku = 'SKU-' || upper(replace(product_id::text, '-', ''));
+
+ALTER TABLE products ALTER COLUMN sku SET NOT NULL;
+
+CREATE UNIQUE INDEX products_sku_idx ON products (sku);
+
+-- Version: 1.13
+-- Description: Scope products to a tenant
+ALTER TABLE products ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE products ALTER COLUMN tenant_id DROP DEFAULT;
+
+DROP INDEX products_sku_idx;
+
+CREATE UNIQUE INDEX products_tenant_sku_idx ON products (tenant_id, sku);
+
+-- Version: 1.14
+-- Description: Reject negative product costs
+ALTER TABLE products ADD CONSTRAINT products_cost_check CHECK (cost >= 0);
+
+-- Version: 1.15
+-- Description: Index product names ignoring case for availability checks
+CREATE INDEX products_tenant_name_lower_idx ON products (tenant_id, lower(name)) WHERE date_deleted IS NULL;
+
+-- Version: 1.16
+-- Description: Create table product_views
+CREATE TABLE product_views (
+    user_id     UUID      NOT NULL,
+    product_id  UUID      NOT NULL,
+    date_viewed TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user_id, product_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(product_id) ON DELETE CASCADE
+);
+
+CREATE INDEX product_views_user_date_idx ON product_views (user_id, date_viewed DESC);
+
+-- Version: 1.17
+-- Description: Add images to products
+ALTER TABLE products ADD COLUMN image_url TEXT NULL;
+ALTER TABLE products ADD COLUMN pending_image_key TEXT NULL;
+
+-- Version: 1.18
+-- Description: Index product names by trigrams for fuzzy searches
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX products_name_trgm_idx ON products USING GIN (name gin_trgm_ops) WHERE date_deleted IS NULL;
+
+-- Version: 1.19
+-- Description: Number the generated product skus
+CREATE SEQUENCE product_sku_seq;
+
+-- Version: 1.20
+-- Description: Index the active products, not deleted and in stock
+CREATE INDEX products_active_idx ON products (tenant_id, product_id) WHERE date_deleted IS NULL AND quantity > 0;
+
+-- Version: 1.21
+-- Description: Create tables tags and product_tags
+CREATE TABLE tags (
+    tag_id    UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    name      TEXT NOT NULL,
+
+    PRIMARY KEY (tag_id),
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE product_tags (
+    product_id UUID NOT NULL,
+    tag_id     UUID NOT NULL,
+
+    PRIMARY KEY (product_id, tag_id),
+    FOREIGN KEY (product_id) REFERENCES products(product_id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(tag_id) ON DELETE CASCADE
+);
+
+CREATE INDEX product_tags_tag_idx ON product_tags (tag_id);
+
+CREATE FUNCTION product_tag_names(id UUID) RETURNS TEXT[] LANGUAGE SQL STABLE AS $$
+    SELECT COALESCE(array_agg(t.name ORDER BY t.name COLLATE "C"), '{}') FROM product_tags AS pt JOIN tags AS t ON t.tag_id = pt.tag_id WHERE pt.product_id = id
+$$;
+
+-- Version: 1.22
+-- Description: Create materialized view popular_products
+CREATE MATERIALIZED VIEW popular_products AS
+SELECT
+    p.product_id,
+    p.tenant_id,
+    count(1) AS views
+FROM
+    product_views AS v
+JOIN
+    products AS p ON p.product_id = v.product_id
+WHERE
+    p.date_deleted IS NULL
+GROUP BY
+    p.product_id, p.tenant_id;
+
+CREATE UNIQUE INDEX popular_products_product_idx ON popular_products (product_id);
+CREATE INDEX popular_products_tenant_views_idx ON popular_products (tenant_id, views DESC, product_id);
+
+CREATE TABLE view_refreshes (
+    view_name      TEXT      NOT NULL,
+    date_refreshed TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (view_name)
+);
+
+-- Version: 1.23
+-- Description: Add the tenant to the products view
+CREATE OR REPLACE VIEW view_products AS
+SELECT
+    p.product_id,
+    p.user_id,
+	p.name,
+    p.cost,
+	p.quantity,
+    p.date_created,
+    p.date_updated,
+    u.name AS user_name,
+    p.tenant_id
+FROM
+    products AS p
+JOIN
+    users AS u ON u.user_id = p.user_id
+WHERE
+    p.date_deleted IS NULL;
@@ -3,6 +3,7 @@ package order
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -17,10 +18,24 @@ var directions = map[string]string{
 	DESC: "DESC",
 }
 
-// By represents a field used to order by and direction.
+// Set of placements of the nulls of a field.
+const (
+	NullsFirst = "NULLS FIRST"
+	NullsLast  = "NULLS LAST"
+)
+
+var nulls = map[string]string{
+	"NULLS_FIRST": NullsFirst,
+	"NULLS_LAST":  NullsLast,
+}
+
+// By represents a field used to order by and direction. Nulls places the
+// nulls of the field, it's empty when the store's default placement for the
+// field is used.
 type By struct {
 	Field     string
 	Direction string
+	Nulls     string
 }
 
 // NewBy constructs a new By value with no checks.
@@ -38,9 +53,44 @@ func NewBy(field string, direction string) By {
 	}
 }
 
+// AllowList maps the names of the fields clients can order by to the fields
+// known by the business layer. Ordering by any other field is rejected, so
+// only fields the store can sort efficiently should be listed.
+type AllowList map[string]string
+
+// Allows reports whether the business field is listed.
+func (al AllowList) Allows(field string) bool {
+	for _, v := range al {
+		if v == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Names returns the sorted names clients can order by.
+func (al AllowList) Names() []string {
+	names := make([]string, 0, len(al))
+	for name := range al {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
 // Parse constructs a By value by parsing a string in the form of
-// "field,direction" ie "user_id,ASC".
-func Parse(fieldMappings map[string]string, orderBy string, defaultOrder By) (By, error) {
+// "field,direction" ie "user_id,ASC". A third part places the nulls of the
+// field, ie "user_id,ASC,NULLS_FIRST". The direction and the placement are
+// case insensitive.
+// The field must be in the allow list, and so must the field of the default
+// order since it's what the query falls back to.
+func Parse(allowed AllowList, orderBy string, defaultOrder By) (By, error) {
+	if !allowed.Allows(defaultOrder.Field) {
+		return By{}, fmt.Errorf("default order %q is not in the allow list", defaultOrder.Field)
+	}
+
 	if orderBy == "" {
 		return defaultOrder, nil
 	}
@@ -48,9 +98,9 @@ func Parse(fieldMappings map[string]string, orderBy string, defaultOrder By) (By
 	orderParts := strings.Split(orderBy, ",")
 
 	orgFieldName := strings.TrimSpace(orderParts[0])
-	fieldName, exists := fieldMappings[orgFieldName]
+	fieldName, exists := allowed[orgFieldName]
 	if !exists {
-		return By{}, fmt.Errorf("unknown order: %s", orgFieldName)
+		return By{}, fmt.Errorf("unknown order: %s, must be one of %s", orgFieldName, strings.Join(allowed.Names(), ", "))
 	}
 
 	switch len(orderParts) {
@@ -58,14 +108,61 @@ func Parse(fieldMappings map[string]string, orderBy string, defaultOrder By) (By
 		return NewBy(fieldName, ASC), nil
 
 	case 2:
-		direction := strings.TrimSpace(orderParts[1])
+		direction := strings.ToUpper(strings.TrimSpace(orderParts[1]))
 		if _, exists := directions[direction]; !exists {
 			return By{}, fmt.Errorf("unknown direction: %s", direction)
 		}
 
 		return NewBy(fieldName, direction), nil
 
+	case 3:
+		direction := strings.ToUpper(strings.TrimSpace(orderParts[1]))
+		if _, exists := directions[direction]; !exists {
+			return By{}, fmt.Errorf("unknown direction: %s", direction)
+		}
+
+		placement, exists := nulls[strings.ToUpper(strings.TrimSpace(orderParts[2]))]
+		if !exists {
+			return By{}, fmt.Errorf("unknown nulls placement: %s, must be NULLS_FIRST or NULLS_LAST", strings.TrimSpace(orderParts[2]))
+		}
+
+		by := NewBy(fieldName, direction)
+		by.Nulls = placement
+
+		return by, nil
+
 	default:
 		return By{}, fmt.Errorf("unknown order: %s", orderBy)
 	}
 }
+
+// ParseMany constructs an ordered set of By values by parsing a string of
+// clauses separated by a semicolon, ie "name,ASC;date_updated,DESC". Each
+// clause is validated with Parse and the first unknown field is reported.
+func ParseMany(allowed AllowList, orderBy string, defaultOrder By) ([]By, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		if !allowed.Allows(defaultOrder.Field) {
+			return nil, fmt.Errorf("default order %q is not in the allow list", defaultOrder.Field)
+		}
+
+		return []By{defaultOrder}, nil
+	}
+
+	clauses := strings.Split(orderBy, ";")
+	bys := make([]By, 0, len(clauses))
+
+	for _, clause := range clauses {
+		if strings.TrimSpace(clause) == "" {
+			return nil, fmt.Errorf("empty order clause: %s", orderBy)
+		}
+
+		by, err := Parse(allowed, clause, defaultOrder)
+		if err != nil {
+			return nil, err
+		}
+
+		bys = append(bys, by)
+	}
+
+	return bys, nil
+}
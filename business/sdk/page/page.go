@@ -2,51 +2,104 @@
 package page
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 )
 
-// Page represents the requested page and rows per page.
+// DefaultMaxRowsPerPage is the largest rows per page value accepted when no
+// other maximum is configured.
+const DefaultMaxRowsPerPage = 100
+
+// Set of errors identifying which of the paging values is invalid.
+var (
+	ErrInvalidPage   = errors.New("invalid page")
+	ErrInvalidRows   = errors.New("invalid rows")
+	ErrInvalidOffset = errors.New("invalid offset")
+)
+
+// Page represents the requested page and rows per page. A page parsed from
+// a limit and offset starts at the offset, which doesn't have to be a
+// multiple of the rows per page.
 type Page struct {
 	number int
 	rows   int
+	offset int
 }
 
 // Parse parses the strings and validates the values are in reason.
 func Parse(page string, rowsPerPage string) (Page, error) {
-	number := 1
-	if page != "" {
-		var err error
-		number, err = strconv.Atoi(page)
-		if err != nil {
-			return Page{}, fmt.Errorf("page conversion: %w", err)
-		}
+	p, err := parse(page, rowsPerPage)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if p.rows > DefaultMaxRowsPerPage {
+		return Page{}, fmt.Errorf("%w: value too large, must be less than %d", ErrInvalidRows, DefaultMaxRowsPerPage)
+	}
+
+	return p, nil
+}
+
+// ParseClamped parses the strings like Parse, but a rows per page value above
+// max is lowered to max instead of being rejected. DefaultMaxRowsPerPage is
+// used when max is not larger than 0.
+func ParseClamped(page string, rowsPerPage string, max int) (Page, error) {
+	if max <= 0 {
+		max = DefaultMaxRowsPerPage
+	}
+
+	p, err := parse(page, rowsPerPage)
+	if err != nil {
+		return Page{}, err
+	}
+
+	p.rows = min(p.rows, max)
+	p.offset = (p.number - 1) * p.rows
+
+	return p, nil
+}
+
+// ParseOffsetClamped parses a limit and offset into a page holding limit rows
+// that starts at the offset. The page number is the page holding the first
+// row. A limit above max is lowered to max like ParseClamped does.
+func ParseOffsetClamped(limit string, offset string, max int) (Page, error) {
+	if max <= 0 {
+		max = DefaultMaxRowsPerPage
 	}
 
 	rows := 10
-	if rowsPerPage != "" {
+	if limit != "" {
 		var err error
-		rows, err = strconv.Atoi(rowsPerPage)
+		rows, err = strconv.Atoi(limit)
 		if err != nil {
-			return Page{}, fmt.Errorf("rows conversion: %w", err)
+			return Page{}, fmt.Errorf("%w: conversion: %w", ErrInvalidRows, err)
 		}
 	}
 
-	if number <= 0 {
-		return Page{}, fmt.Errorf("page value too small, must be larger than 0")
+	var skip int
+	if offset != "" {
+		var err error
+		skip, err = strconv.Atoi(offset)
+		if err != nil {
+			return Page{}, fmt.Errorf("%w: conversion: %w", ErrInvalidOffset, err)
+		}
 	}
 
 	if rows <= 0 {
-		return Page{}, fmt.Errorf("rows value too small, must be larger than 0")
+		return Page{}, fmt.Errorf("%w: value too small, must be larger than 0", ErrInvalidRows)
 	}
 
-	if rows > 100 {
-		return Page{}, fmt.Errorf("rows value too large, must be less than 100")
+	if skip < 0 {
+		return Page{}, fmt.Errorf("%w: value too small, must not be negative", ErrInvalidOffset)
 	}
 
+	rows = min(rows, max)
+
 	p := Page{
-		number: number,
+		number: skip/rows + 1,
 		rows:   rows,
+		offset: skip,
 	}
 
 	return p, nil
@@ -76,3 +129,44 @@ func (p Page) Number() int {
 func (p Page) RowsPerPage() int {
 	return p.rows
 }
+
+// Offset returns the number of rows to skip before the page.
+func (p Page) Offset() int {
+	return p.offset
+}
+
+func parse(page string, rowsPerPage string) (Page, error) {
+	number := 1
+	if page != "" {
+		var err error
+		number, err = strconv.Atoi(page)
+		if err != nil {
+			return Page{}, fmt.Errorf("%w: conversion: %w", ErrInvalidPage, err)
+		}
+	}
+
+	rows := 10
+	if rowsPerPage != "" {
+		var err error
+		rows, err = strconv.Atoi(rowsPerPage)
+		if err != nil {
+			return Page{}, fmt.Errorf("%w: conversion: %w", ErrInvalidRows, err)
+		}
+	}
+
+	if number <= 0 {
+		return Page{}, fmt.Errorf("%w: value too small, must be larger than 0", ErrInvalidPage)
+	}
+
+	if rows <= 0 {
+		return Page{}, fmt.Errorf("%w: value too small, must be larger than 0", ErrInvalidRows)
+	}
+
+	p := Page{
+		number: number,
+		rows:   rows,
+		offset: (number - 1) * rows,
+	}
+
+	return p, nil
+}
@@ -4,6 +4,7 @@ package sqldb
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/web"
 	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
@@ -21,14 +23,20 @@ import (
 // lib/pq errorCodeNames
 // https://github.com/lib/pq/blob/master/error.go#L178
 const (
-	uniqueViolation = "23505"
-	undefinedTable  = "42P01"
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	checkViolation       = "23514"
+	undefinedTable       = "42P01"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
 )
 
 // Set of error variables for CRUD operations.
 var (
 	ErrDBNotFound        = sql.ErrNoRows
 	ErrDBDuplicatedEntry = errors.New("duplicated entry")
+	ErrDBForeignKey      = errors.New("foreign key violation")
+	ErrDBCheckViolation  = errors.New("check violation")
 	ErrUndefinedTable    = errors.New("undefined table")
 )
 
@@ -135,17 +143,10 @@ func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.exec", attribute.String("query", q))
 	defer span.End()
 
+	defer web.TimeSince(ctx, web.TimingDB, time.Now())
+
 	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) {
-			switch pqerr.Code {
-			case undefinedTable:
-				return ErrUndefinedTable
-			case uniqueViolation:
-				return ErrDBDuplicatedEntry
-			}
-		}
-		return err
+		return toDBError(err)
 	}
 
 	return nil
@@ -183,6 +184,8 @@ func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 	ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.queryslice", attribute.String("query", q))
 	defer span.End()
 
+	defer web.TimeSince(ctx, web.TimingDB, time.Now())
+
 	var rows *sqlx.Rows
 
 	switch withIn {
@@ -207,11 +210,7 @@ func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 	}
 
 	if err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) && pqerr.Code == undefinedTable {
-			return ErrUndefinedTable
-		}
-		return err
+		return toDBError(err)
 	}
 	defer rows.Close()
 
@@ -223,6 +222,12 @@ func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 		}
 		slice = append(slice, *v)
 	}
+
+	// Errors raised while the statement runs, like a constraint violation
+	// of a data modifying CTE, can surface while the rows are read.
+	if err := rows.Err(); err != nil {
+		return toDBError(err)
+	}
 	*dest = slice
 
 	return nil
@@ -259,6 +264,8 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	ctx, span := otel.AddSpan(ctx, "business.sdk.sqldb.query", attribute.String("query", q))
 	defer span.End()
 
+	defer web.TimeSince(ctx, web.TimingDB, time.Now())
+
 	var rows *sqlx.Rows
 
 	switch withIn {
@@ -283,15 +290,14 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	}
 
 	if err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) && pqerr.Code == undefinedTable {
-			return ErrUndefinedTable
-		}
-		return err
+		return toDBError(err)
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return toDBError(err)
+		}
 		return ErrDBNotFound
 	}
 
@@ -327,3 +333,48 @@ func queryString(query string, args any) string {
 
 	return strings.Trim(query, " ")
 }
+
+// toDBError maps the postgres errors the domains care about to the set of
+// error variables for CRUD operations.
+func toDBError(err error) error {
+	var pqerr *pgconn.PgError
+	if errors.As(err, &pqerr) {
+		switch pqerr.Code {
+		case undefinedTable:
+			return ErrUndefinedTable
+		case uniqueViolation:
+			return ErrDBDuplicatedEntry
+		case foreignKeyViolation:
+			return ErrDBForeignKey
+		case checkViolation:
+			return ErrDBCheckViolation
+		}
+	}
+
+	return err
+}
+
+// IsRetryable reports if the error is a transient failure the statement can
+// be retried after. Serialization failures, deadlocks and connection errors
+// that happened before anything was sent to the database are retryable.
+func IsRetryable(err error) bool {
+	var pqerr *pgconn.PgError
+	if errors.As(err, &pqerr) {
+		switch pqerr.Code {
+		case serializationFailure, deadlockDetected:
+			return true
+		}
+		return false
+	}
+
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) {
+		return true
+	}
+
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	return pgconn.SafeToRetry(err)
+}
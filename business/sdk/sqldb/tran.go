@@ -2,6 +2,7 @@ package sqldb
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -17,6 +18,12 @@ type CommitRollbacker interface {
 	Rollback() error
 }
 
+// AfterCommitter represents a transaction that can run functions once it
+// commits.
+type AfterCommitter interface {
+	AfterCommit(fn func())
+}
+
 // =============================================================================
 
 // DBBeginner implements the Beginner interface,
@@ -34,12 +41,66 @@ func NewBeginner(sqlxDB *sqlx.DB) *DBBeginner {
 // Begin implements the Beginner interface and returns a concrete value that
 // implements the CommitRollbacker interface.
 func (db *DBBeginner) Begin() (CommitRollbacker, error) {
-	return db.sqlxDB.Beginx()
+	tx, err := db.sqlxDB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{Tx: tx}, nil
+}
+
+// Tx is a transaction that runs the functions registered with AfterCommit
+// once it commits. The functions are dropped when it rolls back.
+type Tx struct {
+	*sqlx.Tx
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// AfterCommit implements the AfterCommitter interface.
+func (tx *Tx) AfterCommit(fn func()) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+// Commit commits the transaction and then runs the functions registered
+// with AfterCommit in the order they were registered.
+func (tx *Tx) Commit() error {
+	if err := tx.Tx.Commit(); err != nil {
+		return err
+	}
+
+	tx.mu.Lock()
+	fns := tx.afterCommit
+	tx.afterCommit = nil
+	tx.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+
+	return nil
+}
+
+// Rollback aborts the transaction and drops the functions registered with
+// AfterCommit.
+func (tx *Tx) Rollback() error {
+	tx.mu.Lock()
+	tx.afterCommit = nil
+	tx.mu.Unlock()
+
+	return tx.Tx.Rollback()
 }
 
 // GetExtContext is a helper function that extracts the sqlx value
 // from the domain transactor interface for transactional use.
 func GetExtContext(tx CommitRollbacker) (sqlx.ExtContext, error) {
+	if t, ok := tx.(*Tx); ok {
+		return t.Tx, nil
+	}
+
 	ec, ok := tx.(sqlx.ExtContext)
 	if !ok {
 		return nil, fmt.Errorf("Transactor(%T) not of a type *sql.Tx", tx)
@@ -0,0 +1,33 @@
+// Package tenant provides support for scoping business data to the tenant a
+// request acts on behalf of.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Default is the tenant of requests that don't identify one. Data stored
+// before tenants were introduced belongs to it.
+var Default = uuid.Nil
+
+type ctxKey int
+
+const tenantKey ctxKey = 1
+
+// Set stores the tenant in the context.
+func Set(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// Get returns the tenant stored in the context. Default is returned when the
+// context doesn't carry a tenant.
+func Get(ctx context.Context) uuid.UUID {
+	v, ok := ctx.Value(tenantKey).(uuid.UUID)
+	if !ok {
+		return Default
+	}
+
+	return v
+}
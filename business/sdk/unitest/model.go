@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/categorybus"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
@@ -19,8 +20,9 @@ type User struct {
 
 // SeedData represents data that was seeded for the test.
 type SeedData struct {
-	Users  []User
-	Admins []User
+	Users      []User
+	Admins     []User
+	Categories []categorybus.Category
 }
 
 // Table represent fields needed for running an unit test.
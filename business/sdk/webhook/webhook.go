@@ -0,0 +1,218 @@
+// Package webhook provides support for delivering domain events to a set of
+// webhook urls. It plugs into the delegate system so domains don't need to
+// know the events are being forwarded.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// SignatureHeader is the header carrying the HMAC-SHA256 signature of the
+// request body, hex encoded and prefixed with "sha256=".
+const SignatureHeader = "X-Webhook-Signature"
+
+// ErrQueueFull is returned when an event can't be queued for delivery.
+var ErrQueueFull = errors.New("webhook queue full")
+
+// Config represents the information required to deliver webhooks.
+type Config struct {
+	Log       *logger.Logger
+	URLs      []string
+	Secret    string
+	Client    *http.Client
+	Retries   int
+	Backoff   time.Duration
+	QueueSize int
+}
+
+type delivery struct {
+	url  string
+	body []byte
+}
+
+// Dispatcher delivers events asynchronously so callers are never blocked by
+// a slow or failing webhook receiver.
+type Dispatcher struct {
+	log     *logger.Logger
+	urls    []string
+	secret  []byte
+	client  *http.Client
+	retries int
+	backoff time.Duration
+	queue   chan delivery
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// New constructs a dispatcher and starts the goroutine delivering events.
+func New(cfg Config) *Dispatcher {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := Dispatcher{
+		log:     cfg.Log,
+		urls:    cfg.URLs,
+		secret:  []byte(cfg.Secret),
+		client:  cfg.Client,
+		retries: cfg.Retries,
+		backoff: cfg.Backoff,
+		queue:   make(chan delivery, cfg.QueueSize),
+		cancel:  cancel,
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.run(ctx)
+	}()
+
+	return &d
+}
+
+// Register adds the dispatcher to the delegate for the specified domain and
+// set of actions.
+func (d *Dispatcher) Register(dlg *delegate.Delegate, domain string, actions ...string) {
+	for _, action := range actions {
+		dlg.Register(domain, action, d.Send)
+	}
+}
+
+// Send queues the event for delivery to every configured url. The event is
+// delivered as the action parameters along with a type field set to
+// "<domain>.<action>". Send implements the delegate.Func signature.
+func (d *Dispatcher) Send(ctx context.Context, data delegate.Data) error {
+	event := map[string]any{}
+	if len(data.RawParams) > 0 {
+		if err := json.Unmarshal(data.RawParams, &event); err != nil {
+			return fmt.Errorf("unmarshal params: %w", err)
+		}
+	}
+
+	event["type"] = fmt.Sprintf("%s.%s", data.Domain, data.Action)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	for _, url := range d.urls {
+		select {
+		case d.queue <- delivery{url: url, body: body}:
+		default:
+			return fmt.Errorf("url[%s]: %w", url, ErrQueueFull)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting deliveries and waits for the queued ones to be
+// attempted or for the context to be done.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	close(d.queue)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+// Sign returns the signature of the body for the specified secret. Receivers
+// can use it to verify the value of the SignatureHeader.
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// =============================================================================
+
+func (d *Dispatcher) run(ctx context.Context) {
+	for dlv := range d.queue {
+		if err := d.deliver(ctx, dlv); err != nil {
+			d.log.Error(ctx, "webhook", "status", "delivery failed", "url", dlv.url, "err", err)
+		}
+	}
+}
+
+// deliver posts the event, retrying with an exponential backoff when the
+// request fails or the receiver doesn't respond with a 2xx status.
+func (d *Dispatcher) deliver(ctx context.Context, dlv delivery) error {
+	backoff := d.backoff
+
+	var err error
+	for attempt := 0; attempt <= d.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		if err = d.post(ctx, dlv); err == nil {
+			return nil
+		}
+
+		d.log.Info(ctx, "webhook", "status", "delivery attempt failed", "url", dlv.url, "attempt", attempt+1, "err", err)
+	}
+
+	return fmt.Errorf("attempts[%d]: %w", d.retries+1, err)
+}
+
+func (d *Dispatcher) post(ctx context.Context, dlv delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dlv.url, bytes.NewReader(dlv.body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(d.secret, dlv.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/webhook"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Dispatcher(t *testing.T) {
+	const secret = "secret"
+
+	var attempts atomic.Int32
+	received := make(chan []byte, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		// Fail the first attempt to force a retry.
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if got, exp := r.Header.Get(webhook.SignatureHeader), webhook.Sign([]byte(secret), body); got != exp {
+			t.Errorf("Should receive a valid signature: got[%s] exp[%s]", got, exp)
+		}
+
+		received <- body
+	}))
+	defer srv.Close()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	d := webhook.New(webhook.Config{
+		Log:     log,
+		URLs:    []string{srv.URL},
+		Secret:  secret,
+		Retries: 2,
+		Backoff: 10 * time.Millisecond,
+	})
+
+	data := delegate.Data{
+		Domain:    "product",
+		Action:    "created",
+		RawParams: []byte(`{"productID":"45b5fbd3-755f-4379-8f07-a58d4a30fa2f","timestamp":"2024-01-01T00:00:00Z"}`),
+	}
+
+	if err := d.Send(context.Background(), data); err != nil {
+		t.Fatalf("Should be able to send the event : %s", err)
+	}
+
+	select {
+	case body := <-received:
+		var event map[string]string
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Fatalf("Should be able to unmarshal the event : %s", err)
+		}
+
+		exp := map[string]string{
+			"type":      "product.created",
+			"productID": "45b5fbd3-755f-4379-8f07-a58d4a30fa2f",
+			"timestamp": "2024-01-01T00:00:00Z",
+		}
+
+		for k, v := range exp {
+			if event[k] != v {
+				t.Errorf("Should receive %s[%s], got[%s]", k, v, event[k])
+			}
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should receive the event")
+	}
+
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown cleanly : %s", err)
+	}
+
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("Should have taken 2 attempts, got %d", n)
+	}
+}
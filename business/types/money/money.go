@@ -3,26 +3,38 @@ package money
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 )
 
-// Money represents a money in the system.
+// maxCents is the largest amount of money supported, expressed in cents.
+const maxCents = 1_000_000 * 100
+
+// Money represents a money in the system. It's stored as a whole number of
+// cents so arithmetic on it doesn't suffer from floating point rounding.
 type Money struct {
-	value float64
+	cents int64
 }
 
 // Value returns the float value of the money.
 func (m Money) Value() float64 {
-	return m.value
+	return float64(m.cents) / 100
+}
+
+// Cents returns the value of the money as a whole number of cents.
+func (m Money) Cents() int64 {
+	return m.cents
 }
 
 // String returns the value of the money.
 func (m Money) String() string {
-	return fmt.Sprintf("%.2f", m.value)
+	return fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100)
 }
 
 // Equal provides support for the go-cmp package and testing.
 func (m Money) Equal(m2 Money) bool {
-	return m.value == m2.value
+	return m.cents == m2.cents
 }
 
 // MarshalText provides support for logging and any marshal needs.
@@ -30,16 +42,53 @@ func (m Money) MarshalText() ([]byte, error) {
 	return []byte(m.String()), nil
 }
 
+// UnmarshalText provides support for any unmarshal needs.
+func (m *Money) UnmarshalText(data []byte) error {
+	money, err := ParseString(string(data))
+	if err != nil {
+		return err
+	}
+
+	*m = money
+	return nil
+}
+
 // =============================================================================
 
 // Parse parses the float value and returns a money if the value complies
 // with the rules for money.
 func Parse(value float64) (Money, error) {
-	if value < 0 || value > 1_000_000 {
-		return Money{}, fmt.Errorf("invalid money %.2f", value)
+	cents := math.Round(value * 100)
+	if math.Abs(value*100-cents) > 1e-6 {
+		return Money{}, fmt.Errorf("invalid money %v: more than two decimal places", value)
+	}
+
+	return fromCents(int64(cents))
+}
+
+// ParseString parses the decimal string value, like "19.99", and returns a
+// money if the value complies with the rules for money.
+func ParseString(value string) (Money, error) {
+	whole, frac, hasFrac := strings.Cut(value, ".")
+
+	switch {
+	case whole == "" || !isDigits(whole):
+		return Money{}, fmt.Errorf("invalid money %q", value)
+	case hasFrac && (frac == "" || !isDigits(frac)):
+		return Money{}, fmt.Errorf("invalid money %q", value)
+	case len(frac) > 2:
+		return Money{}, fmt.Errorf("invalid money %q: more than two decimal places", value)
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > maxCents/100 {
+		return Money{}, fmt.Errorf("invalid money %q", value)
 	}
 
-	return Money{value}, nil
+	frac += strings.Repeat("0", 2-len(frac))
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+
+	return fromCents(units*100 + cents)
 }
 
 // MustParse parses the string value and returns a money if the value
@@ -52,3 +101,21 @@ func MustParse(value float64) Money {
 
 	return money
 }
+
+func fromCents(cents int64) (Money, error) {
+	if cents < 0 || cents > maxCents {
+		return Money{}, fmt.Errorf("invalid money %.2f", float64(cents)/100)
+	}
+
+	return Money{cents}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
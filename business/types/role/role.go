@@ -7,6 +7,8 @@ import "fmt"
 var (
 	Admin = newRole("ADMIN")
 	User  = newRole("USER")
+	Sales = newRole("SALES")
+	Buyer = newRole("BUYER")
 )
 
 // =============================================================================
@@ -0,0 +1,52 @@
+// Package sku represents a stock keeping unit in the system.
+package sku
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SKU represents a stock keeping unit in the system.
+type SKU struct {
+	value string
+}
+
+// String returns the value of the sku.
+func (s SKU) String() string {
+	return s.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (s SKU) Equal(s2 SKU) bool {
+	return s.value == s2.value
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (s SKU) MarshalText() ([]byte, error) {
+	return []byte(s.value), nil
+}
+
+// =============================================================================
+
+var skuRegEx = regexp.MustCompile("^[A-Z0-9][A-Z0-9-]{2,39}$")
+
+// Parse parses the string value and returns a sku if the value complies
+// with the rules for a sku.
+func Parse(value string) (SKU, error) {
+	if !skuRegEx.MatchString(value) {
+		return SKU{}, fmt.Errorf("invalid sku %q", value)
+	}
+
+	return SKU{value}, nil
+}
+
+// MustParse parses the string value and returns a sku if the value
+// complies with the rules for a sku. If an error occurs the function panics.
+func MustParse(value string) SKU {
+	sku, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return sku
+}
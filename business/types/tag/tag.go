@@ -0,0 +1,56 @@
+// Package tag represents a free-form label grouping products in the system.
+package tag
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Tag represents a free-form label grouping products in the system.
+type Tag struct {
+	value string
+}
+
+// String returns the value of the tag.
+func (t Tag) String() string {
+	return t.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (t Tag) Equal(t2 Tag) bool {
+	return t.value == t2.value
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (t Tag) MarshalText() ([]byte, error) {
+	return []byte(t.value), nil
+}
+
+// =============================================================================
+
+var tagRegEx = regexp.MustCompile("^[a-z0-9][a-z0-9 _-]{0,49}$")
+
+// Parse parses the string value and returns a tag if the value complies
+// with the rules for a tag. Tags are kept in lower case, without the spaces
+// around them, so Sale and " sale" are the same tag.
+func Parse(value string) (Tag, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if !tagRegEx.MatchString(value) {
+		return Tag{}, fmt.Errorf("invalid tag %q", value)
+	}
+
+	return Tag{value}, nil
+}
+
+// MustParse parses the string value and returns a tag if the value
+// complies with the rules for a tag. If an error occurs the function panics.
+func MustParse(value string) Tag {
+	tag, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return tag
+}
@@ -0,0 +1,175 @@
+// Package breaker provides a circuit breaker failing the calls made to a
+// system fast while the system is failing, so it isn't piled on while it
+// recovers.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned for the calls the breaker rejects.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker.
+type State int
+
+// Set of states of a breaker. A closed breaker lets every call through, an
+// open one rejects them all and a half-open one lets a single call through
+// to probe whether the system recovered.
+const (
+	Closed State = iota
+	Open
+	HalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+
+	return "closed"
+}
+
+// Policy defines when a breaker opens and for how long.
+type Policy struct {
+	// ErrorRate is the share of failed calls, between 0 and 1, that opens
+	// the breaker. A breaker never opens when it's 0.
+	ErrorRate float64
+
+	// MinCalls is the number of calls made within a window before its error
+	// rate is considered, so a few failures of a quiet system don't open the
+	// breaker.
+	MinCalls int
+
+	// Window is the period the error rate is measured over.
+	Window time.Duration
+
+	// Cooldown is how long the breaker stays open before a call is let
+	// through to probe the system.
+	Cooldown time.Duration
+}
+
+// Breaker is a circuit breaker. It's safe for concurrent use.
+type Breaker struct {
+	policy Policy
+
+	mu          sync.Mutex
+	state       State
+	windowStart time.Time
+	calls       int
+	failures    int
+	openedAt    time.Time
+}
+
+// New constructs a closed breaker. A window of 10 seconds and a cooldown of
+// 5 seconds are used when they're not set.
+func New(policy Policy) *Breaker {
+	if policy.MinCalls < 1 {
+		policy.MinCalls = 1
+	}
+
+	if policy.Window <= 0 {
+		policy.Window = 10 * time.Second
+	}
+
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = 5 * time.Second
+	}
+
+	return &Breaker{
+		policy:      policy,
+		windowStart: time.Now(),
+	}
+}
+
+// State returns the state of the breaker. An open breaker whose cooldown is
+// over is reported as half-open since its next call is let through.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && time.Since(b.openedAt) >= b.policy.Cooldown {
+		return HalfOpen
+	}
+
+	return b.state
+}
+
+// Allow returns ErrOpen when the call can't be made. Once the cooldown of an
+// open breaker is over a single call is allowed, the others are rejected
+// until its outcome is recorded. The outcome of every call allowed must be
+// recorded with Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.policy.Cooldown {
+			return ErrOpen
+		}
+		b.state = HalfOpen
+		return nil
+
+	case HalfOpen:
+		return ErrOpen
+	}
+
+	return nil
+}
+
+// Record records the outcome of a call. The breaker opens once the error
+// rate of the window reaches the policy's. The probe of a half-open breaker
+// closes it when it succeeds and opens it again when it fails.
+func (b *Breaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	switch b.state {
+	case HalfOpen:
+		if failed {
+			b.open(now)
+			return
+		}
+		b.state = Closed
+		b.reset(now)
+		return
+
+	case Open:
+		// The call was allowed before the breaker opened.
+		return
+	}
+
+	if now.Sub(b.windowStart) >= b.policy.Window {
+		b.reset(now)
+	}
+
+	b.calls++
+	if failed {
+		b.failures++
+	}
+
+	if b.policy.ErrorRate > 0 && b.calls >= b.policy.MinCalls && float64(b.failures)/float64(b.calls) >= b.policy.ErrorRate {
+		b.open(now)
+	}
+}
+
+func (b *Breaker) open(now time.Time) {
+	b.state = Open
+	b.openedAt = now
+	b.reset(now)
+}
+
+func (b *Breaker) reset(now time.Time) {
+	b.windowStart = now
+	b.calls = 0
+	b.failures = 0
+}
@@ -0,0 +1,117 @@
+package breaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/breaker"
+)
+
+func Test_Breaker(t *testing.T) {
+	b := breaker.New(breaker.Policy{
+		ErrorRate: 0.5,
+		MinCalls:  4,
+		Window:    time.Minute,
+		Cooldown:  50 * time.Millisecond,
+	})
+
+	// Too few calls were made for failures to open the breaker.
+	for range 3 {
+		call(t, b, true)
+	}
+
+	if b.State() != breaker.Closed {
+		t.Fatalf("Should stay closed below the minimum of calls, got %s", b.State())
+	}
+
+	call(t, b, true)
+
+	if b.State() != breaker.Open {
+		t.Fatalf("Should open once the error rate is reached, got %s", b.State())
+	}
+
+	if err := b.Allow(); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("Should reject calls while open, got %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if b.State() != breaker.HalfOpen {
+		t.Fatalf("Should be half-open after the cooldown, got %s", b.State())
+	}
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Should allow the probe, got %v", err)
+	}
+
+	if err := b.Allow(); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("Should reject calls while probing, got %v", err)
+	}
+
+	b.Record(true)
+
+	if b.State() != breaker.Open {
+		t.Fatalf("Should open again when the probe fails, got %s", b.State())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	call(t, b, false)
+
+	if b.State() != breaker.Closed {
+		t.Fatalf("Should close when the probe succeeds, got %s", b.State())
+	}
+
+	// The failures made before the breaker closed are forgotten.
+	call(t, b, true)
+	for range 3 {
+		call(t, b, false)
+	}
+
+	if b.State() != breaker.Closed {
+		t.Fatalf("Should stay closed below the error rate, got %s", b.State())
+	}
+}
+
+func Test_BreakerWindow(t *testing.T) {
+	b := breaker.New(breaker.Policy{
+		ErrorRate: 0.5,
+		MinCalls:  2,
+		Window:    50 * time.Millisecond,
+	})
+
+	call(t, b, true)
+
+	time.Sleep(60 * time.Millisecond)
+
+	// The failure of the previous window doesn't count.
+	call(t, b, false)
+	call(t, b, false)
+
+	if b.State() != breaker.Closed {
+		t.Fatalf("Should only count the calls of the window, got %s", b.State())
+	}
+}
+
+func Test_BreakerDisabled(t *testing.T) {
+	b := breaker.New(breaker.Policy{})
+
+	for range 100 {
+		call(t, b, true)
+	}
+
+	if b.State() != breaker.Closed {
+		t.Fatalf("Should never open without an error rate, got %s", b.State())
+	}
+}
+
+func call(t *testing.T, b *breaker.Breaker, failed bool) {
+	t.Helper()
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Should allow the call, got %v", err)
+	}
+
+	b.Record(failed)
+}
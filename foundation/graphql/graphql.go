@@ -0,0 +1,582 @@
+// Package graphql provides support for parsing GraphQL requests and building
+// their responses. It supports the subset of the language needed to expose a
+// resource: a query or mutation operation made of fields with aliases,
+// arguments, variables and nested selection sets. Fragments, directives and
+// subscriptions aren't supported.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Set of operation types.
+const (
+	OperationQuery    = "query"
+	OperationMutation = "mutation"
+)
+
+// Request represents a GraphQL request sent over HTTP.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Decode implements the decoder interface. Numbers are kept as json.Number
+// values so integers don't lose precision.
+func (r *Request) Decode(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	if err := d.Decode(r); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(r.Query) == "" {
+		return errors.New("query is required")
+	}
+
+	return nil
+}
+
+// Operation represents the operation of a request that is executed.
+type Operation struct {
+	Type       string
+	Name       string
+	Selections []Field
+}
+
+// Field represents a field of a selection set. Argument values are the
+// values of the request with variables replaced. Strings, enums, numbers as
+// json.Number, booleans, nil, []any and map[string]any are used, like a
+// JSON document decoded with UseNumber.
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]any
+	Selections []Field
+}
+
+// Key returns the name of the field in the response.
+func (f Field) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+// Parse parses the query of the request and returns the operation to
+// execute. The operation name must be provided when the query holds more
+// than one operation.
+func Parse(req Request) (Operation, error) {
+	p := parser{lex: lexer{src: req.Query}}
+	if err := p.next(); err != nil {
+		return Operation{}, err
+	}
+
+	var ops []operation
+	for p.tok.kind != tokEOF {
+		op, err := p.parseOperation()
+		if err != nil {
+			return Operation{}, err
+		}
+		ops = append(ops, op)
+	}
+
+	if len(ops) == 0 {
+		return Operation{}, errors.New("document has no operations")
+	}
+
+	var op operation
+	switch {
+	case req.OperationName != "":
+		var found bool
+		for _, o := range ops {
+			if o.Name == req.OperationName {
+				op, found = o, true
+				break
+			}
+		}
+		if !found {
+			return Operation{}, fmt.Errorf("unknown operation %q", req.OperationName)
+		}
+
+	case len(ops) > 1:
+		return Operation{}, errors.New("operation name is required when the document has more than one operation")
+
+	default:
+		op = ops[0]
+	}
+
+	vars, err := op.variables(req.Variables)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	selections, err := bindFields(op.Selections, vars)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return Operation{
+		Type:       op.Type,
+		Name:       op.Name,
+		Selections: selections,
+	}, nil
+}
+
+// =============================================================================
+
+// variable is the value of an argument that references a variable.
+type variable string
+
+// variableDefinition describes a variable an operation accepts.
+type variableDefinition struct {
+	name       string
+	nonNull    bool
+	defaultVal any
+	hasDefault bool
+}
+
+// operation is an operation as found in the document before variables are
+// bound.
+type operation struct {
+	Operation
+	definitions []variableDefinition
+}
+
+// variables returns the value of every variable the operation defines.
+func (op operation) variables(values map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(op.definitions))
+	for _, def := range op.definitions {
+		v, exists := values[def.name]
+		switch {
+		case exists:
+			vars[def.name] = v
+		case def.hasDefault:
+			vars[def.name] = def.defaultVal
+		case def.nonNull:
+			return nil, fmt.Errorf("variable $%s is required", def.name)
+		default:
+			vars[def.name] = nil
+		}
+
+		if def.nonNull && vars[def.name] == nil {
+			return nil, fmt.Errorf("variable $%s can't be null", def.name)
+		}
+	}
+
+	return vars, nil
+}
+
+func bindFields(fields []Field, vars map[string]any) ([]Field, error) {
+	bound := make([]Field, len(fields))
+	for i, f := range fields {
+		bound[i] = Field{
+			Alias: f.Alias,
+			Name:  f.Name,
+		}
+
+		if f.Arguments != nil {
+			bound[i].Arguments = make(map[string]any, len(f.Arguments))
+			for name, v := range f.Arguments {
+				v, err := bindValue(v, vars)
+				if err != nil {
+					return nil, err
+				}
+				bound[i].Arguments[name] = v
+			}
+		}
+
+		if f.Selections != nil {
+			sel, err := bindFields(f.Selections, vars)
+			if err != nil {
+				return nil, err
+			}
+			bound[i].Selections = sel
+		}
+	}
+
+	return bound, nil
+}
+
+func bindValue(v any, vars map[string]any) (any, error) {
+	switch v := v.(type) {
+	case variable:
+		value, exists := vars[string(v)]
+		if !exists {
+			return nil, fmt.Errorf("variable $%s is not defined", string(v))
+		}
+		return value, nil
+
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			item, err := bindValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+
+	case map[string]any:
+		obj := make(map[string]any, len(v))
+		for name, item := range v {
+			item, err := bindValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = item
+		}
+		return obj, nil
+	}
+
+	return v, nil
+}
+
+// =============================================================================
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+
+	return nil
+}
+
+// expect consumes the punctuator or fails.
+func (p *parser) expect(punct string) error {
+	if p.tok.kind != tokPunct || p.tok.value != punct {
+		return p.unexpected(fmt.Sprintf("%q", punct))
+	}
+
+	return p.next()
+}
+
+// peek reports if the current token is the punctuator.
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.value == punct
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokName {
+		return "", p.unexpected("a name")
+	}
+
+	name := p.tok.value
+
+	return name, p.next()
+}
+
+func (p *parser) unexpected(exp string) error {
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("syntax error: expected %s, got the end of the document", exp)
+	}
+
+	return fmt.Errorf("syntax error at position %d: expected %s, got %q", p.tok.pos, exp, p.tok.value)
+}
+
+func (p *parser) parseOperation() (operation, error) {
+	op := operation{Operation: Operation{Type: OperationQuery}}
+
+	// The shorthand form of a query is only a selection set.
+	if !p.peek("{") {
+		typ, err := p.name()
+		if err != nil {
+			return operation{}, err
+		}
+
+		switch typ {
+		case OperationQuery, OperationMutation:
+			op.Type = typ
+		case "fragment":
+			return operation{}, errors.New("fragments are not supported")
+		default:
+			return operation{}, fmt.Errorf("operation type %q is not supported", typ)
+		}
+
+		if p.tok.kind == tokName {
+			op.Name = p.tok.value
+			if err := p.next(); err != nil {
+				return operation{}, err
+			}
+		}
+
+		if p.peek("(") {
+			defs, err := p.parseVariableDefinitions()
+			if err != nil {
+				return operation{}, err
+			}
+			op.definitions = defs
+		}
+	}
+
+	if p.peek("@") {
+		return operation{}, errors.New("directives are not supported")
+	}
+
+	sel, err := p.parseSelectionSet()
+	if err != nil {
+		return operation{}, err
+	}
+	op.Selections = sel
+
+	return op, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]variableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	var defs []variableDefinition
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		nonNull, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		def := variableDefinition{name: name, nonNull: nonNull}
+
+		if p.peek("=") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+
+			v, err := p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			def.defaultVal, def.hasDefault = v, true
+		}
+
+		defs = append(defs, def)
+	}
+
+	return defs, p.next()
+}
+
+// parseType consumes a type reference and reports if the type is non null.
+// Values aren't validated against the types so the names are dropped.
+func (p *parser) parseType() (bool, error) {
+	switch {
+	case p.peek("["):
+		if err := p.next(); err != nil {
+			return false, err
+		}
+		if _, err := p.parseType(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+
+	default:
+		if _, err := p.name(); err != nil {
+			return false, err
+		}
+	}
+
+	if !p.peek("!") {
+		return false, nil
+	}
+
+	return true, p.next()
+}
+
+func (p *parser) parseSelectionSet() ([]Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	for !p.peek("}") {
+		if p.peek("...") {
+			return nil, errors.New("fragments are not supported")
+		}
+
+		f, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("selection set can't be empty")
+	}
+
+	return fields, p.next()
+}
+
+func (p *parser) parseField() (Field, error) {
+	name, err := p.name()
+	if err != nil {
+		return Field{}, err
+	}
+
+	f := Field{Name: name}
+
+	if p.peek(":") {
+		if err := p.next(); err != nil {
+			return Field{}, err
+		}
+
+		f.Alias = name
+		if f.Name, err = p.name(); err != nil {
+			return Field{}, err
+		}
+	}
+
+	if p.peek("(") {
+		if err := p.next(); err != nil {
+			return Field{}, err
+		}
+
+		f.Arguments = make(map[string]any)
+		for !p.peek(")") {
+			name, err := p.name()
+			if err != nil {
+				return Field{}, err
+			}
+
+			if err := p.expect(":"); err != nil {
+				return Field{}, err
+			}
+
+			v, err := p.parseValue(false)
+			if err != nil {
+				return Field{}, err
+			}
+
+			if _, exists := f.Arguments[name]; exists {
+				return Field{}, fmt.Errorf("argument %q is provided more than once", name)
+			}
+			f.Arguments[name] = v
+		}
+
+		if err := p.next(); err != nil {
+			return Field{}, err
+		}
+	}
+
+	if p.peek("@") {
+		return Field{}, errors.New("directives are not supported")
+	}
+
+	if p.peek("{") {
+		if f.Selections, err = p.parseSelectionSet(); err != nil {
+			return Field{}, err
+		}
+	}
+
+	return f, nil
+}
+
+// parseValue parses an input value. Variables aren't allowed in constant
+// values like the default value of a variable.
+func (p *parser) parseValue(constant bool) (any, error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case tokString:
+		return tok.value, p.next()
+
+	case tokNumber:
+		return json.Number(tok.value), p.next()
+
+	case tokName:
+		var v any
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = tok.value
+		}
+		return v, p.next()
+	}
+
+	switch {
+	case p.peek("$") && !constant:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		return variable(name), nil
+
+	case p.peek("["):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		list := []any{}
+		for !p.peek("]") {
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.next()
+
+	case p.peek("{"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		obj := map[string]any{}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+		}
+		return obj, p.next()
+	}
+
+	return nil, p.unexpected("a value")
+}
@@ -0,0 +1,129 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/graphql"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Parse(t *testing.T) {
+	req := graphql.Request{
+		Query: `
+			# Products of a category.
+			query Products($rows: Int = 10, $category: ID!) {
+				list: products(filter: {categoryID: $category, nameLike: "gui\"tar"}, rows: $rows, orderBy: NAME) {
+					total
+					items { id name }
+				}
+			}
+
+			mutation Delete { deleteProduct(id: "1") }
+		`,
+		OperationName: "Products",
+		Variables:     map[string]any{"category": "c1"},
+	}
+
+	op, err := graphql.Parse(req)
+	if err != nil {
+		t.Fatalf("Should be able to parse the request : %s", err)
+	}
+
+	exp := graphql.Operation{
+		Type: graphql.OperationQuery,
+		Name: "Products",
+		Selections: []graphql.Field{
+			{
+				Alias: "list",
+				Name:  "products",
+				Arguments: map[string]any{
+					"filter":  map[string]any{"categoryID": "c1", "nameLike": `gui"tar`},
+					"rows":    json.Number("10"),
+					"orderBy": "NAME",
+				},
+				Selections: []graphql.Field{
+					{Name: "total"},
+					{Name: "items", Selections: []graphql.Field{{Name: "id"}, {Name: "name"}}},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(op, exp); diff != "" {
+		t.Errorf("Should get the expected operation:\n%s", diff)
+	}
+}
+
+func Test_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		req  graphql.Request
+	}{
+		{"syntax", graphql.Request{Query: `{ products( }`}},
+		{"empty-selection", graphql.Request{Query: `{ products {} }`}},
+		{"fragment", graphql.Request{Query: `{ products { ...Fields } }`}},
+		{"directive", graphql.Request{Query: `{ products @skip(if: true) { total } }`}},
+		{"subscription", graphql.Request{Query: `subscription { products { total } }`}},
+		{"missing-variable", graphql.Request{Query: `query ($id: ID!) { product(id: $id) { id } }`}},
+		{"undefined-variable", graphql.Request{Query: `{ product(id: $id) { id } }`}},
+		{"ambiguous", graphql.Request{Query: `query A { a } query B { b }`}},
+		{"unknown-operation", graphql.Request{Query: `query A { a }`, OperationName: "B"}},
+		{"string", graphql.Request{Query: `{ product(id: "1) { id } }`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := graphql.Parse(tt.req); err == nil {
+				t.Errorf("Should fail to parse %q", tt.req.Query)
+			}
+		})
+	}
+}
+
+func Test_Select(t *testing.T) {
+	type item struct {
+		ID   string `json:"id"`
+		Cost string `json:"cost,omitempty"`
+	}
+
+	type page struct {
+		Items []item `json:"items"`
+		Total int    `json:"total"`
+	}
+
+	itemType := &graphql.Type{Name: "Item", Fields: map[string]*graphql.Type{"id": nil, "cost": nil}}
+	pageType := &graphql.Type{Name: "Page", Fields: map[string]*graphql.Type{"items": itemType, "total": nil}}
+
+	fields := []graphql.Field{
+		{Name: "total"},
+		{Alias: "products", Name: "items", Selections: []graphql.Field{{Name: "__typename"}, {Name: "cost"}, {Name: "id"}}},
+	}
+
+	got, err := graphql.Select(pageType, fields, page{Items: []item{{ID: "1", Cost: "2.00"}, {ID: "2"}}, Total: 2})
+	if err != nil {
+		t.Fatalf("Should be able to select the fields : %s", err)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the selection : %s", err)
+	}
+
+	exp := `{"total":2,"products":[{"__typename":"Item","cost":"2.00","id":"1"},{"__typename":"Item","cost":null,"id":"2"}]}`
+	if string(data) != exp {
+		t.Errorf("Should get %s, got %s", exp, data)
+	}
+
+	bad := [][]graphql.Field{
+		{{Name: "unknown"}},
+		{{Name: "items"}},
+		{{Name: "total", Selections: []graphql.Field{{Name: "id"}}}},
+	}
+
+	for _, fields := range bad {
+		if _, err := graphql.Select(pageType, fields, page{}); err == nil {
+			t.Errorf("Should fail to select %+v", fields)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokNumber
+	tokString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// lexer splits a GraphQL document into tokens. Commas, white space and
+// comments are ignored like the specification requires.
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, value: "...", pos: start}, nil
+
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, value: string(c), pos: start}, nil
+
+	case isNameStart(c):
+		for l.pos < len(l.src) && isNameContinue(l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokName, value: l.src[start:l.pos], pos: start}, nil
+
+	case c == '-' || isDigit(c):
+		return l.number()
+
+	case c == '"':
+		return l.string()
+	}
+
+	return token{}, fmt.Errorf("syntax error at position %d: unexpected character %q", start, c)
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+
+		case strings.HasPrefix(l.src[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+
+	if !l.digits() {
+		return token{}, fmt.Errorf("syntax error at position %d: invalid number", start)
+	}
+
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		if !l.digits() {
+			return token{}, fmt.Errorf("syntax error at position %d: invalid number", start)
+		}
+	}
+
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.digits() {
+			return token{}, fmt.Errorf("syntax error at position %d: invalid number", start)
+		}
+	}
+
+	return token{kind: tokNumber, value: l.src[start:l.pos], pos: start}, nil
+}
+
+// digits consumes a run of digits and reports if there was at least one.
+func (l *lexer) digits() bool {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+
+	return l.pos > start
+}
+
+// string consumes a quoted string. The escape sequences of GraphQL strings
+// are the ones of JSON strings so the JSON decoder unquotes the value.
+// Block strings aren't supported.
+func (l *lexer) string() (token, error) {
+	start := l.pos
+
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		return token{}, fmt.Errorf("syntax error at position %d: block strings are not supported", start)
+	}
+
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+
+		case '\n', '\r':
+			return token{}, fmt.Errorf("syntax error at position %d: unterminated string", start)
+
+		case '"':
+			l.pos++
+
+			var s string
+			if err := json.Unmarshal([]byte(l.src[start:l.pos]), &s); err != nil {
+				return token{}, fmt.Errorf("syntax error at position %d: invalid string", start)
+			}
+
+			return token{kind: tokString, value: s, pos: start}, nil
+		}
+		l.pos++
+	}
+
+	return token{}, fmt.Errorf("syntax error at position %d: unterminated string", start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Error represents an error reported in a response. Path holds the response
+// keys leading to the field that failed.
+type Error struct {
+	Message    string         `json:"message"`
+	Path       []string       `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Response represents the result of executing a request. Data is left out
+// when the request couldn't be executed.
+type Response struct {
+	Data   Object  `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (r Response) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// Member is a key and value of an object.
+type Member struct {
+	Key   string
+	Value any
+}
+
+// Object represents an object of a response. Members are marshaled in order
+// so the response follows the order of the selection set.
+type Object []Member
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// =============================================================================
+
+// Type describes an object type a client can select fields from. A field
+// with a nil type is a scalar or a list of scalars. Lists of objects use the
+// type of the objects.
+type Type struct {
+	Name   string
+	Fields map[string]*Type
+}
+
+// Select returns the value of the selection set for the type. The value is
+// anything that marshals to a JSON object or to an array of objects, which
+// lets the response reuse the JSON names of a model. Fields missing from the
+// JSON value, like empty fields with omitempty, are null.
+func Select(t *Type, fields []Field, v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var doc any
+	if err := d.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return selectValue(t, fields, doc)
+}
+
+func selectValue(t *Type, fields []Field, doc any) (any, error) {
+	switch doc := doc.(type) {
+	case nil:
+		return nil, nil
+
+	case []any:
+		list := make([]any, len(doc))
+		for i, item := range doc {
+			v, err := selectValue(t, fields, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+
+	case map[string]any:
+		obj := make(Object, 0, len(fields))
+		for _, f := range fields {
+			if f.Name == "__typename" {
+				obj = append(obj, Member{Key: f.Key(), Value: t.Name})
+				continue
+			}
+
+			ft, exists := t.Fields[f.Name]
+			if !exists {
+				return nil, fmt.Errorf("cannot query field %q on type %q", f.Name, t.Name)
+			}
+
+			switch {
+			case ft == nil && f.Selections != nil:
+				return nil, fmt.Errorf("field %q of type %q can't have a selection of subfields", f.Name, t.Name)
+
+			case ft != nil && f.Selections == nil:
+				return nil, fmt.Errorf("field %q of type %q must have a selection of subfields", f.Name, t.Name)
+
+			case ft == nil:
+				obj = append(obj, Member{Key: f.Key(), Value: doc[f.Name]})
+
+			default:
+				v, err := selectValue(ft, f.Selections, doc[f.Name])
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, Member{Key: f.Key(), Value: v})
+			}
+		}
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("type %q isn't an object", t.Name)
+}
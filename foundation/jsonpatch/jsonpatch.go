@@ -0,0 +1,330 @@
+// Package jsonpatch provides support for applying JSON Patch (RFC 6902)
+// documents.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of a JSON Patch document.
+const ContentType = "application/json-patch+json"
+
+// ErrTestFailed is returned when a test operation doesn't match the document.
+var ErrTestFailed = errors.New("test operation failed")
+
+// Operation represents a single JSON Patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch represents a JSON Patch document.
+type Patch []Operation
+
+// Decode implements the decoder interface.
+func (p *Patch) Decode(data []byte) error {
+	return json.Unmarshal(data, p)
+}
+
+// Paths returns every path the patch reads from or writes to, including the
+// from location of move and copy operations.
+func (p Patch) Paths() []string {
+	var paths []string
+	for _, op := range p {
+		paths = append(paths, op.Path)
+		if op.From != "" {
+			paths = append(paths, op.From)
+		}
+	}
+
+	return paths
+}
+
+// Apply applies the operations in order to the JSON document and returns the
+// resulting document. If any operation fails the document is left untouched.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	var root any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	for i, op := range p {
+		var err error
+		root, err = apply(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation[%d] %s %s: %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+// =============================================================================
+
+func apply(root any, op Operation) (any, error) {
+	switch op.Op {
+	case "add":
+		value, err := decodeValue(op)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+
+	case "remove":
+		root, _, err := remove(root, op.Path)
+		return root, err
+
+	case "replace":
+		value, err := decodeValue(op)
+		if err != nil {
+			return nil, err
+		}
+		root, _, err = remove(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+
+	case "move":
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.New("can't move a value into one of its children")
+		}
+		root, value, err := remove(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+
+	case "copy":
+		value, err := get(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, clone(value))
+
+	case "test":
+		want, err := decodeValue(op)
+		if err != nil {
+			return nil, err
+		}
+		got, err := get(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(got, want) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+func decodeValue(op Operation) (any, error) {
+	if op.Value == nil {
+		return nil, errors.New("missing value")
+	}
+
+	var value any
+	if err := json.Unmarshal(op.Value, &value); err != nil {
+		return nil, fmt.Errorf("unmarshal value: %w", err)
+	}
+
+	return value, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its reference tokens.
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", path)
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
+
+func get(root any, path string) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	node := root
+	for _, token := range tokens {
+		switch v := node.(type) {
+		case map[string]any:
+			child, exists := v[token]
+			if !exists {
+				return nil, fmt.Errorf("path %q not found", path)
+			}
+			node = child
+
+		case []any:
+			idx, err := index(token, len(v)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = v[idx]
+
+		default:
+			return nil, fmt.Errorf("path %q not found", path)
+		}
+	}
+
+	return node, nil
+}
+
+// add sets the value at the path. The parent must exist, the last token
+// appends to or inserts into an array.
+func add(root any, path string, value any) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(root, parentPath(tokens))
+	if err != nil {
+		return nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+
+	switch v := parent.(type) {
+	case map[string]any:
+		v[last] = value
+		return root, nil
+
+	case []any:
+		idx := len(v)
+		if last != "-" {
+			idx, err = index(last, len(v))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		arr := append(v[:idx], append([]any{value}, v[idx:]...)...)
+		return replaceChild(root, tokens, arr)
+	}
+
+	return nil, fmt.Errorf("path %q not found", path)
+}
+
+// remove deletes the value at the path and returns it.
+func remove(root any, path string) (any, any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, root, nil
+	}
+
+	parent, err := get(root, parentPath(tokens))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+
+	switch v := parent.(type) {
+	case map[string]any:
+		value, exists := v[last]
+		if !exists {
+			return nil, nil, fmt.Errorf("path %q not found", path)
+		}
+		delete(v, last)
+		return root, value, nil
+
+	case []any:
+		idx, err := index(last, len(v)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value := v[idx]
+		arr := append(v[:idx:idx], v[idx+1:]...)
+
+		root, err := replaceChild(root, tokens, arr)
+		return root, value, err
+	}
+
+	return nil, nil, fmt.Errorf("path %q not found", path)
+}
+
+// replaceChild stores a rebuilt array back into its parent since growing or
+// shrinking a slice can produce a new backing array.
+func replaceChild(root any, tokens []string, arr []any) (any, error) {
+	parentTokens := tokens[:len(tokens)-1]
+	if len(parentTokens) == 0 {
+		return arr, nil
+	}
+
+	grand, err := get(root, parentPath(parentTokens))
+	if err != nil {
+		return nil, err
+	}
+
+	key := parentTokens[len(parentTokens)-1]
+
+	switch v := grand.(type) {
+	case map[string]any:
+		v[key] = arr
+	case []any:
+		idx, err := index(key, len(v)-1)
+		if err != nil {
+			return nil, err
+		}
+		v[idx] = arr
+	}
+
+	return root, nil
+}
+
+func parentPath(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens[:len(tokens)-1] {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+
+	return b.String()
+}
+
+func index(token string, maxIdx int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || idx > maxIdx || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	return idx, nil
+}
+
+func clone(value any) any {
+	data, _ := json.Marshal(value)
+
+	var c any
+	json.Unmarshal(data, &c)
+
+	return c
+}
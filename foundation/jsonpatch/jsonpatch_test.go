@@ -0,0 +1,86 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/jsonpatch"
+)
+
+func Test_Apply(t *testing.T) {
+	doc := `{"name":"Comic Books","cost":10,"tags":["a","b"],"dims":{"w":1}}`
+
+	tests := []struct {
+		name  string
+		patch string
+		exp   string
+	}{
+		{"replace", `[{"op":"replace","path":"/cost","value":12.5}]`, `{"name":"Comic Books","cost":12.5,"tags":["a","b"],"dims":{"w":1}}`},
+		{"add-field", `[{"op":"add","path":"/dims/h","value":2}]`, `{"name":"Comic Books","cost":10,"tags":["a","b"],"dims":{"w":1,"h":2}}`},
+		{"add-append", `[{"op":"add","path":"/tags/-","value":"c"}]`, `{"name":"Comic Books","cost":10,"tags":["a","b","c"],"dims":{"w":1}}`},
+		{"add-insert", `[{"op":"add","path":"/tags/0","value":"z"}]`, `{"name":"Comic Books","cost":10,"tags":["z","a","b"],"dims":{"w":1}}`},
+		{"remove", `[{"op":"remove","path":"/tags/0"}]`, `{"name":"Comic Books","cost":10,"tags":["b"],"dims":{"w":1}}`},
+		{"move", `[{"op":"move","from":"/dims/w","path":"/width"}]`, `{"name":"Comic Books","cost":10,"tags":["a","b"],"dims":{},"width":1}`},
+		{"copy", `[{"op":"copy","from":"/name","path":"/title"}]`, `{"name":"Comic Books","cost":10,"tags":["a","b"],"dims":{"w":1},"title":"Comic Books"}`},
+		{"test", `[{"op":"test","path":"/cost","value":10},{"op":"replace","path":"/cost","value":11}]`, `{"name":"Comic Books","cost":11,"tags":["a","b"],"dims":{"w":1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch jsonpatch.Patch
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("Should be able to decode the patch : %s", err)
+			}
+
+			got, err := patch.Apply([]byte(doc))
+			if err != nil {
+				t.Fatalf("Should be able to apply the patch : %s", err)
+			}
+
+			var gotV, expV any
+			json.Unmarshal(got, &gotV)
+			json.Unmarshal([]byte(tt.exp), &expV)
+
+			if !reflect.DeepEqual(gotV, expV) {
+				t.Errorf("Got: %s", got)
+				t.Errorf("Exp: %s", tt.exp)
+			}
+		})
+	}
+}
+
+func Test_ApplyErrors(t *testing.T) {
+	doc := `{"name":"Comic Books","cost":10}`
+
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{"missing-path", `[{"op":"remove","path":"/quantity"}]`},
+		{"missing-value", `[{"op":"replace","path":"/cost"}]`},
+		{"unknown-op", `[{"op":"merge","path":"/cost","value":1}]`},
+		{"bad-pointer", `[{"op":"replace","path":"cost","value":1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch jsonpatch.Patch
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("Should be able to decode the patch : %s", err)
+			}
+
+			if _, err := patch.Apply([]byte(doc)); err == nil {
+				t.Fatal("Should not be able to apply the patch")
+			}
+		})
+	}
+
+	var patch jsonpatch.Patch
+	json.Unmarshal([]byte(`[{"op":"test","path":"/cost","value":11}]`), &patch)
+
+	if _, err := patch.Apply([]byte(doc)); !errors.Is(err, jsonpatch.ErrTestFailed) {
+		t.Fatalf("Should get ErrTestFailed : %v", err)
+	}
+}
@@ -0,0 +1,392 @@
+// Package jsonschema validates JSON documents against a JSON Schema.
+//
+// Only the keywords describing the shape of a document are supported: type,
+// properties, required, additionalProperties, items, enum, minimum, maximum,
+// minLength, maxLength, pattern, minItems, maxItems and the uuid and
+// date-time formats. Annotations like title and description are ignored. A
+// schema using any other keyword is rejected when it's parsed so no part of
+// a contract is silently left unchecked.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Violation describes a part of a document that doesn't comply with the
+// schema. The path names the value, like tags[1] or the name of a property,
+// and is empty for the document itself.
+type Violation struct {
+	Path    string
+	Message string
+}
+
+// Schema is a parsed JSON Schema.
+type Schema struct {
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *bool
+	items                *Schema
+	enum                 []any
+	minimum              *float64
+	maximum              *float64
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minItems             *int
+	maxItems             *int
+	format               string
+}
+
+// annotations are the keywords that don't constrain a document.
+var annotations = []string{"$schema", "$id", "$comment", "title", "description", "default", "examples"}
+
+var knownTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+// Parse parses the schema.
+func Parse(data []byte) (*Schema, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return parse(doc, "")
+}
+
+// MustParse parses the schema and panics when it's invalid.
+func MustParse(data []byte) *Schema {
+	s, err := Parse(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func parse(doc map[string]any, path string) (*Schema, error) {
+	var s Schema
+
+	invalid := func(keyword string) error {
+		return fmt.Errorf("schema%s: invalid %s", path, keyword)
+	}
+
+	for keyword, value := range doc {
+		switch keyword {
+		case "type":
+			switch v := value.(type) {
+			case string:
+				s.types = []string{v}
+			case []any:
+				for _, t := range v {
+					str, ok := t.(string)
+					if !ok {
+						return nil, invalid(keyword)
+					}
+					s.types = append(s.types, str)
+				}
+			default:
+				return nil, invalid(keyword)
+			}
+			for _, t := range s.types {
+				if !slices.Contains(knownTypes, t) {
+					return nil, fmt.Errorf("schema%s: unknown type %q", path, t)
+				}
+			}
+
+		case "properties":
+			props, ok := value.(map[string]any)
+			if !ok {
+				return nil, invalid(keyword)
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, prop := range props {
+				doc, ok := prop.(map[string]any)
+				if !ok {
+					return nil, invalid(keyword)
+				}
+				ps, err := parse(doc, path+"."+name)
+				if err != nil {
+					return nil, err
+				}
+				s.properties[name] = ps
+			}
+
+		case "required":
+			names, ok := value.([]any)
+			if !ok {
+				return nil, invalid(keyword)
+			}
+			for _, n := range names {
+				str, ok := n.(string)
+				if !ok {
+					return nil, invalid(keyword)
+				}
+				s.required = append(s.required, str)
+			}
+
+		case "additionalProperties":
+			b, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("schema%s: additionalProperties must be a boolean", path)
+			}
+			s.additionalProperties = &b
+
+		case "items":
+			doc, ok := value.(map[string]any)
+			if !ok {
+				return nil, invalid(keyword)
+			}
+			items, err := parse(doc, path+"[]")
+			if err != nil {
+				return nil, err
+			}
+			s.items = items
+
+		case "enum":
+			values, ok := value.([]any)
+			if !ok {
+				return nil, invalid(keyword)
+			}
+			s.enum = values
+
+		case "minimum", "maximum":
+			f, ok := value.(float64)
+			if !ok {
+				return nil, invalid(keyword)
+			}
+			if keyword == "minimum" {
+				s.minimum = &f
+			} else {
+				s.maximum = &f
+			}
+
+		case "minLength", "maxLength", "minItems", "maxItems":
+			f, ok := value.(float64)
+			if !ok || f < 0 || f != math.Trunc(f) {
+				return nil, invalid(keyword)
+			}
+			n := int(f)
+			switch keyword {
+			case "minLength":
+				s.minLength = &n
+			case "maxLength":
+				s.maxLength = &n
+			case "minItems":
+				s.minItems = &n
+			case "maxItems":
+				s.maxItems = &n
+			}
+
+		case "pattern":
+			str, ok := value.(string)
+			if !ok {
+				return nil, invalid(keyword)
+			}
+			re, err := regexp.Compile(str)
+			if err != nil {
+				return nil, fmt.Errorf("schema%s: pattern: %w", path, err)
+			}
+			s.pattern = re
+
+		case "format":
+			str, ok := value.(string)
+			if !ok || (str != "uuid" && str != "date-time") {
+				return nil, fmt.Errorf("schema%s: unsupported format %v", path, value)
+			}
+			s.format = str
+
+		default:
+			if !slices.Contains(annotations, keyword) {
+				return nil, fmt.Errorf("schema%s: unsupported keyword %q", path, keyword)
+			}
+		}
+	}
+
+	return &s, nil
+}
+
+// =============================================================================
+
+// Validate checks the document against the schema and returns every
+// violation found. An error is returned when the document isn't valid JSON.
+func (s *Schema) Validate(data []byte) ([]Violation, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	var vs []Violation
+	s.validate(doc, "", &vs)
+
+	return vs, nil
+}
+
+func (s *Schema) validate(value any, path string, vs *[]Violation) {
+	add := func(format string, args ...any) {
+		*vs = append(*vs, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	typ := typeOf(value)
+
+	if len(s.types) > 0 && !s.hasType(typ, value) {
+		if len(s.types) == 1 {
+			add("must be of type %s", s.types[0])
+		} else {
+			add("must be of type %s", strings.Join(s.types, " or "))
+		}
+		return
+	}
+
+	if s.enum != nil && !s.inEnum(value) {
+		values := make([]string, len(s.enum))
+		for i, v := range s.enum {
+			data, _ := json.Marshal(v)
+			values[i] = string(data)
+		}
+		add("must be one of %s", strings.Join(values, ", "))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.required {
+			if _, exists := v[name]; !exists {
+				*vs = append(*vs, Violation{Path: join(path, name), Message: "is required"})
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			prop, exists := s.properties[name]
+			switch {
+			case exists:
+				prop.validate(v[name], join(path, name), vs)
+			case s.additionalProperties != nil && !*s.additionalProperties:
+				*vs = append(*vs, Violation{Path: join(path, name), Message: "is not a known field"})
+			}
+		}
+
+	case []any:
+		if s.minItems != nil && len(v) < *s.minItems {
+			add("must hold at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			add("must hold at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, fmt.Sprintf("%s[%d]", path, i), vs)
+			}
+		}
+
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			add("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			add("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("must match the pattern %s", s.pattern)
+		}
+		switch s.format {
+		case "uuid":
+			if _, err := uuid.Parse(v); err != nil {
+				add("must be a uuid")
+			}
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				add("must be an RFC 3339 date-time")
+			}
+		}
+
+	case json.Number:
+		f, _ := v.Float64()
+		if s.minimum != nil && f < *s.minimum {
+			add("must be at least %s", strconv.FormatFloat(*s.minimum, 'f', -1, 64))
+		}
+		if s.maximum != nil && f > *s.maximum {
+			add("must be at most %s", strconv.FormatFloat(*s.maximum, 'f', -1, 64))
+		}
+	}
+}
+
+// hasType reports whether the value is of one of the types of the schema.
+// An integer is a number too.
+func (s *Schema) hasType(typ string, value any) bool {
+	for _, t := range s.types {
+		switch {
+		case t == typ:
+			return true
+		case t == "integer" && typ == "number" && isInteger(value.(json.Number)):
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Schema) inEnum(value any) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	for _, v := range s.enum {
+		exp, err := json.Marshal(v)
+		if err == nil && bytes.Equal(data, exp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+
+	return "null"
+}
+
+func isInteger(n json.Number) bool {
+	f, err := n.Float64()
+	return err == nil && f == math.Trunc(f)
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,135 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/jsonschema"
+)
+
+const schema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "NewProduct",
+	"type": "object",
+	"additionalProperties": false,
+	"required": ["name", "cost"],
+	"properties": {
+		"name": {"type": "string", "minLength": 3, "maxLength": 20, "pattern": "^[a-zA-Z ]+$"},
+		"cost": {"type": "string"},
+		"quantity": {"type": "integer", "minimum": 1, "maximum": 100},
+		"categoryID": {"type": ["string", "null"], "format": "uuid"},
+		"color": {"enum": ["red", "blue"]},
+		"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+		"dims": {"type": "object", "properties": {"width": {"type": "number"}}}
+	}
+}`
+
+func Test_Validate(t *testing.T) {
+	s := jsonschema.MustParse([]byte(schema))
+
+	tests := []struct {
+		name string
+		doc  string
+		exp  []jsonschema.Violation
+	}{
+		{
+			name: "valid",
+			doc:  `{"name":"Guitar","cost":"10.00","quantity":3,"categoryID":null,"color":"red","tags":["music"],"dims":{"width":1.5}}`,
+		},
+		{
+			name: "integer-as-float",
+			doc:  `{"name":"Guitar","cost":"10.00","quantity":3.0}`,
+		},
+		{
+			name: "missing-required",
+			doc:  `{"name":"Guitar"}`,
+			exp:  []jsonschema.Violation{{Path: "cost", Message: "is required"}},
+		},
+		{
+			name: "unknown-field",
+			doc:  `{"name":"Guitar","cost":"10.00","price":"10.00"}`,
+			exp:  []jsonschema.Violation{{Path: "price", Message: "is not a known field"}},
+		},
+		{
+			name: "type-mismatch",
+			doc:  `{"name":"Guitar","cost":10,"quantity":"3","categoryID":5}`,
+			exp: []jsonschema.Violation{
+				{Path: "categoryID", Message: "must be of type string or null"},
+				{Path: "cost", Message: "must be of type string"},
+				{Path: "quantity", Message: "must be of type integer"},
+			},
+		},
+		{
+			name: "fraction",
+			doc:  `{"name":"Guitar","cost":"10.00","quantity":1.5}`,
+			exp:  []jsonschema.Violation{{Path: "quantity", Message: "must be of type integer"}},
+		},
+		{
+			name: "constraints",
+			doc:  `{"name":"G1","cost":"10.00","quantity":0,"categoryID":"abc","color":"green","tags":["a",1,"c"]}`,
+			exp: []jsonschema.Violation{
+				{Path: "categoryID", Message: "must be a uuid"},
+				{Path: "color", Message: `must be one of "red", "blue"`},
+				{Path: "name", Message: "must be at least 3 characters long"},
+				{Path: "name", Message: "must match the pattern ^[a-zA-Z ]+$"},
+				{Path: "quantity", Message: "must be at least 1"},
+				{Path: "tags", Message: "must hold at most 2 items"},
+				{Path: "tags[1]", Message: "must be of type string"},
+			},
+		},
+		{
+			name: "nested",
+			doc:  `{"name":"Guitar","cost":"10.00","dims":{"width":"wide"}}`,
+			exp:  []jsonschema.Violation{{Path: "dims.width", Message: "must be of type number"}},
+		},
+		{
+			name: "not-an-object",
+			doc:  `[]`,
+			exp:  []jsonschema.Violation{{Path: "", Message: "must be of type object"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Validate([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Should be able to validate the document: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.exp) {
+				t.Errorf("Got: %v", got)
+				t.Errorf("Exp: %v", tt.exp)
+			}
+		})
+	}
+}
+
+func Test_ValidateInvalidJSON(t *testing.T) {
+	s := jsonschema.MustParse([]byte(schema))
+
+	if _, err := s.Validate([]byte(`{"name":`)); err == nil {
+		t.Fatal("Should not be able to validate a document that isn't JSON")
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"unsupported-keyword", `{"type":"object","oneOf":[]}`},
+		{"unknown-type", `{"type":"decimal"}`},
+		{"unsupported-format", `{"type":"string","format":"email"}`},
+		{"bad-pattern", `{"type":"string","pattern":"("}`},
+		{"nested", `{"type":"object","properties":{"name":{"type":"string","const":"a"}}}`},
+		{"schema-additional-properties", `{"type":"object","additionalProperties":{"type":"string"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := jsonschema.Parse([]byte(tt.schema)); err == nil {
+				t.Fatalf("Should not be able to parse the schema")
+			}
+		})
+	}
+}
@@ -17,20 +17,33 @@ import (
 // the specified context.
 type TraceIDFn func(ctx context.Context) string
 
+// RequestIDFn represents a function that can return the request id from
+// the specified context.
+type RequestIDFn func(ctx context.Context) string
+
 // Logger represents a logger for logging information.
 type Logger struct {
-	handler   slog.Handler
-	traceIDFn TraceIDFn
+	handler     slog.Handler
+	traceIDFn   TraceIDFn
+	requestIDFn RequestIDFn
 }
 
 // New constructs a new log for application use.
-func New(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn) *Logger {
-	return new(w, minLevel, serviceName, traceIDFn, Events{})
+func New(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, options ...func(log *Logger)) *Logger {
+	return new(w, minLevel, serviceName, traceIDFn, Events{}, options)
 }
 
 // NewWithEvents constructs a new log for application use with events.
-func NewWithEvents(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
-	return new(w, minLevel, serviceName, traceIDFn, events)
+func NewWithEvents(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events, options ...func(log *Logger)) *Logger {
+	return new(w, minLevel, serviceName, traceIDFn, events, options)
+}
+
+// WithRequestID adds the request id returned by fn to every log written with
+// a context holding one.
+func WithRequestID(fn RequestIDFn) func(log *Logger) {
+	return func(log *Logger) {
+		log.requestIDFn = fn
+	}
 }
 
 // NewWithHandler returns a new log for application use with the underlying
@@ -99,12 +112,18 @@ func (log *Logger) write(ctx context.Context, level Level, caller int, msg strin
 	if log.traceIDFn != nil {
 		args = append(args, "trace_id", log.traceIDFn(ctx))
 	}
+
+	if log.requestIDFn != nil {
+		if id := log.requestIDFn(ctx); id != "" {
+			args = append(args, "request_id", id)
+		}
+	}
 	r.Add(args...)
 
 	log.handler.Handle(ctx, r)
 }
 
-func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events) *Logger {
+func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, events Events, options []func(log *Logger)) *Logger {
 
 	// Convert the file name to just the name.ext when this key/value will
 	// be logged.
@@ -136,8 +155,14 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 	// Add those attributes and capture the final handler.
 	handler = handler.WithAttrs(attrs)
 
-	return &Logger{
+	log := Logger{
 		handler:   handler,
 		traceIDFn: traceIDFn,
 	}
+
+	for _, option := range options {
+		option(&log)
+	}
+
+	return &log
 }
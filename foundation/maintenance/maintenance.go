@@ -0,0 +1,32 @@
+// Package maintenance provides the switch putting a service in read-only mode
+// while it's being maintained, like during a migration.
+package maintenance
+
+import (
+	"sync/atomic"
+)
+
+// Mode reports whether the service is read-only. It can be flipped at any time
+// and is safe for concurrent use. The mode is kept in memory so every instance
+// of a service has to be flipped on its own.
+type Mode struct {
+	readOnly atomic.Bool
+}
+
+// New constructs a mode that starts read-only when readOnly is set.
+func New(readOnly bool) *Mode {
+	var m Mode
+	m.readOnly.Store(readOnly)
+
+	return &m
+}
+
+// ReadOnly reports whether writes must be rejected.
+func (m *Mode) ReadOnly() bool {
+	return m.readOnly.Load()
+}
+
+// SetReadOnly turns the read-only mode on or off.
+func (m *Mode) SetReadOnly(readOnly bool) {
+	m.readOnly.Store(readOnly)
+}
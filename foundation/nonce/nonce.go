@@ -0,0 +1,68 @@
+// Package nonce provides an in-memory record of the nonces already used, to
+// reject replayed requests.
+package nonce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired nonces are removed so the memory used
+// is bound by the nonces seen within their ttl.
+const sweepInterval = time.Minute
+
+// Memory remembers every nonce it's given until the ttl of the nonce
+// expires.
+type Memory struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemory constructs an empty record of nonces.
+func NewMemory() *Memory {
+	return &Memory{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// WithClock replaces the clock used by the record, for testing.
+func (m *Memory) WithClock(now func() time.Time) *Memory {
+	m.now = now
+	return m
+}
+
+// Use records the nonce for the ttl. False is returned when the nonce was
+// already used within its ttl.
+func (m *Memory) Use(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	if expires, exists := m.nonces[nonce]; exists && now.Before(expires) {
+		return false, nil
+	}
+
+	m.nonces[nonce] = now.Add(ttl)
+
+	return true, nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	for nonce, expires := range m.nonces {
+		if !now.Before(expires) {
+			delete(m.nonces, nonce)
+		}
+	}
+
+	m.lastSweep = now
+}
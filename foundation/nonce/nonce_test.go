@@ -0,0 +1,35 @@
+package nonce_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/nonce"
+)
+
+func Test_Memory(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	nonces := nonce.NewMemory().WithClock(clock)
+	ctx := context.Background()
+
+	if ok, _ := nonces.Use(ctx, "abc", time.Minute); !ok {
+		t.Fatal("Should accept a new nonce")
+	}
+
+	if ok, _ := nonces.Use(ctx, "abc", time.Minute); ok {
+		t.Fatal("Should reject a nonce used within its ttl")
+	}
+
+	if ok, _ := nonces.Use(ctx, "xyz", time.Minute); !ok {
+		t.Fatal("Should track each nonce separately")
+	}
+
+	now = now.Add(time.Minute)
+
+	if ok, _ := nonces.Use(ctx, "abc", time.Minute); !ok {
+		t.Fatal("Should accept a nonce again once its ttl expired")
+	}
+}
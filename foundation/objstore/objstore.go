@@ -0,0 +1,208 @@
+// Package objstore provides support for pre-signing requests to S3 compatible
+// object storage so clients can transfer objects without going through the
+// service. Requests are signed with AWS Signature Version 4 using query
+// parameters.
+package objstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxExpires is the longest time a pre-signed request can stay valid.
+const MaxExpires = 7 * 24 * time.Hour
+
+// Config represents the information required to sign requests for a bucket.
+type Config struct {
+	// BucketURL is the base url of the bucket. Both virtual hosted urls like
+	// https://bucket.s3.us-east-1.amazonaws.com and path style urls like
+	// http://minio:9000/bucket are supported.
+	BucketURL string
+
+	// PublicURL is the base url objects are served from once uploaded. The
+	// BucketURL is used when it's empty.
+	PublicURL string
+
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Signer pre-signs requests to the objects of a bucket.
+type Signer struct {
+	bucket    *url.URL
+	public    string
+	region    string
+	accessKey string
+	secretKey string
+}
+
+// New constructs a signer for the bucket in the configuration.
+func New(cfg Config) (*Signer, error) {
+	bucket, err := url.Parse(cfg.BucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse bucket url: %w", err)
+	}
+
+	if bucket.Scheme == "" || bucket.Host == "" {
+		return nil, fmt.Errorf("bucket url %q must be absolute", cfg.BucketURL)
+	}
+
+	if cfg.Region == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("region and credentials are required")
+	}
+
+	public := cfg.PublicURL
+	if public == "" {
+		public = cfg.BucketURL
+	}
+
+	s := Signer{
+		bucket:    bucket,
+		public:    strings.TrimSuffix(public, "/"),
+		region:    cfg.Region,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+	}
+
+	return &s, nil
+}
+
+// PresignPut returns a url that uploads the object with a PUT request. The
+// upload is only accepted with the specified Content-Type and Content-Length
+// headers since both are part of the signature.
+func (s *Signer) PresignPut(key string, contentType string, size int64, expires time.Duration, now time.Time) (string, error) {
+	headers := map[string]string{
+		"content-type":   contentType,
+		"content-length": strconv.FormatInt(size, 10),
+	}
+
+	return s.Presign("PUT", key, headers, expires, now)
+}
+
+// PublicURL returns the url the object is served from.
+func (s *Signer) PublicURL(key string) string {
+	return s.public + "/" + encodePath(key)
+}
+
+// Presign returns a url performing the request for the object. The headers
+// are signed and have to be sent with the request unchanged. Header names
+// are expected in lower case.
+func (s *Signer) Presign(method string, key string, headers map[string]string, expires time.Duration, now time.Time) (string, error) {
+	if expires <= 0 || expires > MaxExpires {
+		return "", fmt.Errorf("expires must be between 1s and %s", MaxExpires)
+	}
+
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+
+	signed := map[string]string{"host": s.bucket.Host}
+	for name, value := range headers {
+		signed[name] = strings.TrimSpace(value)
+	}
+
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.accessKey + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(expires.Seconds()))},
+		"X-Amz-SignedHeaders": {strings.Join(names, ";")},
+	}
+
+	path := strings.TrimSuffix(s.bucket.EscapedPath(), "/") + "/" + encodePath(key)
+	canonicalQuery := encodeQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		strings.Join(names, ";"),
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	u := *s.bucket
+	u.RawPath = path
+	u.Path, _ = url.PathUnescape(path)
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+
+	return u.String(), nil
+}
+
+// =============================================================================
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// encodePath escapes every segment of the key the way the signature expects,
+// keeping the slashes between them.
+func encodePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// encodeQuery encodes the values sorted by name as the canonical query.
+func encodeQuery(values url.Values) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var parts []string
+	for _, name := range names {
+		for _, value := range values[name] {
+			parts = append(parts, escape(name)+"="+escape(value))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// escape percent encodes everything but the unreserved characters of RFC
+// 3986, which is the encoding the signature is calculated over.
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
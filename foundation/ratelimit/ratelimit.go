@@ -0,0 +1,98 @@
+// Package ratelimit provides an in-memory token bucket rate limiter.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that refilled completely are removed so
+// the memory used is bound by the number of active keys.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Memory is a token bucket limiter that keeps one bucket per key in memory.
+// Each bucket holds up to burst tokens and is refilled at rate tokens per
+// second.
+type Memory struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemory constructs a limiter allowing rate requests per second per key
+// with bursts of up to burst requests.
+func NewMemory(rate float64, burst int) *Memory {
+	return &Memory{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// WithClock replaces the clock used by the limiter, for testing.
+func (m *Memory) WithClock(now func() time.Time) *Memory {
+	m.now = now
+	return m
+}
+
+// Allow takes a token from the bucket for the key. When no token is
+// available false is returned along with the time until one will be.
+func (m *Memory) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, exists := m.buckets[key]
+	if !exists {
+		b = &bucket{tokens: m.burst, last: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = m.refill(b, now)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+
+	if m.rate <= 0 {
+		return false, time.Duration(math.MaxInt64), nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / m.rate * float64(time.Second))
+
+	return false, wait, nil
+}
+
+func (m *Memory) refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.last).Seconds()*m.rate
+	return math.Min(tokens, m.burst)
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, b := range m.buckets {
+		if m.refill(b, now) >= m.burst {
+			delete(m.buckets, key)
+		}
+	}
+
+	m.lastSweep = now
+}
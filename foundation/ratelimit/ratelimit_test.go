@@ -0,0 +1,42 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/ratelimit"
+)
+
+func Test_Memory(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	lmt := ratelimit.NewMemory(1, 2).WithClock(clock)
+	ctx := context.Background()
+
+	for i := range 2 {
+		if ok, _, _ := lmt.Allow(ctx, "bill"); !ok {
+			t.Fatalf("Should allow request %d within the burst", i)
+		}
+	}
+
+	ok, wait, _ := lmt.Allow(ctx, "bill")
+	if ok {
+		t.Fatal("Should reject the request after the burst")
+	}
+
+	if wait != time.Second {
+		t.Errorf("Should wait one second, got %s", wait)
+	}
+
+	if ok, _, _ := lmt.Allow(ctx, "jill"); !ok {
+		t.Fatal("Should track each key separately")
+	}
+
+	now = now.Add(time.Second)
+
+	if ok, _, _ := lmt.Allow(ctx, "bill"); !ok {
+		t.Fatal("Should allow a request once a token is refilled")
+	}
+}
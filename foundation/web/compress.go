@@ -0,0 +1,195 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Compress returns a middleware that compresses the response body with gzip
+// or deflate when the client accepts it and the body is at least minSize
+// bytes. Bodies that are already compressed are sent as is. Responses the
+// handler writes itself, like streams, are never compressed so the
+// middleware can be attached to any route.
+func Compress(minSize int) MidFunc {
+	m := func(next HandlerFunc) HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) Encoder {
+			resp := next(ctx, r)
+
+			switch resp.(type) {
+			case nil, error, NoResponse, NotModified:
+				return resp
+			}
+
+			w := GetWriter(ctx)
+			if w == nil {
+				return resp
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				return resp
+			}
+
+			c := compressed{
+				Encoder:  resp,
+				w:        w,
+				encoding: encoding,
+				minSize:  minSize,
+			}
+
+			if _, ok := resp.(Negotiator); ok {
+				return negotiableCompressed{c}
+			}
+
+			return c
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// compressed wraps the encoder of a response so the encoded body is
+// compressed before it's written.
+type compressed struct {
+	Encoder
+	w        http.ResponseWriter
+	encoding string
+	minSize  int
+}
+
+// Encode implements the Encoder interface.
+func (c compressed) Encode() ([]byte, string, error) {
+	data, contentType, err := c.Encoder.Encode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(data) < c.minSize || isCompressed(contentType) || c.w.Header().Get("Content-Encoding") != "" {
+		return data, contentType, nil
+	}
+
+	var buf bytes.Buffer
+
+	var cw io.WriteCloser
+	switch c.encoding {
+	case "gzip":
+		cw = gzip.NewWriter(&buf)
+	default:
+		cw = zlib.NewWriter(&buf)
+	}
+
+	if _, err := cw.Write(data); err != nil {
+		return nil, "", fmt.Errorf("compress: write: %w", err)
+	}
+
+	if err := cw.Close(); err != nil {
+		return nil, "", fmt.Errorf("compress: close: %w", err)
+	}
+
+	c.w.Header().Set("Content-Encoding", c.encoding)
+	c.w.Header().Del("Content-Length")
+
+	return buf.Bytes(), contentType, nil
+}
+
+// HTTPStatus implements the httpStatus interface so the status of the
+// wrapped response is kept.
+func (c compressed) HTTPStatus() int {
+	if v, ok := c.Encoder.(httpStatus); ok {
+		return v.HTTPStatus()
+	}
+
+	return http.StatusOK
+}
+
+// negotiableCompressed keeps a response that supports content negotiation
+// negotiable once it's wrapped for compression.
+type negotiableCompressed struct {
+	compressed
+}
+
+// Negotiate implements the Negotiator interface so the representation picked
+// for the client is compressed as well.
+func (c negotiableCompressed) Negotiate(mediaType string) (Encoder, bool) {
+	enc, ok := c.Encoder.(Negotiator).Negotiate(mediaType)
+	if !ok {
+		return nil, false
+	}
+
+	cmp := c.compressed
+	cmp.Encoder = enc
+
+	return cmp, true
+}
+
+// negotiateEncoding returns the encoding to use based on the value of an
+// Accept-Encoding header. Gzip is preferred when both are equally accepted.
+// An empty string is returned when neither is acceptable.
+func negotiateEncoding(header string) string {
+	var best string
+	var bestQ float64
+
+	for _, v := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(v, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		switch coding {
+		case "gzip", "*":
+			coding = "gzip"
+		case "deflate":
+		default:
+			continue
+		}
+
+		if q > bestQ || (q == bestQ && coding == "gzip") {
+			best, bestQ = coding, q
+		}
+	}
+
+	return best
+}
+
+// isCompressed reports whether the content type is one that's already
+// compressed so compressing it again would only cost time.
+func isCompressed(contentType string) bool {
+	mt, _, _ := strings.Cut(contentType, ";")
+	mt = strings.ToLower(strings.TrimSpace(mt))
+
+	switch {
+	case strings.HasPrefix(mt, "image/") && mt != "image/svg+xml",
+		strings.HasPrefix(mt, "video/"),
+		strings.HasPrefix(mt, "audio/"):
+		return true
+	}
+
+	switch mt {
+	case "application/gzip", "application/x-gzip", "application/zip", "application/zstd", "application/x-bzip2":
+		return true
+	}
+
+	return false
+}
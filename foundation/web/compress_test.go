@@ -0,0 +1,94 @@
+package web_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type payload struct {
+	data        []byte
+	contentType string
+}
+
+func (p payload) Encode() ([]byte, string, error) {
+	return p.data, p.contentType, nil
+}
+
+func Test_Compress(t *testing.T) {
+	large := []byte(strings.Repeat("a", 2048))
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		resp           payload
+		expEncoding    string
+	}{
+		{name: "gzip", acceptEncoding: "gzip, deflate", resp: payload{large, "application/json"}, expEncoding: "gzip"},
+		{name: "deflate", acceptEncoding: "deflate", resp: payload{large, "application/json"}, expEncoding: "deflate"},
+		{name: "weighted", acceptEncoding: "gzip;q=0.5, deflate", resp: payload{large, "application/json"}, expEncoding: "deflate"},
+		{name: "refused", acceptEncoding: "gzip;q=0", resp: payload{large, "application/json"}},
+		{name: "none", resp: payload{large, "application/json"}},
+		{name: "tiny", acceptEncoding: "gzip", resp: payload{[]byte("{}"), "application/json"}},
+		{name: "compressed", acceptEncoding: "gzip", resp: payload{large, "image/png"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+			app := web.NewApp(log.Info, nil)
+
+			h := func(ctx context.Context, r *http.Request) web.Encoder {
+				return tt.resp
+			}
+			app.HandlerFunc(http.MethodGet, "", "/test", h, web.Compress(1024))
+
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+
+			app.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Should set the Vary header, got %q", got)
+			}
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.expEncoding {
+				t.Fatalf("Should use encoding %q, got %q", tt.expEncoding, got)
+			}
+
+			body := w.Body.Bytes()
+
+			switch tt.expEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("Should be able to read the gzip body: %s", err)
+				}
+				body, _ = io.ReadAll(zr)
+
+			case "deflate":
+				zr, err := zlib.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("Should be able to read the deflate body: %s", err)
+				}
+				body, _ = io.ReadAll(zr)
+			}
+
+			if !bytes.Equal(body, tt.resp.data) {
+				t.Errorf("Should get back the original body, got %d bytes", len(body))
+			}
+		})
+	}
+}
@@ -13,6 +13,9 @@ type ctxKey int
 const (
 	tracerKey ctxKey = iota + 1
 	writerKey
+	acceptKey
+	requestIDKey
+	timingKey
 )
 
 func setTracer(ctx context.Context, tracer trace.Tracer) context.Context {
@@ -35,6 +38,15 @@ func setWriter(ctx context.Context, w http.ResponseWriter) context.Context {
 	return context.WithValue(ctx, writerKey, w)
 }
 
+func setAccept(ctx context.Context, accept string) context.Context {
+	return context.WithValue(ctx, acceptKey, accept)
+}
+
+func getAccept(ctx context.Context) string {
+	v, _ := ctx.Value(acceptKey).(string)
+	return v
+}
+
 // GetWriter returns the underlying writer for the request.
 func GetWriter(ctx context.Context) http.ResponseWriter {
 	v, ok := ctx.Value(writerKey).(http.ResponseWriter)
@@ -44,3 +56,15 @@ func GetWriter(ctx context.Context) http.ResponseWriter {
 
 	return v
 }
+
+// SetRequestID stores the id correlating the logs of the request.
+func SetRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// GetRequestID returns the id correlating the logs of the request. An empty
+// string is returned when the context doesn't hold one.
+func GetRequestID(ctx context.Context) string {
+	v, _ := ctx.Value(requestIDKey).(string)
+	return v
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// NewETag constructs a strong entity tag from the specified parts. The same
+// parts always produce the same tag so every instance of a service agrees.
+func NewETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, ":")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NewWeakETag constructs a weak entity tag from the specified parts. A weak
+// tag marks responses that are equivalent without being byte for byte the
+// same.
+func NewWeakETag(parts ...string) string {
+	return "W/" + NewETag(parts...)
+}
+
+// MatchETag reports whether the value of an If-None-Match or If-Match header
+// matches the specified entity tag. The header can hold a list of tags or
+// the wildcard.
+func MatchETag(header string, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchWeakETag reports whether the value of an If-None-Match header matches
+// the specified entity tag using the weak comparison, where tags match
+// whether or not they're weak.
+func MatchWeakETag(header string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetHeader sets a header on the response for the current request.
+func SetHeader(ctx context.Context, key string, value string) {
+	if w := GetWriter(ctx); w != nil {
+		w.Header().Set(key, value)
+	}
+}
@@ -0,0 +1,36 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_MatchWeakETag(t *testing.T) {
+	etag := web.NewWeakETag("products", "1")
+	strong := web.NewETag("products", "1")
+
+	tt := []struct {
+		name   string
+		header string
+		exp    bool
+	}{
+		{name: "weak", header: etag, exp: true},
+		{name: "strong", header: strong, exp: true},
+		{name: "list", header: `"other", ` + etag, exp: true},
+		{name: "wildcard", header: "*", exp: true},
+		{name: "other", header: web.NewWeakETag("products", "2"), exp: false},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			if got := web.MatchWeakETag(test.header, etag); got != test.exp {
+				t.Errorf("Should match %q against %q: %t, got %t", test.header, etag, test.exp, got)
+			}
+		})
+	}
+
+	if web.MatchETag(etag, strong) {
+		t.Errorf("Should not match a weak tag using the strong comparison")
+	}
+}
@@ -0,0 +1,321 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Naming is a strategy for naming the fields of JSON documents.
+type Naming string
+
+// Set of naming strategies. NamingCamel is the naming the models are
+// declared with, so documents are only renamed for NamingSnake.
+const (
+	NamingCamel Naming = "camelCase"
+	NamingSnake Naming = "snake_case"
+)
+
+// NamingHeader is the request header a client sets to the naming strategy
+// it wants the response fields in.
+const NamingHeader = "X-Field-Naming"
+
+// ParseNaming parses the name of a naming strategy. An empty value is
+// NamingCamel.
+func ParseNaming(value string) (Naming, error) {
+	switch Naming(value) {
+	case "", NamingCamel:
+		return NamingCamel, nil
+	case NamingSnake:
+		return NamingSnake, nil
+	}
+
+	return "", fmt.Errorf("invalid naming %q, must be %s or %s", value, NamingCamel, NamingSnake)
+}
+
+// FieldNaming returns a middleware that renames the fields of the JSON
+// documents a handler produces to the naming strategy the client asks for in
+// the X-Field-Naming header, or to def when it doesn't ask or asks for a
+// strategy that doesn't exist. The fields of JSON request bodies are renamed
+// to camelCase whatever the header says, so a client can send either naming.
+// Only the field names change, values are left untouched. The middleware
+// must come after Compress so the body is renamed before it's compressed.
+func FieldNaming(def Naming) MidFunc {
+	m := func(next HandlerFunc) HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) Encoder {
+			if isJSONMediaType(r.Header.Get("Content-Type")) && r.Body != nil {
+				renameBody(r)
+			}
+
+			resp := next(ctx, r)
+
+			w := GetWriter(ctx)
+			if w == nil {
+				return resp
+			}
+
+			w.Header().Add("Vary", NamingHeader)
+
+			naming := def
+			if v := r.Header.Get(NamingHeader); v != "" {
+				if n, err := ParseNaming(v); err == nil {
+					naming = n
+				}
+			}
+
+			switch resp.(type) {
+			case nil, error, NoResponse, NotModified:
+				return resp
+			}
+
+			if naming != NamingSnake {
+				return resp
+			}
+
+			n := renamed{Encoder: resp, rename: toSnake}
+
+			if _, ok := resp.(Negotiator); ok {
+				return negotiableRenamed{n}
+			}
+
+			return n
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// renameBody replaces the body of the request with one holding the same
+// document with its fields in camelCase. A body that can't be read or isn't
+// valid JSON is handed to the handler as is so it reports the error.
+func renameBody(r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{err}))
+		return
+	}
+
+	if out, err := renameKeys(data, toCamel); err == nil {
+		data = out
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.ContentLength = int64(len(data))
+}
+
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
+
+// renamed wraps the encoder of a response so the fields of the encoded JSON
+// document are renamed. Other content types are sent as is.
+type renamed struct {
+	Encoder
+	rename func(string) string
+}
+
+// Encode implements the Encoder interface.
+func (n renamed) Encode() ([]byte, string, error) {
+	data, contentType, err := n.Encoder.Encode()
+	if err != nil || !isJSONMediaType(contentType) {
+		return data, contentType, err
+	}
+
+	data, err = renameKeys(data, n.rename)
+	if err != nil {
+		return nil, "", fmt.Errorf("naming: %w", err)
+	}
+
+	return data, contentType, nil
+}
+
+// HTTPStatus implements the httpStatus interface so the status of the
+// wrapped response is kept.
+func (n renamed) HTTPStatus() int {
+	if v, ok := n.Encoder.(httpStatus); ok {
+		return v.HTTPStatus()
+	}
+
+	return http.StatusOK
+}
+
+// negotiableRenamed keeps a response that supports content negotiation
+// negotiable once it's wrapped for renaming.
+type negotiableRenamed struct {
+	renamed
+}
+
+// Negotiate implements the Negotiator interface so the representation picked
+// for the client is renamed as well.
+func (n negotiableRenamed) Negotiate(mediaType string) (Encoder, bool) {
+	enc, ok := n.Encoder.(Negotiator).Negotiate(mediaType)
+	if !ok {
+		return nil, false
+	}
+
+	rn := n.renamed
+	rn.Encoder = enc
+
+	return rn, true
+}
+
+// isJSONMediaType reports whether the content type is JSON, like
+// application/json or a vendor type such as application/vnd.myapp.v2+json.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// =============================================================================
+
+// renameKeys rewrites the JSON document with every object key renamed,
+// nested objects included. The order of the keys and the values are kept
+// as they are.
+func renameKeys(data []byte, rename func(string) string) ([]byte, error) {
+	type frame struct {
+		object    bool
+		expectKey bool
+		count     int
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	var stack []*frame
+
+	top := func() *frame {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1]
+	}
+
+	// beginValue writes the separator for a value of an array. The values of
+	// an object get theirs with the key.
+	beginValue := func() {
+		if f := top(); f != nil && !f.object && f.count > 0 {
+			buf.WriteByte(',')
+		}
+	}
+
+	endValue := func() {
+		if f := top(); f != nil {
+			f.count++
+			f.expectKey = f.object
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '{', '[':
+				beginValue()
+				buf.WriteByte(byte(v))
+				stack = append(stack, &frame{object: v == '{', expectKey: v == '{'})
+
+			default:
+				buf.WriteByte(byte(v))
+				stack = stack[:len(stack)-1]
+				endValue()
+			}
+
+		default:
+			if f := top(); f != nil && f.expectKey {
+				if f.count > 0 {
+					buf.WriteByte(',')
+				}
+
+				key, err := json.Marshal(rename(v.(string)))
+				if err != nil {
+					return nil, err
+				}
+				buf.Write(key)
+				buf.WriteByte(':')
+				f.expectKey = false
+				continue
+			}
+
+			beginValue()
+
+			value, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(value)
+			endValue()
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// toSnake converts a camelCase name into snake_case. A run of capitals is
+// taken as an initialism, so userID becomes user_id and imageURL image_url.
+func toSnake(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// toCamel converts a snake_case name into camelCase. Initialisms aren't
+// restored, user_id becomes userId, which decodes into a userID field since
+// field names are matched without regard to case.
+func toCamel(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = b.Len() > 0
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type namingBody struct {
+	UserID      string `json:"userID"`
+	ImageURL    string `json:"imageURL"`
+	DateCreated string `json:"dateCreated"`
+}
+
+func (n *namingBody) Decode(data []byte) error {
+	return json.Unmarshal(data, n)
+}
+
+func Test_FieldNaming(t *testing.T) {
+	body := `{"userID":"1","imageURL":"a_b","dateCreated":"x","tags":[{"tagName":"t"},2,null,true]}`
+
+	tests := []struct {
+		name   string
+		def    web.Naming
+		header string
+		exp    string
+	}{
+		{name: "default", def: web.NamingCamel, exp: body},
+		{name: "snake-default", def: web.NamingSnake, exp: `{"user_id":"1","image_url":"a_b","date_created":"x","tags":[{"tag_name":"t"},2,null,true]}`},
+		{name: "snake-header", def: web.NamingCamel, header: "snake_case", exp: `{"user_id":"1","image_url":"a_b","date_created":"x","tags":[{"tag_name":"t"},2,null,true]}`},
+		{name: "camel-header", def: web.NamingSnake, header: "camelCase", exp: body},
+		{name: "unknown-header", def: web.NamingCamel, header: "kebab-case", exp: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+			app := web.NewApp(log.Info, nil)
+
+			h := func(ctx context.Context, r *http.Request) web.Encoder {
+				return payload{[]byte(body), "application/json"}
+			}
+			app.HandlerFunc(http.MethodGet, "", "/test", h, web.FieldNaming(tt.def))
+
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				r.Header.Set(web.NamingHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			app.ServeHTTP(w, r)
+
+			if got := w.Body.String(); got != tt.exp {
+				t.Errorf("Should get back the renamed document:\n got: %s\n exp: %s", got, tt.exp)
+			}
+
+			if v := w.Header().Get("Vary"); v != web.NamingHeader {
+				t.Errorf("Should vary on the naming header, got %q", v)
+			}
+		})
+	}
+}
+
+func Test_FieldNamingRequest(t *testing.T) {
+	for _, body := range []string{
+		`{"userID":"1","imageURL":"a","dateCreated":"x"}`,
+		`{"user_id":"1","image_url":"a","date_created":"x"}`,
+	} {
+		log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+		app := web.NewApp(log.Info, nil)
+
+		var got namingBody
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if err := web.Decode(r, &got); err != nil {
+				t.Fatalf("Should be able to decode %s: %s", body, err)
+			}
+			return nil
+		}
+		app.HandlerFunc(http.MethodPost, "", "/test", h, web.FieldNaming(web.NamingCamel))
+
+		r := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		app.ServeHTTP(w, r)
+
+		exp := namingBody{UserID: "1", ImageURL: "a", DateCreated: "x"}
+		if got != exp {
+			t.Errorf("Should decode %s into %+v, got %+v", body, exp, got)
+		}
+	}
+}
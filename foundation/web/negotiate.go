@@ -0,0 +1,93 @@
+package web
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Negotiator is implemented by responses that offer representations other
+// than the default one returned by Encode. Negotiate returns the encoder for
+// the specified media type or false when the response can't be represented
+// in that media type.
+type Negotiator interface {
+	Negotiate(mediaType string) (Encoder, bool)
+}
+
+// negotiate picks the representation of the response that best matches the
+// Accept header. The response is returned as is when it doesn't support
+// negotiation or the client accepts anything. False is returned when none
+// of the media types the client accepts can be provided.
+func negotiate(accept string, resp Encoder) (Encoder, bool) {
+	n, ok := resp.(Negotiator)
+	if !ok || strings.TrimSpace(accept) == "" {
+		return resp, true
+	}
+
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if mediaType == "*/*" {
+			return resp, true
+		}
+
+		if enc, ok := n.Negotiate(mediaType); ok {
+			return enc, true
+		}
+	}
+
+	return nil, false
+}
+
+// acceptedMediaTypes returns the media types listed in an Accept header
+// ordered by preference. Media types with a quality of zero are dropped.
+func acceptedMediaTypes(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+
+	var ranges []mediaRange
+	for _, v := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(v, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				f = 0
+			}
+			q = f
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+
+	slices.SortStableFunc(ranges, func(a, b mediaRange) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	mediaTypes := make([]string, len(ranges))
+	for i, r := range ranges {
+		mediaTypes[i] = r.mediaType
+	}
+
+	return mediaTypes
+}
@@ -0,0 +1,79 @@
+package web_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+const versionedType = "application/vnd.test.v2+json"
+
+type versioned struct{}
+
+func (versioned) Encode() ([]byte, string, error) {
+	return []byte(`{"v":1}`), "application/json", nil
+}
+
+func (v versioned) Negotiate(mediaType string) (web.Encoder, bool) {
+	switch mediaType {
+	case "application/json":
+		return v, true
+	case versionedType:
+		return payload{[]byte(`{"v":2}`), versionedType}, true
+	}
+
+	return nil, false
+}
+
+func Test_Negotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		accept         string
+		expStatus      int
+		expContentType string
+	}{
+		{name: "default", expStatus: http.StatusOK, expContentType: "application/json"},
+		{name: "any", accept: "*/*", expStatus: http.StatusOK, expContentType: "application/json"},
+		{name: "versioned", accept: versionedType, expStatus: http.StatusOK, expContentType: versionedType},
+		{name: "preference", accept: "application/json;q=0.5, " + versionedType, expStatus: http.StatusOK, expContentType: versionedType},
+		{name: "fallback", accept: "application/vnd.test.v3+json, application/json;q=0.1", expStatus: http.StatusOK, expContentType: "application/json"},
+		{name: "unknown", accept: "application/vnd.test.v3+json", expStatus: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+			app := web.NewApp(log.Info, nil)
+
+			h := func(ctx context.Context, r *http.Request) web.Encoder {
+				return versioned{}
+			}
+			app.HandlerFunc(http.MethodGet, "", "/test", h, web.Compress(1024))
+
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.expStatus {
+				t.Fatalf("Should get status %d, got %d", tt.expStatus, w.Code)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tt.expContentType {
+				t.Errorf("Should get content type %q, got %q", tt.expContentType, got)
+			}
+
+			if got := w.Header().Values("Vary"); len(got) != 2 || got[1] != "Accept" {
+				t.Errorf("Should vary on Accept, got %v", got)
+			}
+		})
+	}
+}
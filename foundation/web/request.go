@@ -1,9 +1,13 @@
 package web
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"strings"
 )
 
 // Param returns the web call parameters from the request.
@@ -11,6 +15,19 @@ func Param(r *http.Request, key string) string {
 	return r.PathValue(key)
 }
 
+// Accepts reports whether the Accept header of the request lists the
+// specified media type. Parameters such as quality values are ignored.
+func Accepts(r *http.Request, mediaType string) bool {
+	for _, v := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, _ := strings.Cut(v, ";")
+		if strings.EqualFold(strings.TrimSpace(mt), mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Decoder represents data that can be decoded.
 type Decoder interface {
 	Decode(data []byte) error
@@ -41,3 +58,58 @@ func Decode(r *http.Request, v Decoder) error {
 
 	return nil
 }
+
+// UnknownFieldError is returned by DecodeStrict for a body holding a field
+// the data model doesn't have.
+type UnknownFieldError struct {
+	Field string
+}
+
+// Error implements the error interface.
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
+
+// DecodeStrict works like Decode but rejects a JSON body holding a field the
+// data model doesn't have with an UnknownFieldError, so a misspelled field
+// isn't silently dropped. Fields are matched like the json package does,
+// without regard to case. Bodies that aren't valid JSON are left for the
+// data model to reject.
+func DecodeStrict(r *http.Request, v Decoder) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("request: unable to read payload: %w", err)
+	}
+
+	if err := checkUnknownFields(data, v); err != nil {
+		return fmt.Errorf("request: decode: %w", err)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	return Decode(r, v)
+}
+
+// checkUnknownFields decodes the data into a new value of the type of the
+// data model, which is left untouched, to find the fields it doesn't have.
+func checkUnknownFields(data []byte, v Decoder) error {
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Pointer {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(reflect.New(t.Elem()).Interface())
+	if err == nil {
+		return nil
+	}
+
+	// The json package doesn't export the error of an unknown field.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &UnknownFieldError{Field: strings.Trim(field, `"`)}
+	}
+
+	return nil
+}
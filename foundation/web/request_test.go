@@ -0,0 +1,61 @@
+package web_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type strictBody struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+func (s *strictBody) Decode(data []byte) error {
+	return json.Unmarshal(data, s)
+}
+
+func Test_DecodeStrict(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+		exp   strictBody
+	}{
+		{name: "known", body: `{"name":"Guitar","quantity":2}`, exp: strictBody{Name: "Guitar", Quantity: 2}},
+		{name: "case", body: `{"Name":"Guitar"}`, exp: strictBody{Name: "Guitar"}},
+		{name: "unknown", body: `{"name":"Guitar","quanity":2}`, field: "quanity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(tt.body))
+
+			var got strictBody
+			err := web.DecodeStrict(r, &got)
+
+			if tt.field != "" {
+				var ufe *web.UnknownFieldError
+				if !errors.As(err, &ufe) {
+					t.Fatalf("Should get an unknown field error: %v", err)
+				}
+				if ufe.Field != tt.field {
+					t.Errorf("Should name the field: got %q, exp %q", ufe.Field, tt.field)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Should be able to decode: %s", err)
+			}
+			if got != tt.exp {
+				t.Errorf("Should decode the body: got %+v, exp %+v", got, tt.exp)
+			}
+		})
+	}
+}
@@ -2,9 +2,13 @@ package web
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -23,13 +27,34 @@ func (NoResponse) Encode() ([]byte, string, error) {
 	return nil, "", nil
 }
 
+// NotModified tells the Respond function to reply with a 304 and no body
+// since the client already holds the current representation.
+type NotModified struct{}
+
+// NewNotModified constructs a not modified value.
+func NewNotModified() NotModified {
+	return NotModified{}
+}
+
+// Encode implements the Encoder interface.
+func (NotModified) Encode() ([]byte, string, error) {
+	return nil, "", nil
+}
+
+// HTTPStatus implements the httpStatus interface.
+func (NotModified) HTTPStatus() int {
+	return http.StatusNotModified
+}
+
 // =============================================================================
 
 type httpStatus interface {
 	HTTPStatus() int
 }
 
-// Respond sends a response to the client.
+// Respond sends a response to the client. The time spent encoding the
+// response is recorded and the phases timed while serving the request are
+// reported in the Server-Timing header.
 func Respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 	if _, ok := resp.(NoResponse); ok {
 		return nil
@@ -61,18 +86,35 @@ func Respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 	_, span := addSpan(ctx, "web.send.response", attribute.Int("status", statusCode))
 	defer span.End()
 
-	if statusCode == http.StatusNoContent {
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		setServerTiming(ctx, w)
 		w.WriteHeader(statusCode)
 		return nil
 	}
 
+	if _, ok := resp.(Negotiator); ok {
+		w.Header().Add("Vary", "Accept")
+
+		var acceptable bool
+		if resp, acceptable = negotiate(getAccept(ctx), resp); !acceptable {
+			setServerTiming(ctx, w)
+			w.WriteHeader(http.StatusNotAcceptable)
+			return nil
+		}
+	}
+
+	start := time.Now()
 	data, contentType, err := resp.Encode()
+	AddTiming(ctx, TimingSerialize, time.Since(start))
+
 	if err != nil {
+		setServerTiming(ctx, w)
 		w.WriteHeader(http.StatusInternalServerError)
 		return fmt.Errorf("respond: encode: %w", err)
 	}
 
 	w.Header().Set("Content-Type", contentType)
+	setServerTiming(ctx, w)
 	w.WriteHeader(statusCode)
 
 	if _, err := w.Write(data); err != nil {
@@ -81,3 +123,134 @@ func Respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 
 	return nil
 }
+
+// RespondCSV streams the specified rows to the client as comma separated
+// values. Each row is written as it is produced so the full document is never
+// held in memory. The filename is suggested to the client through the
+// Content-Disposition header.
+func RespondCSV(ctx context.Context, w http.ResponseWriter, filename string, header []string, rows iter.Seq[[]string]) error {
+	_, span := addSpan(ctx, "web.send.csv", attribute.Int("status", http.StatusOK))
+	defer span.End()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	setServerTiming(ctx, w)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("respondcsv: header: %w", err)
+	}
+
+	for row := range rows {
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("respondcsv: row: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("respondcsv: flush: %w", err)
+	}
+
+	return nil
+}
+
+// ndjsonFlushEvery is the number of values written between flushes so the
+// client receives the stream progressively.
+const ndjsonFlushEvery = 100
+
+// RespondNDJSON streams the specified values to the client as newline
+// delimited JSON. Each value is written as it is produced so the full
+// document is never held in memory. The stream stops at the first error
+// produced by the sequence, which is returned.
+func RespondNDJSON[T any](ctx context.Context, w http.ResponseWriter, values iter.Seq2[T, error]) error {
+	_, span := addSpan(ctx, "web.send.ndjson", attribute.Int("status", http.StatusOK))
+	defer span.End()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	setServerTiming(ctx, w)
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	var n int
+	for v, err := range values {
+		if err != nil {
+			return fmt.Errorf("respondndjson: value: %w", err)
+		}
+
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("respondndjson: encode: %w", err)
+		}
+
+		n++
+		if flusher != nil && n%ndjsonFlushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// RespondJSONArray streams the specified values to the client as a single
+// JSON array. Like RespondNDJSON the values are written as they are produced
+// and the stream stops at the first error produced by the sequence. The
+// array is left unterminated in that case so the client can't mistake it
+// for a complete document.
+func RespondJSONArray[T any](ctx context.Context, w http.ResponseWriter, values iter.Seq2[T, error]) error {
+	_, span := addSpan(ctx, "web.send.jsonarray", attribute.Int("status", http.StatusOK))
+	defer span.End()
+
+	w.Header().Set("Content-Type", "application/json")
+	setServerTiming(ctx, w)
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+
+	if _, err := w.Write([]byte("[")); err != nil {
+		return fmt.Errorf("respondjsonarray: open: %w", err)
+	}
+
+	var n int
+	for v, err := range values {
+		if err != nil {
+			return fmt.Errorf("respondjsonarray: value: %w", err)
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("respondjsonarray: encode: %w", err)
+		}
+
+		if n > 0 {
+			data = append([]byte(","), data...)
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("respondjsonarray: write: %w", err)
+		}
+
+		n++
+		if flusher != nil && n%ndjsonFlushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if _, err := w.Write([]byte("]")); err != nil {
+		return fmt.Errorf("respondjsonarray: close: %w", err)
+	}
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_RespondJSONArray(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	values := func(yield func(int, error) bool) {
+		for i := range 3 {
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+
+	if err := web.RespondJSONArray(context.Background(), w, values); err != nil {
+		t.Fatalf("Should be able to stream the values: %s", err)
+	}
+
+	if body := w.Body.String(); body != "[0,1,2]" {
+		t.Errorf("Should write the values as an array, got %q", body)
+	}
+}
+
+func Test_RespondJSONArrayError(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	values := func(yield func(int, error) bool) {
+		if !yield(1, nil) {
+			return
+		}
+		yield(0, errors.New("query failed"))
+	}
+
+	if err := web.RespondJSONArray(context.Background(), w, values); err == nil {
+		t.Fatal("Should get back the error of the sequence")
+	}
+
+	if body := w.Body.String(); body != "[1" {
+		t.Errorf("Should leave the array unterminated, got %q", body)
+	}
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Event represents a server-sent event. Name is sent as the event type when
+// it's set.
+type Event struct {
+	ID   string
+	Name string
+	Data []byte
+}
+
+// RespondSSE streams the events as server-sent events until the channel is
+// closed or the context is done, like when the client goes away. A comment
+// is sent every heartbeat while no events are so proxies don't close the
+// idle connection. The write deadline of the server is lifted since the
+// stream is expected to outlive it.
+func RespondSSE(ctx context.Context, w http.ResponseWriter, events <-chan Event, heartbeat time.Duration) error {
+	_, span := addSpan(ctx, "web.send.sse", attribute.Int("status", http.StatusOK))
+	defer span.End()
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	setServerTiming(ctx, w)
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return fmt.Errorf("respondsse: flush: %w", err)
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return fmt.Errorf("respondsse: heartbeat: %w", err)
+			}
+
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if err := writeEvent(w, event); err != nil {
+				return fmt.Errorf("respondsse: event: %w", err)
+			}
+
+			ticker.Reset(heartbeat)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return fmt.Errorf("respondsse: flush: %w", err)
+		}
+	}
+}
+
+// writeEvent writes the event in the text/event-stream format. Every line
+// of the data is sent as its own data field so the data can hold newlines.
+func writeEvent(w io.Writer, event Event) error {
+	var buf bytes.Buffer
+
+	if event.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", event.ID)
+	}
+
+	if event.Name != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event.Name)
+	}
+
+	for line := range bytes.SplitSeq(event.Data, []byte("\n")) {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,53 @@
+package web_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_RespondSSE(t *testing.T) {
+	w := httptest.NewRecorder()
+	events := make(chan web.Event)
+
+	go func() {
+		events <- web.Event{ID: "1", Name: "product.created", Data: []byte(`{"a":1}`)}
+		events <- web.Event{Data: []byte("line1\nline2")}
+		time.Sleep(50 * time.Millisecond)
+		close(events)
+	}()
+
+	if err := web.RespondSSE(context.Background(), w, events, 10*time.Millisecond); err != nil {
+		t.Fatalf("Should be able to stream the events: %s", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Should set the event stream content type, got %q", ct)
+	}
+
+	body := w.Body.String()
+
+	exp := "id: 1\nevent: product.created\ndata: {\"a\":1}\n\ndata: line1\ndata: line2\n\n"
+	if !strings.HasPrefix(body, exp) {
+		t.Errorf("Should write the events in order, got %q", body)
+	}
+
+	if !strings.Contains(body, ": heartbeat\n\n") {
+		t.Errorf("Should send heartbeats while idle, got %q", body)
+	}
+}
+
+func Test_RespondSSECanceled(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := web.RespondSSE(ctx, w, make(chan web.Event), time.Minute); err != nil {
+		t.Fatalf("Should stop quietly once the client is gone: %s", err)
+	}
+}
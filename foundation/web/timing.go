@@ -0,0 +1,95 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Set of phases reported in the Server-Timing header.
+const (
+	TimingAuth      = "auth"
+	TimingDB        = "db"
+	TimingSerialize = "serialize"
+)
+
+// timings accumulates the time spent in each phase of a request. A phase
+// can be recorded many times, like one database call per query, and the
+// durations add up. Phases are reported in the order first recorded.
+type timings struct {
+	mu     sync.Mutex
+	names  []string
+	phases map[string]time.Duration
+}
+
+func newTimings() *timings {
+	return &timings{
+		phases: make(map[string]time.Duration),
+	}
+}
+
+func (t *timings) add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, exists := t.phases[name]; !exists {
+		t.names = append(t.names, name)
+	}
+	t.phases[name] += d
+}
+
+// header returns the value of the Server-Timing header with the durations in
+// milliseconds as the header specifies.
+func (t *timings) header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	metrics := make([]string, len(t.names))
+	for i, name := range t.names {
+		metrics[i] = fmt.Sprintf("%s;dur=%.3f", name, float64(t.phases[name])/float64(time.Millisecond))
+	}
+
+	return strings.Join(metrics, ", ")
+}
+
+func setTimings(ctx context.Context, t *timings) context.Context {
+	return context.WithValue(ctx, timingKey, t)
+}
+
+func getTimings(ctx context.Context) *timings {
+	v, _ := ctx.Value(timingKey).(*timings)
+	return v
+}
+
+// AddTiming adds the duration to the time spent in the named phase of the
+// request. Nothing is recorded when the context doesn't belong to a request
+// served by the App, like in background work.
+func AddTiming(ctx context.Context, name string, d time.Duration) {
+	if t := getTimings(ctx); t != nil {
+		t.add(name, d)
+	}
+}
+
+// TimeSince adds the time elapsed since start to the named phase of the
+// request. It's meant to be deferred:
+//
+//	defer web.TimeSince(ctx, web.TimingDB, time.Now())
+func TimeSince(ctx context.Context, name string, start time.Time) {
+	AddTiming(ctx, name, time.Since(start))
+}
+
+// setServerTiming sets the Server-Timing header from the phases recorded so
+// far. It must be called before the header is written.
+func setServerTiming(ctx context.Context, w http.ResponseWriter) {
+	t := getTimings(ctx)
+	if t == nil {
+		return
+	}
+
+	if v := t.header(); v != "" {
+		w.Header().Set("Server-Timing", v)
+	}
+}
@@ -0,0 +1,43 @@
+package web_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_ServerTiming(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	app := web.NewApp(log.Info, nil)
+
+	auth := func(next web.HandlerFunc) web.HandlerFunc {
+		return func(ctx context.Context, r *http.Request) web.Encoder {
+			web.AddTiming(ctx, web.TimingAuth, 2*time.Millisecond)
+			return next(ctx, r)
+		}
+	}
+
+	h := func(ctx context.Context, r *http.Request) web.Encoder {
+		web.AddTiming(ctx, web.TimingDB, time.Millisecond)
+		web.AddTiming(ctx, web.TimingDB, 1500*time.Microsecond)
+		return payload{[]byte("{}"), "application/json"}
+	}
+	app.HandlerFunc(http.MethodGet, "", "/test", h, auth)
+
+	r := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+
+	app.ServeHTTP(w, r)
+
+	exp := regexp.MustCompile(`^auth;dur=2\.000, db;dur=2\.500, serialize;dur=[0-9]+\.[0-9]{3}$`)
+	if got := w.Header().Get("Server-Timing"); !exp.MatchString(got) {
+		t.Fatalf("Should report the time of every phase, got %q", got)
+	}
+}
@@ -105,7 +105,8 @@ func (a *App) corsHandler(webHandler HandlerFunc) HandlerFunc {
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "POST, PATCH, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		return webHandler(ctx, r)
@@ -120,6 +121,7 @@ func (a *App) corsHandler(webHandler HandlerFunc) HandlerFunc {
 func (a *App) HandlerFuncNoMid(method string, group string, path string, handlerFunc HandlerFunc) {
 	h := func(w http.ResponseWriter, r *http.Request) {
 		ctx := setWriter(r.Context(), w)
+		ctx = setAccept(ctx, r.Header.Get("Accept"))
 
 		resp := handlerFunc(ctx, r)
 
@@ -151,6 +153,8 @@ func (a *App) HandlerFunc(method string, group string, path string, handlerFunc
 	h := func(w http.ResponseWriter, r *http.Request) {
 		ctx := setTracer(r.Context(), a.tracer)
 		ctx = setWriter(ctx, w)
+		ctx = setAccept(ctx, r.Header.Get("Accept"))
+		ctx = setTimings(ctx, newTimings())
 
 		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(w.Header()))
 
@@ -190,6 +194,7 @@ func (a *App) RawHandlerFunc(method string, group string, path string, rawHandle
 	h := func(w http.ResponseWriter, r *http.Request) {
 		ctx := setTracer(r.Context(), a.tracer)
 		ctx = setWriter(ctx, w)
+		ctx = setTimings(ctx, newTimings())
 
 		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(w.Header()))
 